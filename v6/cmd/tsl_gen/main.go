@@ -0,0 +1,83 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command tsl_gen compiles a TSL filter into a typed Go predicate function.
+//
+// It is meant to run from go:generate, next to the struct type:
+//
+//	//go:generate tsl_gen -type Book -name BigBooks -i "spec.pages > 100 and author = 'Joe'"
+//
+// The generated file, bigbooks_tsl.go, declares:
+//
+//	func BigBooks(b *Book) bool
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/gocode"
+)
+
+func check(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	// Setup the input.
+	inputPtr := flag.String("i", "", "the tsl filter to compile (e.g. \"spec.pages > 100\")")
+	typePtr := flag.String("type", "", "the struct type the filter applies to (e.g. Book)")
+	namePtr := flag.String("name", "", "the name of the generated function (default Match<type>)")
+	dirPtr := flag.String("dir", ".", "the directory of the package declaring the type")
+	outputPtr := flag.String("o", "", "the output file (default <name>_tsl.go in the package directory)")
+	flag.Parse()
+
+	// Sanity check.
+	if *inputPtr == "" {
+		log.Fatal("missing required flag -i (the tsl filter to compile)")
+	}
+	if *typePtr == "" {
+		log.Fatal("missing required flag -type (the struct type the filter applies to)")
+	}
+	if *namePtr == "" {
+		*namePtr = "Match" + *typePtr
+	}
+	if *outputPtr == "" {
+		*outputPtr = filepath.Join(*dirPtr, strings.ToLower(*namePtr)+"_tsl.go")
+	}
+
+	// Read the struct fields from the package source.
+	pkg, s, err := gocode.LoadStruct(*dirPtr, *typePtr)
+	check(err)
+
+	// Parse input string into a TSL tree.
+	tree, err := tsl.ParseTSL(*inputPtr)
+	check(err)
+
+	// Compile the tree into a Go function.
+	f, err := gocode.Walk(tree, *namePtr, s)
+	check(err)
+
+	src, err := gocode.File(pkg, f)
+	check(err)
+
+	check(os.WriteFile(*outputPtr, src, 0o644))
+}
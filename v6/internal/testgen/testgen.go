@@ -0,0 +1,109 @@
+// Package testgen creates random TSL filters and records for the property
+// tests of the walkers.
+package testgen
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Generator creates random filters on the fields of Records
+type Generator struct {
+	r *rand.Rand
+}
+
+// NewGenerator creates a new generator, the same seed always produces the
+// same filters
+func NewGenerator(seed int64) *Generator {
+	return &Generator{r: rand.New(rand.NewSource(seed))}
+}
+
+func (g *Generator) pick(options ...string) string {
+	return options[g.r.Intn(len(options))]
+}
+
+// constant returns a small number literal
+func (g *Generator) constant() string {
+	return fmt.Sprint(g.r.Intn(5))
+}
+
+// Number returns a random number expression, nesting arithmetic up to depth
+func (g *Generator) Number(depth int) string {
+	if depth <= 0 || g.r.Intn(3) == 0 {
+		return g.pick("n", "m", g.constant(), g.constant())
+	}
+	switch g.r.Intn(4) {
+	case 0:
+		return "-(" + g.Number(depth-1) + ")"
+	case 1:
+		return "(" + g.Number(depth-1) + " " + g.pick("+", "-", "*") + " " + g.Number(depth-1) + ")"
+	case 2:
+		return "(" + g.Number(depth-1) + " " + g.pick("/", "%") + " " + fmt.Sprint(g.r.Intn(3)+1) + ")"
+	}
+	return "len tags"
+}
+
+// Boolean returns a random filter, nesting AND, OR and NOT up to depth
+func (g *Generator) Boolean(depth int) string {
+	if depth <= 0 || g.r.Intn(4) == 0 {
+		switch g.r.Intn(12) {
+		case 0:
+			return g.pick("true", "false", "f", "f = true", "f != false", "1 < 2")
+		case 1:
+			return "s " + g.pick("in", "not in") + " [" + g.pick("'a'", "'b'", "'a', 'b'", "1", "") + "]"
+		case 2:
+			return "n " + g.pick("in", "not in") + " [" + g.pick(g.constant(), g.constant()+", "+g.constant(), "'a'") + "]"
+		case 3:
+			return "s " + g.pick("like 'a%'", "ilike 'A%'", "~= 'b'", "~! 'b'", "= 'a'", "= 'ab'", "!= 'a'")
+		case 4:
+			return g.pick("n", "m") + " between " + g.constant() + " and " + fmt.Sprint(g.r.Intn(5)+2)
+		case 5:
+			return g.pick("any", "all") + " (nums " + g.pick(">", "<", "=") + " " + g.constant() + ")"
+		case 6:
+			return g.pick("n", "m", "s", "f") + " is " + g.pick("null", "not null")
+		case 7:
+			return g.Number(depth-1) + " " + g.pick("=", "!=", "<", "<=", ">", ">=") + " " + g.Number(depth-1)
+		case 8:
+			return g.constant() + " " + g.pick("=", "!=", "<", "<=", ">", ">=") + " " + g.pick("n", "m")
+		}
+		return g.pick("n", "m") + " " + g.pick("=", "!=", "<", "<=", ">", ">=") + " " + g.pick("", "-") + g.constant()
+	}
+
+	switch g.r.Intn(4) {
+	case 0:
+		return "not (" + g.Boolean(depth-1) + ")"
+	case 1:
+		return "(" + g.Boolean(depth-1) + " or " + g.Boolean(depth-1) + ")"
+	}
+	return "(" + g.Boolean(depth-1) + " and " + g.Boolean(depth-1) + ")"
+}
+
+// Records returns all the combinations of some values of the fields the
+// filters use, including null values
+func Records() []map[string]interface{} {
+	var all []map[string]interface{}
+	for _, n := range []interface{}{nil, -1.0, 0.0, 1.5, 2.0, 7.0} {
+		for _, m := range []interface{}{nil, 0.0, 5.0} {
+			for _, s := range []interface{}{nil, "a", "ab", "b"} {
+				for _, f := range []interface{}{nil, true, false} {
+					for _, nums := range [][]interface{}{{}, {1.0, 4.0}} {
+						all = append(all, map[string]interface{}{
+							"n": n, "m": m, "s": s, "f": f,
+							"nums": nums, "tags": []interface{}{"x"},
+						})
+					}
+				}
+			}
+		}
+	}
+	return all
+}
+
+// Eval returns an evaluation function for the semantics walker reading the
+// fields of a record
+func Eval(record map[string]interface{}) func(name string) (interface{}, bool) {
+	return func(name string) (interface{}, bool) {
+		value, ok := record[name]
+		return value, ok
+	}
+}
@@ -0,0 +1,25 @@
+// Package tsltest holds the assertions shared by the tests of the packages
+// that create TSL trees.
+package tsltest
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// ExpectTree checks that tree equals the tree parsed from the TSL phrase
+func ExpectTree(tree *tsl.TSLNode, phrase string) {
+	ExpectWithOffset(1, tree.String()).To(Equal(phrase))
+
+	parsed, err := tsl.ParseTSL(phrase)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+
+	treeJSON, err := json.Marshal(tree)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	parsedJSON, err := json.Marshal(parsed)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	ExpectWithOffset(1, treeJSON).To(MatchJSON(parsedJSON))
+}
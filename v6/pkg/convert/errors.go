@@ -0,0 +1,54 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is returned when the input is not a valid expression of the
+// converted language
+type SyntaxError struct {
+	Message  string
+	Position int
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+// UnsupportedError reports a construct that has no TSL equivalent
+type UnsupportedError struct {
+	Construct string
+	Position  int
+}
+
+func (e UnsupportedError) Error() string {
+	return fmt.Sprintf("unsupported %s at position %d", e.Construct, e.Position)
+}
+
+// UnsupportedErrors lists all the unsupported constructs of an expression,
+// ordered by position
+type UnsupportedErrors []UnsupportedError
+
+func (e UnsupportedErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
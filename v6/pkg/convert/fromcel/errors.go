@@ -0,0 +1,13 @@
+package fromcel
+
+import "github.com/yaacov/tree-search-language/v6/pkg/convert"
+
+// SyntaxError is returned when the input is not a valid CEL expression
+type SyntaxError = convert.SyntaxError
+
+// UnsupportedError reports a CEL construct that has no TSL equivalent
+type UnsupportedError = convert.UnsupportedError
+
+// UnsupportedErrors lists all the unsupported constructs of an expression,
+// ordered by position
+type UnsupportedErrors = convert.UnsupportedErrors
@@ -0,0 +1,783 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fromcel converts Common Expression Language (CEL) expressions
+// into TSL trees.
+package fromcel
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/convert"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Convert parses a CEL expression and returns the equivalent TSL tree.
+//
+// Supported constructs are the logical, relation and arithmetic
+// operators, the in operator with a list, comparisons with null, field
+// selection and constant indexes, the matches, startsWith, endsWith,
+// contains and size functions, has(), the exists and all macros over a
+// condition on the macro variable, timestamp() and numeric conversions,
+// and string, number, boolean and null literals. matches() calls using an
+// anchored regular expression that a LIKE pattern can express become
+// LIKE or ILIKE, the reverse of the cel walker.
+//
+// Constructs without a TSL equivalent, such as the conditional operator,
+// maps, bytes, durations and other functions and macros, are returned as
+// UnsupportedErrors holding the position of each construct in the input.
+// Positions are counted in runes.
+//
+//	tree, err := fromcel.Convert(`name.startsWith("jo") && age >= 21`)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(tree) // name LIKE 'jo%' AND age >= 21
+func Convert(expression string) (*tsl.TSLNode, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &converter{Reader: convert.NewReader(tokens), variables: map[string]string{}, allMacros: map[*tsl.TSLNode]*tsl.TSLNode{}}
+
+	tree, err := c.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := c.Peek(); t.kind != tokenEOF {
+		return nil, c.Unexpected(t)
+	}
+
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// reserved are the CEL reserved words that can not be used as identifiers
+var reserved = map[string]bool{
+	"true": true, "false": true, "null": true, "in": true, "as": true,
+	"break": true, "const": true, "continue": true, "else": true,
+	"for": true, "function": true, "if": true, "import": true, "let": true,
+	"loop": true, "package": true, "namespace": true, "return": true,
+	"var": true, "void": true, "while": true,
+}
+
+// relations maps CEL relation operators to TSL operators
+var relations = map[string]tsl.Operator{
+	"==": tsl.OpEQ,
+	"!=": tsl.OpNE,
+	"<":  tsl.OpLT,
+	"<=": tsl.OpLE,
+	">":  tsl.OpGT,
+	">=": tsl.OpGE,
+}
+
+// likeWildcards maps the regular expressions written for LIKE wildcards by
+// tsl.LikeToRegexp back to the wildcards
+var likeWildcards = map[string]string{
+	"(?s:.*)": "%",
+	"(?s:.)":  "_",
+}
+
+// regexpMeta are the regular expression characters that need escaping
+const regexpMeta = `\.+*?()|[]{}^$`
+
+// keyPattern matches map keys that can be written in TSL identifiers
+var keyPattern = regexp.MustCompile(`^[^\[\]]+$`)
+
+// converter is a recursive descent parser building the TSL tree
+type converter struct {
+	convert.Reader[token]
+
+	// variables maps the variables of the macros being parsed to their
+	// array identifiers
+	variables map[string]string
+
+	// allMacros maps the trees of all macros to their ALL node
+	allMacros map[*tsl.TSLNode]*tsl.TSLNode
+}
+
+// expr parses the conditional operator
+func (c *converter) expr() (*tsl.TSLNode, error) {
+	node, err := c.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := c.Peek(); t.Is("?") {
+		c.Next()
+		if _, err := c.or(); err != nil {
+			return nil, err
+		}
+		if err := c.Expect(":"); err != nil {
+			return nil, err
+		}
+		if _, err := c.expr(); err != nil {
+			return nil, err
+		}
+		return c.Unsupported("conditional operator ?:", t.position), nil
+	}
+	return node, nil
+}
+
+// or parses || operators
+func (c *converter) or() (*tsl.TSLNode, error) {
+	left, err := c.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for c.Accept("||") {
+		right, err := c.and()
+		if err != nil {
+			return nil, err
+		}
+		left = tsl.NewBinaryExpr(tsl.OpOr, left, right)
+	}
+	return left, nil
+}
+
+// and parses && operators
+func (c *converter) and() (*tsl.TSLNode, error) {
+	left, err := c.relation()
+	if err != nil {
+		return nil, err
+	}
+
+	for c.Accept("&&") {
+		right, err := c.relation()
+		if err != nil {
+			return nil, err
+		}
+		left = c.joinAnd(left, right)
+	}
+	return left, nil
+}
+
+// joinAnd joins two operands of &&. The all macro is true for empty arrays
+// while ALL is false, so `size(a) > 0 && a.all(...)` becomes ALL.
+func (c *converter) joinAnd(left, right *tsl.TSLNode) *tsl.TSLNode {
+	if all, ok := c.allMacros[right]; ok {
+		op, _ := all.AsExprOp()
+		cond, _ := op.Right.AsExprOp()
+		if isNonEmptyCheck(left, cond.Left) {
+			return all
+		}
+		if op, ok := left.AsExprOp(); ok && left.Type() == tsl.KindBinaryExpr && op.Operator == tsl.OpAnd && isNonEmptyCheck(op.Right, cond.Left) {
+			return tsl.NewBinaryExpr(tsl.OpAnd, op.Left, all)
+		}
+	}
+	return tsl.NewBinaryExpr(tsl.OpAnd, left, right)
+}
+
+// isNonEmptyCheck returns true if n is `LEN array > 0`
+func isNonEmptyCheck(n *tsl.TSLNode, array *tsl.TSLNode) bool {
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != tsl.KindBinaryExpr || op.Operator != tsl.OpGT {
+		return false
+	}
+	size, ok := op.Left.AsExprOp()
+	if !ok || op.Left.Type() != tsl.KindUnaryExpr || size.Operator != tsl.OpLen || size.Right.Type() != tsl.KindIdentifier {
+		return false
+	}
+	zero, ok := op.Right.AsFloat64()
+	return ok && op.Right.Type() == tsl.KindNumericLiteral && zero == 0 && size.Right.Value() == array.Value()
+}
+
+// relation parses relation operators and the in operator
+func (c *converter) relation() (*tsl.TSLNode, error) {
+	left, err := c.additive()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := c.Peek()
+		op, isRelation := relations[t.text]
+		if (!isRelation || t.kind != tokenSymbol) && !t.Is("in") {
+			return left, nil
+		}
+		c.Next()
+
+		right, err := c.additive()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case t.Is("in"):
+			left = c.in(t, left, right)
+		case op == tsl.OpEQ || op == tsl.OpNE:
+			left = equality(op, left, right)
+		default:
+			left = tsl.NewBinaryExpr(op, left, right)
+		}
+	}
+}
+
+// in converts the in operator, membership in a list becomes IN and
+// membership in an array identifier becomes ANY
+func (c *converter) in(t token, left, right *tsl.TSLNode) *tsl.TSLNode {
+	switch right.Type() {
+	case tsl.KindArrayLiteral:
+		return tsl.NewBinaryExpr(tsl.OpIn, left, right)
+	case tsl.KindIdentifier:
+		if left.Type() != tsl.KindIdentifier {
+			return tsl.NewUnaryExpr(tsl.OpAny, tsl.NewBinaryExpr(tsl.OpEQ, right, left))
+		}
+	}
+	return c.Unsupported("in operator without a list", t.position)
+}
+
+// equality converts == and !=, comparisons with null become IS NULL
+func equality(op tsl.Operator, left, right *tsl.TSLNode) *tsl.TSLNode {
+	if left.Type() == tsl.KindNullLiteral {
+		left, right = right, left
+	}
+	if right.Type() != tsl.KindNullLiteral {
+		return tsl.NewBinaryExpr(op, left, right)
+	}
+
+	node := tsl.NewBinaryExpr(tsl.OpIs, left, right)
+	if op == tsl.OpNE {
+		return tsl.NewUnaryExpr(tsl.OpNot, node)
+	}
+	return node
+}
+
+// additive parses + and - operators
+func (c *converter) additive() (*tsl.TSLNode, error) {
+	left, err := c.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var op tsl.Operator
+		switch t := c.Peek(); {
+		case t.Is("+"):
+			op = tsl.OpPlus
+		case t.Is("-"):
+			op = tsl.OpMinus
+		default:
+			return left, nil
+		}
+		c.Next()
+
+		right, err := c.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = tsl.NewBinaryExpr(op, left, right)
+	}
+}
+
+// multiplicative parses *, / and % operators
+func (c *converter) multiplicative() (*tsl.TSLNode, error) {
+	left, err := c.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var op tsl.Operator
+		switch t := c.Peek(); {
+		case t.Is("*"):
+			op = tsl.OpStar
+		case t.Is("/"):
+			op = tsl.OpSlash
+		case t.Is("%"):
+			op = tsl.OpPercent
+		default:
+			return left, nil
+		}
+		c.Next()
+
+		right, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = tsl.NewBinaryExpr(op, left, right)
+	}
+}
+
+// unary parses logical not and unary minus
+func (c *converter) unary() (*tsl.TSLNode, error) {
+	t := c.Peek()
+	switch {
+	case t.Is("!"):
+		c.Next()
+		right, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		// Negated regular expressions have their own operator
+		if op, ok := right.AsExprOp(); ok && right.Type() == tsl.KindBinaryExpr && op.Operator == tsl.OpREQ {
+			return tsl.NewBinaryExpr(tsl.OpRNE, op.Left, op.Right), nil
+		}
+		node := tsl.NewUnaryExpr(tsl.OpNot, right)
+		node.SetPosition(t.position)
+		return node, nil
+
+	case t.Is("-"):
+		c.Next()
+		right, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		node := tsl.NewUnaryExpr(tsl.OpUMinus, right)
+		node.SetPosition(t.position)
+		return node, nil
+	}
+
+	return c.member()
+}
+
+// member parses field selections, indexes and receiver function calls
+func (c *converter) member() (*tsl.TSLNode, error) {
+	node, err := c.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := c.Peek()
+		switch {
+		case t.Is("."):
+			c.Next()
+			name := c.Next()
+			if name.kind != tokenIdent {
+				return nil, c.Unexpected(name)
+			}
+
+			if c.Peek().Is("(") {
+				node, err = c.method(node, name)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			if node.Type() != tsl.KindIdentifier {
+				node = c.Unsupported("field selection of an expression", t.position)
+				continue
+			}
+			node = at(tsl.NewIdentifier(node.Value().(string)+"."+name.text), node.Position())
+
+		case t.Is("["):
+			c.Next()
+			key, err := c.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := c.Expect("]"); err != nil {
+				return nil, err
+			}
+			node = c.index(t, node, key)
+
+		default:
+			return node, nil
+		}
+	}
+}
+
+// index converts a constant index of an identifier into a TSL identifier,
+// e.g. `services["my.service"]` into "services[my.service]"
+func (c *converter) index(t token, node, key *tsl.TSLNode) *tsl.TSLNode {
+	if node.Type() != tsl.KindIdentifier {
+		return c.Unsupported("index of an expression", t.position)
+	}
+
+	var text string
+	switch key.Type() {
+	case tsl.KindNumericLiteral:
+		v, _ := key.AsFloat64()
+		if v != float64(int64(v)) || v < 0 {
+			return c.Unsupported("index "+strconv.FormatFloat(v, 'g', -1, 64), t.position)
+		}
+		text = strconv.FormatInt(int64(v), 10)
+	case tsl.KindStringLiteral:
+		text, _ = key.AsString()
+		if !keyPattern.MatchString(text) {
+			return c.Unsupported(fmt.Sprintf("index %q", text), t.position)
+		}
+	default:
+		return c.Unsupported("index that is not a constant", t.position)
+	}
+
+	return at(tsl.NewIdentifier(node.Value().(string)+"["+text+"]"), node.Position())
+}
+
+// arguments parses the arguments of a function call
+func (c *converter) arguments() ([]*tsl.TSLNode, error) {
+	if err := c.Expect("("); err != nil {
+		return nil, err
+	}
+
+	args := []*tsl.TSLNode{}
+	for !c.Peek().Is(")") {
+		if len(args) > 0 {
+			if err := c.Expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := c.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	c.Next()
+
+	return args, nil
+}
+
+// method converts a receiver function call or macro
+func (c *converter) method(receiver *tsl.TSLNode, name token) (*tsl.TSLNode, error) {
+	switch name.text {
+	case "exists", "all", "exists_one", "map", "filter":
+		if c.PeekAt(1).kind == tokenIdent && c.PeekAt(2).Is(",") {
+			return c.macro(receiver, name)
+		}
+	}
+
+	args, err := c.arguments()
+	if err != nil {
+		return nil, err
+	}
+	return c.call(name, append([]*tsl.TSLNode{receiver}, args...)), nil
+}
+
+// macro converts the exists and all macros into ANY and ALL
+func (c *converter) macro(receiver *tsl.TSLNode, name token) (*tsl.TSLNode, error) {
+	c.Next()
+	variable := c.Next()
+	c.Next()
+
+	if receiver.Type() != tsl.KindIdentifier {
+		c.Unsupported("macro "+name.text+" of an expression", name.position)
+		receiver = tsl.NewIdentifier("")
+	}
+	array := receiver.Value().(string)
+
+	// Inside the macro the variable stands for the array
+	shadowed, isShadowed := c.variables[variable.text]
+	c.variables[variable.text] = array
+	predicate, err := c.expr()
+	if isShadowed {
+		c.variables[variable.text] = shadowed
+	} else {
+		delete(c.variables, variable.text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Expect(")"); err != nil {
+		return nil, err
+	}
+
+	if name.text != "exists" && name.text != "all" {
+		return c.Unsupported("macro "+name.text, name.position), nil
+	}
+
+	op, ok := predicate.AsExprOp()
+	if !ok || predicate.Type() != tsl.KindBinaryExpr || op.Left.Type() != tsl.KindIdentifier || !isElement(op.Left.Value().(string), array) {
+		return c.Unsupported("macro "+name.text+" without a comparison of the variable", name.position), nil
+	}
+	switch op.Operator {
+	case tsl.OpAnd, tsl.OpOr:
+		return c.Unsupported("macro "+name.text+" without a comparison of the variable", name.position), nil
+	}
+
+	if name.text == "exists" {
+		return at(tsl.NewUnaryExpr(tsl.OpAny, predicate), name.position), nil
+	}
+
+	// ALL is false for empty arrays, the all macro is true
+	all := at(tsl.NewUnaryExpr(tsl.OpAll, predicate), name.position)
+	empty := tsl.NewBinaryExpr(tsl.OpEQ, tsl.NewUnaryExpr(tsl.OpLen, tsl.NewIdentifier(array)), tsl.NewNumericLiteral(0))
+	node := tsl.NewBinaryExpr(tsl.OpOr, empty, all)
+	c.allMacros[node] = all
+	return node, nil
+}
+
+// isElement returns true if name is the array identifier or a field of
+// its elements
+func isElement(name, array string) bool {
+	return name == array || strings.HasPrefix(name, array+".") || strings.HasPrefix(name, array+"[")
+}
+
+// call converts a function call, receiver calls have the receiver as
+// first argument
+func (c *converter) call(name token, args []*tsl.TSLNode) *tsl.TSLNode {
+	stringArgument := func() (string, bool) {
+		if len(args) != 2 || args[1].Type() != tsl.KindStringLiteral {
+			return "", false
+		}
+		return args[1].AsString()
+	}
+
+	switch name.text {
+	case "matches":
+		if re, ok := stringArgument(); ok {
+			if pattern, ilike, ok := regexpToLike(re); ok {
+				op := tsl.OpLike
+				if ilike {
+					op = tsl.OpILike
+				}
+				return tsl.NewBinaryExpr(op, args[0], tsl.NewStringLiteral(pattern))
+			}
+			return tsl.NewBinaryExpr(tsl.OpREQ, args[0], args[1])
+		}
+
+	case "startsWith", "endsWith", "contains":
+		if s, ok := stringArgument(); ok {
+			pattern := tsl.EscapeLike(s)
+			switch name.text {
+			case "startsWith":
+				pattern = pattern + "%"
+			case "endsWith":
+				pattern = "%" + pattern
+			default:
+				pattern = "%" + pattern + "%"
+			}
+			return tsl.NewBinaryExpr(tsl.OpLike, args[0], tsl.NewStringLiteral(pattern))
+		}
+
+	case "size":
+		if len(args) == 1 && args[0].Type() == tsl.KindIdentifier {
+			return at(tsl.NewUnaryExpr(tsl.OpLen, args[0]), name.position)
+		}
+
+	case "has":
+		if len(args) == 1 && args[0].Type() == tsl.KindIdentifier {
+			return tsl.NewUnaryExpr(tsl.OpNot, tsl.NewBinaryExpr(tsl.OpIs, args[0], tsl.NewNullLiteral()))
+		}
+
+	case "int", "uint", "double":
+		// TSL numbers are doubles
+		if len(args) == 1 {
+			return args[0]
+		}
+
+	case "timestamp":
+		if len(args) == 1 && args[0].Type() == tsl.KindStringLiteral {
+			s, _ := args[0].AsString()
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return at(tsl.NewTimestampLiteral(t), name.position)
+			}
+			return c.Unsupported(fmt.Sprintf("timestamp %q", s), name.position)
+		}
+	}
+
+	return c.Unsupported("function "+name.text, name.position)
+}
+
+// regexpToLike converts an anchored regular expression written by
+// tsl.LikeToRegexp, with an optional (?i) flag, back into a LIKE pattern
+func regexpToLike(re string) (string, bool, bool) {
+	ilike := strings.HasPrefix(re, "(?i)")
+	re = strings.TrimPrefix(re, "(?i)")
+	if !strings.HasPrefix(re, "^") || !strings.HasSuffix(re, "$") || strings.HasSuffix(re, `\$`) {
+		return "", false, false
+	}
+	re = re[1 : len(re)-1]
+
+	var b strings.Builder
+	runes := []rune(re)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if r == '(' {
+			matched := false
+			for expr, wildcard := range likeWildcards {
+				if strings.HasPrefix(string(runes[i:]), expr) {
+					b.WriteString(wildcard)
+					i += len(expr) - 1
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return "", false, false
+			}
+			continue
+		}
+
+		if r == '\\' {
+			if i+1 == len(runes) || !strings.ContainsRune(regexpMeta, runes[i+1]) {
+				return "", false, false
+			}
+			i++
+			r = runes[i]
+		} else if strings.ContainsRune(regexpMeta, r) {
+			return "", false, false
+		}
+		b.WriteString(tsl.EscapeLike(string(r)))
+	}
+
+	return b.String(), ilike, true
+}
+
+// primary parses literals, identifiers, lists, global function calls and
+// parenthesized expressions
+func (c *converter) primary() (*tsl.TSLNode, error) {
+	t := c.Peek()
+
+	switch t.kind {
+	case tokenInt, tokenUint, tokenDouble:
+		c.Next()
+		var value float64
+		var err error
+		if t.kind == tokenDouble {
+			value, err = strconv.ParseFloat(t.text, 64)
+		} else {
+			var i uint64
+			i, err = strconv.ParseUint(t.text, 0, 64)
+			value = float64(i)
+		}
+		if err != nil {
+			return nil, SyntaxError{Message: "invalid number " + t.text, Position: t.position}
+		}
+		return at(tsl.NewNumericLiteral(value), t.position), nil
+
+	case tokenString:
+		c.Next()
+		return at(tsl.NewStringLiteral(t.text), t.position), nil
+
+	case tokenBytes:
+		c.Next()
+		return c.Unsupported("bytes literal", t.position), nil
+
+	case tokenIdent:
+		return c.identifier()
+
+	case tokenSymbol:
+		switch t.text {
+		case "(":
+			c.Next()
+			node, err := c.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := c.Expect(")"); err != nil {
+				return nil, err
+			}
+			return node, nil
+
+		case "[":
+			return c.list()
+
+		case "{":
+			return c.skipMap()
+
+		case ".":
+			// Leading dot of an identifier in the root scope
+			c.Next()
+			if c.Peek().kind != tokenIdent {
+				return nil, c.Unexpected(c.Peek())
+			}
+			return c.identifier()
+		}
+	}
+
+	return nil, c.Unexpected(t)
+}
+
+// identifier parses keyword literals, global function calls, variables
+// and identifiers
+func (c *converter) identifier() (*tsl.TSLNode, error) {
+	t := c.Next()
+
+	switch t.text {
+	case "true", "false":
+		return at(tsl.NewBooleanLiteral(t.text == "true"), t.position), nil
+	case "null":
+		return at(tsl.NewNullLiteral(), t.position), nil
+	}
+	if reserved[t.text] {
+		return nil, c.Unexpected(t)
+	}
+
+	if c.Peek().Is("(") {
+		args, err := c.arguments()
+		if err != nil {
+			return nil, err
+		}
+		return c.call(t, args), nil
+	}
+
+	if array, ok := c.variables[t.text]; ok {
+		return at(tsl.NewIdentifier(array), t.position), nil
+	}
+	return at(tsl.NewIdentifier(t.text), t.position), nil
+}
+
+// list parses a list literal
+func (c *converter) list() (*tsl.TSLNode, error) {
+	open := c.Next()
+
+	values := []*tsl.TSLNode{}
+	for !c.Peek().Is("]") {
+		if len(values) > 0 {
+			if err := c.Expect(","); err != nil {
+				return nil, err
+			}
+			// Trailing comma
+			if c.Peek().Is("]") {
+				break
+			}
+		}
+		value, err := c.expr()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	c.Next()
+
+	return at(tsl.NewArrayLiteral(values...), open.position), nil
+}
+
+// skipMap consumes a map literal
+func (c *converter) skipMap() (*tsl.TSLNode, error) {
+	open := c.Next()
+
+	for depth := 1; depth > 0; {
+		t := c.Next()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, SyntaxError{Message: "unbalanced brace", Position: open.position}
+		case t.Is("{"):
+			depth++
+		case t.Is("}"):
+			depth--
+		}
+	}
+	return c.Unsupported("map literal", open.position), nil
+}
+
+// at sets the position of a node
+func at(n *tsl.TSLNode, position int) *tsl.TSLNode {
+	n.SetPosition(position)
+	return n
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fromcel
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/internal/tsltest"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/cel"
+)
+
+func TestFromCEL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CEL to TSL converter")
+}
+
+var _ = Describe("Convert", func() {
+	DescribeTable("Converts CEL into the expected TSL tree",
+		func(input string, expected string) {
+			tree, err := Convert(input)
+			Expect(err).ToNot(HaveOccurred())
+			tsltest.ExpectTree(tree, expected)
+		},
+
+		Entry("comparison", `name == "joe"`, "name = 'joe'"),
+		Entry("relations", "a != 1 && b < 2 && c <= 3 && d > 4 && e >= 5.5",
+			"a != 1 AND b < 2 AND c <= 3 AND d > 4 AND e >= 5.5"),
+		Entry("logical precedence", "a == 1 || b == 2 && c == 3", "a = 1 OR b = 2 AND c = 3"),
+		Entry("parentheses", "(a == 1 || b == 2) && c == 3", "(a = 1 OR b = 2) AND c = 3"),
+		Entry("not", "!(a == 1) && !active", "NOT (a = 1) AND NOT active"),
+		Entry("in", `city in ["rome", "paris"]`, "city IN ['rome', 'paris']"),
+		Entry("not in", "!(id in [1, 2, 3,])", "id NOT IN [1, 2, 3]"),
+		Entry("in array field", `"admin" in roles`, "ANY (roles = 'admin')"),
+		Entry("is null", "email == null", "email IS NULL"),
+		Entry("is not null", "null != email", "email IS NOT NULL"),
+		Entry("has", "has(spec.replicas)", "spec.replicas IS NOT NULL"),
+		Entry("like", `name.matches("^jo(?s:.*)$")`, "name LIKE 'jo%'"),
+		Entry("like escapes", `name.matches("^a\\.b_c%(?s:.)$")`, `name LIKE 'a.b\\_c\\%_'`),
+		Entry("ilike", `name.matches("(?i)^JO(?s:.*)$")`, "name ILIKE 'JO%'"),
+		Entry("regex", `email.matches("^jo.*@gmail")`, "email ~= '^jo.*@gmail'"),
+		Entry("global matches", `matches(email, "@gmail")`, "email ~= '@gmail'"),
+		Entry("not regex", `!email.matches("@gmail")`, "email ~! '@gmail'"),
+		Entry("starts with", `name.startsWith("jo_")`, `name LIKE 'jo\\_%'`),
+		Entry("ends with", `name.endsWith("son")`, "name LIKE '%son'"),
+		Entry("contains", `name.contains("oh")`, "name LIKE '%oh%'"),
+		Entry("arithmetic", "salary * 12 + bonus > 100000", "salary * 12 + bonus > 100000"),
+		Entry("arithmetic precedence", "a - (b - c) * 2 % 3 == 1", "a - (b - c) * 2 % 3 = 1"),
+		Entry("unary minus", "-balance > -10", "-balance > -10"),
+		Entry("numeric conversions", "double(a) / 2.0 < int(b)", "a / 2 < b"),
+		Entry("number literals", "a == 0x1F || b == 3u || c == 1e3 || d == .5", "a = 31 OR b = 3 OR c = 1000 OR d = 0.5"),
+		Entry("size", "size(tags) > 2 && labels.size() == 0", "LEN tags > 2 AND LEN labels = 0"),
+		Entry("exists", `tags.exists(t, t.startsWith("fic"))`, "ANY (tags LIKE 'fic%')"),
+		Entry("exists of element fields", `pods.exists(p, p.status == "ok")`, "ANY (pods.status = 'ok')"),
+		Entry("all", "size(scores) > 0 && scores.all(s, s >= 50)", "ALL (scores >= 50)"),
+		Entry("all in a conjunction", "a && size(scores) > 0 && scores.all(s, s >= 50)", "a AND ALL (scores >= 50)"),
+		Entry("all of possibly empty array", "scores.all(s, s >= 50)", "LEN scores = 0 OR ALL (scores >= 50)"),
+		Entry("timestamp", `created > timestamp("2020-01-01T10:00:00Z")`, "created > 2020-01-01T10:00:00Z"),
+		Entry("booleans", "active == true && deleted == false", "active = TRUE AND deleted = FALSE"),
+		Entry("strings", `a == 'single' && b == "it's" && c == r"\d" && d == """x"y"""`,
+			`a = 'single' AND b = 'it\'s' AND c = '\\d' AND d = 'x"y'`),
+		Entry("string escapes", `a == "tab\there\x21é\101"`, "a = 'tab\\there!éA'"),
+		Entry("indexes", `pods[0].status == "ok" && services["my.service"].ip != ""`,
+			"pods[0].status = 'ok' AND services[my.service].ip != ''"),
+		Entry("root identifier", ".a == 1", "a = 1"),
+		Entry("comment", "a == 1 // only a\n && b == 2", "a = 1 AND b = 2"),
+	)
+
+	DescribeTable("Converts expressions of the cel walker back",
+		func(phrase string) {
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			expression, err := cel.Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+
+			converted, err := Convert(expression)
+			Expect(err).ToNot(HaveOccurred())
+			tsltest.ExpectTree(converted, tree.String())
+		},
+
+		Entry("comparisons", "name = 'joe' AND age >= 21 AND 20 < b"),
+		Entry("logical operators", "NOT (a = 1 OR b != 2) AND c"),
+		Entry("like", `name LIKE 'a.b\\_c_%' OR name NOT ILIKE 'JO%'`),
+		Entry("regex", "email ~= '^jo.*@gmail' AND email ~! 'yahoo$'"),
+		Entry("in", "city IN ['rome', 'paris'] AND id NOT IN [1, 2]"),
+		Entry("null", "email IS NULL OR phone IS NOT NULL"),
+		Entry("arithmetic", "a - (b - c) * 2 % 3 / -d = 1.5"),
+		Entry("len", "LEN tags > 2"),
+		Entry("any", "ANY (tags LIKE 'fic%')"),
+		Entry("all", "ALL (scores >= x)"),
+		Entry("timestamp", "created < 2020-01-01T10:00:00Z"),
+		Entry("identifiers", "pods[0].status = 'ok' AND services[my.service].ip != ''"),
+	)
+
+	DescribeTable("Reports unsupported constructs with positions",
+		func(input string, expected ...UnsupportedError) {
+			_, err := Convert(input)
+			Expect(err).To(Equal(UnsupportedErrors(expected)))
+		},
+
+		Entry("function", `lowerAscii(name) == "joe"`,
+			UnsupportedError{Construct: "function lowerAscii", Position: 0}),
+		Entry("several constructs", `a ? b : c || d == {"k": 1} || e == b"x"`,
+			UnsupportedError{Construct: "conditional operator ?:", Position: 2},
+			UnsupportedError{Construct: "map literal", Position: 18},
+			UnsupportedError{Construct: "bytes literal", Position: 35}),
+		Entry("in without a list", "a in b", UnsupportedError{Construct: "in operator without a list", Position: 2}),
+		Entry("exists_one macro", "tags.exists_one(t, t == 1)", UnsupportedError{Construct: "macro exists_one", Position: 5}),
+		Entry("exists without comparison", "tags.exists(t, t == 1 || t == 2)",
+			UnsupportedError{Construct: "macro exists without a comparison of the variable", Position: 5}),
+		Entry("field of an expression", "(a + b).c == 1",
+			UnsupportedError{Construct: "field selection of an expression", Position: 7}),
+		Entry("dynamic index", "a[b] == 1", UnsupportedError{Construct: "index that is not a constant", Position: 1}),
+		Entry("duration", `d < duration("1h")`, UnsupportedError{Construct: "function duration", Position: 4}),
+	)
+
+	DescribeTable("Returns syntax errors with positions",
+		func(input string, position int) {
+			_, err := Convert(input)
+			Expect(err).To(BeAssignableToTypeOf(SyntaxError{}))
+			Expect(err.(SyntaxError).Position).To(Equal(position))
+		},
+
+		Entry("unterminated string", `a == "x`, 5),
+		Entry("invalid escape", `a == "\q"`, 6),
+		Entry("missing operand", "a == ", 5),
+		Entry("missing parenthesis", "(a == 1", 7),
+		Entry("trailing tokens", "a == 1 b == 2", 7),
+		Entry("reserved word", "if == 1", 0),
+		Entry("unexpected character", "a == #", 5),
+	)
+
+	It("Sets positions of the CEL input", func() {
+		tree, err := Convert(`a == 1 && name.startsWith("x")`)
+		Expect(err).ToNot(HaveOccurred())
+
+		op, _ := tree.AsExprOp()
+		like, _ := op.Right.AsExprOp()
+		Expect(like.Left.Position()).To(Equal(10))
+	})
+})
@@ -0,0 +1,275 @@
+package fromcel
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of a CEL token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenUint
+	tokenDouble
+	tokenString
+	tokenBytes
+	tokenSymbol
+)
+
+// token is one CEL token, position is the rune index in the input
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// Is returns true if the token is the identifier, keyword or symbol s
+func (t token) Is(s string) bool {
+	return (t.kind == tokenSymbol || t.kind == tokenIdent) && t.text == s
+}
+
+// EOF returns true if the token ends the input
+func (t token) EOF() bool {
+	return t.kind == tokenEOF
+}
+
+// Quoted returns true if the token is a string literal
+func (t token) Quoted() bool {
+	return t.kind == tokenString
+}
+
+// Text returns the text of the token
+func (t token) Text() string {
+	return t.text
+}
+
+// Position returns the position of the token in the input
+func (t token) Position() int {
+	return t.position
+}
+
+// symbols are the CEL operators and punctuation, longest first
+var symbols = []string{
+	"||", "&&", "==", "!=", "<=", ">=",
+	"<", ">", "!", "+", "-", "*", "/", "%", "?", ":", ".", ",", "(", ")", "[", "]", "{", "}",
+}
+
+// tokenize splits a CEL expression into tokens
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	tokens := []token{}
+
+	for pos := 0; pos < len(runes); {
+		c := runes[pos]
+		start := pos
+
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+
+		case c == '/' && pos+1 < len(runes) && runes[pos+1] == '/':
+			// Line comment
+			for pos < len(runes) && runes[pos] != '\n' {
+				pos++
+			}
+
+		case isStringStart(runes, pos):
+			kind, text, end, err := scanString(runes, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: kind, text: text, position: start})
+			pos = end
+
+		case unicode.IsDigit(c) || (c == '.' && pos+1 < len(runes) && unicode.IsDigit(runes[pos+1])):
+			kind, end := scanNumber(runes, pos)
+			text := string(runes[start:end])
+			if kind == tokenUint {
+				text = strings.TrimRight(text, "uU")
+			}
+			tokens = append(tokens, token{kind: kind, text: text, position: start})
+			pos = end
+
+		case c == '_' || (c < unicode.MaxASCII && unicode.IsLetter(c)):
+			for pos < len(runes) && (runes[pos] == '_' || (runes[pos] < unicode.MaxASCII && (unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos])))) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:pos]), position: start})
+
+		default:
+			matched := false
+			for _, s := range symbols {
+				if strings.HasPrefix(string(runes[pos:min(pos+len(s), len(runes))]), s) {
+					tokens = append(tokens, token{kind: tokenSymbol, text: s, position: start})
+					pos += len(s)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, SyntaxError{Message: "unexpected character '" + string(c) + "'", Position: start}
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, position: len(runes)}), nil
+}
+
+// isStringStart returns true if a string or bytes literal, with optional
+// raw and bytes prefixes, starts at pos
+func isStringStart(runes []rune, pos int) bool {
+	for i := 0; i < 3 && pos+i < len(runes); i++ {
+		switch runes[pos+i] {
+		case '"', '\'':
+			return true
+		case 'r', 'R', 'b', 'B':
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+// scanString scans a string or bytes literal starting at pos. It returns
+// the kind and unquoted text of the literal and the position after it.
+func scanString(runes []rune, pos int) (tokenKind, string, int, error) {
+	start := pos
+	kind, raw := tokenString, false
+	for ; runes[pos] != '"' && runes[pos] != '\''; pos++ {
+		switch runes[pos] {
+		case 'r', 'R':
+			raw = true
+		case 'b', 'B':
+			kind = tokenBytes
+		}
+	}
+
+	quote := string(runes[pos])
+	if strings.HasPrefix(string(runes[pos:min(pos+3, len(runes))]), strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	pos += len(quote)
+
+	var b strings.Builder
+	for pos < len(runes) {
+		if strings.HasPrefix(string(runes[pos:min(pos+len(quote), len(runes))]), quote) {
+			return kind, b.String(), pos + len(quote), nil
+		}
+
+		c := runes[pos]
+		if c == '\n' && len(quote) == 1 {
+			break
+		}
+		if c != '\\' || raw {
+			b.WriteRune(c)
+			pos++
+			continue
+		}
+
+		value, end, ok := scanEscape(runes, pos)
+		if !ok {
+			return 0, "", 0, SyntaxError{Message: "invalid escape sequence", Position: pos}
+		}
+		b.WriteString(value)
+		pos = end
+	}
+
+	return 0, "", 0, SyntaxError{Message: "unterminated string", Position: start}
+}
+
+// scanEscape scans the escape sequence starting at pos
+func scanEscape(runes []rune, pos int) (string, int, bool) {
+	if pos+1 >= len(runes) {
+		return "", pos, false
+	}
+
+	simple := map[rune]string{
+		'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v",
+		'\\': "\\", '\'': "'", '"': "\"", '`': "`", '?': "?",
+	}
+	c := runes[pos+1]
+	if s, ok := simple[c]; ok {
+		return s, pos + 2, true
+	}
+
+	digits, base := 0, 16
+	switch c {
+	case 'x', 'X':
+		digits = 2
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	case '0', '1', '2', '3':
+		digits, base = 3, 8
+	default:
+		return "", pos, false
+	}
+
+	first := pos + 2
+	if base == 8 {
+		first = pos + 1
+	}
+	if first+digits > len(runes) {
+		return "", pos, false
+	}
+	value, err := strconv.ParseUint(string(runes[first:first+digits]), base, 32)
+	if err != nil {
+		return "", pos, false
+	}
+	return string(rune(value)), first + digits, true
+}
+
+// scanNumber scans an int, uint or double literal and returns its kind
+// and the position after it
+func scanNumber(runes []rune, pos int) (tokenKind, int) {
+	isHex := func(r rune) bool {
+		return unicode.IsDigit(r) || strings.ContainsRune("abcdefABCDEF", r)
+	}
+	digits := func() {
+		for pos < len(runes) && unicode.IsDigit(runes[pos]) {
+			pos++
+		}
+	}
+	suffix := func() tokenKind {
+		if pos < len(runes) && (runes[pos] == 'u' || runes[pos] == 'U') {
+			pos++
+			return tokenUint
+		}
+		return tokenInt
+	}
+
+	if runes[pos] == '0' && pos+2 < len(runes) && (runes[pos+1] == 'x' || runes[pos+1] == 'X') && isHex(runes[pos+2]) {
+		for pos += 2; pos < len(runes) && isHex(runes[pos]); pos++ {
+		}
+		return suffix(), pos
+	}
+
+	kind := tokenInt
+	digits()
+	if pos+1 < len(runes) && runes[pos] == '.' && unicode.IsDigit(runes[pos+1]) {
+		pos++
+		digits()
+		kind = tokenDouble
+	}
+	if pos+1 < len(runes) && (runes[pos] == 'e' || runes[pos] == 'E') {
+		next := pos + 1
+		if runes[next] == '+' || runes[next] == '-' {
+			next++
+		}
+		if next < len(runes) && unicode.IsDigit(runes[next]) {
+			pos = next
+			digits()
+			kind = tokenDouble
+		}
+	}
+
+	if kind == tokenInt {
+		return suffix(), pos
+	}
+	return kind, pos
+}
@@ -0,0 +1,620 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fromsql converts SQL WHERE clauses into TSL trees.
+package fromsql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/convert"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Convert parses an SQL boolean expression, optionally starting with the
+// WHERE keyword, and returns the equivalent TSL tree.
+//
+// Supported constructs are comparisons, AND, OR, NOT, [NOT] IN,
+// [NOT] BETWEEN, [NOT] LIKE / ILIKE with an optional ESCAPE clause,
+// IS [NOT] NULL, REGEXP, arithmetic, and string, number, boolean,
+// DATE and TIMESTAMP literals. LIKE patterns without an ESCAPE clause use
+// a backslash as escape character, like MySQL and PostgreSQL.
+//
+// Constructs without a TSL equivalent, such as function calls, sub queries,
+// CASE expressions or bind parameters, are returned as UnsupportedErrors
+// holding the position of each construct in the input. Positions are
+// counted in runes. Invalid LIKE patterns return tsl.LikePatternError, and
+// ESCAPE strings longer than one character tsl.LikeEscapeError.
+//
+//	tree, err := fromsql.Convert("WHERE name LIKE 'jo%' AND age >= 21")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(tree) // name LIKE 'jo%' AND age >= 21
+func Convert(where string) (*tsl.TSLNode, error) {
+	tokens, err := tokenize(where)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &converter{Reader: convert.NewReader(tokens)}
+	c.Accept("where")
+
+	tree, err := c.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	c.Accept(";")
+	if t := c.Peek(); t.kind != tokenEOF {
+		return nil, c.Unexpected(t)
+	}
+
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// reserved are the SQL keywords that can not be used as identifiers
+var reserved = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "between": true,
+	"like": true, "ilike": true, "is": true, "null": true, "true": true,
+	"false": true, "escape": true, "case": true, "when": true, "then": true,
+	"else": true, "end": true, "exists": true, "select": true, "regexp": true,
+	"rlike": true, "where": true,
+}
+
+// comparisons maps SQL comparison operators to TSL operators
+var comparisons = map[string]tsl.Operator{
+	"=":  tsl.OpEQ,
+	"==": tsl.OpEQ,
+	"<>": tsl.OpNE,
+	"!=": tsl.OpNE,
+	"<":  tsl.OpLT,
+	"<=": tsl.OpLE,
+	">":  tsl.OpGT,
+	">=": tsl.OpGE,
+	"~":  tsl.OpREQ,
+	"!~": tsl.OpRNE,
+}
+
+// identifierPattern matches identifiers that can be written in TSL
+var identifierPattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_./]*$`)
+
+// timestampLayouts are the accepted TIMESTAMP literal formats
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// converter is a recursive descent parser building the TSL tree
+type converter struct {
+	convert.Reader[token]
+}
+
+// skipParens consumes a parenthesized token sequence
+func (c *converter) skipParens() error {
+	open := c.Peek()
+	if err := c.Expect("("); err != nil {
+		return err
+	}
+
+	for depth := 1; depth > 0; {
+		t := c.Next()
+		switch {
+		case t.kind == tokenEOF:
+			return SyntaxError{Message: "unbalanced parenthesis", Position: open.position}
+		case t.Is("("):
+			depth++
+		case t.Is(")"):
+			depth--
+		}
+	}
+	return nil
+}
+
+// expr parses OR expressions
+func (c *converter) expr() (*tsl.TSLNode, error) {
+	left, err := c.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for c.Accept("or") {
+		right, err := c.and()
+		if err != nil {
+			return nil, err
+		}
+		left = tsl.NewBinaryExpr(tsl.OpOr, left, right)
+	}
+	return left, nil
+}
+
+// and parses AND expressions
+func (c *converter) and() (*tsl.TSLNode, error) {
+	left, err := c.not()
+	if err != nil {
+		return nil, err
+	}
+
+	for c.Accept("and") {
+		right, err := c.not()
+		if err != nil {
+			return nil, err
+		}
+		left = tsl.NewBinaryExpr(tsl.OpAnd, left, right)
+	}
+	return left, nil
+}
+
+// not parses NOT expressions
+func (c *converter) not() (*tsl.TSLNode, error) {
+	if c.Accept("not") {
+		right, err := c.not()
+		if err != nil {
+			return nil, err
+		}
+		return tsl.NewUnaryExpr(tsl.OpNot, right), nil
+	}
+	return c.predicate()
+}
+
+// predicate parses comparisons, IN, BETWEEN, LIKE and IS NULL
+func (c *converter) predicate() (*tsl.TSLNode, error) {
+	left, err := c.additive()
+	if err != nil {
+		return nil, err
+	}
+
+	t := c.Peek()
+	if op, ok := comparisons[t.text]; ok && t.kind == tokenSymbol {
+		c.Next()
+		right, err := c.additive()
+		if err != nil {
+			return nil, err
+		}
+		if right.Type() == tsl.KindNullLiteral || left.Type() == tsl.KindNullLiteral {
+			return c.Unsupported("comparison with NULL, use IS [NOT] NULL", t.position), nil
+		}
+		return tsl.NewBinaryExpr(op, left, right), nil
+	}
+
+	if c.Accept("is") {
+		return c.is(left)
+	}
+
+	negated := false
+	if c.Peek().Is("not") {
+		switch next := c.PeekAt(1); {
+		case next.Is("in"), next.Is("between"), next.Is("like"), next.Is("ilike"), next.Is("regexp"), next.Is("rlike"):
+			c.Next()
+			negated = true
+		}
+	}
+
+	var node *tsl.TSLNode
+	switch t := c.Peek(); {
+	case t.Is("in"):
+		c.Next()
+		node, err = c.in(left)
+	case t.Is("between"):
+		c.Next()
+		node, err = c.between(left)
+	case t.Is("like"):
+		c.Next()
+		node, err = c.like(tsl.OpLike, left)
+	case t.Is("ilike"):
+		c.Next()
+		node, err = c.like(tsl.OpILike, left)
+	case t.Is("regexp"), t.Is("rlike"):
+		c.Next()
+		var right *tsl.TSLNode
+		if right, err = c.additive(); err == nil {
+			node = tsl.NewBinaryExpr(tsl.OpREQ, left, right)
+		}
+	default:
+		return left, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if negated {
+		return tsl.NewUnaryExpr(tsl.OpNot, node), nil
+	}
+	return node, nil
+}
+
+// is parses the right side of IS [NOT] NULL
+func (c *converter) is(left *tsl.TSLNode) (*tsl.TSLNode, error) {
+	negated := c.Accept("not")
+
+	t := c.Next()
+	if !t.Is("null") {
+		if t.kind != tokenWord {
+			return nil, c.Unexpected(t)
+		}
+		construct := "IS " + strings.ToUpper(t.text)
+		if t.Is("distinct") {
+			c.Accept("from")
+			if _, err := c.additive(); err != nil {
+				return nil, err
+			}
+			construct = "IS DISTINCT FROM"
+		}
+		return c.Unsupported(construct, t.position), nil
+	}
+
+	null := tsl.NewNullLiteral()
+	null.SetPosition(t.position)
+	node := tsl.NewBinaryExpr(tsl.OpIs, left, null)
+	if negated {
+		return tsl.NewUnaryExpr(tsl.OpNot, node), nil
+	}
+	return node, nil
+}
+
+// in parses the list of an IN predicate
+func (c *converter) in(left *tsl.TSLNode) (*tsl.TSLNode, error) {
+	open := c.Peek()
+	if c.PeekAt(1).Is("select") {
+		if err := c.skipParens(); err != nil {
+			return nil, err
+		}
+		return c.Unsupported("sub query", open.position), nil
+	}
+	if err := c.Expect("("); err != nil {
+		return nil, err
+	}
+
+	values := []*tsl.TSLNode{}
+	for !c.Peek().Is(")") {
+		if len(values) > 0 {
+			if err := c.Expect(","); err != nil {
+				return nil, err
+			}
+		}
+		value, err := c.additive()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	c.Next()
+
+	array := tsl.NewArrayLiteral(values...)
+	array.SetPosition(open.position)
+	return tsl.NewBinaryExpr(tsl.OpIn, left, array), nil
+}
+
+// between parses the range of a BETWEEN predicate
+func (c *converter) between(left *tsl.TSLNode) (*tsl.TSLNode, error) {
+	if t := c.Peek(); t.Is("symmetric") || t.Is("asymmetric") {
+		c.Next()
+		c.Unsupported("BETWEEN "+strings.ToUpper(t.text), t.position)
+	}
+
+	low, err := c.additive()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Expect("and"); err != nil {
+		return nil, err
+	}
+	high, err := c.additive()
+	if err != nil {
+		return nil, err
+	}
+
+	return tsl.NewBinaryExpr(tsl.OpBetween, left, tsl.NewArrayLiteral(low, high)), nil
+}
+
+// like parses the pattern and optional ESCAPE clause of a LIKE predicate
+func (c *converter) like(op tsl.Operator, left *tsl.TSLNode) (*tsl.TSLNode, error) {
+	patternToken := c.Peek()
+	right, err := c.additive()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, isLiteral := right.AsString()
+	isLiteral = isLiteral && right.Type() == tsl.KindStringLiteral
+
+	escape := string(tsl.LikeEscape)
+	if c.Accept("escape") {
+		t := c.Next()
+		if t.kind != tokenString {
+			return nil, SyntaxError{Message: "ESCAPE requires a string literal", Position: t.position}
+		}
+		if !isLiteral {
+			return c.Unsupported("ESCAPE with a pattern that is not a string literal", t.position), nil
+		}
+		escape = t.text
+	}
+
+	if isLiteral {
+		converted, err := tsl.ConvertLikeEscape(pattern, escape)
+		if err != nil {
+			return nil, err
+		}
+		right = tsl.NewStringLiteral(converted)
+		right.SetPosition(patternToken.position)
+	}
+
+	return tsl.NewBinaryExpr(op, left, right), nil
+}
+
+// additive parses + and - operators
+func (c *converter) additive() (*tsl.TSLNode, error) {
+	left, err := c.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := c.Peek()
+		var op tsl.Operator
+		switch {
+		case t.Is("+"):
+			op = tsl.OpPlus
+		case t.Is("-"):
+			op = tsl.OpMinus
+		case t.Is("||"):
+			c.Next()
+			if _, err := c.multiplicative(); err != nil {
+				return nil, err
+			}
+			left = c.Unsupported("string concatenation ||", t.position)
+			continue
+		default:
+			return left, nil
+		}
+		c.Next()
+
+		right, err := c.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = tsl.NewBinaryExpr(op, left, right)
+	}
+}
+
+// multiplicative parses *, / and % operators
+func (c *converter) multiplicative() (*tsl.TSLNode, error) {
+	left, err := c.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var op tsl.Operator
+		switch t := c.Peek(); {
+		case t.Is("*"):
+			op = tsl.OpStar
+		case t.Is("/"):
+			op = tsl.OpSlash
+		case t.Is("%"):
+			op = tsl.OpPercent
+		default:
+			return left, nil
+		}
+		c.Next()
+
+		right, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = tsl.NewBinaryExpr(op, left, right)
+	}
+}
+
+// unary parses unary minus and plus, and type casts
+func (c *converter) unary() (*tsl.TSLNode, error) {
+	t := c.Peek()
+	switch {
+	case t.Is("-"):
+		c.Next()
+		right, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		node := tsl.NewUnaryExpr(tsl.OpUMinus, right)
+		node.SetPosition(t.position)
+		return node, nil
+	case t.Is("+"):
+		c.Next()
+		return c.unary()
+	}
+
+	node, err := c.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for c.Peek().Is("::") {
+		cast := c.Next()
+		if t := c.Next(); t.kind != tokenWord && t.kind != tokenQuotedIdentifier {
+			return nil, c.Unexpected(t)
+		}
+		if c.Peek().Is("(") {
+			if err := c.skipParens(); err != nil {
+				return nil, err
+			}
+		}
+		node = c.Unsupported("type cast ::", cast.position)
+	}
+	return node, nil
+}
+
+// primary parses literals, identifiers and parenthesized expressions
+func (c *converter) primary() (*tsl.TSLNode, error) {
+	t := c.Peek()
+
+	switch t.kind {
+	case tokenNumber:
+		c.Next()
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, SyntaxError{Message: "invalid number " + t.text, Position: t.position}
+		}
+		return at(tsl.NewNumericLiteral(value), t), nil
+
+	case tokenString:
+		c.Next()
+		return at(tsl.NewStringLiteral(t.text), t), nil
+
+	case tokenParameter:
+		c.Next()
+		return c.Unsupported("bind parameter "+t.text, t.position), nil
+
+	case tokenQuotedIdentifier:
+		return c.identifier()
+
+	case tokenSymbol:
+		if !t.Is("(") {
+			return nil, c.Unexpected(t)
+		}
+		if c.PeekAt(1).Is("select") {
+			if err := c.skipParens(); err != nil {
+				return nil, err
+			}
+			return c.Unsupported("sub query", t.position), nil
+		}
+
+		c.Next()
+		node, err := c.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Expect(")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+
+	case tokenWord:
+		return c.word()
+	}
+
+	return nil, c.Unexpected(t)
+}
+
+// word parses keyword literals, typed literals, function calls and identifiers
+func (c *converter) word() (*tsl.TSLNode, error) {
+	t := c.Peek()
+	next := c.PeekAt(1)
+
+	switch {
+	case t.Is("true"), t.Is("false"):
+		c.Next()
+		return at(tsl.NewBooleanLiteral(t.Is("true")), t), nil
+
+	case t.Is("null"):
+		c.Next()
+		return at(tsl.NewNullLiteral(), t), nil
+
+	case t.Is("date") && next.kind == tokenString:
+		c.Next()
+		c.Next()
+		if _, err := time.Parse("2006-01-02", next.text); err != nil {
+			return nil, SyntaxError{Message: "invalid DATE literal '" + next.text + "'", Position: next.position}
+		}
+		return at(tsl.NewDateLiteral(next.text), t), nil
+
+	case t.Is("timestamp") && next.kind == tokenString:
+		c.Next()
+		c.Next()
+		for _, layout := range timestampLayouts {
+			if value, err := time.Parse(layout, next.text); err == nil {
+				return at(tsl.NewTimestampLiteral(value), t), nil
+			}
+		}
+		return nil, SyntaxError{Message: "invalid TIMESTAMP literal '" + next.text + "'", Position: next.position}
+
+	case t.Is("interval") && next.kind == tokenString:
+		c.Next()
+		c.Next()
+		if w := c.Peek(); w.kind == tokenWord && !reserved[strings.ToLower(w.text)] {
+			c.Next()
+		}
+		return c.Unsupported("INTERVAL literal", t.position), nil
+
+	case t.Is("case"):
+		c.Next()
+		for depth := 1; depth > 0; {
+			switch w := c.Next(); {
+			case w.kind == tokenEOF:
+				return nil, SyntaxError{Message: "CASE without END", Position: t.position}
+			case w.Is("case"):
+				depth++
+			case w.Is("end"):
+				depth--
+			}
+		}
+		return c.Unsupported("CASE expression", t.position), nil
+
+	case t.Is("exists"):
+		c.Next()
+		if err := c.skipParens(); err != nil {
+			return nil, err
+		}
+		return c.Unsupported("EXISTS sub query", t.position), nil
+
+	case reserved[strings.ToLower(t.text)]:
+		return nil, c.Unexpected(t)
+
+	case next.Is("("):
+		c.Next()
+		if err := c.skipParens(); err != nil {
+			return nil, err
+		}
+		return c.Unsupported("function "+strings.ToUpper(t.text), t.position), nil
+	}
+
+	return c.identifier()
+}
+
+// identifier parses a possibly qualified and quoted identifier
+func (c *converter) identifier() (*tsl.TSLNode, error) {
+	first := c.Next()
+	parts := []string{first.text}
+
+	for c.Peek().Is(".") {
+		c.Next()
+		t := c.Next()
+		if t.kind != tokenWord && t.kind != tokenQuotedIdentifier {
+			return nil, c.Unexpected(t)
+		}
+		parts = append(parts, t.text)
+	}
+
+	name := strings.Join(parts, ".")
+	if !identifierPattern.MatchString(name) {
+		return c.Unsupported(fmt.Sprintf("identifier %q", name), first.position), nil
+	}
+	return at(tsl.NewIdentifier(name), first), nil
+}
+
+// at sets the position of a node to the position of a token
+func at(n *tsl.TSLNode, t token) *tsl.TSLNode {
+	n.SetPosition(t.position)
+	return n
+}
@@ -0,0 +1,150 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fromsql
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestFromSQL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SQL to TSL converter")
+}
+
+var _ = Describe("Convert", func() {
+	DescribeTable("Converts SQL into the expected TSL tree",
+		func(input string, expected string) {
+			tree, err := Convert(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tree.String()).To(Equal(expected))
+
+			// The tree must equal the tree parsed from the TSL phrase
+			parsed, err := tsl.ParseTSL(expected)
+			Expect(err).ToNot(HaveOccurred())
+
+			treeJSON, err := json.Marshal(tree)
+			Expect(err).ToNot(HaveOccurred())
+			parsedJSON, err := json.Marshal(parsed)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(treeJSON).To(MatchJSON(parsedJSON))
+		},
+
+		Entry("comparison", "name = 'joe'", "name = 'joe'"),
+		Entry("where keyword", "WHERE age >= 21;", "age >= 21"),
+		Entry("not equal", "a <> 1 and b != 2", "a != 1 AND b != 2"),
+		Entry("logical precedence", "a = 1 or b = 2 and c = 3", "a = 1 OR b = 2 AND c = 3"),
+		Entry("parentheses", "(a = 1 or b = 2) and c = 3", "(a = 1 OR b = 2) AND c = 3"),
+		Entry("not", "not a = 1 and not (b = 2 or c = 3)", "NOT (a = 1) AND NOT (b = 2 OR c = 3)"),
+		Entry("in", "city in ('rome', 'paris')", "city IN ['rome', 'paris']"),
+		Entry("not in", "id not in (1, 2, 3)", "id NOT IN [1, 2, 3]"),
+		Entry("between", "age between 20 and 30", "age BETWEEN 20 AND 30"),
+		Entry("not between", "age not between 20 and 30 and b = 1", "age NOT BETWEEN 20 AND 30 AND b = 1"),
+		Entry("like", "name like 'jo%'", "name LIKE 'jo%'"),
+		Entry("not ilike", "name not ilike 'JO%'", "name NOT ILIKE 'JO%'"),
+		Entry("like escape", "name like '100!%' escape '!'", `name LIKE '100\\%'`),
+		Entry("like default escape", `name like 'a\_b'`, `name LIKE 'a\\_b'`),
+		Entry("is null", "email is null", "email IS NULL"),
+		Entry("is not null", "email IS NOT NULL", "email IS NOT NULL"),
+		Entry("regexp", "email regexp '@gmail' or email ~ '@yahoo'", "email ~= '@gmail' OR email ~= '@yahoo'"),
+		Entry("not regexp", "email not regexp '@gmail' and email !~ '@yahoo'", "NOT (email ~= '@gmail') AND email ~! '@yahoo'"),
+		Entry("arithmetic", "(salary * 12) + bonus > 100000", "salary * 12 + bonus > 100000"),
+		Entry("arithmetic precedence", "a - (b - c) * 2 % 3 = 1", "a - (b - c) * 2 % 3 = 1"),
+		Entry("unary minus", "-a < -1.5", "-a < -1.5"),
+		Entry("exponent", "a > 1e3", "a > 1000"),
+		Entry("quoted string", "name = 'it''s'", `name = 'it\'s'`),
+		Entry("booleans", "active = true and deleted = FALSE", "active = TRUE AND deleted = FALSE"),
+		Entry("date", "created > DATE '2020-01-01'", "created > 2020-01-01"),
+		Entry("timestamp", "created > TIMESTAMP '2020-01-01 10:00:00'", "created > 2020-01-01T10:00:00Z"),
+		Entry("date column", "date < DATE '2020-01-01'", "date < 2020-01-01"),
+		Entry("qualified identifier", "users.name = 'joe'", "users.name = 'joe'"),
+		Entry("quoted identifiers", `"users"."name" = 'joe' and `+"`age`"+` > 1 and [city] = 'rome'`,
+			"users.name = 'joe' AND age > 1 AND city = 'rome'"),
+		Entry("comment", "a = 1 -- only a\n and b = 2", "a = 1 AND b = 2"),
+	)
+
+	DescribeTable("Reports unsupported constructs with positions",
+		func(input string, expected ...UnsupportedError) {
+			_, err := Convert(input)
+			Expect(err).To(Equal(UnsupportedErrors(expected)))
+		},
+
+		Entry("function", "lower(name) = 'joe'",
+			UnsupportedError{Construct: "function LOWER", Position: 0}),
+		Entry("several constructs", "a = ? and b in (select id from t) or c = lower(d)",
+			UnsupportedError{Construct: "bind parameter ?", Position: 4},
+			UnsupportedError{Construct: "sub query", Position: 15},
+			UnsupportedError{Construct: "function LOWER", Position: 41}),
+		Entry("case", "case when a = 1 then 1 else 0 end = 1",
+			UnsupportedError{Construct: "CASE expression", Position: 0}),
+		Entry("exists", "not exists (select 1 from t)",
+			UnsupportedError{Construct: "EXISTS sub query", Position: 4}),
+		Entry("concatenation", "a || b = 'xy'",
+			UnsupportedError{Construct: "string concatenation ||", Position: 2}),
+		Entry("cast", "a::int = 1",
+			UnsupportedError{Construct: "type cast ::", Position: 1}),
+		Entry("comparison with null", "a = null",
+			UnsupportedError{Construct: "comparison with NULL, use IS [NOT] NULL", Position: 2}),
+		Entry("is true", "a is not true",
+			UnsupportedError{Construct: "IS TRUE", Position: 9}),
+		Entry("identifier", `"first name" = 'joe'`,
+			UnsupportedError{Construct: `identifier "first name"`, Position: 0}),
+		Entry("named parameter", "a = :name",
+			UnsupportedError{Construct: "bind parameter :name", Position: 4}),
+	)
+
+	DescribeTable("Returns syntax errors with positions",
+		func(input string, position int) {
+			_, err := Convert(input)
+			Expect(err).To(BeAssignableToTypeOf(SyntaxError{}))
+			Expect(err.(SyntaxError).Position).To(Equal(position))
+		},
+
+		Entry("unterminated string", "a = 'x", 4),
+		Entry("missing operand", "a = ", 4),
+		Entry("missing parenthesis", "(a = 1", 6),
+		Entry("between without and", "a between 1 or 2", 12),
+		Entry("trailing tokens", "a = 1 b = 2", 6),
+		Entry("invalid date", "a = date '2020-13-01'", 9),
+		Entry("unexpected character", "a = #", 4),
+	)
+
+	DescribeTable("Returns LIKE pattern errors",
+		func(input string, expected error) {
+			_, err := Convert(input)
+			Expect(err).To(Equal(expected))
+		},
+
+		Entry("escape longer than one character", "a like 'x' escape 'ab'", tsl.LikeEscapeError{Escape: "ab"}),
+		Entry("pattern ends with the escape", "a like 'x!' escape '!'", tsl.LikePatternError{Pattern: "x!"}),
+		Entry("pattern ends with the default escape", `a like 'x\'`, tsl.LikePatternError{Pattern: `x\`}),
+	)
+
+	It("Sets positions of the SQL input", func() {
+		tree, err := Convert("a = 1 and name like 'x%'")
+		Expect(err).ToNot(HaveOccurred())
+
+		op, _ := tree.AsExprOp()
+		like, _ := op.Right.AsExprOp()
+		Expect(like.Left.Position()).To(Equal(10))
+		Expect(like.Right.Position()).To(Equal(20))
+	})
+})
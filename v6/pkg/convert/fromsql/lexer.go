@@ -0,0 +1,188 @@
+package fromsql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of an SQL token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenQuotedIdentifier
+	tokenString
+	tokenNumber
+	tokenSymbol
+	tokenParameter
+)
+
+// token is one SQL token, position is the rune index in the input
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// Is returns true if the token is the keyword or symbol s
+func (t token) Is(s string) bool {
+	switch t.kind {
+	case tokenWord:
+		return strings.EqualFold(t.text, s)
+	case tokenSymbol:
+		return t.text == s
+	}
+	return false
+}
+
+// EOF returns true if the token ends the input
+func (t token) EOF() bool {
+	return t.kind == tokenEOF
+}
+
+// Quoted returns true if the token is a string literal
+func (t token) Quoted() bool {
+	return t.kind == tokenString
+}
+
+// Text returns the text of the token
+func (t token) Text() string {
+	return t.text
+}
+
+// Position returns the position of the token in the input
+func (t token) Position() int {
+	return t.position
+}
+
+// symbols are the SQL operators, longest first
+var symbols = []string{
+	"<>", "!=", "<=", ">=", "==", "||", "!~", "::",
+	"=", "<", ">", "+", "-", "*", "/", "%", "(", ")", ",", "~", ";", ".",
+}
+
+// tokenize splits an SQL expression into tokens
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	tokens := []token{}
+
+	for pos := 0; pos < len(runes); {
+		c := runes[pos]
+		start := pos
+
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+
+		case c == '-' && pos+1 < len(runes) && runes[pos+1] == '-':
+			// Line comment
+			for pos < len(runes) && runes[pos] != '\n' {
+				pos++
+			}
+
+		case c == '\'':
+			s, end, ok := scanQuoted(runes, pos, '\'')
+			if !ok {
+				return nil, SyntaxError{Message: "unterminated string", Position: start}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, position: start})
+			pos = end
+
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			s, end, ok := scanQuoted(runes, pos, closing)
+			if !ok {
+				return nil, SyntaxError{Message: "unterminated quoted identifier", Position: start}
+			}
+			tokens = append(tokens, token{kind: tokenQuotedIdentifier, text: s, position: start})
+			pos = end
+
+		case unicode.IsDigit(c) || (c == '.' && pos+1 < len(runes) && unicode.IsDigit(runes[pos+1])):
+			pos = scanNumber(runes, pos)
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:pos]), position: start})
+
+		case unicode.IsLetter(c) || c == '_':
+			for pos < len(runes) && (unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos]) || runes[pos] == '_' || runes[pos] == '$') {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:pos]), position: start})
+
+		case c == '?' || c == '$' || c == '@' || (c == ':' && pos+1 < len(runes) && runes[pos+1] != ':'):
+			// Bind parameters: ?, $1, :name and @name
+			pos++
+			for pos < len(runes) && (unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos]) || runes[pos] == '_') {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenParameter, text: string(runes[start:pos]), position: start})
+
+		default:
+			matched := false
+			for _, s := range symbols {
+				if strings.HasPrefix(string(runes[pos:min(pos+len(s), len(runes))]), s) {
+					tokens = append(tokens, token{kind: tokenSymbol, text: s, position: start})
+					pos += len(s)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, SyntaxError{Message: "unexpected character '" + string(c) + "'", Position: start}
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, position: len(runes)}), nil
+}
+
+// scanQuoted scans a quoted string or identifier starting at pos, a doubled
+// closing quote stands for one quote character. It returns the unquoted
+// text and the position after the closing quote.
+func scanQuoted(runes []rune, pos int, closing rune) (string, int, bool) {
+	var b strings.Builder
+
+	for pos++; pos < len(runes); pos++ {
+		if runes[pos] == closing {
+			if pos+1 < len(runes) && runes[pos+1] == closing {
+				b.WriteRune(closing)
+				pos++
+				continue
+			}
+			return b.String(), pos + 1, true
+		}
+		b.WriteRune(runes[pos])
+	}
+
+	return "", pos, false
+}
+
+// scanNumber scans a decimal number with an optional exponent and returns
+// the position after it
+func scanNumber(runes []rune, pos int) int {
+	digits := func() {
+		for pos < len(runes) && unicode.IsDigit(runes[pos]) {
+			pos++
+		}
+	}
+
+	digits()
+	if pos < len(runes) && runes[pos] == '.' {
+		pos++
+		digits()
+	}
+	if pos+1 < len(runes) && (runes[pos] == 'e' || runes[pos] == 'E') {
+		next := pos + 1
+		if runes[next] == '+' || runes[next] == '-' {
+			next++
+		}
+		if next < len(runes) && unicode.IsDigit(runes[next]) {
+			pos = next
+			digits()
+		}
+	}
+
+	return pos
+}
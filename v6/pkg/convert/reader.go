@@ -0,0 +1,135 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package convert holds the errors and the token reader shared by the
+// converters of other query languages into TSL trees, such as the fromsql,
+// fromcel, odata and rsql packages.
+package convert
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Token is a token of the converted language
+type Token interface {
+	// Is returns true if the token is the keyword or symbol s
+	Is(s string) bool
+
+	// EOF returns true if the token ends the input
+	EOF() bool
+
+	// Quoted returns true if the token is a string literal
+	Quoted() bool
+
+	// Text returns the text of the token
+	Text() string
+
+	// Position returns the position of the token in the input
+	Position() int
+}
+
+// DescribeToken returns a short description of a token for error messages
+func DescribeToken(t Token) string {
+	switch {
+	case t.EOF():
+		return "end of input"
+	case t.Quoted():
+		return fmt.Sprintf("string %q", t.Text())
+	default:
+		return fmt.Sprintf("%q", t.Text())
+	}
+}
+
+// Reader reads the tokens of an expression for a recursive descent
+// converter, and records the unsupported constructs it finds. The last
+// token must end the input.
+type Reader[T Token] struct {
+	tokens      []T
+	pos         int
+	unsupported UnsupportedErrors
+}
+
+// NewReader creates a reader of tokens
+func NewReader[T Token](tokens []T) Reader[T] {
+	return Reader[T]{tokens: tokens}
+}
+
+// Peek returns the next token without consuming it
+func (r *Reader[T]) Peek() T {
+	return r.tokens[r.pos]
+}
+
+// PeekAt returns the token offset tokens after the next one, or the last
+// token if the input ends before it
+func (r *Reader[T]) PeekAt(offset int) T {
+	if r.pos+offset >= len(r.tokens) {
+		return r.tokens[len(r.tokens)-1]
+	}
+	return r.tokens[r.pos+offset]
+}
+
+// Next consumes the next token, the last token is never consumed
+func (r *Reader[T]) Next() T {
+	t := r.tokens[r.pos]
+	if !t.EOF() {
+		r.pos++
+	}
+	return t
+}
+
+// Accept consumes the next token if it is the keyword or symbol s
+func (r *Reader[T]) Accept(s string) bool {
+	if r.Peek().Is(s) {
+		r.Next()
+		return true
+	}
+	return false
+}
+
+// Expect consumes the next token, it must be the keyword or symbol s
+func (r *Reader[T]) Expect(s string) error {
+	if t := r.Peek(); !t.Is(s) {
+		return SyntaxError{Message: fmt.Sprintf("expected %q, found %s", s, DescribeToken(t)), Position: t.Position()}
+	}
+	r.Next()
+	return nil
+}
+
+// Unexpected returns the syntax error of an unexpected token
+func (r *Reader[T]) Unexpected(t T) error {
+	return SyntaxError{Message: "unexpected " + DescribeToken(t), Position: t.Position()}
+}
+
+// Unsupported records an unsupported construct, the returned placeholder
+// lets the conversion continue and report further constructs
+func (r *Reader[T]) Unsupported(construct string, position int) *tsl.TSLNode {
+	r.unsupported = append(r.unsupported, UnsupportedError{Construct: construct, Position: position})
+	return tsl.NewIdentifier("")
+}
+
+// Err returns the recorded unsupported constructs ordered by position, or
+// nil if there are none
+func (r *Reader[T]) Err() error {
+	if len(r.unsupported) == 0 {
+		return nil
+	}
+	sort.SliceStable(r.unsupported, func(i, j int) bool {
+		return r.unsupported[i].Position < r.unsupported[j].Position
+	})
+	return r.unsupported
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestConvert(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Convert")
+}
+
+// word is a token of a language of space separated words and quoted
+// strings, the empty word ends the input
+type word struct {
+	text     string
+	quoted   bool
+	position int
+}
+
+func (w word) Is(s string) bool { return !w.quoted && w.text == s }
+func (w word) EOF() bool        { return w.text == "" && !w.quoted }
+func (w word) Quoted() bool     { return w.quoted }
+func (w word) Text() string     { return w.text }
+func (w word) Position() int    { return w.position }
+
+var _ = Describe("Reader", func() {
+	var r Reader[word]
+
+	BeforeEach(func() {
+		r = NewReader([]word{{text: "a"}, {text: "b", quoted: true, position: 2}, {position: 5}})
+	})
+
+	It("Reads tokens up to the end of the input", func() {
+		Expect(r.PeekAt(1).Text()).To(Equal("b"))
+		Expect(r.PeekAt(10).EOF()).To(BeTrue())
+		Expect(r.Accept("a")).To(BeTrue())
+		Expect(r.Accept("b")).To(BeFalse())
+		Expect(r.Next().Text()).To(Equal("b"))
+		Expect(r.Next().EOF()).To(BeTrue())
+		Expect(r.Peek().EOF()).To(BeTrue())
+	})
+
+	It("Describes the found token in syntax errors", func() {
+		Expect(r.Expect("b")).To(Equal(SyntaxError{Message: `expected "b", found "a"`, Position: 0}))
+		r.Next()
+		Expect(r.Unexpected(r.Peek())).To(Equal(SyntaxError{Message: `unexpected string "b"`, Position: 2}))
+		r.Next()
+		Expect(r.Expect("b")).To(MatchError("syntax error at position 5: expected \"b\", found end of input"))
+	})
+
+	It("Returns the unsupported constructs ordered by position", func() {
+		Expect(r.Err()).ToNot(HaveOccurred())
+
+		r.Unsupported("b", 2)
+		r.Unsupported("a", 0)
+		Expect(r.Err()).To(Equal(UnsupportedErrors{{Construct: "a", Position: 0}, {Construct: "b", Position: 2}}))
+	})
+})
@@ -0,0 +1,120 @@
+package graphql
+
+import (
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// comparison is a field of a comparison input type
+type comparison struct {
+	name     string
+	operator tsl.Operator
+	negated  bool
+	list     bool
+}
+
+// Comparison fields, in the order they are converted into TSL
+var comparisons = []comparison{
+	{name: "_eq", operator: tsl.OpEQ},
+	{name: "_neq", operator: tsl.OpNE},
+	{name: "_gt", operator: tsl.OpGT},
+	{name: "_gte", operator: tsl.OpGE},
+	{name: "_lt", operator: tsl.OpLT},
+	{name: "_lte", operator: tsl.OpLE},
+	{name: "_in", operator: tsl.OpIn, list: true},
+	{name: "_nin", operator: tsl.OpIn, negated: true, list: true},
+	{name: "_is_null", operator: tsl.OpIs},
+	{name: "_like", operator: tsl.OpLike},
+	{name: "_nlike", operator: tsl.OpLike, negated: true},
+	{name: "_ilike", operator: tsl.OpILike},
+	{name: "_nilike", operator: tsl.OpILike, negated: true},
+	{name: "_regex", operator: tsl.OpREQ},
+	{name: "_nregex", operator: tsl.OpRNE},
+}
+
+// stringComparisons are the comparison fields only strings have
+var stringComparisons = map[string]bool{
+	"_like": true, "_nlike": true, "_ilike": true, "_nilike": true, "_regex": true, "_nregex": true,
+}
+
+// booleanComparisons are the comparison fields booleans have
+var booleanComparisons = map[string]bool{
+	"_eq": true, "_neq": true, "_is_null": true,
+}
+
+// hasComparison returns true if fields of the type have the comparison
+func hasComparison(fieldType FieldType, name string) bool {
+	switch fieldType {
+	case String:
+		return true
+	case Boolean:
+		return booleanComparisons[name]
+	}
+	return !stringComparisons[name]
+}
+
+// scalars are the GraphQL scalars of the field values
+var scalars = map[FieldType]*gql.Scalar{
+	String:    gql.String,
+	Number:    gql.Float,
+	Boolean:   gql.Boolean,
+	Date:      gql.String,
+	Timestamp: gql.DateTime,
+}
+
+// comparisonName returns the name of the comparison input type of a field type
+func comparisonName(fieldType FieldType) string {
+	switch fieldType {
+	case Date:
+		return "Date_comparison_exp"
+	case Timestamp:
+		return "DateTime_comparison_exp"
+	}
+	return scalars[fieldType].Name() + "_comparison_exp"
+}
+
+// comparisonInputs are the comparison input types, shared by all where
+// input types of a schema
+var comparisonInputs = map[FieldType]*gql.InputObject{}
+
+func init() {
+	for fieldType := String; fieldType <= Timestamp; fieldType++ {
+		fields := gql.InputObjectConfigFieldMap{}
+		for _, c := range comparisons {
+			if !hasComparison(fieldType, c.name) {
+				continue
+			}
+			fields[c.name] = &gql.InputObjectFieldConfig{Type: comparisonType(fieldType, c)}
+		}
+
+		comparisonInputs[fieldType] = gql.NewInputObject(gql.InputObjectConfig{
+			Name:   comparisonName(fieldType),
+			Fields: fields,
+		})
+	}
+}
+
+// comparisonType returns the GraphQL type of a comparison field
+func comparisonType(fieldType FieldType, c comparison) gql.Input {
+	switch {
+	case c.name == "_is_null":
+		return gql.Boolean
+	case c.list:
+		return gql.NewList(gql.NewNonNull(scalars[fieldType]))
+	}
+	return scalars[fieldType]
+}
+
+// writeComparisonSDL writes the comparison input type of a field type
+func writeComparisonSDL(b *strings.Builder, fieldType FieldType) {
+	b.WriteString("input " + comparisonName(fieldType) + " {\n")
+	for _, c := range comparisons {
+		if hasComparison(fieldType, c.name) {
+			b.WriteString("  " + c.name + ": " + comparisonType(fieldType, c).String() + "\n")
+		}
+	}
+	b.WriteString("}\n\n")
+}
@@ -0,0 +1,54 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the graphql package.
+func Example() {
+	// Create a where input type for the filterable fields.
+	where, _ := NewWhereInput("BookWhereInput", Fields{
+		"title":      String,
+		"author":     String,
+		"spec.pages": Number,
+	})
+
+	// A where argument value, as a GraphQL client sends it.
+	var value map[string]interface{}
+	_ = json.Unmarshal([]byte(`{
+		"author": {"_eq": "Joe"},
+		"_or": [{"spec": {"pages": {"_gt": 100}}}, {"title": {"_like": "%Book"}}]
+	}`), &value)
+
+	// Convert the value into a TSL tree, ready for the sql or semantics walkers.
+	tree, _ := where.ToTSL(value)
+	fmt.Println(tree)
+
+	// Convert a TSL tree into a where argument value.
+	tree, _ = tsl.ParseTSL("title IS NOT NULL AND spec.pages BETWEEN 100 AND 200")
+	converted, _ := where.FromTSL(tree)
+	data, _ := json.Marshal(converted)
+	fmt.Println(string(data))
+
+	// Output:
+	// author = 'Joe' AND (spec.pages > 100 OR title LIKE '%Book')
+	// {"spec":{"pages":{"_gte":100,"_lte":200}},"title":{"_is_null":false}}
+}
@@ -0,0 +1,34 @@
+package graphql
+
+import "fmt"
+
+// InvalidNameError is returned when a type or field name is not a valid
+// GraphQL name
+type InvalidNameError struct {
+	Name string
+}
+
+func (e InvalidNameError) Error() string {
+	return fmt.Sprintf("invalid GraphQL name: %s", e.Name)
+}
+
+// UnknownFieldError is returned when a where input value or a TSL tree uses
+// a field that is not in the where input type
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field: %s", e.Field)
+}
+
+// UnsupportedError is returned when a TSL expression can not be expressed
+// as a where input value
+type UnsupportedError struct {
+	Expression string
+	Reason     string
+}
+
+func (e UnsupportedError) Error() string {
+	return fmt.Sprintf("where input can not express %s: %s", e.Expression, e.Reason)
+}
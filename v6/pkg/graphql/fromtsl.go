@@ -0,0 +1,296 @@
+package graphql
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// comparisonNames are the comparison fields of TSL operators
+var comparisonNames = map[tsl.Operator]string{
+	tsl.OpEQ:    "_eq",
+	tsl.OpNE:    "_neq",
+	tsl.OpGT:    "_gt",
+	tsl.OpGE:    "_gte",
+	tsl.OpLT:    "_lt",
+	tsl.OpLE:    "_lte",
+	tsl.OpIn:    "_in",
+	tsl.OpLike:  "_like",
+	tsl.OpILike: "_ilike",
+	tsl.OpREQ:   "_regex",
+	tsl.OpRNE:   "_nregex",
+}
+
+// negatedNames are the comparison fields of negated TSL operators
+var negatedNames = map[tsl.Operator]string{
+	tsl.OpIn:    "_nin",
+	tsl.OpLike:  "_nlike",
+	tsl.OpILike: "_nilike",
+	tsl.OpREQ:   "_nregex",
+}
+
+// FromTSL converts a TSL tree into a where input value, for example:
+//
+//	"author = 'Joe' AND (spec.pages > 100 OR title LIKE '%Book')"
+//
+// becomes {author: {_eq: "Joe"}, _or: [{spec: {pages: {_gt: 100}}}, {title: {_like: "%Book"}}]}.
+// Conditions compare a field with literals, dates and timestamps are
+// written as strings.
+func (w *WhereInput) FromTSL(n *tsl.TSLNode) (map[string]interface{}, error) {
+	switch n.Type() {
+	case tsl.KindBooleanLiteral:
+		if b, _ := n.AsBool(); b {
+			return map[string]interface{}{}, nil
+		}
+		return map[string]interface{}{orKey: []interface{}{}}, nil
+
+	case tsl.KindIdentifier:
+		// A boolean field
+		return w.comparison(n, n, "_eq", tsl.NewBooleanLiteral(true))
+
+	case tsl.KindBinaryExpr:
+		return w.binaryFromTSL(n)
+
+	case tsl.KindUnaryExpr:
+		return w.unaryFromTSL(n)
+	}
+
+	return nil, UnsupportedError{Expression: n.String(), Reason: "expected a condition"}
+}
+
+// binaryFromTSL converts logical operators and comparisons
+func (w *WhereInput) binaryFromTSL(n *tsl.TSLNode) (map[string]interface{}, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpAnd:
+		where := map[string]interface{}{}
+		rest := []interface{}{}
+		for _, operand := range junction(n, tsl.OpAnd) {
+			value, err := w.FromTSL(operand)
+			if err != nil {
+				return nil, err
+			}
+			if mergeable(where, value) {
+				merge(where, value)
+			} else {
+				rest = append(rest, value)
+			}
+		}
+		if len(rest) > 0 {
+			where[andKey] = rest
+		}
+		return where, nil
+
+	case tsl.OpOr:
+		operands := junction(n, tsl.OpOr)
+		list := make([]interface{}, len(operands))
+		for i, operand := range operands {
+			value, err := w.FromTSL(operand)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return map[string]interface{}{orKey: list}, nil
+
+	case tsl.OpIs:
+		if op.Right.Type() != tsl.KindNullLiteral {
+			return nil, tsl.TypeMismatchError{Expected: "null", Got: op.Right.Type()}
+		}
+		return w.comparison(n, op.Left, "_is_null", tsl.NewBooleanLiteral(true))
+
+	case tsl.OpBetween:
+		bounds, ok := op.Right.AsArray()
+		if !ok || len(bounds.Values) != 2 {
+			return nil, tsl.BetweenOperatorError{Message: "BETWEEN requires exactly two values"}
+		}
+		from, err := w.comparison(n, op.Left, "_gte", bounds.Values[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := w.comparison(n, op.Left, "_lte", bounds.Values[1])
+		if err != nil {
+			return nil, err
+		}
+		merge(from, to)
+		return from, nil
+	}
+
+	name, ok := comparisonNames[op.Operator]
+	if !ok {
+		return nil, UnsupportedError{Expression: n.String(), Reason: op.Operator.String() + " has no comparison field"}
+	}
+
+	left, right := op.Left, op.Right
+	if flipped, ok := tsl.FlippedComparisons[op.Operator]; ok && left.Type() != tsl.KindIdentifier {
+		left, right = right, left
+		name = comparisonNames[flipped]
+	}
+	return w.comparison(n, left, name, right)
+}
+
+// unaryFromTSL converts NOT, using the negated comparison fields when possible
+func (w *WhereInput) unaryFromTSL(n *tsl.TSLNode) (map[string]interface{}, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+	if op.Operator != tsl.OpNot {
+		return nil, UnsupportedError{Expression: n.String(), Reason: op.Operator.String() + " has no comparison field"}
+	}
+
+	switch op.Right.Type() {
+	case tsl.KindIdentifier:
+		// A boolean field
+		return w.comparison(n, op.Right, "_eq", tsl.NewBooleanLiteral(false))
+
+	case tsl.KindBinaryExpr:
+		inner := op.Right.Value().(tsl.TSLExpressionOp)
+		if inner.Operator == tsl.OpIs && inner.Right.Type() == tsl.KindNullLiteral {
+			return w.comparison(n, inner.Left, "_is_null", tsl.NewBooleanLiteral(false))
+		}
+		if name, ok := negatedNames[inner.Operator]; ok && inner.Left.Type() == tsl.KindIdentifier {
+			return w.comparison(n, inner.Left, name, inner.Right)
+		}
+	}
+
+	value, err := w.FromTSL(op.Right)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{notKey: value}, nil
+}
+
+// comparison returns the where input value comparing a field with a literal
+func (w *WhereInput) comparison(n, field *tsl.TSLNode, name string, value *tsl.TSLNode) (map[string]interface{}, error) {
+	if field.Type() != tsl.KindIdentifier {
+		return nil, UnsupportedError{Expression: n.String(), Reason: "expected a field on the left side"}
+	}
+
+	identifier, _ := field.AsString()
+	fieldType, ok := w.root.lookup(identifier)
+	if !ok {
+		return nil, UnknownFieldError{Field: identifier}
+	}
+	if !hasComparison(fieldType, name) {
+		return nil, UnsupportedError{Expression: n.String(), Reason: comparisonName(fieldType) + " has no " + name + " field"}
+	}
+
+	var v interface{}
+	if name == "_in" || name == "_nin" {
+		array, ok := value.AsArray()
+		if !ok || value.Type() != tsl.KindArrayLiteral {
+			return nil, tsl.TypeMismatchError{Expected: "array", Got: value.Type()}
+		}
+		list := make([]interface{}, len(array.Values))
+		for i, item := range array.Values {
+			var err error
+			if list[i], err = literalValue(n, fieldType, item); err != nil {
+				return nil, err
+			}
+		}
+		v = list
+	} else if name == "_is_null" {
+		v, _ = value.AsBool()
+	} else {
+		var err error
+		if v, err = literalValue(n, fieldType, value); err != nil {
+			return nil, err
+		}
+	}
+
+	// Nest the comparison in the objects of the identifier path
+	var where interface{} = map[string]interface{}{name: v}
+	segments := strings.Split(identifier, ".")
+	for i := len(segments) - 1; i >= 0; i-- {
+		where = map[string]interface{}{segments[i]: where}
+	}
+	return where.(map[string]interface{}), nil
+}
+
+// lookup returns the type of a field identifier
+func (o *object) lookup(identifier string) (FieldType, bool) {
+	segments := strings.Split(identifier, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		nested, ok := o.objects[segment]
+		if !ok {
+			return 0, false
+		}
+		o = nested
+	}
+
+	fieldType, ok := o.fields[segments[len(segments)-1]]
+	return fieldType, ok
+}
+
+// literalValue returns the field value of a TSL literal
+func literalValue(n *tsl.TSLNode, fieldType FieldType, value *tsl.TSLNode) (interface{}, error) {
+	kind := value.Type()
+	switch {
+	case fieldType == String && kind == tsl.KindStringLiteral,
+		fieldType == Boolean && kind == tsl.KindBooleanLiteral,
+		fieldType == Number && kind == tsl.KindNumericLiteral,
+		fieldType == Date && kind == tsl.KindDateLiteral:
+		return value.Value(), nil
+
+	case fieldType == Timestamp && kind == tsl.KindTimestampLiteral:
+		if t, ok := value.Value().(time.Time); ok {
+			return t.Format(time.RFC3339Nano), nil
+		}
+
+	case fieldType == Number && kind == tsl.KindUnaryExpr:
+		// Negative numbers
+		op := value.Value().(tsl.TSLExpressionOp)
+		if v, ok := op.Right.AsFloat64(); ok && op.Operator == tsl.OpUMinus && op.Right.Type() == tsl.KindNumericLiteral {
+			return -v, nil
+		}
+
+	case kind == tsl.KindIdentifier:
+		return nil, UnsupportedError{Expression: n.String(), Reason: "fields can only be compared with literals"}
+	}
+
+	if kind == tsl.KindBinaryExpr || kind == tsl.KindUnaryExpr {
+		return nil, UnsupportedError{Expression: n.String(), Reason: "fields can only be compared with literals"}
+	}
+	return nil, tsl.TypeMismatchError{Expected: comparisonName(fieldType), Got: kind}
+}
+
+// junction flattens nested AND / OR operators into one list of operands
+func junction(n *tsl.TSLNode, operator tsl.Operator) []*tsl.TSLNode {
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != tsl.KindBinaryExpr || op.Operator != operator {
+		return []*tsl.TSLNode{n}
+	}
+	return append(junction(op.Left, operator), junction(op.Right, operator)...)
+}
+
+// mergeable returns true if the fields of src can be added to dst, nested
+// field objects are merged while comparison and logical fields can not
+// repeat
+func mergeable(dst, src map[string]interface{}) bool {
+	for key, value := range src {
+		existing, ok := dst[key]
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(key, "_") {
+			return false
+		}
+		a, okA := existing.(map[string]interface{})
+		b, okB := value.(map[string]interface{})
+		if !okA || !okB || !mergeable(a, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// merge adds the fields of src to dst
+func merge(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if existing, ok := dst[key].(map[string]interface{}); ok {
+			merge(existing, value.(map[string]interface{}))
+			continue
+		}
+		dst[key] = value
+	}
+}
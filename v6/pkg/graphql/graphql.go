@@ -0,0 +1,247 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graphql helps to create structured GraphQL filter input types,
+// and to convert their values into TSL trees and back.
+package graphql
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+// FieldType is the type of a field that can be filtered
+type FieldType int
+
+// Field types
+const (
+	String FieldType = iota
+	Number
+	Boolean
+	Date
+	Timestamp
+)
+
+// Fields maps TSL identifiers to their types, dotted identifiers such as
+// "spec.pages" become nested input objects.
+type Fields map[string]FieldType
+
+// nameRegexp matches GraphQL names
+var nameRegexp = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// Logical operators of where input objects
+const (
+	andKey = "_and"
+	orKey  = "_or"
+	notKey = "_not"
+)
+
+// WhereInput is a structured filter input type, in the style of Hasura and
+// Prisma where arguments:
+//
+//	{author: {_eq: "Joe"}, _or: [{spec: {pages: {_gt: 100}}}, {title: {_like: "%Book"}}]}
+//
+// Each field has a comparison input type of its type, fields of an object
+// are joined using AND, and the _and, _or and _not fields combine where
+// input objects.
+type WhereInput struct {
+	root *object
+}
+
+// object is a where input object, nested objects hold the fields of
+// dotted identifiers
+type object struct {
+	name    string
+	path    string
+	fields  map[string]FieldType
+	objects map[string]*object
+	input   *gql.InputObject
+}
+
+// NewWhereInput creates a where input type named name for the fields.
+//
+//	where, err := graphql.NewWhereInput("BookWhereInput", graphql.Fields{
+//		"title":      graphql.String,
+//		"author":     graphql.String,
+//		"spec.pages": graphql.Number,
+//	})
+//
+// The path segments of the identifiers must be GraphQL names.
+func NewWhereInput(name string, fields Fields) (*WhereInput, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, InvalidNameError{Name: name}
+	}
+
+	// Sort the identifiers so conflicting names are reported consistently
+	identifiers := make([]string, 0, len(fields))
+	for identifier := range fields {
+		identifiers = append(identifiers, identifier)
+	}
+	sort.Strings(identifiers)
+
+	root := newObject(name, "")
+	for _, identifier := range identifiers {
+		fieldType := fields[identifier]
+		if fieldType < String || fieldType > Timestamp {
+			return nil, InvalidNameError{Name: identifier}
+		}
+
+		o := root
+		segments := strings.Split(identifier, ".")
+		for i, segment := range segments {
+			if !nameRegexp.MatchString(segment) || strings.HasPrefix(segment, "_") {
+				return nil, InvalidNameError{Name: identifier}
+			}
+			if i == len(segments)-1 {
+				if _, ok := o.objects[segment]; ok {
+					return nil, InvalidNameError{Name: identifier}
+				}
+				o.fields[segment] = fieldType
+				break
+			}
+
+			if _, ok := o.fields[segment]; ok {
+				return nil, InvalidNameError{Name: identifier}
+			}
+			if _, ok := o.objects[segment]; !ok {
+				o.objects[segment] = newObject(o.name+"_"+segment, strings.TrimPrefix(o.path+"."+segment, "."))
+			}
+			o = o.objects[segment]
+		}
+	}
+
+	return &WhereInput{root: root}, nil
+}
+
+// newObject creates an empty where input object
+func newObject(name, path string) *object {
+	return &object{name: name, path: path, fields: map[string]FieldType{}, objects: map[string]*object{}}
+}
+
+// keys returns the sorted field and nested object names of an object
+func (o *object) keys() []string {
+	keys := make([]string, 0, len(o.fields)+len(o.objects))
+	for key := range o.fields {
+		keys = append(keys, key)
+	}
+	for key := range o.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// identifier returns the TSL identifier of a field of the object
+func (o *object) identifier(key string) string {
+	if o.path == "" {
+		return key
+	}
+	return o.path + "." + key
+}
+
+// InputObject returns the where input type for graphql-go schemas.
+//
+//	"books": &graphql.Field{
+//		Type: graphql.NewList(bookType),
+//		Args: graphql.FieldConfigArgument{
+//			"where": &graphql.ArgumentConfig{Type: where.InputObject()},
+//		},
+//		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+//			tree, err := where.ToTSL(p.Args["where"].(map[string]interface{}))
+//			...
+//		},
+//	},
+func (w *WhereInput) InputObject() *gql.InputObject {
+	return w.root.inputObject(true)
+}
+
+// inputObject creates the graphql-go input object of an object once
+func (o *object) inputObject(root bool) *gql.InputObject {
+	if o.input != nil {
+		return o.input
+	}
+
+	o.input = gql.NewInputObject(gql.InputObjectConfig{
+		Name: o.name,
+		Fields: gql.InputObjectConfigFieldMapThunk(func() gql.InputObjectConfigFieldMap {
+			fields := gql.InputObjectConfigFieldMap{}
+			for key, fieldType := range o.fields {
+				fields[key] = &gql.InputObjectFieldConfig{Type: comparisonInputs[fieldType]}
+			}
+			for key, nested := range o.objects {
+				fields[key] = &gql.InputObjectFieldConfig{Type: nested.inputObject(false)}
+			}
+			if root {
+				fields[andKey] = &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(o.input))}
+				fields[orKey] = &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(o.input))}
+				fields[notKey] = &gql.InputObjectFieldConfig{Type: o.input}
+			}
+			return fields
+		}),
+	})
+	return o.input
+}
+
+// SDL returns the where input type, its nested and comparison input types,
+// in the GraphQL schema definition language, for schema first servers.
+func (w *WhereInput) SDL() string {
+	var b strings.Builder
+
+	used := map[FieldType]bool{}
+	w.root.writeSDL(&b, true, used)
+
+	types := make([]FieldType, 0, len(used))
+	for fieldType := range used {
+		types = append(types, fieldType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	if used[Timestamp] {
+		b.WriteString("scalar DateTime\n\n")
+	}
+	for _, fieldType := range types {
+		writeComparisonSDL(&b, fieldType)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeSDL writes the input type of an object and its nested objects
+func (o *object) writeSDL(b *strings.Builder, root bool, used map[FieldType]bool) {
+	b.WriteString("input " + o.name + " {\n")
+	if root {
+		b.WriteString("  " + andKey + ": [" + o.name + "!]\n")
+		b.WriteString("  " + orKey + ": [" + o.name + "!]\n")
+		b.WriteString("  " + notKey + ": " + o.name + "\n")
+	}
+	for _, key := range o.keys() {
+		if fieldType, ok := o.fields[key]; ok {
+			used[fieldType] = true
+			b.WriteString("  " + key + ": " + comparisonName(fieldType) + "\n")
+			continue
+		}
+		b.WriteString("  " + key + ": " + o.objects[key].name + "\n")
+	}
+	b.WriteString("}\n\n")
+
+	for _, key := range o.keys() {
+		if nested, ok := o.objects[key]; ok {
+			nested.writeSDL(b, false, used)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"encoding/json"
+	"testing"
+
+	gql "github.com/graphql-go/graphql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+)
+
+func TestGraphQL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GraphQL where input")
+}
+
+// bookFields are the fields of the test where input type
+var bookFields = Fields{
+	"title":        String,
+	"author":       String,
+	"published":    Boolean,
+	"rating":       Number,
+	"released":     Date,
+	"updated":      Timestamp,
+	"spec.pages":   Number,
+	"spec.cover":   String,
+	"spec.size.cm": Number,
+}
+
+// newBookWhere creates the test where input type
+func newBookWhere() *WhereInput {
+	where, err := NewWhereInput("BookWhereInput", bookFields)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	return where
+}
+
+// whereValue decodes a JSON where input value
+func whereValue(data string) map[string]interface{} {
+	value := map[string]interface{}{}
+	ExpectWithOffset(1, json.Unmarshal([]byte(data), &value)).To(Succeed())
+	return value
+}
+
+var _ = Describe("WhereInput", func() {
+	DescribeTable("Converts where input values into TSL",
+		func(value string, phrase string) {
+			tree, err := newBookWhere().ToTSL(whereValue(value))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tree.String()).To(Equal(phrase))
+
+			// The phrase must parse into the same tree
+			parsed, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.String()).To(Equal(phrase))
+		},
+
+		Entry("empty", `{}`, "TRUE"),
+		Entry("equal", `{"author": {"_eq": "Joe"}}`, "author = 'Joe'"),
+		Entry("comparisons", `{"rating": {"_gt": 1, "_lt": 5, "_neq": 3}}`, "rating != 3 AND rating > 1 AND rating < 5"),
+		Entry("range", `{"rating": {"_gte": 1, "_lte": 5}}`, "rating BETWEEN 1 AND 5"),
+		Entry("in", `{"author": {"_in": ["Joe", "Jane"]}}`, "author IN ['Joe', 'Jane']"),
+		Entry("not in", `{"rating": {"_nin": [1, 2]}}`, "rating NOT IN [1, 2]"),
+		Entry("is null", `{"title": {"_is_null": true}}`, "title IS NULL"),
+		Entry("is not null", `{"title": {"_is_null": false}}`, "title IS NOT NULL"),
+		Entry("like", `{"title": {"_like": "%Book", "_nilike": "the%"}}`, "title LIKE '%Book' AND title NOT ILIKE 'the%'"),
+		Entry("regex", `{"title": {"_regex": "^a", "_nregex": "b$"}}`, "title ~= '^a' AND title ~! 'b$'"),
+		Entry("boolean", `{"published": {"_eq": true}}`, "published = TRUE"),
+		Entry("date", `{"released": {"_lt": "2020-01-01"}}`, "released < 2020-01-01"),
+		Entry("timestamp", `{"updated": {"_gt": "2020-01-01T10:00:00Z"}}`, "updated > 2020-01-01T10:00:00Z"),
+		Entry("nested fields", `{"spec": {"pages": {"_gt": 100}, "size": {"cm": {"_lt": 30}}}}`,
+			"spec.pages > 100 AND spec.size.cm < 30"),
+		Entry("fields order", `{"title": {"_eq": "a"}, "author": {"_eq": "b"}}`, "author = 'b' AND title = 'a'"),
+		Entry("and", `{"_and": [{"rating": {"_gt": 1}}, {"rating": {"_lt": 5}}]}`, "rating > 1 AND rating < 5"),
+		Entry("or", `{"author": {"_eq": "Joe"}, "_or": [{"rating": {"_gt": 4}}, {"title": {"_like": "%Go%"}}]}`,
+			"author = 'Joe' AND (rating > 4 OR title LIKE '%Go%')"),
+		Entry("empty or", `{"_or": []}`, "FALSE"),
+		Entry("not", `{"_not": {"author": {"_eq": "Joe"}, "rating": {"_gt": 4}}}`, "NOT (author = 'Joe' AND rating > 4)"),
+		Entry("null values", `{"author": null, "title": {"_eq": "a", "_neq": null}}`, "title = 'a'"),
+	)
+
+	DescribeTable("Converts TSL into where input values and back",
+		func(phrase string, value string, back string) {
+			where := newBookWhere()
+
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := where.FromTSL(tree)
+			Expect(err).ToNot(HaveOccurred())
+			data, err := json.Marshal(actual)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(MatchJSON(value))
+
+			converted, err := where.ToTSL(whereValue(value))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(converted.String()).To(Equal(back))
+		},
+
+		Entry("equal", "author = 'Joe'", `{"author": {"_eq": "Joe"}}`, "author = 'Joe'"),
+		Entry("literal on the left", "4 < rating", `{"rating": {"_gt": 4}}`, "rating > 4"),
+		Entry("negative number", "rating > -1.5", `{"rating": {"_gt": -1.5}}`, "rating > -1.5"),
+		Entry("merged comparisons", "rating > 1 AND rating < 5", `{"rating": {"_gt": 1, "_lt": 5}}`, "rating > 1 AND rating < 5"),
+		Entry("repeated comparisons", "rating > 1 AND rating > 2",
+			`{"rating": {"_gt": 1}, "_and": [{"rating": {"_gt": 2}}]}`, "rating > 1 AND rating > 2"),
+		Entry("between", "rating BETWEEN 1 AND 5", `{"rating": {"_gte": 1, "_lte": 5}}`, "rating BETWEEN 1 AND 5"),
+		Entry("not between", "rating NOT BETWEEN 1 AND 5", `{"_not": {"rating": {"_gte": 1, "_lte": 5}}}`, "rating NOT BETWEEN 1 AND 5"),
+		Entry("not in", "author NOT IN ['a', 'b']", `{"author": {"_nin": ["a", "b"]}}`, "author NOT IN ['a', 'b']"),
+		Entry("not like", "title NOT LIKE 'a%'", `{"title": {"_nlike": "a%"}}`, "title NOT LIKE 'a%'"),
+		Entry("regex", "title ~= 'a' AND author ~! 'b'", `{"title": {"_regex": "a"}, "author": {"_nregex": "b"}}`,
+			"author ~! 'b' AND title ~= 'a'"),
+		Entry("is not null", "title IS NOT NULL", `{"title": {"_is_null": false}}`, "title IS NOT NULL"),
+		Entry("boolean field", "published AND NOT (spec.cover = 'soft')",
+			`{"published": {"_eq": true}, "_not": {"spec": {"cover": {"_eq": "soft"}}}}`,
+			"published = TRUE AND NOT (spec.cover = 'soft')"),
+		Entry("not boolean field", "NOT published", `{"published": {"_eq": false}}`, "published = FALSE"),
+		Entry("nested fields", "spec.pages > 100 AND spec.cover = 'hard'",
+			`{"spec": {"pages": {"_gt": 100}, "cover": {"_eq": "hard"}}}`, "spec.cover = 'hard' AND spec.pages > 100"),
+		Entry("or", "author = 'Joe' AND (rating > 4 OR title LIKE '%Go%')",
+			`{"author": {"_eq": "Joe"}, "_or": [{"rating": {"_gt": 4}}, {"title": {"_like": "%Go%"}}]}`,
+			"author = 'Joe' AND (rating > 4 OR title LIKE '%Go%')"),
+		Entry("dates", "released >= 2020-01-01 AND updated < 2020-01-01T10:00:00Z",
+			`{"released": {"_gte": "2020-01-01"}, "updated": {"_lt": "2020-01-01T10:00:00Z"}}`,
+			"released >= 2020-01-01 AND updated < 2020-01-01T10:00:00Z"),
+	)
+
+	DescribeTable("Reports where input values it can not convert",
+		func(value string, expected error) {
+			_, err := newBookWhere().ToTSL(whereValue(value))
+			Expect(err).To(Equal(expected))
+		},
+
+		Entry("unknown field", `{"isbn": {"_eq": "1"}}`, UnknownFieldError{Field: "isbn"}),
+		Entry("unknown nested field", `{"spec": {"weight": {"_eq": 1}}}`, UnknownFieldError{Field: "spec.weight"}),
+		Entry("nested logical operator", `{"spec": {"_or": []}}`, UnknownFieldError{Field: "spec._or"}),
+		Entry("unknown comparison", `{"rating": {"_like": "1%"}}`, UnknownFieldError{Field: "rating._like"}),
+		Entry("value type", `{"rating": {"_eq": "1"}}`, tsl.TypeMismatchError{Expected: "Float", Got: "string"}),
+		Entry("invalid date", `{"released": {"_eq": "2020-13-01"}}`,
+			tsl.TypeMismatchError{Expected: "Date (YYYY-MM-DD)", Got: "2020-13-01"}),
+		Entry("list value", `{"author": {"_in": "Joe"}}`, tsl.TypeMismatchError{Expected: "list", Got: "string"}),
+	)
+
+	DescribeTable("Reports TSL trees it can not convert",
+		func(phrase string, expected error) {
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = newBookWhere().FromTSL(tree)
+			Expect(err).To(Equal(expected))
+		},
+
+		Entry("unknown field", "isbn = '1'", UnknownFieldError{Field: "isbn"}),
+		Entry("field comparison", "author = title",
+			UnsupportedError{Expression: "author = title", Reason: "fields can only be compared with literals"}),
+		Entry("arithmetic", "rating + 1 > 2",
+			UnsupportedError{Expression: "rating + 1 > 2", Reason: "expected a field on the left side"}),
+		Entry("length", "LEN title > 2",
+			UnsupportedError{Expression: "LEN title > 2", Reason: "expected a field on the left side"}),
+		Entry("comparison field", "rating LIKE '1%'",
+			UnsupportedError{Expression: "rating LIKE '1%'", Reason: "Float_comparison_exp has no _like field"}),
+		Entry("value type", "rating = 'high'", tsl.TypeMismatchError{Expected: "Float_comparison_exp", Got: tsl.KindStringLiteral}),
+	)
+
+	DescribeTable("Rejects invalid names",
+		func(name string, fields Fields, invalid string) {
+			_, err := NewWhereInput(name, fields)
+			Expect(err).To(Equal(InvalidNameError{Name: invalid}))
+		},
+
+		Entry("type name", "Book-Where", Fields{"a": String}, "Book-Where"),
+		Entry("field name", "BookWhere", Fields{"a-b": String}, "a-b"),
+		Entry("array index", "BookWhere", Fields{"a[0]": String}, "a[0]"),
+		Entry("logical field", "BookWhere", Fields{"_or": String}, "_or"),
+		Entry("field and object", "BookWhere", Fields{"a": String, "a.b": String}, "a.b"),
+	)
+
+	It("Prints the schema definition language", func() {
+		where, err := NewWhereInput("BookWhereInput", Fields{"title": String, "spec.pages": Number})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(where.SDL()).To(Equal(`input BookWhereInput {
+  _and: [BookWhereInput!]
+  _or: [BookWhereInput!]
+  _not: BookWhereInput
+  spec: BookWhereInput_spec
+  title: String_comparison_exp
+}
+
+input BookWhereInput_spec {
+  pages: Float_comparison_exp
+}
+
+input String_comparison_exp {
+  _eq: String
+  _neq: String
+  _gt: String
+  _gte: String
+  _lt: String
+  _lte: String
+  _in: [String!]
+  _nin: [String!]
+  _is_null: Boolean
+  _like: String
+  _nlike: String
+  _ilike: String
+  _nilike: String
+  _regex: String
+  _nregex: String
+}
+
+input Float_comparison_exp {
+  _eq: Float
+  _neq: Float
+  _gt: Float
+  _gte: Float
+  _lt: Float
+  _lte: Float
+  _in: [Float!]
+  _nin: [Float!]
+  _is_null: Boolean
+}
+`))
+	})
+
+	It("Filters a graphql-go query", func() {
+		where := newBookWhere()
+
+		books := []map[string]interface{}{
+			{"title": "Go in Action", "author": "Joe", "rating": 4.5, "updated": "2020-01-02T00:00:00Z"},
+			{"title": "Learning Go", "author": "Jane", "rating": 3.0, "updated": "2019-01-02T00:00:00Z"},
+			{"title": "Rust Book", "author": "Joe", "rating": 5.0, "updated": "2021-01-02T00:00:00Z"},
+		}
+		bookType := gql.NewObject(gql.ObjectConfig{
+			Name: "Book",
+			Fields: gql.Fields{
+				"title": &gql.Field{Type: gql.String},
+			},
+		})
+
+		schema, err := gql.NewSchema(gql.SchemaConfig{
+			Query: gql.NewObject(gql.ObjectConfig{
+				Name: "Query",
+				Fields: gql.Fields{
+					"books": &gql.Field{
+						Type: gql.NewList(bookType),
+						Args: gql.FieldConfigArgument{
+							"where": &gql.ArgumentConfig{Type: where.InputObject()},
+						},
+						Resolve: func(p gql.ResolveParams) (interface{}, error) {
+							filter, _ := p.Args["where"].(map[string]interface{})
+							tree, err := where.ToTSL(filter)
+							if err != nil {
+								return nil, err
+							}
+
+							matches := []map[string]interface{}{}
+							for _, book := range books {
+								eval := func(key string) (interface{}, bool) {
+									value, ok := book[key]
+									return value, ok
+								}
+								match, err := semantics.Walk(tree, eval)
+								if err != nil {
+									return nil, err
+								}
+								if match.(bool) {
+									matches = append(matches, book)
+								}
+							}
+							return matches, nil
+						},
+					},
+				},
+			}),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		result := gql.Do(gql.Params{
+			Schema: schema,
+			RequestString: `{
+				books(where: {
+					author: {_eq: "Joe"},
+					_or: [{title: {_like: "%Go%"}}, {updated: {_gt: "2020-06-01T00:00:00Z"}}],
+					_not: {rating: {_lt: 4.6}}
+				}) { title }
+			}`,
+		})
+		Expect(result.Errors).To(BeEmpty())
+
+		data, err := json.Marshal(result.Data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(`{"books": [{"title": "Rust Book"}]}`))
+	})
+})
@@ -0,0 +1,294 @@
+package graphql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// dateLayout is the layout of date field values
+const dateLayout = "2006-01-02"
+
+// ToTSL converts a where input value into a TSL tree, for example:
+//
+//	{author: {_eq: "Joe"}, spec: {pages: {_gt: 100}}}
+//
+// becomes "author = 'Joe' AND spec.pages > 100". Fields are joined in
+// alphabetical order followed by _and, _or and _not, an empty where
+// input value matches everything.
+func (w *WhereInput) ToTSL(where map[string]interface{}) (*tsl.TSLNode, error) {
+	return w.root.toTSL(where, true)
+}
+
+// toTSL converts the value of an object into the conjunction of its fields
+func (o *object) toTSL(where map[string]interface{}, root bool) (*tsl.TSLNode, error) {
+	nodes := []*tsl.TSLNode{}
+
+	for _, key := range o.keys() {
+		value, ok := where[key]
+		if !ok || value == nil {
+			continue
+		}
+
+		var n *tsl.TSLNode
+		var err error
+		if fieldType, ok := o.fields[key]; ok {
+			n, err = comparisonToTSL(o.identifier(key), fieldType, value)
+		} else {
+			var nested map[string]interface{}
+			if nested, err = asObject(o.identifier(key), value); err == nil {
+				n, err = o.objects[key].toTSL(nested, false)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+
+	for key := range where {
+		if _, ok := o.fields[key]; ok {
+			continue
+		}
+		if _, ok := o.objects[key]; ok {
+			continue
+		}
+		if !root || (key != andKey && key != orKey && key != notKey) {
+			return nil, UnknownFieldError{Field: o.identifier(key)}
+		}
+	}
+
+	if root {
+		logical, err := o.logicalToTSL(where)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, logical...)
+	}
+
+	return join(tsl.OpAnd, nodes, true), nil
+}
+
+// logicalToTSL converts the _and, _or and _not fields of a where input value
+func (o *object) logicalToTSL(where map[string]interface{}) ([]*tsl.TSLNode, error) {
+	nodes := []*tsl.TSLNode{}
+
+	for _, key := range []string{andKey, orKey} {
+		value, ok := where[key]
+		if !ok || value == nil {
+			continue
+		}
+
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, tsl.TypeMismatchError{Expected: "list of where input objects", Got: fmt.Sprintf("%T", value)}
+		}
+
+		operands := make([]*tsl.TSLNode, len(list))
+		for i, item := range list {
+			nested, err := asObject(key, item)
+			if err != nil {
+				return nil, err
+			}
+			if operands[i], err = o.toTSL(nested, true); err != nil {
+				return nil, err
+			}
+		}
+
+		if key == andKey {
+			nodes = append(nodes, join(tsl.OpAnd, operands, true))
+		} else {
+			nodes = append(nodes, join(tsl.OpOr, operands, false))
+		}
+	}
+
+	if value, ok := where[notKey]; ok && value != nil {
+		nested, err := asObject(notKey, value)
+		if err != nil {
+			return nil, err
+		}
+		n, err := o.toTSL(nested, true)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, tsl.NewUnaryExpr(tsl.OpNot, n))
+	}
+
+	return nodes, nil
+}
+
+// join joins nodes with a logical operator, no nodes become the empty value
+func join(operator tsl.Operator, nodes []*tsl.TSLNode, empty bool) *tsl.TSLNode {
+	if len(nodes) == 0 {
+		return tsl.NewBooleanLiteral(empty)
+	}
+
+	n := nodes[0]
+	for _, next := range nodes[1:] {
+		n = tsl.NewBinaryExpr(operator, n, next)
+	}
+	return n
+}
+
+// asObject returns a where input object value
+func asObject(key string, value interface{}) (map[string]interface{}, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, tsl.TypeMismatchError{Expected: key + " input object", Got: fmt.Sprintf("%T", value)}
+	}
+	return object, nil
+}
+
+// comparisonToTSL converts the value of a comparison input type into the
+// conjunction of its comparisons
+func comparisonToTSL(identifier string, fieldType FieldType, value interface{}) (*tsl.TSLNode, error) {
+	fields, err := asObject(identifier, value)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range fields {
+		if !hasComparison(fieldType, key) || !isComparison(key) {
+			return nil, UnknownFieldError{Field: identifier + "." + key}
+		}
+	}
+
+	nodes := []*tsl.TSLNode{}
+	field := tsl.NewIdentifier(identifier)
+
+	// A range of _gte and _lte is a BETWEEN
+	if fields["_gte"] != nil && fields["_lte"] != nil {
+		from, err := literal(fieldType, fields["_gte"])
+		if err != nil {
+			return nil, err
+		}
+		to, err := literal(fieldType, fields["_lte"])
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, tsl.NewBinaryExpr(tsl.OpBetween, field, tsl.NewArrayLiteral(from, to)))
+	}
+
+	for _, c := range comparisons {
+		value, ok := fields[c.name]
+		if !ok || value == nil {
+			continue
+		}
+		if (c.name == "_gte" || c.name == "_lte") && fields["_gte"] != nil && fields["_lte"] != nil {
+			continue
+		}
+
+		var n *tsl.TSLNode
+		switch {
+		case c.name == "_is_null":
+			isNull, ok := value.(bool)
+			if !ok {
+				return nil, tsl.TypeMismatchError{Expected: "Boolean", Got: fmt.Sprintf("%T", value)}
+			}
+			n = tsl.NewBinaryExpr(tsl.OpIs, field, tsl.NewNullLiteral())
+			if !isNull {
+				n = tsl.NewUnaryExpr(tsl.OpNot, n)
+			}
+			nodes = append(nodes, n)
+			continue
+
+		case c.list:
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil, tsl.TypeMismatchError{Expected: "list", Got: fmt.Sprintf("%T", value)}
+			}
+			values := make([]*tsl.TSLNode, len(list))
+			for i, item := range list {
+				if values[i], err = literal(fieldType, item); err != nil {
+					return nil, err
+				}
+			}
+			n = tsl.NewBinaryExpr(c.operator, field, tsl.NewArrayLiteral(values...))
+
+		default:
+			v, err := literal(fieldType, value)
+			if err != nil {
+				return nil, err
+			}
+			n = tsl.NewBinaryExpr(c.operator, field, v)
+		}
+
+		if c.negated {
+			n = tsl.NewUnaryExpr(tsl.OpNot, n)
+		}
+		nodes = append(nodes, n)
+	}
+
+	return join(tsl.OpAnd, nodes, true), nil
+}
+
+// isComparison returns true if key is a comparison field
+func isComparison(key string) bool {
+	for _, c := range comparisons {
+		if c.name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// literal converts a field value into a TSL literal of the field type
+func literal(fieldType FieldType, value interface{}) (*tsl.TSLNode, error) {
+	switch fieldType {
+	case String:
+		if s, ok := value.(string); ok {
+			return tsl.NewStringLiteral(s), nil
+		}
+		return nil, tsl.TypeMismatchError{Expected: "String", Got: fmt.Sprintf("%T", value)}
+
+	case Number:
+		switch v := value.(type) {
+		case float64:
+			return tsl.NewNumericLiteral(v), nil
+		case float32:
+			return tsl.NewNumericLiteral(float64(v)), nil
+		case int:
+			return tsl.NewNumericLiteral(float64(v)), nil
+		case int32:
+			return tsl.NewNumericLiteral(float64(v)), nil
+		case int64:
+			return tsl.NewNumericLiteral(float64(v)), nil
+		}
+		return nil, tsl.TypeMismatchError{Expected: "Float", Got: fmt.Sprintf("%T", value)}
+
+	case Boolean:
+		if b, ok := value.(bool); ok {
+			return tsl.NewBooleanLiteral(b), nil
+		}
+		return nil, tsl.TypeMismatchError{Expected: "Boolean", Got: fmt.Sprintf("%T", value)}
+
+	case Date:
+		switch v := value.(type) {
+		case string:
+			if _, err := time.Parse(dateLayout, v); err == nil {
+				return tsl.NewDateLiteral(v), nil
+			}
+		case time.Time:
+			return tsl.NewDateLiteral(v.Format(dateLayout)), nil
+		}
+		return nil, tsl.TypeMismatchError{Expected: "Date (YYYY-MM-DD)", Got: value}
+
+	case Timestamp:
+		switch v := value.(type) {
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return tsl.NewTimestampLiteral(t), nil
+			}
+		case *time.Time:
+			if v != nil {
+				return tsl.NewTimestampLiteral(*v), nil
+			}
+		case time.Time:
+			return tsl.NewTimestampLiteral(v), nil
+		}
+		return nil, tsl.TypeMismatchError{Expected: "DateTime (RFC3339)", Got: value}
+	}
+
+	return nil, tsl.TypeMismatchError{Expected: "field value", Got: value}
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the index package.
+func Example() {
+	// Create a store with a hash index on author and a sorted index on pages.
+	store := NewStore([]map[string]interface{}{
+		{"title": "Book", "author": "Joe", "pages": 100},
+		{"title": "Good Book", "author": "Jane", "pages": 200},
+		{"title": "Other Book", "author": "Joe", "pages": 300},
+	})
+	store.CreateHashIndex("author")
+	store.CreateSortedIndex("pages")
+
+	// Parse input string into a TSL tree.
+	tree, _ := tsl.ParseTSL("pages > 150 and author = 'Joe'")
+
+	p, _ := store.Plan(tree)
+	fmt.Println(p)
+
+	found, _ := store.Search(tree)
+	for _, book := range found {
+		fmt.Println(book["title"])
+	}
+
+	// Output:
+	// probe hash index on author: author = 'Joe'; filter: pages > 150
+	// Other Book
+}
@@ -0,0 +1,195 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package index is a reference in-memory store of records, with hash and
+// sorted indexes on fields, that searches records using TSL filters.
+//
+// Search plans each filter with the plan walker, probes the index the plan
+// chooses instead of scanning all the records, and evaluates the residual
+// filter on the probed records with the semantics walker.
+//
+// Usage:
+//
+//	store := index.NewStore(books)
+//	store.CreateHashIndex("author")
+//	store.CreateSortedIndex("pages")
+//
+//	found, err := store.Search(tree)
+package index
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/plan"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+)
+
+// entry is an indexed value and the position of its record
+type entry struct {
+	key interface{}
+	id  int
+}
+
+// Store holds records and their indexes.
+type Store struct {
+	records []map[string]interface{}
+	kinds   map[string]plan.IndexKind
+	hashes  map[string]map[interface{}][]int
+	sorted  map[string][]entry
+}
+
+// NewStore creates a new Store holding records, records are maps from
+// field names to values.
+func NewStore(records []map[string]interface{}) *Store {
+	return &Store{
+		records: records,
+		kinds:   map[string]plan.IndexKind{},
+		hashes:  map[string]map[interface{}][]int{},
+		sorted:  map[string][]entry{},
+	}
+}
+
+// CreateHashIndex indexes a field for equality and IN lookups.
+func (s *Store) CreateHashIndex(field string) {
+	hash := map[interface{}][]int{}
+	for id, record := range s.records {
+		if key, ok := key(record[field]); ok {
+			hash[key] = append(hash[key], id)
+		}
+	}
+
+	delete(s.sorted, field)
+	s.hashes[field] = hash
+	s.kinds[field] = plan.HashIndex
+}
+
+// CreateSortedIndex indexes a field for equality, IN, range and prefix
+// lookups.
+func (s *Store) CreateSortedIndex(field string) {
+	var entries []entry
+	for id, record := range s.records {
+		if key, ok := key(record[field]); ok {
+			entries = append(entries, entry{key: key, id: id})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return compare(entries[i].key, entries[j].key) < 0 })
+
+	delete(s.hashes, field)
+	s.sorted[field] = entries
+	s.kinds[field] = plan.SortedIndex
+}
+
+// Plan returns the access plan of a filter on the store.
+func (s *Store) Plan(n *tsl.TSLNode) (plan.AccessPlan, error) {
+	return plan.Walk(n, s.kinds)
+}
+
+// Search returns the records matching a filter, in the order they were
+// added to the store.
+func (s *Store) Search(n *tsl.TSLNode) ([]map[string]interface{}, error) {
+	p, err := s.Plan(n)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := s.probe(p)
+	results := []map[string]interface{}{}
+	for _, id := range ids {
+		record := s.records[id]
+		if p.Residual != nil {
+			matched, err := semantics.Walk(p.Residual, func(name string) (interface{}, bool) {
+				value, ok := record[name]
+				return value, ok
+			})
+			if err != nil {
+				return nil, err
+			}
+			if match, ok := matched.(bool); !ok || !match {
+				continue
+			}
+		}
+		results = append(results, record)
+	}
+	return results, nil
+}
+
+// probe returns the sorted positions of the records a plan reads
+func (s *Store) probe(p plan.AccessPlan) []int {
+	if p.Probe == nil {
+		ids := make([]int, len(s.records))
+		for i := range ids {
+			ids[i] = i
+		}
+		return ids
+	}
+
+	var ids []int
+	c := p.Probe
+	switch {
+	case c.Values != nil && p.Index == plan.HashIndex:
+		hash := s.hashes[c.Field]
+		for _, value := range c.Values {
+			ids = append(ids, hash[value]...)
+		}
+	case c.Values != nil:
+		for _, value := range c.Values {
+			ids = append(ids, s.scan(c.Field, &plan.Bound{Value: value, Inclusive: true}, &plan.Bound{Value: value, Inclusive: true}, "")...)
+		}
+	default:
+		ids = s.scan(c.Field, c.Lower, c.Upper, c.Prefix)
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+// scan returns the positions of the records whose value is in a range of a
+// sorted index, and starts with a prefix
+func (s *Store) scan(field string, lower, upper *plan.Bound, prefix string) []int {
+	entries := s.sorted[field]
+
+	// Start at the lower bound, or at the prefix if it is greater
+	var start interface{} = prefix
+	inclusive := true
+	switch {
+	case lower != nil && (prefix == "" || compare(lower.Value, prefix) >= 0):
+		start, inclusive = lower.Value, lower.Inclusive
+	case lower == nil && prefix == "":
+		start = lowest(upper.Value)
+	}
+	first := sort.Search(len(entries), func(i int) bool {
+		c := compare(entries[i].key, start)
+		return c > 0 || (c == 0 && inclusive)
+	})
+
+	var ids []int
+	for _, e := range entries[first:] {
+		if !sameType(e.key, start) {
+			break
+		}
+		if upper != nil {
+			if c := compare(e.key, upper.Value); c > 0 || (c == 0 && !upper.Inclusive) {
+				break
+			}
+		}
+		if prefix != "" && !strings.HasPrefix(e.key.(string), prefix) {
+			break
+		}
+		ids = append(ids, e.id)
+	}
+	return ids
+}
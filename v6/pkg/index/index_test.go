@@ -0,0 +1,149 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/plan"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+)
+
+func TestIndex(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Index store")
+}
+
+// records returns books with a few values in each field
+func records() []map[string]interface{} {
+	titles := []interface{}{nil, "Go", "Go Book", "Gopher", "Python", "a%b", "go"}
+	var all []map[string]interface{}
+	for i := 0; i < 60; i++ {
+		all = append(all, map[string]interface{}{
+			"id":     i,
+			"title":  titles[i%len(titles)],
+			"pages":  float64(i%7) * 50,
+			"author": []interface{}{"Joe", "Jane", nil}[i%3],
+			"loaned": i%4 == 0,
+		})
+	}
+	return all
+}
+
+// search returns the records matching a filter, scanning all the records
+func search(tree *tsl.TSLNode, all []map[string]interface{}) ([]map[string]interface{}, error) {
+	results := []map[string]interface{}{}
+	for _, record := range all {
+		matched, err := semantics.Walk(tree, func(name string) (interface{}, bool) {
+			value, ok := record[name]
+			return value, ok
+		})
+		if err != nil {
+			return nil, err
+		}
+		if match, ok := matched.(bool); ok && match {
+			results = append(results, record)
+		}
+	}
+	return results, nil
+}
+
+var _ = Describe("Store", func() {
+	var store *Store
+
+	BeforeEach(func() {
+		store = NewStore(records())
+		store.CreateHashIndex("id")
+		store.CreateHashIndex("author")
+		store.CreateHashIndex("loaned")
+		store.CreateSortedIndex("pages")
+		store.CreateSortedIndex("title")
+	})
+
+	DescribeTable("Searches using the indexes",
+		func(input string, index plan.IndexKind) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			p, err := store.Plan(tree)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.Index).To(Equal(index))
+
+			found, err := store.Search(tree)
+			Expect(err).ToNot(HaveOccurred())
+			expected, err := search(tree, records())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(Equal(expected))
+		},
+		Entry("equality", "author = 'Joe'", plan.HashIndex),
+		Entry("in list", "id in [1, 5, 70]", plan.HashIndex),
+		Entry("boolean", "loaned and pages > 100", plan.HashIndex),
+		Entry("range", "pages >= 100 and pages < 250", plan.SortedIndex),
+		Entry("upper bound", "pages < 100", plan.SortedIndex),
+		Entry("exclusive bounds", "pages > 100 and pages < 300", plan.SortedIndex),
+		Entry("values on sorted index", "pages in [50, 300]", plan.SortedIndex),
+		Entry("prefix", "title like 'Go%'", plan.SortedIndex),
+		Entry("prefix and pattern", "title like 'Go%k'", plan.SortedIndex),
+		Entry("escaped prefix", "title like 'a\\%%'", plan.SortedIndex),
+		Entry("prefix and bound", "title like 'Go%' and title > 'Go B'", plan.SortedIndex),
+		Entry("string range", "title >= 'Go' and title < 'H'", plan.SortedIndex),
+		Entry("no values", "id = 1 and id = 2", plan.HashIndex),
+		Entry("scan", "author = 'Joe' or pages > 100", plan.NoIndex),
+	)
+
+	It("Returns the same records as a scan", func() {
+		r := rand.New(rand.NewSource(42))
+		pick := func(options ...string) string { return options[r.Intn(len(options))] }
+		operand := func() string {
+			switch r.Intn(6) {
+			case 0:
+				return "author " + pick("=", "!=") + " " + pick("'Joe'", "'Jane'")
+			case 1:
+				return "id " + pick("=", "<", ">", "in") + " " + pick("3", "[1, 2, 30]", "40")
+			case 2:
+				return "pages " + pick("=", "<", "<=", ">", ">=") + " " + fmt.Sprint(r.Intn(7)*50)
+			case 3:
+				return "title " + pick("like 'Go%'", "like 'G%r'", "< 'P'", ">= 'Go '", "= 'Python'", "is null")
+			case 4:
+				return pick("loaned", "not loaned", "pages between 50 and 200")
+			}
+			return "(" + pick("id = 1", "pages > 200") + " or " + pick("author = 'Jane'", "title like 'P%'") + ")"
+		}
+
+		for i := 0; i < 300; i++ {
+			input := operand()
+			for j := r.Intn(3); j > 0; j-- {
+				input += " and " + operand()
+			}
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred(), input)
+
+			expected, err := search(tree, records())
+			if err != nil {
+				continue
+			}
+			found, err := store.Search(tree)
+			Expect(err).ToNot(HaveOccurred(), input)
+			Expect(found).To(Equal(expected), input)
+		}
+	})
+})
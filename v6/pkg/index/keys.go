@@ -0,0 +1,102 @@
+package index
+
+import "math"
+
+// key returns the index key of a value: numbers are float64, strings and
+// booleans are kept, other values are not indexed
+func key(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case string, bool:
+		return v, true
+	}
+	return nil, false
+}
+
+// rank orders the types of keys: booleans, numbers and then strings
+func rank(key interface{}) int {
+	switch key.(type) {
+	case bool:
+		return 0
+	case float64:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// lowest returns the lowest key with the type of a key
+func lowest(key interface{}) interface{} {
+	switch key.(type) {
+	case bool:
+		return false
+	case float64:
+		return math.Inf(-1)
+	}
+	return ""
+}
+
+// sameType returns true if two keys have the same type
+func sameType(a, b interface{}) bool {
+	return rank(a) == rank(b)
+}
+
+// compare orders keys, keys of different types are ordered by type
+func compare(a, b interface{}) int {
+	if rank(a) != rank(b) {
+		return rank(a) - rank(b)
+	}
+
+	switch x := a.(type) {
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	default:
+		sx, sy := a.(string), b.(string)
+		switch {
+		case sx < sy:
+			return -1
+		case sx > sy:
+			return 1
+		}
+		return 0
+	}
+}
@@ -82,6 +82,7 @@ const (
 	OpREQ
 	OpRNE
 	OpUMinus
+	OpMatch
 )
 
 // String returns the string representation of OpType
@@ -139,6 +140,8 @@ func (op OpType) String() string {
 		return "~!"
 	case OpUMinus:
 		return "NEG"
+	case OpMatch:
+		return "MATCH"
 	default:
 		return "UNKNOWN"
 	}
@@ -155,6 +158,10 @@ type Node struct {
 	Right    *Node
 	Children []*Node
 	Position int // Position in the input string for error reporting
+
+	// pattern is the LIKE pattern of a string literal read by the lexer,
+	// with the \% and \_ escapes the string value does not keep
+	pattern *string
 }
 
 // parseSizeValue converts size strings like "5k", "2M", "1G" to numeric values
@@ -344,6 +351,7 @@ func (n *Node) Clone() *Node {
 		Value:    n.Value,
 		Operator: n.Operator,
 		Position: n.Position,
+		pattern:  n.pattern,
 	}
 
 	if n.Left != nil {
@@ -10,6 +10,7 @@ import (
 type Token struct {
 	Type     int    // Token type (matches yacc token constants)
 	Value    string // Token value/text
+	Pattern  string // LIKE pattern of a string literal, keeping the \% and \_ escapes
 	Position int    // Position in input string
 }
 
@@ -33,6 +34,7 @@ type Lexer struct {
 var keywords = map[string]int{
 	"like":    1, // Will be updated to match generated constants
 	"ilike":   1,
+	"escape":  1,
 	"and":     1,
 	"or":      1,
 	"between": 1,
@@ -46,6 +48,7 @@ var keywords = map[string]int{
 	"any":     1,
 	"all":     1,
 	"sum":     1,
+	"match":   1,
 }
 
 // Regular expressions for token patterns
@@ -197,6 +200,15 @@ func (l *Lexer) scanToken() error {
 				Position: l.start,
 			}
 		}
+	case '@':
+		if l.match('@') {
+			l.addToken(ATAT, "@@")
+		} else {
+			return &ParseError{
+				Message:  "Unexpected character '@'",
+				Position: l.start,
+			}
+		}
 	case '\'':
 		return l.scanString('\'')
 	case '"':
@@ -240,7 +252,7 @@ func (l *Lexer) isDateTimePattern() bool {
 				// Look for a complete RFC3339 pattern
 				for i := 10; i < len(remaining); i++ {
 					c := remaining[i]
-					if c == ' ' || c == '\t' || c == '\n' || c == ')' || c == ',' {
+					if c == ' ' || c == '\t' || c == '\n' || c == ')' || c == ',' || c == ']' {
 						// End of potential timestamp
 						candidate := string(remaining[:i])
 						return rfc3339Pattern.MatchString(candidate)
@@ -288,7 +300,7 @@ func (l *Lexer) scanDateTime() error {
 
 // scanString scans a quoted string literal
 func (l *Lexer) scanString(quote rune) error {
-	var value strings.Builder
+	var value, pattern strings.Builder
 
 	for !l.isAtEnd() && l.peek() != quote {
 		c := l.advance()
@@ -297,29 +309,25 @@ func (l *Lexer) scanString(quote rune) error {
 			escaped := l.advance()
 			switch escaped {
 			case 'n':
-				value.WriteRune('\n')
+				c = '\n'
 			case 't':
-				value.WriteRune('\t')
+				c = '\t'
 			case 'r':
-				value.WriteRune('\r')
+				c = '\r'
 			case 'b':
-				value.WriteRune('\b')
+				c = '\b'
 			case 'f':
-				value.WriteRune('\f')
-			case '\\':
-				value.WriteRune('\\')
-			case '\'':
-				value.WriteRune('\'')
-			case '"':
-				value.WriteRune('"')
-			case '`':
-				value.WriteRune('`')
+				c = '\f'
+			case '%', '_':
+				// Keep LIKE pattern escapes for the LIKE operators only
+				pattern.WriteRune(LikeEscape)
+				c = escaped
 			default:
-				value.WriteRune(escaped)
+				c = escaped
 			}
-		} else {
-			value.WriteRune(c)
 		}
+		value.WriteRune(c)
+		pattern.WriteRune(c)
 	}
 
 	if l.isAtEnd() {
@@ -341,6 +349,7 @@ func (l *Lexer) scanString(quote rune) error {
 		l.addToken(RFC3339, str)
 	} else {
 		l.addToken(STRING_LITERAL, str)
+		l.tokens[len(l.tokens)-1].Pattern = pattern.String()
 	}
 
 	return nil
@@ -19,8 +19,10 @@ func TestLexerTokenTypes(t *testing.T) {
 		{"number size suffix", "5Ki", []int{NUMERIC_LITERAL, EOF}},
 		{"date", "2023-01-01", []int{DATE, EOF}},
 		{"rfc3339", "2023-01-01T15:04:05Z", []int{RFC3339, EOF}},
+		{"rfc3339 closing array", "[2023-01-01T15:04:05Z]", []int{LBRACKET, RFC3339, RBRACKET, EOF}},
 		{"operators", "= != < <= > >=", []int{EQ, NE, LT, LE, GT, GE, EOF}},
 		{"regex operators", "~= ~!", []int{REQ, RNE, EOF}},
+		{"full-text search operator", "a @@ b", []int{IDENTIFIER, ATAT, IDENTIFIER, EOF}},
 		{"arithmetic", "+ - * / %", []int{PLUS, MINUS, STAR, SLASH, PERCENT, EOF}},
 		{"parens and brackets", "( ) [ ]", []int{LPAREN, RPAREN, LBRACKET, RBRACKET, EOF}},
 		{"comma", ",", []int{COMMA, EOF}},
@@ -55,7 +57,7 @@ func TestLexerTokenTypes(t *testing.T) {
 }
 
 func TestLexerKeywords(t *testing.T) {
-	keywords := []string{"and", "or", "not", "like", "ilike", "between", "in", "is", "null", "true", "false", "len", "any", "all", "sum"}
+	keywords := []string{"and", "or", "not", "like", "ilike", "between", "in", "is", "null", "true", "false", "len", "any", "all", "sum", "match"}
 
 	for _, kw := range keywords {
 		t.Run(kw, func(t *testing.T) {
@@ -125,11 +127,14 @@ func TestLexerStringEscapes(t *testing.T) {
 		name     string
 		input    string
 		expected string
+		pattern  string
 	}{
-		{"newline", `'hello\nworld'`, "hello\nworld"},
-		{"tab", `'hello\tworld'`, "hello\tworld"},
-		{"escaped quote", `'it\'s'`, "it's"},
-		{"backslash", `'path\\to'`, "path\\to"},
+		{"newline", `'hello\nworld'`, "hello\nworld", "hello\nworld"},
+		{"tab", `'hello\tworld'`, "hello\tworld", "hello\tworld"},
+		{"escaped quote", `'it\'s'`, "it's", "it's"},
+		{"backslash", `'path\\to'`, "path\\to", "path\\to"},
+		{"like percent", `'100\%'`, "100%", `100\%`},
+		{"like underscore", `'a\_b'`, "a_b", `a\_b`},
 	}
 
 	for _, tt := range tests {
@@ -143,6 +148,9 @@ func TestLexerStringEscapes(t *testing.T) {
 			if lexer.tokens[0].Value != tt.expected {
 				t.Errorf("expected value %q, got %q", tt.expected, lexer.tokens[0].Value)
 			}
+			if lexer.tokens[0].Pattern != tt.pattern {
+				t.Errorf("expected pattern %q, got %q", tt.pattern, lexer.tokens[0].Pattern)
+			}
 		})
 	}
 }
@@ -214,6 +222,9 @@ func TestParseErrorPositions(t *testing.T) {
 		{"unexpected token", "a = = b", 4},
 		{"unterminated string", "'hello", 0},
 		{"unicode prefix", "名前 = = b", 5},
+		{"escape pattern not a string", "a like b escape '!'", 7},
+		{"escape longer than one character", "a like 'x' escape 'ab'", 18},
+		{"pattern ends with escape", "a like 'x!' escape '!'", 7},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,94 @@
+package parser
+
+import "strings"
+
+// LikeEscape is the escape character of LIKE and ILIKE patterns. An escaped
+// '%', '_' or escape character matches itself, for example the pattern
+// 'a\%' matches the string "a%".
+const LikeEscape = '\\'
+
+// NewLikeNode creates a LIKE or ILIKE node for a pattern with an ESCAPE
+// clause. The pattern must be a string literal, it is rewritten to use the
+// default LikeEscape character so walkers only handle one escape character.
+// An empty escape string disables escaping.
+func NewLikeNode(op OpType, left, pattern *Node, escape string, pos int) (*Node, error) {
+	pattern = likePattern(pattern)
+	value, ok := pattern.Value.(string)
+	if pattern.Kind != NodeStringLiteral || !ok {
+		return nil, &ParseError{
+			Message:  "ESCAPE requires a string literal pattern",
+			Position: pattern.Position,
+		}
+	}
+
+	escapeRunes := []rune(escape)
+	if len(escapeRunes) > 1 {
+		return nil, &ParseError{
+			Message:  "ESCAPE requires a single character",
+			Position: pos,
+		}
+	}
+
+	var escapeChar rune = -1
+	if len(escapeRunes) == 1 {
+		escapeChar = escapeRunes[0]
+	}
+
+	rewritten, ok := RewriteLikePattern(value, escapeChar)
+	if !ok {
+		return nil, &ParseError{
+			Message:  "LIKE pattern must not end with the escape character",
+			Position: pattern.Position,
+		}
+	}
+
+	return NewBinaryOpNode(op, left, NewStringNode(rewritten, pattern.Position), left.Position), nil
+}
+
+// newStringPatternNode creates a string literal node read by the lexer,
+// keeping its LIKE pattern for the LIKE operators
+func newStringPatternNode(value, pattern string, pos int) *Node {
+	n := NewStringNode(value, pos)
+	n.pattern = &pattern
+	return n
+}
+
+// likePattern returns the LIKE pattern of a string literal read by the
+// lexer, where '\%' and '\_' match a literal '%' and '_', other nodes are
+// returned as is
+func likePattern(n *Node) *Node {
+	if n == nil || n.Kind != NodeStringLiteral || n.pattern == nil {
+		return n
+	}
+	return NewStringNode(*n.pattern, n.Position)
+}
+
+// RewriteLikePattern replaces the escape character of a pattern by
+// LikeEscape, an escape character of -1 means the pattern has no escapes.
+// It returns false if the pattern ends with an unused escape character.
+func RewriteLikePattern(pattern string, escape rune) (string, bool) {
+	var b strings.Builder
+	runes := []rune(pattern)
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		literal := false
+
+		if c == escape {
+			if i+1 == len(runes) {
+				return "", false
+			}
+			i++
+			c = runes[i]
+			literal = true
+		}
+
+		// Special characters that match themselves must be escaped
+		if c == LikeEscape || (literal && (c == '%' || c == '_')) {
+			b.WriteRune(LikeEscape)
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String(), true
+}
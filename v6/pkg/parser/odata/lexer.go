@@ -0,0 +1,161 @@
+package odata
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of an OData token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenNumber
+	tokenDate
+	tokenTimestamp
+	tokenSymbol
+)
+
+// token is one OData token, position is the rune index in the input
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// Is returns true if the token is the keyword or symbol s, OData keywords
+// are case sensitive
+func (t token) Is(s string) bool {
+	return (t.kind == tokenWord || t.kind == tokenSymbol) && t.text == s
+}
+
+// EOF returns true if the token ends the input
+func (t token) EOF() bool {
+	return t.kind == tokenEOF
+}
+
+// Quoted returns true if the token is a string literal
+func (t token) Quoted() bool {
+	return t.kind == tokenString
+}
+
+// Text returns the text of the token
+func (t token) Text() string {
+	return t.text
+}
+
+// Position returns the position of the token in the input
+func (t token) Position() int {
+	return t.position
+}
+
+// Date and time literal patterns
+var (
+	datePattern      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+	timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:\d{2})`)
+)
+
+// tokenize splits an OData filter expression into tokens
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	tokens := []token{}
+
+	for pos := 0; pos < len(runes); {
+		c := runes[pos]
+		start := pos
+
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+
+		case c == '\'':
+			s, end, ok := scanString(runes, pos)
+			if !ok {
+				return nil, SyntaxError{Message: "unterminated string", Position: start}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, position: start})
+			pos = end
+
+		case unicode.IsDigit(c):
+			rest := string(runes[pos:])
+			if m := timestampPattern.FindString(rest); m != "" {
+				tokens = append(tokens, token{kind: tokenTimestamp, text: m, position: start})
+				pos += len([]rune(m))
+			} else if m := datePattern.FindString(rest); m != "" {
+				tokens = append(tokens, token{kind: tokenDate, text: m, position: start})
+				pos += len([]rune(m))
+			} else {
+				pos = scanNumber(runes, pos)
+				tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:pos]), position: start})
+			}
+
+		case unicode.IsLetter(c) || c == '_' || c == '$':
+			pos++
+			for pos < len(runes) && (unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos]) || runes[pos] == '_' || runes[pos] == '.') {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:pos]), position: start})
+
+		case strings.ContainsRune("(),/:-", c):
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), position: start})
+			pos++
+
+		default:
+			return nil, SyntaxError{Message: "unexpected character '" + string(c) + "'", Position: start}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, position: len(runes)}), nil
+}
+
+// scanString scans a quoted string starting at pos, a doubled quote stands
+// for one quote character. It returns the unquoted text and the position
+// after the closing quote.
+func scanString(runes []rune, pos int) (string, int, bool) {
+	var b strings.Builder
+
+	for pos++; pos < len(runes); pos++ {
+		if runes[pos] == '\'' {
+			if pos+1 < len(runes) && runes[pos+1] == '\'' {
+				b.WriteRune('\'')
+				pos++
+				continue
+			}
+			return b.String(), pos + 1, true
+		}
+		b.WriteRune(runes[pos])
+	}
+
+	return "", pos, false
+}
+
+// scanNumber scans a decimal number with an optional exponent and returns
+// the position after it
+func scanNumber(runes []rune, pos int) int {
+	digits := func() {
+		for pos < len(runes) && unicode.IsDigit(runes[pos]) {
+			pos++
+		}
+	}
+
+	digits()
+	if pos+1 < len(runes) && runes[pos] == '.' && unicode.IsDigit(runes[pos+1]) {
+		pos++
+		digits()
+	}
+	if pos+1 < len(runes) && (runes[pos] == 'e' || runes[pos] == 'E') {
+		next := pos + 1
+		if runes[next] == '+' || runes[next] == '-' {
+			next++
+		}
+		if next < len(runes) && unicode.IsDigit(runes[next]) {
+			pos = next
+			digits()
+		}
+	}
+
+	return pos
+}
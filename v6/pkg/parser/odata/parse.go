@@ -0,0 +1,674 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package odata converts OData $filter expressions into TSL trees, and TSL
+// trees back into OData $filter expressions.
+package odata
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/convert"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Parse parses an OData $filter expression and returns the same TSL tree
+// tsl.ParseTSL returns for the equivalent TSL phrase.
+//
+// Supported constructs are the eq, ne, gt, ge, lt, le, in, and, or and not
+// operators, arithmetic, string, number, boolean, null, date and
+// DateTimeOffset literals, member paths, the contains, startswith, endswith,
+// length and matchesPattern functions, tolower and toupper compared with a
+// literal, and the any and all lambda operators over a comparison of the
+// lambda variable.
+//
+// Member paths such as Address/City become the dotted identifier
+// Address.City, comparisons with null become IS [NOT] NULL, and string
+// functions become LIKE and ILIKE patterns. The all operator is true for
+// empty collections while TSL ALL is false, so Tags/all(t: t eq 'a') becomes
+// "LEN Tags = 0 OR ALL (Tags = 'a')".
+//
+// Constructs without a TSL equivalent, such as other functions, has, type
+// casts or typed literals, are returned as UnsupportedErrors holding the
+// position of each construct in the input. Positions are counted in runes.
+//
+//	tree, err := odata.Parse("Name eq 'Milk' and Price lt 2.55")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(tree) // Name = 'Milk' AND Price < 2.55
+func Parse(filter string) (*tsl.TSLNode, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &converter{
+		Reader:     convert.NewReader(tokens),
+		variables:  map[string]string{},
+		allLambdas: map[*tsl.TSLNode]allLambda{},
+		caseFolded: map[*tsl.TSLNode]token{},
+	}
+
+	tree, err := c.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := c.Peek(); t.kind != tokenEOF {
+		return nil, c.Unexpected(t)
+	}
+
+	// Case folding functions that are not compared with a literal
+	for _, t := range c.caseFolded {
+		c.Unsupported("function "+t.text, t.position)
+	}
+
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// comparisons maps OData comparison operators to TSL operators
+var comparisons = map[string]tsl.Operator{
+	"eq": tsl.OpEQ,
+	"ne": tsl.OpNE,
+	"gt": tsl.OpGT,
+	"ge": tsl.OpGE,
+	"lt": tsl.OpLT,
+	"le": tsl.OpLE,
+}
+
+// additiveOperators and multiplicativeOperators map OData arithmetic
+// operators to TSL operators, div and divby both divide
+var (
+	additiveOperators = map[string]tsl.Operator{
+		"add": tsl.OpPlus,
+		"sub": tsl.OpMinus,
+	}
+	multiplicativeOperators = map[string]tsl.Operator{
+		"mul":   tsl.OpStar,
+		"div":   tsl.OpSlash,
+		"divby": tsl.OpSlash,
+		"mod":   tsl.OpPercent,
+	}
+)
+
+// timestampLayouts are the accepted DateTimeOffset literal formats
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+}
+
+// allLambda is the ALL node of an all lambda over a collection
+type allLambda struct {
+	all        *tsl.TSLNode
+	collection string
+}
+
+// converter is a recursive descent parser building the TSL tree
+type converter struct {
+	convert.Reader[token]
+
+	// variables maps the variables of the lambdas being parsed to their
+	// collection identifiers
+	variables map[string]string
+
+	// allLambdas maps the trees of all lambdas to their ALL node and
+	// collection
+	allLambdas map[*tsl.TSLNode]allLambda
+
+	// caseFolded maps the identifiers of tolower and toupper calls to the
+	// function token, until they are compared with a literal
+	caseFolded map[*tsl.TSLNode]token
+}
+
+// or parses the or operator
+func (c *converter) or() (*tsl.TSLNode, error) {
+	left, err := c.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for c.Peek().Is("or") {
+		t := c.Next()
+		right, err := c.and()
+		if err != nil {
+			return nil, err
+		}
+		left = at(tsl.NewBinaryExpr(tsl.OpOr, left, right), t.position)
+	}
+	return left, nil
+}
+
+// and parses the and operator
+func (c *converter) and() (*tsl.TSLNode, error) {
+	left, err := c.not()
+	if err != nil {
+		return nil, err
+	}
+
+	for c.Peek().Is("and") {
+		t := c.Next()
+		right, err := c.not()
+		if err != nil {
+			return nil, err
+		}
+		left = at(c.joinAnd(left, right), t.position)
+	}
+	return left, nil
+}
+
+// joinAnd joins two operands of and. The all lambda is true for empty
+// collections while ALL is false, so `Tags/any() and Tags/all(...)` becomes
+// ALL.
+func (c *converter) joinAnd(left, right *tsl.TSLNode) *tsl.TSLNode {
+	if lambda, ok := c.allLambdas[right]; ok {
+		if isNonEmptyCheck(left, lambda.collection) {
+			return lambda.all
+		}
+		if op, ok := left.AsExprOp(); ok && left.Type() == tsl.KindBinaryExpr && op.Operator == tsl.OpAnd && isNonEmptyCheck(op.Right, lambda.collection) {
+			return tsl.NewBinaryExpr(tsl.OpAnd, op.Left, lambda.all)
+		}
+	}
+	return tsl.NewBinaryExpr(tsl.OpAnd, left, right)
+}
+
+// isNonEmptyCheck returns true if n is `LEN collection > 0`
+func isNonEmptyCheck(n *tsl.TSLNode, collection string) bool {
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != tsl.KindBinaryExpr || op.Operator != tsl.OpGT {
+		return false
+	}
+	size, ok := op.Left.AsExprOp()
+	if !ok || op.Left.Type() != tsl.KindUnaryExpr || size.Operator != tsl.OpLen || size.Right.Type() != tsl.KindIdentifier {
+		return false
+	}
+	zero, ok := op.Right.AsFloat64()
+	return ok && op.Right.Type() == tsl.KindNumericLiteral && zero == 0 && size.Right.Value() == collection
+}
+
+// not parses the not operator, it applies to a comparison
+func (c *converter) not() (*tsl.TSLNode, error) {
+	if t := c.Peek(); t.Is("not") {
+		c.Next()
+		right, err := c.not()
+		if err != nil {
+			return nil, err
+		}
+		return at(tsl.NewUnaryExpr(tsl.OpNot, right), t.position), nil
+	}
+	return c.comparison()
+}
+
+// comparison parses the comparison operators, in and has
+func (c *converter) comparison() (*tsl.TSLNode, error) {
+	left, err := c.additive()
+	if err != nil {
+		return nil, err
+	}
+
+	t := c.Peek()
+	switch {
+	case t.kind == tokenWord && comparisons[t.text] != 0:
+		c.Next()
+		right, err := c.additive()
+		if err != nil {
+			return nil, err
+		}
+		return c.compare(comparisons[t.text], left, right, t), nil
+
+	case t.Is("in"):
+		c.Next()
+		list, err := c.list()
+		if err != nil {
+			return nil, err
+		}
+		return at(tsl.NewBinaryExpr(tsl.OpIn, left, list), t.position), nil
+
+	case t.Is("has"):
+		c.Next()
+		if _, err := c.additive(); err != nil {
+			return nil, err
+		}
+		return c.Unsupported("has operator", t.position), nil
+	}
+
+	return left, nil
+}
+
+// compare builds a comparison, comparisons with null become IS NULL and
+// case folded fields compared with a literal become ILIKE
+func (c *converter) compare(operator tsl.Operator, left, right *tsl.TSLNode, t token) *tsl.TSLNode {
+	if left.Type() == tsl.KindNullLiteral {
+		left, right = right, left
+	}
+	if right.Type() == tsl.KindNullLiteral {
+		switch operator {
+		case tsl.OpEQ:
+			return at(tsl.NewBinaryExpr(tsl.OpIs, left, right), t.position)
+		case tsl.OpNE:
+			return at(tsl.NewUnaryExpr(tsl.OpNot, at(tsl.NewBinaryExpr(tsl.OpIs, left, right), t.position)), t.position)
+		}
+		return c.Unsupported("comparison "+t.text+" with null", t.position)
+	}
+
+	if fold, ok := c.caseFolded[left]; ok && (operator == tsl.OpEQ || operator == tsl.OpNE) {
+		if value, ok := foldedLiteral(fold, right); ok {
+			delete(c.caseFolded, left)
+			n := at(tsl.NewBinaryExpr(tsl.OpILike, left, at(tsl.NewStringLiteral(tsl.EscapeLike(value)), right.Position())), t.position)
+			if operator == tsl.OpNE {
+				n = at(tsl.NewUnaryExpr(tsl.OpNot, n), t.position)
+			}
+			return n
+		}
+	}
+
+	return at(tsl.NewBinaryExpr(operator, left, right), t.position)
+}
+
+// foldedLiteral returns the value of a string literal compared with a case
+// folded field, the literal must already be case folded
+func foldedLiteral(fold token, n *tsl.TSLNode) (string, bool) {
+	value, ok := n.AsString()
+	if !ok || n.Type() != tsl.KindStringLiteral {
+		return "", false
+	}
+	if fold.text == "tolower" {
+		return value, value == strings.ToLower(value)
+	}
+	return strings.ToLower(value), value == strings.ToUpper(value)
+}
+
+// list parses the parenthesized list of the in operator
+func (c *converter) list() (*tsl.TSLNode, error) {
+	start := c.Peek()
+	if err := c.Expect("("); err != nil {
+		return nil, err
+	}
+
+	values := []*tsl.TSLNode{}
+	for {
+		value, err := c.additive()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if !c.Accept(",") {
+			break
+		}
+	}
+	if err := c.Expect(")"); err != nil {
+		return nil, err
+	}
+	return at(tsl.NewArrayLiteral(values...), start.position), nil
+}
+
+// additive parses the add and sub operators
+func (c *converter) additive() (*tsl.TSLNode, error) {
+	left, err := c.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := c.Peek()
+		operator, ok := additiveOperators[t.text]
+		if !ok || t.kind != tokenWord {
+			return left, nil
+		}
+		c.Next()
+		right, err := c.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = at(tsl.NewBinaryExpr(operator, left, right), t.position)
+	}
+}
+
+// multiplicative parses the mul, div, divby and mod operators
+func (c *converter) multiplicative() (*tsl.TSLNode, error) {
+	left, err := c.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := c.Peek()
+		operator, ok := multiplicativeOperators[t.text]
+		if !ok || t.kind != tokenWord {
+			return left, nil
+		}
+		c.Next()
+		right, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = at(tsl.NewBinaryExpr(operator, left, right), t.position)
+	}
+}
+
+// unary parses the negation operator
+func (c *converter) unary() (*tsl.TSLNode, error) {
+	if t := c.Peek(); t.Is("-") {
+		c.Next()
+		right, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		return at(tsl.NewUnaryExpr(tsl.OpUMinus, right), t.position), nil
+	}
+	return c.primary()
+}
+
+// primary parses literals, parenthesized expressions, function calls and
+// member paths
+func (c *converter) primary() (*tsl.TSLNode, error) {
+	t := c.Next()
+
+	switch t.kind {
+	case tokenString:
+		return at(tsl.NewStringLiteral(t.text), t.position), nil
+
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, SyntaxError{Message: "invalid number " + t.text, Position: t.position}
+		}
+		return at(tsl.NewNumericLiteral(value), t.position), nil
+
+	case tokenDate:
+		if _, err := time.Parse("2006-01-02", t.text); err != nil {
+			return nil, SyntaxError{Message: "invalid date " + t.text, Position: t.position}
+		}
+		return at(tsl.NewDateLiteral(t.text), t.position), nil
+
+	case tokenTimestamp:
+		for _, layout := range timestampLayouts {
+			if value, err := time.Parse(layout, t.text); err == nil {
+				return at(tsl.NewTimestampLiteral(value), t.position), nil
+			}
+		}
+		return nil, SyntaxError{Message: "invalid DateTimeOffset " + t.text, Position: t.position}
+
+	case tokenSymbol:
+		if t.Is("(") {
+			n, err := c.or()
+			if err != nil {
+				return nil, err
+			}
+			if err := c.Expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+
+	case tokenWord:
+		switch t.text {
+		case "true", "false":
+			return at(tsl.NewBooleanLiteral(t.text == "true"), t.position), nil
+		case "null":
+			return at(tsl.NewNullLiteral(), t.position), nil
+		}
+
+		switch next := c.Peek(); {
+		case next.Is("("):
+			return c.call(t)
+		case next.kind == tokenString:
+			c.Next()
+			return c.Unsupported("typed literal "+t.text, t.position), nil
+		}
+		return c.member(t)
+	}
+
+	return nil, c.Unexpected(t)
+}
+
+// member parses a member path, paths starting with a lambda variable
+// refer to the lambda collection
+func (c *converter) member(first token) (*tsl.TSLNode, error) {
+	segments := []string{first.text}
+	if collection, ok := c.variables[first.text]; ok {
+		segments[0] = collection
+	}
+
+	var unsupported *token
+	if strings.HasPrefix(first.text, "$") || strings.Contains(first.text, ".") {
+		unsupported = &first
+	}
+
+	for c.Peek().Is("/") {
+		c.Next()
+		t := c.Next()
+		if t.kind != tokenWord {
+			return nil, c.Unexpected(t)
+		}
+
+		if (t.text == "any" || t.text == "all") && c.Peek().Is("(") && unsupported == nil {
+			return c.lambda(strings.Join(segments, "."), first, t)
+		}
+		if (strings.HasPrefix(t.text, "$") || strings.Contains(t.text, ".")) && unsupported == nil {
+			unsupported = &t
+		}
+		segments = append(segments, t.text)
+	}
+
+	if unsupported != nil {
+		return c.Unsupported("path segment "+unsupported.text, unsupported.position), nil
+	}
+	return at(tsl.NewIdentifier(strings.Join(segments, ".")), first.position), nil
+}
+
+// lambda converts the any and all lambda operators into ANY and ALL
+func (c *converter) lambda(collection string, first, name token) (*tsl.TSLNode, error) {
+	c.Next()
+	array := at(tsl.NewIdentifier(collection), first.position)
+
+	// any() is true for collections with elements
+	if c.Accept(")") {
+		if name.text == "all" {
+			return nil, SyntaxError{Message: "all requires a lambda expression", Position: name.position}
+		}
+		size := at(tsl.NewUnaryExpr(tsl.OpLen, array), name.position)
+		return at(tsl.NewBinaryExpr(tsl.OpGT, size, tsl.NewNumericLiteral(0)), name.position), nil
+	}
+
+	variable := c.Next()
+	if variable.kind != tokenWord {
+		return nil, c.Unexpected(variable)
+	}
+	if err := c.Expect(":"); err != nil {
+		return nil, err
+	}
+
+	// Inside the lambda the variable stands for the collection
+	shadowed, isShadowed := c.variables[variable.text]
+	c.variables[variable.text] = collection
+	predicate, err := c.or()
+	if isShadowed {
+		c.variables[variable.text] = shadowed
+	} else {
+		delete(c.variables, variable.text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Expect(")"); err != nil {
+		return nil, err
+	}
+
+	op, ok := predicate.AsExprOp()
+	if !ok || predicate.Type() != tsl.KindBinaryExpr || op.Left.Type() != tsl.KindIdentifier || !isElement(op.Left.Value().(string), collection) {
+		return c.Unsupported("lambda "+name.text+" without a comparison of the variable", name.position), nil
+	}
+	switch op.Operator {
+	case tsl.OpAnd, tsl.OpOr:
+		return c.Unsupported("lambda "+name.text+" without a comparison of the variable", name.position), nil
+	}
+
+	if name.text == "any" {
+		return at(tsl.NewUnaryExpr(tsl.OpAny, predicate), name.position), nil
+	}
+
+	// ALL is false for empty collections, the all lambda is true
+	all := at(tsl.NewUnaryExpr(tsl.OpAll, predicate), name.position)
+	empty := tsl.NewBinaryExpr(tsl.OpEQ, tsl.NewUnaryExpr(tsl.OpLen, tsl.NewIdentifier(collection)), tsl.NewNumericLiteral(0))
+	node := tsl.NewBinaryExpr(tsl.OpOr, empty, all)
+	c.allLambdas[node] = allLambda{all: all, collection: collection}
+	return node, nil
+}
+
+// isElement returns true if name is the collection identifier or a field
+// of its elements
+func isElement(name, collection string) bool {
+	return name == collection || strings.HasPrefix(name, collection+".")
+}
+
+// call converts a function call
+func (c *converter) call(name token) (*tsl.TSLNode, error) {
+	c.Next()
+
+	args := []*tsl.TSLNode{}
+	if !c.Accept(")") {
+		for {
+			arg, err := c.or()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !c.Accept(",") {
+				break
+			}
+		}
+		if err := c.Expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	switch name.text {
+	case "contains", "startswith", "endswith", "matchesPattern":
+		if len(args) != 2 || args[1].Type() != tsl.KindStringLiteral || args[0].Type() != tsl.KindIdentifier {
+			break
+		}
+		value, _ := args[1].AsString()
+
+		pattern := tsl.EscapeLike(value)
+		switch name.text {
+		case "contains":
+			pattern = "%" + pattern + "%"
+		case "startswith":
+			pattern = pattern + "%"
+		case "endswith":
+			pattern = "%" + pattern
+		case "matchesPattern":
+			like, ok := regexpToLike(value)
+			if !ok {
+				if _, folded := c.caseFolded[args[0]]; folded {
+					return c.Unsupported("function matchesPattern of a case folded field", name.position), nil
+				}
+				return at(tsl.NewBinaryExpr(tsl.OpREQ, args[0], args[1]), name.position), nil
+			}
+			pattern = like
+		}
+
+		// Case folded fields are compared with ILIKE
+		operator := tsl.OpLike
+		if fold, ok := c.caseFolded[args[0]]; ok {
+			folded, ok := foldedLiteral(fold, tsl.NewStringLiteral(pattern))
+			if !ok {
+				break
+			}
+			delete(c.caseFolded, args[0])
+			pattern = folded
+			operator = tsl.OpILike
+		}
+		return at(tsl.NewBinaryExpr(operator, args[0], at(tsl.NewStringLiteral(pattern), args[1].Position())), name.position), nil
+
+	case "length":
+		if len(args) == 1 && args[0].Type() == tsl.KindIdentifier {
+			return at(tsl.NewUnaryExpr(tsl.OpLen, args[0]), name.position), nil
+		}
+
+	case "tolower", "toupper":
+		if len(args) == 1 && args[0].Type() == tsl.KindIdentifier {
+			c.caseFolded[args[0]] = name
+			return args[0], nil
+		}
+	}
+
+	return c.Unsupported("function "+name.text, name.position), nil
+}
+
+// likeWildcards are the regular expressions the serializer writes for LIKE
+// wildcards, longest first
+var likeWildcards = []struct{ expr, wildcard string }{
+	{`[\s\S]*`, "%"},
+	{`[\s\S]`, "_"},
+}
+
+// regexpMeta are the regular expression characters that need escaping
+const regexpMeta = `\.+*?()|[]{}^$`
+
+// regexpToLike converts an anchored regular expression of literals and
+// wildcards, as written by the serializer, back into a LIKE pattern
+func regexpToLike(re string) (string, bool) {
+	if !strings.HasPrefix(re, "^") || !strings.HasSuffix(re, "$") || strings.HasSuffix(re, `\$`) || len(re) < 2 {
+		return "", false
+	}
+	re = re[1 : len(re)-1]
+
+	var b strings.Builder
+	runes := []rune(re)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if r == '[' {
+			matched := false
+			for _, w := range likeWildcards {
+				if strings.HasPrefix(string(runes[i:]), w.expr) {
+					b.WriteString(w.wildcard)
+					i += len(w.expr) - 1
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return "", false
+			}
+			continue
+		}
+
+		if r == '\\' {
+			if i+1 == len(runes) || !strings.ContainsRune(regexpMeta, runes[i+1]) {
+				return "", false
+			}
+			i++
+			r = runes[i]
+		} else if strings.ContainsRune(regexpMeta, r) {
+			return "", false
+		}
+		b.WriteString(tsl.EscapeLike(string(r)))
+	}
+
+	return b.String(), true
+}
+
+// at sets the input position of a node
+func at(n *tsl.TSLNode, position int) *tsl.TSLNode {
+	n.SetPosition(position)
+	return n
+}
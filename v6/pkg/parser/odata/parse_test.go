@@ -0,0 +1,206 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package odata
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/internal/tsltest"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestOData(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OData filter parser")
+}
+
+var _ = Describe("Parse", func() {
+	// Examples from OData Version 4.01 URL Conventions, 5.1.1 Built-in
+	// Filter Operations and 5.1.1.x Built-in Query Functions
+	DescribeTable("Parses the specification examples",
+		func(filter string, expected string) {
+			tree, err := Parse(filter)
+			Expect(err).ToNot(HaveOccurred())
+			tsltest.ExpectTree(tree, expected)
+		},
+
+		// Logical operators
+		Entry("eq", "Name eq 'Milk'", "Name = 'Milk'"),
+		Entry("ne", "Name ne 'Milk'", "Name != 'Milk'"),
+		Entry("gt", "Name gt 'Milk'", "Name > 'Milk'"),
+		Entry("ge", "Name ge 'Milk'", "Name >= 'Milk'"),
+		Entry("lt", "Name lt 'Milk'", "Name < 'Milk'"),
+		Entry("le", "Name le 'Milk'", "Name <= 'Milk'"),
+		Entry("and", "Name eq 'Milk' and Price lt 2.55", "Name = 'Milk' AND Price < 2.55"),
+		Entry("or", "Name eq 'Milk' or Price lt 2.55", "Name = 'Milk' OR Price < 2.55"),
+		Entry("not", "not endswith(Name,'ilk')", "Name NOT LIKE '%ilk'"),
+		Entry("in", "Name in ('Milk', 'Cheese')", "Name IN ['Milk', 'Cheese']"),
+
+		// Arithmetic operators
+		Entry("add", "Price add 2.45 eq 5.00", "Price + 2.45 = 5"),
+		Entry("sub", "Price sub 0.55 eq 2.00", "Price - 0.55 = 2"),
+		Entry("negation", "-Price lt 3.55", "-Price < 3.55"),
+		Entry("mul", "Price mul 2.0 eq 5.10", "Price * 2 = 5.1"),
+		Entry("div", "Price div 2 eq 2.55", "Price / 2 = 2.55"),
+		Entry("divby", "Rating divby 2 eq 2.5", "Rating / 2 = 2.5"),
+		Entry("mod", "Rating mod 5 eq 0", "Rating % 5 = 0"),
+		Entry("grouping", "(4 add 5) mod (4 sub 1) eq 0", "(4 + 5) % (4 - 1) = 0"),
+		Entry("precedence", "Price add 2 mul 3 gt 1 or not (Price lt 1) and Rating eq 1",
+			"Price + 2 * 3 > 1 OR NOT (Price < 1) AND Rating = 1"),
+
+		// Functions
+		Entry("contains", "contains(CompanyName,'freds')", "CompanyName LIKE '%freds%'"),
+		Entry("endswith", "endswith(CompanyName,'Futterkiste')", "CompanyName LIKE '%Futterkiste'"),
+		Entry("startswith", "startswith(CompanyName,'Alfr')", "CompanyName LIKE 'Alfr%'"),
+		Entry("escaped wildcards", "contains(CompanyName,'100%_')", `CompanyName LIKE '%100\\%\\_%'`),
+		Entry("length", "length(CompanyName) eq 19", "LEN CompanyName = 19"),
+		Entry("tolower", "tolower(CompanyName) eq 'alfreds futterkiste'", "CompanyName ILIKE 'alfreds futterkiste'"),
+		Entry("toupper", "toupper(CompanyName) ne 'ALFREDS FUTTERKISTE'", "CompanyName NOT ILIKE 'alfreds futterkiste'"),
+		Entry("case insensitive contains", "contains(tolower(CompanyName),'freds')", "CompanyName ILIKE '%freds%'"),
+		Entry("matchesPattern", "matchesPattern(CompanyName,'^A.*e$')", "CompanyName ~= '^A.*e$'"),
+		Entry("matchesPattern wildcards", `matchesPattern(CompanyName,'^A[\s\S]\.[\s\S]*$')`, `CompanyName LIKE 'A_.%'`),
+
+		// Literals, paths and lambdas
+		Entry("member path", "Address/City eq 'Redmond'", "Address.City = 'Redmond'"),
+		Entry("null", "Name eq null and null ne Address/City", "Name IS NULL AND Address.City IS NOT NULL"),
+		Entry("boolean", "Active eq true and Deleted eq false", "Active = TRUE AND Deleted = FALSE"),
+		Entry("quoted string", "Name eq 'O''Neil'", `Name = 'O\'Neil'`),
+		Entry("date", "BirthDate lt 2000-01-01", "BirthDate < 2000-01-01"),
+		Entry("DateTimeOffset", "CreatedAt gt 2012-12-03T07:16:23Z", "CreatedAt > 2012-12-03T07:16:23Z"),
+		Entry("exponent", "Price lt 1.5e3", "Price < 1500"),
+		Entry("any", "Items/any(d:d/Quantity gt 100)", "ANY (Items.Quantity > 100)"),
+		Entry("any element", "Tags/any(t: t eq 'Fruit')", "ANY (Tags = 'Fruit')"),
+		Entry("any without lambda", "Tags/any()", "LEN Tags > 0"),
+		Entry("all", "Items/all(d:d/Quantity gt 100)", "LEN Items = 0 OR ALL (Items.Quantity > 100)"),
+		Entry("all of non empty", "Items/any() and Items/all(d:d/Quantity gt 100)", "ALL (Items.Quantity > 100)"),
+	)
+
+	DescribeTable("Reports unsupported constructs with positions",
+		func(filter string, expected ...UnsupportedError) {
+			_, err := Parse(filter)
+			Expect(err).To(Equal(UnsupportedErrors(expected)))
+		},
+
+		Entry("function", "year(BirthDate) eq 2000",
+			UnsupportedError{Construct: "function year", Position: 0}),
+		Entry("several constructs", "year(Birth) eq 2000 and Style has Sales.Pattern'Yellow'",
+			UnsupportedError{Construct: "function year", Position: 0},
+			UnsupportedError{Construct: "has operator", Position: 30},
+			UnsupportedError{Construct: "typed literal Sales.Pattern", Position: 34}),
+		Entry("case folding without literal", "tolower(Name) eq Title",
+			UnsupportedError{Construct: "function tolower", Position: 0}),
+		Entry("comparison with null", "Price gt null",
+			UnsupportedError{Construct: "comparison gt with null", Position: 6}),
+		Entry("root path", "$it/Name eq 'x'",
+			UnsupportedError{Construct: "path segment $it", Position: 0}),
+		Entry("type cast", "Item/Sales.Product/Price gt 1",
+			UnsupportedError{Construct: "path segment Sales.Product", Position: 5}),
+		Entry("lambda with logical operators", "Tags/any(t:t eq 'a' or t eq 'b')",
+			UnsupportedError{Construct: "lambda any without a comparison of the variable", Position: 5}),
+	)
+
+	DescribeTable("Returns syntax errors with positions",
+		func(filter string, position int) {
+			_, err := Parse(filter)
+			Expect(err).To(BeAssignableToTypeOf(SyntaxError{}))
+			Expect(err.(SyntaxError).Position).To(Equal(position))
+		},
+
+		Entry("unterminated string", "Name eq 'x", 8),
+		Entry("missing operand", "Name eq", 7),
+		Entry("missing parenthesis", "(Name eq 'x'", 12),
+		Entry("trailing tokens", "Name eq 'x' Price", 12),
+		Entry("all without lambda", "Tags/all()", 5),
+		Entry("invalid date", "Day eq 2020-13-01", 7),
+		Entry("unexpected character", "Name eq #", 8),
+	)
+
+	It("Sets positions of the OData input", func() {
+		tree, err := Parse("Price lt 5 and startswith(Name,'x')")
+		Expect(err).ToNot(HaveOccurred())
+
+		op, _ := tree.AsExprOp()
+		like, _ := op.Right.AsExprOp()
+		Expect(op.Right.Position()).To(Equal(15))
+		Expect(like.Left.Position()).To(Equal(26))
+		Expect(like.Right.Position()).To(Equal(31))
+	})
+})
+
+var _ = Describe("Serialize", func() {
+	DescribeTable("Serializes TSL trees and parses them back",
+		func(phrase string, filter string, back string) {
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := Serialize(tree)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(filter))
+
+			parsed, err := Parse(actual)
+			Expect(err).ToNot(HaveOccurred())
+			tsltest.ExpectTree(parsed, back)
+		},
+
+		Entry("comparison", "name = 'joe'", "name eq 'joe'", "name = 'joe'"),
+		Entry("logical operators", "a = 1 AND (b = 2 OR c != 3)", "a eq 1 and (b eq 2 or c ne 3)", "a = 1 AND (b = 2 OR c != 3)"),
+		Entry("not", "NOT (a = 1 AND b = 2)", "not (a eq 1 and b eq 2)", "NOT (a = 1 AND b = 2)"),
+		Entry("arithmetic", "a + b * 2 > 1", "a add b mul 2 gt 1", "a + b * 2 > 1"),
+		Entry("arithmetic grouping", "a - (b - c) / 2 = -1.5", "a sub (b sub c) div 2 eq -1.5", "a - (b - c) / 2 = -1.5"),
+		Entry("in", "city IN ['rome', 'paris']", "city in ('rome','paris')", "city IN ['rome', 'paris']"),
+		Entry("not in", "id NOT IN [1, 2]", "not (id in (1,2))", "id NOT IN [1, 2]"),
+		Entry("between", "age BETWEEN 20 AND 30", "age ge 20 and age le 30", "age >= 20 AND age <= 30"),
+		Entry("is null", "email IS NULL OR phone IS NOT NULL", "email eq null or phone ne null", "email IS NULL OR phone IS NOT NULL"),
+		Entry("starts with", "name LIKE 'jo%'", "startswith(name,'jo')", "name LIKE 'jo%'"),
+		Entry("ends with", "name LIKE '%jo'", "endswith(name,'jo')", "name LIKE '%jo'"),
+		Entry("contains", "name NOT LIKE '%jo%'", "not contains(name,'jo')", "name NOT LIKE '%jo%'"),
+		Entry("escaped wildcards", `name LIKE '100\\%%'`, "startswith(name,'100%')", `name LIKE '100\\%%'`),
+		Entry("like without wildcards", "name LIKE 'jo'", "name eq 'jo'", "name = 'jo'"),
+		Entry("like pattern", "name LIKE 'j_o%'", `matchesPattern(name,'^j[\s\S]o[\s\S]*$')`, "name LIKE 'j_o%'"),
+		Entry("ilike", "name ILIKE 'Jo%'", "startswith(tolower(name),'jo')", "name ILIKE 'jo%'"),
+		Entry("ilike pattern", "name ILIKE '_O'", `matchesPattern(tolower(name),'^[\s\S]o$')`, "name ILIKE '_o'"),
+		Entry("regular expressions", "name ~= '^a.*' AND name ~! 'b'", "matchesPattern(name,'^a.*') and not matchesPattern(name,'b')",
+			"name ~= '^a.*' AND NOT (name ~= 'b')"),
+		Entry("length", "LEN name > 3", "length(name) gt 3", "LEN name > 3"),
+		Entry("member path", "address.city = 'rome'", "address/city eq 'rome'", "address.city = 'rome'"),
+		Entry("any", "ANY (tags = 'admin')", "tags/any(x:x eq 'admin')", "ANY (tags = 'admin')"),
+		Entry("all", "ALL (items.qty > 1)", "items/qty/any() and items/qty/all(x:x gt 1)", "ALL (items.qty > 1)"),
+		Entry("lambda variable", "ANY (x = 1)", "x/any(x1:x1 eq 1)", "ANY (x = 1)"),
+		Entry("literals", "active = TRUE AND name = 'it\\'s' AND day < 2020-01-01 AND created > 2020-01-01T10:00:00Z",
+			"active eq true and name eq 'it''s' and day lt 2020-01-01 and created gt 2020-01-01T10:00:00Z",
+			"active = TRUE AND name = 'it\\'s' AND day < 2020-01-01 AND created > 2020-01-01T10:00:00Z"),
+	)
+
+	DescribeTable("Reports expressions OData can not express",
+		func(phrase string, expected error) {
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Serialize(tree)
+			Expect(err).To(Equal(expected))
+		},
+
+		Entry("array index", "pods[0].name = 'x'",
+			SerializeError{Expression: "pods[0].name", Reason: `invalid member path segment "pods[0]"`}),
+		Entry("reserved word", "a.eq = 1",
+			SerializeError{Expression: "a.eq", Reason: `invalid member path segment "eq"`}),
+		Entry("sum", "SUM (prices) > 10",
+			SerializeError{Expression: "SUM prices", Reason: "SUM has no OData operator"}),
+	)
+})
@@ -0,0 +1,447 @@
+package odata
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Precedence levels of OData expressions
+const (
+	levelOr = iota + 1
+	levelAnd
+	levelNot
+	levelComparison
+	levelAdditive
+	levelMultiplicative
+	levelUnary
+	levelPrimary
+)
+
+// operatorNames maps TSL operators to OData operators
+var operatorNames = map[tsl.Operator]string{
+	tsl.OpEQ:      "eq",
+	tsl.OpNE:      "ne",
+	tsl.OpGT:      "gt",
+	tsl.OpGE:      "ge",
+	tsl.OpLT:      "lt",
+	tsl.OpLE:      "le",
+	tsl.OpPlus:    "add",
+	tsl.OpMinus:   "sub",
+	tsl.OpStar:    "mul",
+	tsl.OpSlash:   "div",
+	tsl.OpPercent: "mod",
+	tsl.OpAnd:     "and",
+	tsl.OpOr:      "or",
+}
+
+// operatorLevels are the precedence levels of the binary operators
+var operatorLevels = map[tsl.Operator]int{
+	tsl.OpEQ:      levelComparison,
+	tsl.OpNE:      levelComparison,
+	tsl.OpGT:      levelComparison,
+	tsl.OpGE:      levelComparison,
+	tsl.OpLT:      levelComparison,
+	tsl.OpLE:      levelComparison,
+	tsl.OpPlus:    levelAdditive,
+	tsl.OpMinus:   levelAdditive,
+	tsl.OpStar:    levelMultiplicative,
+	tsl.OpSlash:   levelMultiplicative,
+	tsl.OpPercent: levelMultiplicative,
+	tsl.OpAnd:     levelAnd,
+	tsl.OpOr:      levelOr,
+}
+
+// segmentPattern matches the member path segments of identifiers
+var segmentPattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_]*$`)
+
+// Serialize writes a TSL tree as an OData $filter expression, Parse reads
+// it back into an equivalent tree.
+//
+// Dotted identifiers become member paths, IS [NOT] NULL becomes a
+// comparison with null, BETWEEN becomes ge and le comparisons, and ANY and
+// ALL become the any and all lambda operators. LIKE patterns are written
+// with the contains, startswith and endswith functions when possible, and
+// as matchesPattern regular expressions otherwise, ILIKE compares the
+// tolower of the field. SUM and comparisons of array indexes return a
+// SerializeError.
+//
+//	tree, _ := tsl.ParseTSL("name LIKE 'jo%' AND address.city IN ['rome', 'paris']")
+//	filter, err := odata.Serialize(tree)
+//	// startswith(name,'jo') and address/city in ('rome','paris')
+func Serialize(n *tsl.TSLNode) (string, error) {
+	s, _, err := serialize(n)
+	return s, err
+}
+
+// serialize returns the OData expression of a node and its precedence level
+func serialize(n *tsl.TSLNode) (string, int, error) {
+	switch n.Type() {
+	case tsl.KindBooleanLiteral:
+		b, _ := n.AsBool()
+		return strconv.FormatBool(b), levelPrimary, nil
+
+	case tsl.KindNumericLiteral:
+		v, _ := n.AsFloat64()
+		if v < 0 {
+			return strconv.FormatFloat(v, 'g', -1, 64), levelUnary, nil
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), levelPrimary, nil
+
+	case tsl.KindStringLiteral:
+		s, _ := n.AsString()
+		return quote(s), levelPrimary, nil
+
+	case tsl.KindDateLiteral:
+		s, _ := n.AsString()
+		return s, levelPrimary, nil
+
+	case tsl.KindTimestampLiteral:
+		if t, ok := n.Value().(time.Time); ok {
+			return t.Format(time.RFC3339Nano), levelPrimary, nil
+		}
+
+	case tsl.KindNullLiteral:
+		return "null", levelPrimary, nil
+
+	case tsl.KindIdentifier:
+		s, err := memberPath(n)
+		return s, levelPrimary, err
+
+	case tsl.KindArrayLiteral:
+		array, _ := n.AsArray()
+		values := make([]string, len(array.Values))
+		for i, value := range array.Values {
+			var err error
+			if values[i], err = operand(value, levelAdditive); err != nil {
+				return "", 0, err
+			}
+		}
+		return "(" + strings.Join(values, ",") + ")", levelPrimary, nil
+
+	case tsl.KindBinaryExpr:
+		return serializeBinary(n)
+
+	case tsl.KindUnaryExpr:
+		return serializeUnary(n)
+	}
+
+	return "", 0, SerializeError{Expression: n.String(), Reason: "unexpected node " + n.Type().String()}
+}
+
+// operand serializes a node, adding parentheses when its precedence is
+// lower than level
+func operand(n *tsl.TSLNode, level int) (string, error) {
+	s, l, err := serialize(n)
+	if err != nil {
+		return "", err
+	}
+	if l < level {
+		return "(" + s + ")", nil
+	}
+	return s, nil
+}
+
+// serializeBinary serializes binary operators
+func serializeBinary(n *tsl.TSLNode) (string, int, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpIn:
+		if op.Right.Type() != tsl.KindArrayLiteral {
+			return "", 0, tsl.TypeMismatchError{Expected: "array", Got: op.Right.Type()}
+		}
+		return binary(op.Left, "in", op.Right, levelComparison, levelAdditive, levelPrimary)
+
+	case tsl.OpBetween:
+		bounds, ok := op.Right.AsArray()
+		if !ok || len(bounds.Values) != 2 {
+			return "", 0, tsl.BetweenOperatorError{Message: "BETWEEN requires exactly two values"}
+		}
+		from, _, err := binary(op.Left, "ge", bounds.Values[0], levelComparison, levelAdditive, levelAdditive+1)
+		if err != nil {
+			return "", 0, err
+		}
+		to, _, err := binary(op.Left, "le", bounds.Values[1], levelComparison, levelAdditive, levelAdditive+1)
+		if err != nil {
+			return "", 0, err
+		}
+		return from + " and " + to, levelAnd, nil
+
+	case tsl.OpIs:
+		if op.Right.Type() != tsl.KindNullLiteral {
+			return "", 0, tsl.TypeMismatchError{Expected: "null", Got: op.Right.Type()}
+		}
+		return binary(op.Left, "eq", op.Right, levelComparison, levelAdditive, levelPrimary)
+
+	case tsl.OpLike, tsl.OpILike:
+		return like(n, op)
+
+	case tsl.OpREQ, tsl.OpRNE:
+		field, err := memberPath(op.Left)
+		if err != nil {
+			return "", 0, err
+		}
+		pattern, ok := op.Right.AsString()
+		if !ok || op.Right.Type() != tsl.KindStringLiteral {
+			return "", 0, SerializeError{Expression: n.String(), Reason: "expected a regular expression literal"}
+		}
+		s := "matchesPattern(" + field + "," + quote(pattern) + ")"
+		if op.Operator == tsl.OpRNE {
+			return "not " + s, levelNot, nil
+		}
+		return s, levelPrimary, nil
+	}
+
+	name, ok := operatorNames[op.Operator]
+	if !ok {
+		return "", 0, SerializeError{Expression: n.String(), Reason: op.Operator.String() + " has no OData operator"}
+	}
+
+	level := operatorLevels[op.Operator]
+	switch level {
+	case levelAnd, levelOr:
+		return binary(op.Left, name, op.Right, level, level, level)
+	case levelComparison:
+		return binary(op.Left, name, op.Right, level, levelAdditive, levelAdditive)
+	}
+	return binary(op.Left, name, op.Right, level, level, level+1)
+}
+
+// binary writes a binary operator, the operands are parenthesized when
+// their precedence is lower than the left and right levels
+func binary(left *tsl.TSLNode, name string, right *tsl.TSLNode, level, leftLevel, rightLevel int) (string, int, error) {
+	l, err := operand(left, leftLevel)
+	if err != nil {
+		return "", 0, err
+	}
+	r, err := operand(right, rightLevel)
+	if err != nil {
+		return "", 0, err
+	}
+	return l + " " + name + " " + r, level, nil
+}
+
+// serializeUnary serializes unary operators
+func serializeUnary(n *tsl.TSLNode) (string, int, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpNot:
+		// IS NOT NULL
+		if inner, ok := op.Right.AsExprOp(); ok && op.Right.Type() == tsl.KindBinaryExpr &&
+			inner.Operator == tsl.OpIs && inner.Right.Type() == tsl.KindNullLiteral {
+			return binary(inner.Left, "ne", inner.Right, levelComparison, levelAdditive, levelPrimary)
+		}
+		s, err := operand(op.Right, levelPrimary)
+		if err != nil {
+			return "", 0, err
+		}
+		return "not " + s, levelNot, nil
+
+	case tsl.OpUMinus:
+		s, err := operand(op.Right, levelUnary)
+		if err != nil {
+			return "", 0, err
+		}
+		return "-" + s, levelUnary, nil
+
+	case tsl.OpLen:
+		field, err := memberPath(op.Right)
+		if err != nil {
+			return "", 0, err
+		}
+		return "length(" + field + ")", levelPrimary, nil
+
+	case tsl.OpAny, tsl.OpAll:
+		return lambda(n, op)
+	}
+
+	return "", 0, SerializeError{Expression: n.String(), Reason: op.Operator.String() + " has no OData operator"}
+}
+
+// lambda writes ANY and ALL as lambda operators, the condition compares
+// the collection, or fields of its elements, using the lambda variable.
+// The all lambda is true for empty collections, so ALL also requires any().
+func lambda(n *tsl.TSLNode, op tsl.TSLExpressionOp) (string, int, error) {
+	cond, ok := op.Right.AsExprOp()
+	if !ok || op.Right.Type() != tsl.KindBinaryExpr || cond.Left.Type() != tsl.KindIdentifier {
+		return "", 0, SerializeError{Expression: n.String(), Reason: "expected a condition on a collection"}
+	}
+
+	collection, _ := cond.Left.AsString()
+	path, err := memberPath(cond.Left)
+	if err != nil {
+		return "", 0, err
+	}
+
+	variable := lambdaVariable(op.Right)
+	predicate, err := Serialize(renameElements(op.Right, collection, variable))
+	if err != nil {
+		return "", 0, err
+	}
+
+	if op.Operator == tsl.OpAny {
+		return path + "/any(" + variable + ":" + predicate + ")", levelPrimary, nil
+	}
+	return path + "/any() and " + path + "/all(" + variable + ":" + predicate + ")", levelAnd, nil
+}
+
+// lambdaVariable returns a lambda variable name that the condition does
+// not use as a member path
+func lambdaVariable(n *tsl.TSLNode) string {
+	used := map[string]bool{}
+	collectMembers(n, used)
+
+	variable := "x"
+	for i := 1; used[variable]; i++ {
+		variable = "x" + strconv.Itoa(i)
+	}
+	return variable
+}
+
+// collectMembers adds the first path segment of the identifiers of a tree
+func collectMembers(n *tsl.TSLNode, used map[string]bool) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		name, _ := n.AsString()
+		used[strings.SplitN(name, ".", 2)[0]] = true
+	case tsl.KindBinaryExpr, tsl.KindUnaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		if op.Left != nil {
+			collectMembers(op.Left, used)
+		}
+		collectMembers(op.Right, used)
+	case tsl.KindArrayLiteral:
+		array, _ := n.AsArray()
+		for _, value := range array.Values {
+			collectMembers(value, used)
+		}
+	}
+}
+
+// renameElements returns a copy of the tree where identifiers of the
+// collection elements start with the lambda variable
+func renameElements(n *tsl.TSLNode, collection, variable string) *tsl.TSLNode {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		name, _ := n.AsString()
+		if isElement(name, collection) {
+			return tsl.NewIdentifier(variable + strings.TrimPrefix(name, collection))
+		}
+	case tsl.KindBinaryExpr, tsl.KindUnaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		clone := n.Clone()
+		if op.Left != nil {
+			clone.SetLeft(renameElements(op.Left, collection, variable))
+		}
+		clone.SetRight(renameElements(op.Right, collection, variable))
+		return clone
+	}
+	return n
+}
+
+// like writes LIKE and ILIKE using the contains, startswith and endswith
+// functions, an equality or a matchesPattern regular expression
+func like(n *tsl.TSLNode, op tsl.TSLExpressionOp) (string, int, error) {
+	field, err := memberPath(op.Left)
+	if err != nil {
+		return "", 0, err
+	}
+	pattern, ok := op.Right.AsString()
+	if !ok || op.Right.Type() != tsl.KindStringLiteral {
+		return "", 0, SerializeError{Expression: n.String(), Reason: "expected a pattern literal"}
+	}
+
+	if op.Operator == tsl.OpILike {
+		field = "tolower(" + field + ")"
+		pattern = strings.ToLower(pattern)
+	}
+
+	// A literal with optional leading and trailing %
+	inner := pattern
+	prefix := strings.HasPrefix(inner, "%")
+	if prefix {
+		inner = inner[1:]
+	}
+	suffix := strings.HasSuffix(inner, "%") && !escapedEnd(inner[:len(inner)-1])
+	if suffix {
+		inner = inner[:len(inner)-1]
+	}
+
+	if literal, ok := likeLiteral(inner); ok {
+		switch {
+		case prefix && suffix:
+			return "contains(" + field + "," + quote(literal) + ")", levelPrimary, nil
+		case prefix:
+			return "endswith(" + field + "," + quote(literal) + ")", levelPrimary, nil
+		case suffix:
+			return "startswith(" + field + "," + quote(literal) + ")", levelPrimary, nil
+		}
+		return field + " eq " + quote(literal), levelComparison, nil
+	}
+
+	re, err := tsl.LikeToRegexp(pattern)
+	if err != nil {
+		return "", 0, err
+	}
+	re = strings.NewReplacer("(?s:.*)", `[\s\S]*`, "(?s:.)", `[\s\S]`).Replace(re)
+	return "matchesPattern(" + field + "," + quote(re) + ")", levelPrimary, nil
+}
+
+// escapedEnd returns true if s ends with an escape character
+func escapedEnd(s string) bool {
+	escapes := len(s) - len(strings.TrimRight(s, string(tsl.LikeEscape)))
+	return escapes%2 == 1
+}
+
+// likeLiteral returns the string a LIKE pattern without wildcards matches
+func likeLiteral(pattern string) (string, bool) {
+	var b strings.Builder
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '%', '_':
+			return "", false
+		case tsl.LikeEscape:
+			if i+1 == len(runes) {
+				return "", false
+			}
+			i++
+		}
+		b.WriteRune(runes[i])
+	}
+	return b.String(), true
+}
+
+// memberPath writes a dotted identifier as a member path
+func memberPath(n *tsl.TSLNode) (string, error) {
+	name, ok := n.AsString()
+	if !ok || n.Type() != tsl.KindIdentifier {
+		return "", SerializeError{Expression: n.String(), Reason: "expected a field"}
+	}
+
+	segments := strings.Split(name, ".")
+	for _, segment := range segments {
+		if !segmentPattern.MatchString(segment) || reserved[segment] {
+			return "", SerializeError{Expression: n.String(), Reason: "invalid member path segment " + strconv.Quote(segment)}
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// reserved are the OData keywords that can not be used as member names
+var reserved = map[string]bool{
+	"and": true, "or": true, "not": true, "eq": true, "ne": true, "gt": true,
+	"ge": true, "lt": true, "le": true, "has": true, "in": true, "add": true,
+	"sub": true, "mul": true, "div": true, "divby": true, "mod": true,
+	"true": true, "false": true, "null": true,
+}
+
+// quote writes an OData string literal
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -17,13 +17,10 @@ func (l *tslLexer) Lex(lval *yySymType) int {
 
 	lval.pos = token.Position
 
-	// Set the semantic value for string tokens
-	switch token.Type {
-	default:
-		if token.Value != "" {
-			lval.str = token.Value
-		}
-	}
+	// Set the semantic value for string tokens, an empty string literal
+	// must not keep the value of the previous token
+	lval.str = token.Value
+	lval.pattern = token.Pattern
 
 	return token.Type
 }
@@ -73,6 +70,7 @@ func Parse(input string) (*Node, error) {
 func init() {
 	keywords["like"] = K_LIKE
 	keywords["ilike"] = K_ILIKE
+	keywords["escape"] = K_ESCAPE
 	keywords["and"] = K_AND
 	keywords["or"] = K_OR
 	keywords["between"] = K_BETWEEN
@@ -86,4 +84,5 @@ func init() {
 	keywords["any"] = K_ANY
 	keywords["all"] = K_ALL
 	keywords["sum"] = K_SUM
+	keywords["match"] = K_MATCH
 }
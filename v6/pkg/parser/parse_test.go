@@ -45,3 +45,80 @@ func TestParseConcurrent(t *testing.T) {
 		t.Errorf("concurrent parse failed: %v", err)
 	}
 }
+
+func TestParseLikeEscape(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		not      bool
+		expected string
+	}{
+		{"default escape", `a like 'x\_y'`, false, `x\_y`},
+		{"custom escape", "a like 'x!_y!%' escape '!'", false, `x\_y\%`},
+		{"escaped escape", "a like 'x!!y' escape '!'", false, "x!y"},
+		{"backslash with custom escape", `a like 'x\\y' escape '!'`, false, `x\\y`},
+		{"backslash escape", `a like 'x\_y' escape '\\'`, false, `x\_y`},
+		{"no escape", `a like 'x\\_y' escape ''`, false, `x\\_y`},
+		{"ilike", "a ilike 'x#%' escape '#'", false, `x\%`},
+		{"not like", "a not like 'x#%' escape '#'", true, `x\%`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.not {
+				if node.Operator != OpNot {
+					t.Fatalf("expected NOT node, got %v", node.Operator)
+				}
+				node = node.Right
+			}
+
+			if node.Operator != OpLike && node.Operator != OpILike {
+				t.Fatalf("expected LIKE node, got %v", node.Operator)
+			}
+			if node.Right.Value != tt.expected {
+				t.Errorf("expected pattern %q, got %q", tt.expected, node.Right.Value)
+			}
+		})
+	}
+}
+
+func TestParseStringEscapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"equals", `a = 'x\%y'`, "x%y"},
+		{"not equals", `a != 'x\_y'`, "x_y"},
+		{"regexp", `a ~= 'x\_y'`, "x_y"},
+		{"like", `a like 'x\%y'`, `x\%y`},
+		{"ilike", `a ilike 'x\_y'`, `x\_y`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if node.Right.Value != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, node.Right.Value)
+			}
+		})
+	}
+}
+
+func TestParseEmptyString(t *testing.T) {
+	node, err := Parse("name = ''")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if node.Right.Kind != NodeStringLiteral || node.Right.Value != "" {
+		t.Errorf("expected empty string literal, got %v %q", node.Right.Kind, node.Right.Value)
+	}
+}
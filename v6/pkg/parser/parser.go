@@ -14,51 +14,55 @@ var currentLexer *Lexer
 
 //line parser.y:11
 type yySymType struct {
-	yys  int
-	node *Node
-	str  string
-	pos  int
+	yys     int
+	node    *Node
+	str     string
+	pattern string
+	pos     int
 }
 
 const K_LIKE = 57346
 const K_ILIKE = 57347
-const K_AND = 57348
-const K_OR = 57349
-const K_BETWEEN = 57350
-const K_IN = 57351
-const K_IS = 57352
-const K_NULL = 57353
-const K_NOT = 57354
-const K_TRUE = 57355
-const K_FALSE = 57356
-const K_LEN = 57357
-const K_ANY = 57358
-const K_ALL = 57359
-const K_SUM = 57360
-const NUMERIC_LITERAL = 57361
-const STRING_LITERAL = 57362
-const IDENTIFIER = 57363
-const DATE = 57364
-const RFC3339 = 57365
-const LPAREN = 57366
-const RPAREN = 57367
-const COMMA = 57368
-const PLUS = 57369
-const MINUS = 57370
-const STAR = 57371
-const SLASH = 57372
-const PERCENT = 57373
-const LBRACKET = 57374
-const RBRACKET = 57375
-const EQ = 57376
-const NE = 57377
-const LT = 57378
-const LE = 57379
-const GT = 57380
-const GE = 57381
-const REQ = 57382
-const RNE = 57383
-const UMINUS = 57384
+const K_ESCAPE = 57348
+const K_AND = 57349
+const K_OR = 57350
+const K_BETWEEN = 57351
+const K_IN = 57352
+const K_IS = 57353
+const K_NULL = 57354
+const K_NOT = 57355
+const K_TRUE = 57356
+const K_FALSE = 57357
+const K_LEN = 57358
+const K_ANY = 57359
+const K_ALL = 57360
+const K_SUM = 57361
+const K_MATCH = 57362
+const NUMERIC_LITERAL = 57363
+const STRING_LITERAL = 57364
+const IDENTIFIER = 57365
+const DATE = 57366
+const RFC3339 = 57367
+const LPAREN = 57368
+const RPAREN = 57369
+const COMMA = 57370
+const PLUS = 57371
+const MINUS = 57372
+const STAR = 57373
+const SLASH = 57374
+const PERCENT = 57375
+const LBRACKET = 57376
+const RBRACKET = 57377
+const EQ = 57378
+const NE = 57379
+const LT = 57380
+const LE = 57381
+const GT = 57382
+const GE = 57383
+const REQ = 57384
+const RNE = 57385
+const ATAT = 57386
+const UMINUS = 57387
 
 var yyToknames = [...]string{
 	"$end",
@@ -66,6 +70,7 @@ var yyToknames = [...]string{
 	"$unk",
 	"K_LIKE",
 	"K_ILIKE",
+	"K_ESCAPE",
 	"K_AND",
 	"K_OR",
 	"K_BETWEEN",
@@ -79,6 +84,7 @@ var yyToknames = [...]string{
 	"K_ANY",
 	"K_ALL",
 	"K_SUM",
+	"K_MATCH",
 	"NUMERIC_LITERAL",
 	"STRING_LITERAL",
 	"IDENTIFIER",
@@ -102,6 +108,7 @@ var yyToknames = [...]string{
 	"GE",
 	"REQ",
 	"RNE",
+	"ATAT",
 	"UMINUS",
 }
 
@@ -111,7 +118,7 @@ const yyEofCode = 1
 const yyErrCode = 2
 const yyInitialStackSize = 16
 
-//line parser.y:167
+//line parser.y:207
 
 //line yacctab:1
 var yyExca = [...]int8{
@@ -122,83 +129,95 @@ var yyExca = [...]int8{
 
 const yyPrivate = 57344
 
-const yyLast = 118
+const yyLast = 157
 
 var yyAct = [...]int8{
-	6, 2, 8, 46, 47, 48, 86, 44, 45, 87,
-	85, 5, 92, 49, 50, 51, 52, 53, 7, 4,
-	56, 28, 38, 39, 57, 95, 42, 43, 41, 59,
-	40, 62, 63, 64, 65, 66, 67, 68, 69, 70,
-	71, 61, 29, 78, 79, 93, 44, 45, 60, 82,
-	83, 84, 30, 31, 32, 33, 34, 35, 36, 37,
-	76, 77, 58, 80, 81, 19, 44, 45, 15, 3,
-	9, 25, 26, 88, 89, 90, 91, 20, 21, 22,
-	24, 23, 18, 1, 0, 17, 16, 54, 55, 94,
-	27, 0, 0, 0, 96, 0, 97, 10, 25, 26,
-	11, 12, 13, 14, 20, 21, 22, 24, 23, 18,
-	72, 73, 17, 16, 74, 75, 0, 27,
+	6, 2, 91, 8, 48, 49, 50, 46, 47, 92,
+	90, 110, 109, 7, 51, 52, 53, 54, 55, 104,
+	58, 107, 103, 100, 106, 81, 82, 28, 5, 61,
+	29, 64, 65, 66, 67, 68, 69, 70, 71, 72,
+	73, 74, 75, 46, 47, 83, 84, 46, 47, 105,
+	38, 39, 87, 88, 89, 44, 45, 43, 63, 42,
+	85, 86, 59, 60, 4, 19, 40, 15, 3, 101,
+	1, 0, 46, 47, 94, 0, 93, 95, 96, 97,
+	98, 99, 30, 31, 32, 33, 34, 35, 36, 37,
+	41, 46, 47, 62, 102, 0, 0, 46, 47, 46,
+	47, 0, 108, 0, 0, 0, 9, 0, 111, 10,
+	25, 26, 11, 12, 13, 14, 0, 20, 21, 22,
+	24, 23, 18, 56, 57, 17, 16, 25, 26, 0,
+	27, 0, 0, 0, 20, 21, 22, 24, 23, 18,
+	77, 78, 17, 16, 0, 79, 80, 27, 0, 0,
+	0, 0, 0, 0, 0, 0, 76,
 }
 
 var yyPact = [...]int16{
-	85, -1000, -1000, 14, 36, 18, -20, -26, -1000, -1000,
-	85, 85, 85, 85, 85, -1000, 58, 58, 85, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 85, 85, 85,
-	85, 85, 85, 85, 85, 85, 85, 85, 85, 85,
-	106, 49, 85, 85, 85, 85, 85, 85, 85, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -15, -27, -17, -1000,
-	36, 18, -20, -20, -20, -20, -20, -20, -20, -20,
-	-20, -20, 85, 85, 85, 85, -1000, 1, 39, -20,
-	-26, -26, -1000, -1000, -1000, -1000, -1000, 85, -20, -20,
-	19, -20, -1000, 85, -1000, 85, -20, -20,
+	96, -1000, -1000, 19, 23, 46, -22, -27, -1000, -1000,
+	96, 96, 96, 96, 96, -1000, 113, 113, 96, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 96, 96, 96,
+	96, 96, 96, 96, 96, 96, 96, 96, 96, 96,
+	96, 96, 136, 13, 96, 96, 96, 96, 96, 96,
+	96, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -17, -33,
+	-19, -1000, 23, 46, -22, -22, -22, -22, -22, -22,
+	-22, -22, 70, 68, -22, -22, 96, 96, 96, 96,
+	96, -1000, 11, 62, -22, -27, -27, -1000, -1000, -1000,
+	-1000, -1000, 96, 0, -3, -22, 43, 18, 14, -22,
+	-1000, 96, -1000, -1000, -1000, -10, -11, 96, -22, -1000,
+	-1000, -22,
 }
 
 var yyPgo = [...]int8{
-	0, 83, 1, 69, 19, 11, 0, 18, 2, 70,
-	68, 65, 62, 24,
+	0, 70, 1, 68, 64, 28, 0, 13, 3, 106,
+	67, 65, 63, 62,
 }
 
 var yyR1 = [...]int8{
 	0, 1, 2, 3, 3, 4, 4, 5, 5, 5,
 	5, 5, 5, 5, 5, 5, 5, 5, 5, 5,
-	5, 5, 5, 5, 5, 5, 6, 6, 6, 7,
-	7, 7, 7, 8, 8, 8, 8, 8, 8, 9,
-	9, 9, 9, 9, 11, 13, 13, 13, 12, 12,
-	10, 10, 10, 10, 10, 10, 10,
+	5, 5, 5, 5, 5, 5, 5, 5, 5, 5,
+	5, 5, 5, 6, 6, 6, 7, 7, 7, 7,
+	8, 8, 8, 8, 8, 8, 9, 9, 9, 9,
+	9, 11, 13, 13, 13, 12, 12, 10, 10, 10,
+	10, 10, 10, 10,
 }
 
 var yyR2 = [...]int8{
 	0, 1, 1, 1, 3, 1, 3, 1, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 4, 4,
-	3, 4, 5, 6, 3, 4, 1, 3, 3, 1,
-	3, 3, 3, 1, 2, 2, 2, 2, 2, 1,
-	2, 2, 3, 1, 3, 0, 1, 2, 1, 3,
-	1, 1, 1, 1, 1, 1, 1,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	4, 4, 4, 5, 5, 6, 6, 3, 4, 5,
+	6, 3, 4, 1, 3, 3, 1, 3, 3, 3,
+	1, 2, 2, 2, 2, 2, 1, 2, 2, 3,
+	1, 3, 0, 1, 2, 1, 3, 1, 1, 1,
+	1, 1, 1, 1,
 }
 
 var yyChk = [...]int16{
 	-1000, -1, -2, -3, -4, -5, -6, -7, -8, -9,
-	12, 15, 16, 17, 18, -10, 28, 27, 24, -11,
-	19, 20, 21, 23, 22, 13, 14, 32, 7, 6,
-	34, 35, 36, 37, 38, 39, 40, 41, 4, 5,
-	12, 10, 8, 9, 27, 28, 29, 30, 31, -8,
-	-8, -8, -8, -8, -9, -9, -2, -13, -12, -2,
-	-4, -5, -6, -6, -6, -6, -6, -6, -6, -6,
-	-6, -6, 4, 5, 8, 9, 11, 12, -6, -6,
-	-7, -7, -8, -8, -8, 25, 33, 26, -6, -6,
-	-6, -6, 11, 6, -2, 6, -6, -6,
+	13, 16, 17, 18, 19, -10, 30, 29, 26, -11,
+	21, 22, 23, 25, 24, 14, 15, 34, 8, 7,
+	36, 37, 38, 39, 40, 41, 42, 43, 4, 5,
+	20, 44, 13, 11, 9, 10, 29, 30, 31, 32,
+	33, -8, -8, -8, -8, -8, -9, -9, -2, -13,
+	-12, -2, -4, -5, -6, -6, -6, -6, -6, -6,
+	-6, -6, -6, -6, -6, -6, 20, 4, 5, 9,
+	10, 12, 13, -6, -6, -7, -7, -8, -8, -8,
+	27, 35, 28, 6, 6, -6, -6, -6, -6, -6,
+	12, 7, -2, 22, 22, 6, 6, 7, -6, 22,
+	22, -6,
 }
 
 var yyDef = [...]int8{
-	0, -2, 1, 2, 3, 5, 7, 26, 29, 33,
-	0, 0, 0, 0, 0, 39, 0, 0, 0, 43,
-	50, 51, 52, 53, 54, 55, 56, 45, 0, 0,
+	0, -2, 1, 2, 3, 5, 7, 33, 36, 40,
+	0, 0, 0, 0, 0, 46, 0, 0, 0, 50,
+	57, 58, 59, 60, 61, 62, 63, 52, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 34,
-	35, 36, 37, 38, 40, 41, 0, 0, 46, 48,
-	4, 6, 8, 9, 10, 11, 12, 13, 14, 15,
-	16, 17, 0, 0, 0, 0, 20, 0, 0, 24,
-	27, 28, 30, 31, 32, 42, 44, 47, 18, 19,
-	0, 25, 21, 0, 49, 0, 22, 23,
+	0, 41, 42, 43, 44, 45, 47, 48, 0, 0,
+	53, 55, 4, 6, 8, 9, 10, 11, 12, 13,
+	14, 15, 16, 17, 18, 19, 0, 0, 0, 0,
+	0, 27, 0, 0, 31, 34, 35, 37, 38, 39,
+	49, 51, 54, 0, 0, 20, 21, 22, 0, 32,
+	28, 0, 56, 23, 24, 0, 0, 0, 29, 25,
+	26, 30,
 }
 
 var yyTok1 = [...]int8{
@@ -210,7 +229,7 @@ var yyTok2 = [...]int8{
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
 	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
-	42,
+	42, 43, 44, 45,
 }
 
 var yyTok3 = [...]int8{
@@ -556,298 +575,361 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:48
+//line parser.y:50
 		{
 			parseResult = yyDollar[1].node
 		}
 	case 4:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:57
+//line parser.y:59
 		{
 			yyVAL.node = NewBinaryOpNode(OpOr, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 6:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:62
+//line parser.y:64
 		{
 			yyVAL.node = NewBinaryOpNode(OpAnd, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 8:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:67
+//line parser.y:69
 		{
 			yyVAL.node = NewBinaryOpNode(OpEQ, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 9:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:68
+//line parser.y:70
 		{
 			yyVAL.node = NewBinaryOpNode(OpNE, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 10:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:69
+//line parser.y:71
 		{
 			yyVAL.node = NewBinaryOpNode(OpLT, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 11:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:70
+//line parser.y:72
 		{
 			yyVAL.node = NewBinaryOpNode(OpLE, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 12:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:71
+//line parser.y:73
 		{
 			yyVAL.node = NewBinaryOpNode(OpGT, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 13:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:72
+//line parser.y:74
 		{
 			yyVAL.node = NewBinaryOpNode(OpGE, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 14:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:73
+//line parser.y:75
 		{
 			yyVAL.node = NewBinaryOpNode(OpREQ, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 15:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:74
+//line parser.y:76
 		{
 			yyVAL.node = NewBinaryOpNode(OpRNE, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 16:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:75
+//line parser.y:77
 		{
-			yyVAL.node = NewBinaryOpNode(OpLike, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
+			yyVAL.node = NewBinaryOpNode(OpLike, yyDollar[1].node, likePattern(yyDollar[3].node), yyDollar[1].node.Position)
 		}
 	case 17:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:76
+//line parser.y:78
 		{
-			yyVAL.node = NewBinaryOpNode(OpILike, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
+			yyVAL.node = NewBinaryOpNode(OpILike, yyDollar[1].node, likePattern(yyDollar[3].node), yyDollar[1].node.Position)
 		}
 	case 18:
-		yyDollar = yyS[yypt-4 : yypt+1]
-//line parser.y:77
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:79
 		{
-			likeExpr := NewBinaryOpNode(OpLike, yyDollar[1].node, yyDollar[4].node, yyDollar[1].node.Position)
-			yyVAL.node = NewUnaryOpNode(OpNot, likeExpr, yyDollar[1].node.Position)
+			yyVAL.node = NewBinaryOpNode(OpMatch, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
 	case 19:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:80
+		{
+			yyVAL.node = NewBinaryOpNode(OpMatch, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
+		}
+	case 20:
 		yyDollar = yyS[yypt-4 : yypt+1]
 //line parser.y:81
 		{
-			ilikeExpr := NewBinaryOpNode(OpILike, yyDollar[1].node, yyDollar[4].node, yyDollar[1].node.Position)
+			matchExpr := NewBinaryOpNode(OpMatch, yyDollar[1].node, yyDollar[4].node, yyDollar[1].node.Position)
+			yyVAL.node = NewUnaryOpNode(OpNot, matchExpr, yyDollar[1].node.Position)
+		}
+	case 21:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:85
+		{
+			likeExpr := NewBinaryOpNode(OpLike, yyDollar[1].node, likePattern(yyDollar[4].node), yyDollar[1].node.Position)
+			yyVAL.node = NewUnaryOpNode(OpNot, likeExpr, yyDollar[1].node.Position)
+		}
+	case 22:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:89
+		{
+			ilikeExpr := NewBinaryOpNode(OpILike, yyDollar[1].node, likePattern(yyDollar[4].node), yyDollar[1].node.Position)
 			yyVAL.node = NewUnaryOpNode(OpNot, ilikeExpr, yyDollar[1].node.Position)
 		}
-	case 20:
+	case 23:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.y:93
+		{
+			likeExpr, err := NewLikeNode(OpLike, yyDollar[1].node, yyDollar[3].node, yyDollar[5].str, yyDollar[5].pos)
+			if err != nil {
+				parseError = err
+				return 1
+			}
+			yyVAL.node = likeExpr
+		}
+	case 24:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.y:101
+		{
+			ilikeExpr, err := NewLikeNode(OpILike, yyDollar[1].node, yyDollar[3].node, yyDollar[5].str, yyDollar[5].pos)
+			if err != nil {
+				parseError = err
+				return 1
+			}
+			yyVAL.node = ilikeExpr
+		}
+	case 25:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.y:109
+		{
+			likeExpr, err := NewLikeNode(OpLike, yyDollar[1].node, yyDollar[4].node, yyDollar[6].str, yyDollar[6].pos)
+			if err != nil {
+				parseError = err
+				return 1
+			}
+			yyVAL.node = NewUnaryOpNode(OpNot, likeExpr, yyDollar[1].node.Position)
+		}
+	case 26:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.y:117
+		{
+			ilikeExpr, err := NewLikeNode(OpILike, yyDollar[1].node, yyDollar[4].node, yyDollar[6].str, yyDollar[6].pos)
+			if err != nil {
+				parseError = err
+				return 1
+			}
+			yyVAL.node = NewUnaryOpNode(OpNot, ilikeExpr, yyDollar[1].node.Position)
+		}
+	case 27:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:85
+//line parser.y:125
 		{
 			yyVAL.node = NewBinaryOpNode(OpIs, yyDollar[1].node, NewNullNode(yyDollar[1].node.Position), yyDollar[1].node.Position)
 		}
-	case 21:
+	case 28:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line parser.y:86
+//line parser.y:126
 		{
 			isNullExpr := NewBinaryOpNode(OpIs, yyDollar[1].node, NewNullNode(yyDollar[1].node.Position), yyDollar[1].node.Position)
 			yyVAL.node = NewUnaryOpNode(OpNot, isNullExpr, yyDollar[1].node.Position)
 		}
-	case 22:
+	case 29:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line parser.y:90
+//line parser.y:130
 		{
 			rangeArray := NewArrayNode([]*Node{yyDollar[3].node, yyDollar[5].node}, yyDollar[3].node.Position)
 			yyVAL.node = NewBinaryOpNode(OpBetween, yyDollar[1].node, rangeArray, yyDollar[1].node.Position)
 		}
-	case 23:
+	case 30:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line parser.y:94
+//line parser.y:134
 		{
 			rangeArray := NewArrayNode([]*Node{yyDollar[4].node, yyDollar[6].node}, yyDollar[4].node.Position)
 			betweenExpr := NewBinaryOpNode(OpBetween, yyDollar[1].node, rangeArray, yyDollar[1].node.Position)
 			yyVAL.node = NewUnaryOpNode(OpNot, betweenExpr, yyDollar[1].node.Position)
 		}
-	case 24:
+	case 31:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:99
+//line parser.y:139
 		{
 			yyVAL.node = NewBinaryOpNode(OpIn, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
-	case 25:
+	case 32:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line parser.y:100
+//line parser.y:140
 		{
 			inExpr := NewBinaryOpNode(OpIn, yyDollar[1].node, yyDollar[4].node, yyDollar[1].node.Position)
 			yyVAL.node = NewUnaryOpNode(OpNot, inExpr, yyDollar[1].node.Position)
 		}
-	case 27:
+	case 34:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:108
+//line parser.y:148
 		{
 			yyVAL.node = NewBinaryOpNode(OpPlus, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
-	case 28:
+	case 35:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:109
+//line parser.y:149
 		{
 			yyVAL.node = NewBinaryOpNode(OpMinus, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
-	case 30:
+	case 37:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:114
+//line parser.y:154
 		{
 			yyVAL.node = NewBinaryOpNode(OpStar, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
-	case 31:
+	case 38:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:115
+//line parser.y:155
 		{
 			yyVAL.node = NewBinaryOpNode(OpSlash, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
-	case 32:
+	case 39:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:116
+//line parser.y:156
 		{
 			yyVAL.node = NewBinaryOpNode(OpPercent, yyDollar[1].node, yyDollar[3].node, yyDollar[1].node.Position)
 		}
-	case 34:
+	case 41:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:121
+//line parser.y:161
 		{
 			yyVAL.node = NewUnaryOpNode(OpNot, yyDollar[2].node, yyDollar[2].node.Position)
 		}
-	case 35:
+	case 42:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:122
+//line parser.y:162
 		{
 			yyVAL.node = NewUnaryOpNode(OpLen, yyDollar[2].node, yyDollar[2].node.Position)
 		}
-	case 36:
+	case 43:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:123
+//line parser.y:163
 		{
 			yyVAL.node = NewUnaryOpNode(OpAny, yyDollar[2].node, yyDollar[2].node.Position)
 		}
-	case 37:
+	case 44:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:124
+//line parser.y:164
 		{
 			yyVAL.node = NewUnaryOpNode(OpAll, yyDollar[2].node, yyDollar[2].node.Position)
 		}
-	case 38:
+	case 45:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:125
+//line parser.y:165
 		{
 			yyVAL.node = NewUnaryOpNode(OpSum, yyDollar[2].node, yyDollar[2].node.Position)
 		}
-	case 40:
+	case 47:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:130
+//line parser.y:170
 		{
 			yyVAL.node = NewUnaryOpNode(OpUMinus, yyDollar[2].node, yyDollar[2].node.Position)
 		}
-	case 41:
+	case 48:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:131
+//line parser.y:171
 		{
 			yyVAL.node = yyDollar[2].node
 		}
-	case 42:
+	case 49:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:132
+//line parser.y:172
 		{
 			yyVAL.node = yyDollar[2].node
 		}
-	case 43:
+	case 50:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:133
+//line parser.y:173
 		{
 			yyVAL.node = yyDollar[1].node
 		}
-	case 44:
+	case 51:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:137
+//line parser.y:177
 		{
 			yyVAL.node = yyDollar[2].node
 		}
-	case 45:
+	case 52:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line parser.y:141
+//line parser.y:181
 		{
 			yyVAL.node = NewArrayNode([]*Node{}, 0)
 		}
-	case 46:
+	case 53:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:142
+//line parser.y:182
 		{
 			yyVAL.node = yyDollar[1].node
 		}
-	case 47:
+	case 54:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:143
+//line parser.y:183
 		{
 			yyVAL.node = yyDollar[1].node
 		}
-	case 48:
+	case 55:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:147
+//line parser.y:187
 		{
 			yyVAL.node = NewArrayNode([]*Node{yyDollar[1].node}, yyDollar[1].node.Position)
 		}
-	case 49:
+	case 56:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:150
+//line parser.y:190
 		{
 			// Append to existing array
 			yyDollar[1].node.Children = append(yyDollar[1].node.Children, yyDollar[3].node)
 			yyVAL.node = yyDollar[1].node
 		}
-	case 50:
+	case 57:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:158
+//line parser.y:198
 		{
 			yyVAL.node = NewNumberNode(yyDollar[1].str, yyDollar[1].pos)
 		}
-	case 51:
+	case 58:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:159
+//line parser.y:199
 		{
-			yyVAL.node = NewStringNode(yyDollar[1].str, yyDollar[1].pos)
+			yyVAL.node = newStringPatternNode(yyDollar[1].str, yyDollar[1].pattern, yyDollar[1].pos)
 		}
-	case 52:
+	case 59:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:160
+//line parser.y:200
 		{
 			yyVAL.node = NewIdentifierNode(yyDollar[1].str, yyDollar[1].pos)
 		}
-	case 53:
+	case 60:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:161
+//line parser.y:201
 		{
 			yyVAL.node = NewTimestampNode(yyDollar[1].str, yyDollar[1].pos)
 		}
-	case 54:
+	case 61:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:162
+//line parser.y:202
 		{
 			yyVAL.node = NewDateNode(yyDollar[1].str, yyDollar[1].pos)
 		}
-	case 55:
+	case 62:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:163
+//line parser.y:203
 		{
 			yyVAL.node = NewBooleanNode(true, yyDollar[1].pos)
 		}
-	case 56:
+	case 63:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:164
+//line parser.y:204
 		{
 			yyVAL.node = NewBooleanNode(false, yyDollar[1].pos)
 		}
@@ -11,24 +11,26 @@ var currentLexer *Lexer
 %union {
     node   *Node
     str    string
+    pattern string
     pos    int
 }
 
 // Token declarations
-%token K_LIKE K_ILIKE K_AND K_OR K_BETWEEN K_IN K_IS K_NULL
-%token K_NOT K_TRUE K_FALSE K_LEN K_ANY K_ALL K_SUM
+%token K_LIKE K_ILIKE K_ESCAPE K_AND K_OR K_BETWEEN K_IN K_IS K_NULL
+%token K_NOT K_TRUE K_FALSE K_LEN K_ANY K_ALL K_SUM K_MATCH
 %token <str> NUMERIC_LITERAL STRING_LITERAL IDENTIFIER DATE RFC3339
 %token LPAREN RPAREN COMMA
 %token PLUS MINUS STAR SLASH PERCENT
 %token LBRACKET RBRACKET
-%token EQ NE LT LE GT GE REQ RNE
+%token EQ NE LT LE GT GE REQ RNE ATAT
 %token UMINUS
 
 // Operator precedence and associativity (lowest to highest)
 %left K_OR                         
 %left K_AND
-%left EQ NE LT LE GT GE REQ RNE
-%left K_LIKE K_ILIKE K_IS K_BETWEEN K_IN
+%left EQ NE LT LE GT GE REQ RNE ATAT
+%left K_LIKE K_ILIKE K_IS K_BETWEEN K_IN K_MATCH
+%nonassoc K_ESCAPE
 %left PLUS MINUS                   
 %left STAR SLASH PERCENT           
 %right K_NOT K_LEN K_ANY K_ALL K_SUM   
@@ -72,14 +74,52 @@ comparison_expr:
     | comparison_expr GE additive_expr      { $$ = NewBinaryOpNode(OpGE, $1, $3, $1.Position) }
     | comparison_expr REQ additive_expr     { $$ = NewBinaryOpNode(OpREQ, $1, $3, $1.Position) }
     | comparison_expr RNE additive_expr     { $$ = NewBinaryOpNode(OpRNE, $1, $3, $1.Position) }
-    | comparison_expr K_LIKE additive_expr  { $$ = NewBinaryOpNode(OpLike, $1, $3, $1.Position) }
-    | comparison_expr K_ILIKE additive_expr { $$ = NewBinaryOpNode(OpILike, $1, $3, $1.Position) }
+    | comparison_expr K_LIKE additive_expr  { $$ = NewBinaryOpNode(OpLike, $1, likePattern($3), $1.Position) }
+    | comparison_expr K_ILIKE additive_expr { $$ = NewBinaryOpNode(OpILike, $1, likePattern($3), $1.Position) }
+    | comparison_expr K_MATCH additive_expr { $$ = NewBinaryOpNode(OpMatch, $1, $3, $1.Position) }
+    | comparison_expr ATAT additive_expr    { $$ = NewBinaryOpNode(OpMatch, $1, $3, $1.Position) }
+    | comparison_expr K_NOT K_MATCH additive_expr {
+        matchExpr := NewBinaryOpNode(OpMatch, $1, $4, $1.Position)
+        $$ = NewUnaryOpNode(OpNot, matchExpr, $1.Position)
+    }
     | comparison_expr K_NOT K_LIKE additive_expr  {
-        likeExpr := NewBinaryOpNode(OpLike, $1, $4, $1.Position)
+        likeExpr := NewBinaryOpNode(OpLike, $1, likePattern($4), $1.Position)
         $$ = NewUnaryOpNode(OpNot, likeExpr, $1.Position)
     }
     | comparison_expr K_NOT K_ILIKE additive_expr {
-        ilikeExpr := NewBinaryOpNode(OpILike, $1, $4, $1.Position)
+        ilikeExpr := NewBinaryOpNode(OpILike, $1, likePattern($4), $1.Position)
+        $$ = NewUnaryOpNode(OpNot, ilikeExpr, $1.Position)
+    }
+    | comparison_expr K_LIKE additive_expr K_ESCAPE STRING_LITERAL {
+        likeExpr, err := NewLikeNode(OpLike, $1, $3, $5, $<pos>5)
+        if err != nil {
+            parseError = err
+            return 1
+        }
+        $$ = likeExpr
+    }
+    | comparison_expr K_ILIKE additive_expr K_ESCAPE STRING_LITERAL {
+        ilikeExpr, err := NewLikeNode(OpILike, $1, $3, $5, $<pos>5)
+        if err != nil {
+            parseError = err
+            return 1
+        }
+        $$ = ilikeExpr
+    }
+    | comparison_expr K_NOT K_LIKE additive_expr K_ESCAPE STRING_LITERAL {
+        likeExpr, err := NewLikeNode(OpLike, $1, $4, $6, $<pos>6)
+        if err != nil {
+            parseError = err
+            return 1
+        }
+        $$ = NewUnaryOpNode(OpNot, likeExpr, $1.Position)
+    }
+    | comparison_expr K_NOT K_ILIKE additive_expr K_ESCAPE STRING_LITERAL {
+        ilikeExpr, err := NewLikeNode(OpILike, $1, $4, $6, $<pos>6)
+        if err != nil {
+            parseError = err
+            return 1
+        }
         $$ = NewUnaryOpNode(OpNot, ilikeExpr, $1.Position)
     }
     | comparison_expr K_IS K_NULL           { $$ = NewBinaryOpNode(OpIs, $1, NewNullNode($1.Position), $1.Position) }
@@ -156,7 +196,7 @@ array_elements:
 
 primary:
       NUMERIC_LITERAL       { $$ = NewNumberNode($1, $<pos>1) }
-    | STRING_LITERAL        { $$ = NewStringNode($1, $<pos>1) }
+    | STRING_LITERAL        { $$ = newStringPatternNode($1, $<pattern>1, $<pos>1) }
     | IDENTIFIER            { $$ = NewIdentifierNode($1, $<pos>1) }
     | RFC3339               { $$ = NewTimestampNode($1, $<pos>1) }
     | DATE                  { $$ = NewDateNode($1, $<pos>1) }
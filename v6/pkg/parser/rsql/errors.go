@@ -0,0 +1,28 @@
+package rsql
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/convert"
+)
+
+// SyntaxError is returned when the input is not a valid RSQL expression
+type SyntaxError = convert.SyntaxError
+
+// UnsupportedError reports an RSQL construct that has no TSL equivalent
+type UnsupportedError = convert.UnsupportedError
+
+// UnsupportedErrors lists all the unsupported constructs of an expression,
+// ordered by position
+type UnsupportedErrors = convert.UnsupportedErrors
+
+// SerializeError is returned when a TSL expression can not be written as
+// an RSQL expression
+type SerializeError struct {
+	Expression string
+	Reason     string
+}
+
+func (e SerializeError) Error() string {
+	return fmt.Sprintf("RSQL can not express %s: %s", e.Expression, e.Reason)
+}
@@ -0,0 +1,162 @@
+package rsql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of an RSQL token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenString
+	tokenQuoted
+	tokenOperator
+	tokenSymbol
+)
+
+// token is one RSQL token, position is the rune index in the input
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// Is returns true if the token is the symbol s
+func (t token) Is(s string) bool {
+	return t.kind == tokenSymbol && t.text == s
+}
+
+// EOF returns true if the token ends the input
+func (t token) EOF() bool {
+	return t.kind == tokenEOF
+}
+
+// Quoted returns true if the token is a string literal
+func (t token) Quoted() bool {
+	return t.kind == tokenQuoted
+}
+
+// Text returns the text of the token
+func (t token) Text() string {
+	return t.text
+}
+
+// Position returns the position of the token in the input
+func (t token) Position() int {
+	return t.position
+}
+
+// isKeyword returns true if the token is the unreserved string s, the and
+// and or keywords are alternatives of ; and ,
+func (t token) isKeyword(s string) bool {
+	return t.kind == tokenString && t.text == s
+}
+
+// reservedChars can not appear in unreserved strings
+const reservedChars = `"'();,=!~<>`
+
+// isUnreserved returns true if c can appear in unreserved strings
+func isUnreserved(c rune) bool {
+	return !unicode.IsSpace(c) && !strings.ContainsRune(reservedChars, c)
+}
+
+// tokenize splits an RSQL expression into tokens
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	tokens := []token{}
+
+	for pos := 0; pos < len(runes); {
+		c := runes[pos]
+		start := pos
+
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+
+		case c == '"' || c == '\'':
+			s, end, ok := scanQuoted(runes, pos)
+			if !ok {
+				return nil, SyntaxError{Message: "unterminated string", Position: start}
+			}
+			tokens = append(tokens, token{kind: tokenQuoted, text: s, position: start})
+			pos = end
+
+		case c == '(' || c == ')' || c == ';' || c == ',':
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), position: start})
+			pos++
+
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			end, ok := scanOperator(runes, pos)
+			if !ok {
+				return nil, SyntaxError{Message: "invalid comparison operator", Position: start}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: string(runes[start:end]), position: start})
+			pos = end
+
+		case isUnreserved(c):
+			for pos < len(runes) && isUnreserved(runes[pos]) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[start:pos]), position: start})
+
+		default:
+			return nil, SyntaxError{Message: "unexpected character '" + string(c) + "'", Position: start}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, position: len(runes)}), nil
+}
+
+// scanOperator scans the FIQL operators ==, != and =name=, and the
+// alternative operators <, <=, > and >=. It returns the position after
+// the operator.
+func scanOperator(runes []rune, pos int) (int, bool) {
+	switch runes[pos] {
+	case '<', '>':
+		if pos+1 < len(runes) && runes[pos+1] == '=' {
+			return pos + 2, true
+		}
+		return pos + 1, true
+	case '!':
+		if pos+1 < len(runes) && runes[pos+1] == '=' {
+			return pos + 2, true
+		}
+		return pos, false
+	}
+
+	for end := pos + 1; end < len(runes); end++ {
+		if runes[end] == '=' {
+			return end + 1, true
+		}
+		if !unicode.IsLetter(runes[end]) {
+			break
+		}
+	}
+	return pos, false
+}
+
+// scanQuoted scans a single or double quoted string starting at pos, a
+// backslash makes the next character part of the string. It returns the
+// unquoted text and the position after the closing quote.
+func scanQuoted(runes []rune, pos int) (string, int, bool) {
+	var b strings.Builder
+	quote := runes[pos]
+
+	for pos++; pos < len(runes); pos++ {
+		switch runes[pos] {
+		case '\\':
+			if pos+1 < len(runes) {
+				pos++
+				b.WriteRune(runes[pos])
+			}
+		case quote:
+			return b.String(), pos + 1, true
+		default:
+			b.WriteRune(runes[pos])
+		}
+	}
+
+	return "", pos, false
+}
@@ -0,0 +1,304 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rsql converts RSQL / FIQL expressions into TSL trees, and TSL
+// trees back into RSQL expressions.
+package rsql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/convert"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Parse parses an RSQL expression and returns the same TSL tree
+// tsl.ParseTSL returns for the equivalent TSL phrase.
+//
+// The ; and , operators, or the and and or keywords, join comparisons
+// using AND and OR, and parentheses group them. The comparison operators
+// are ==, !=, =lt= or <, =le= or <=, =gt= or >, =ge= or >=, =in= and =out=,
+// and the extensions =like=, =notlike=, =ilike=, =notilike=, =regex=,
+// =notregex=, =between=, =notbetween= and =isnull=.
+//
+// RSQL arguments are untyped, unquoted arguments that look like numbers,
+// booleans, dates or RFC3339 timestamps become literals of those types,
+// other arguments and all quoted arguments become strings.
+//
+// Unknown comparison operators and selectors that are not TSL identifiers
+// are returned as UnsupportedErrors holding the position of each construct
+// in the input. Positions are counted in runes.
+//
+//	tree, err := rsql.Parse(`name=="Kill Bill";year=gt=2003`)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(tree) // name = 'Kill Bill' AND year > 2003
+func Parse(expression string) (*tsl.TSLNode, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &converter{Reader: convert.NewReader(tokens)}
+	tree, err := c.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := c.Peek(); t.kind != tokenEOF {
+		return nil, c.Unexpected(t)
+	}
+
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// comparison is an RSQL comparison operator
+type comparison struct {
+	operator tsl.Operator
+	negated  bool
+	list     bool
+}
+
+// comparisons maps RSQL comparison operators to TSL operators
+var comparisons = map[string]comparison{
+	"==":           {operator: tsl.OpEQ},
+	"!=":           {operator: tsl.OpNE},
+	"=lt=":         {operator: tsl.OpLT},
+	"<":            {operator: tsl.OpLT},
+	"=le=":         {operator: tsl.OpLE},
+	"<=":           {operator: tsl.OpLE},
+	"=gt=":         {operator: tsl.OpGT},
+	">":            {operator: tsl.OpGT},
+	"=ge=":         {operator: tsl.OpGE},
+	">=":           {operator: tsl.OpGE},
+	"=in=":         {operator: tsl.OpIn, list: true},
+	"=out=":        {operator: tsl.OpIn, negated: true, list: true},
+	"=like=":       {operator: tsl.OpLike},
+	"=notlike=":    {operator: tsl.OpLike, negated: true},
+	"=ilike=":      {operator: tsl.OpILike},
+	"=notilike=":   {operator: tsl.OpILike, negated: true},
+	"=regex=":      {operator: tsl.OpREQ},
+	"=notregex=":   {operator: tsl.OpRNE},
+	"=between=":    {operator: tsl.OpBetween, list: true},
+	"=notbetween=": {operator: tsl.OpBetween, negated: true, list: true},
+	"=isnull=":     {operator: tsl.OpIs},
+}
+
+// Patterns of typed unquoted arguments
+var (
+	numberPattern     = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+	datePattern       = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	timestampPattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`)
+	identifierPattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_./]*(\[[^\]]+\][\p{L}\p{N}_./]*)*$`)
+)
+
+// converter is a recursive descent parser building the TSL tree
+type converter struct {
+	convert.Reader[token]
+}
+
+// or parses the , operator and the or keyword
+func (c *converter) or() (*tsl.TSLNode, error) {
+	left, err := c.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := c.Peek(); t.Is(",") || t.isKeyword("or"); t = c.Peek() {
+		c.Next()
+		right, err := c.and()
+		if err != nil {
+			return nil, err
+		}
+		left = at(tsl.NewBinaryExpr(tsl.OpOr, left, right), t.position)
+	}
+	return left, nil
+}
+
+// and parses the ; operator and the and keyword
+func (c *converter) and() (*tsl.TSLNode, error) {
+	left, err := c.constraint()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := c.Peek(); t.Is(";") || t.isKeyword("and"); t = c.Peek() {
+		c.Next()
+		right, err := c.constraint()
+		if err != nil {
+			return nil, err
+		}
+		left = at(tsl.NewBinaryExpr(tsl.OpAnd, left, right), t.position)
+	}
+	return left, nil
+}
+
+// constraint parses a group or a comparison
+func (c *converter) constraint() (*tsl.TSLNode, error) {
+	if c.Peek().Is("(") {
+		c.Next()
+		n, err := c.or()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Expect(")"); err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	return c.comparison()
+}
+
+// comparison parses a selector, a comparison operator and its arguments
+func (c *converter) comparison() (*tsl.TSLNode, error) {
+	selector := c.Next()
+	if selector.kind != tokenString {
+		return nil, c.Unexpected(selector)
+	}
+	operator := c.Next()
+	if operator.kind != tokenOperator {
+		return nil, SyntaxError{Message: "expected a comparison operator, found " + convert.DescribeToken(operator), Position: operator.position}
+	}
+	args, err := c.arguments()
+	if err != nil {
+		return nil, err
+	}
+
+	field := at(tsl.NewIdentifier(selector.text), selector.position)
+	if !identifierPattern.MatchString(selector.text) {
+		field = c.Unsupported("selector "+selector.text, selector.position)
+	}
+
+	cmp, ok := comparisons[operator.text]
+	if !ok {
+		return c.Unsupported("comparison operator "+operator.text, operator.position), nil
+	}
+
+	var n *tsl.TSLNode
+	switch {
+	case cmp.operator == tsl.OpIs:
+		if len(args) != 1 || args[0].Type() != tsl.KindBooleanLiteral {
+			return nil, SyntaxError{Message: operator.text + " requires true or false", Position: operator.position}
+		}
+		n = at(tsl.NewBinaryExpr(tsl.OpIs, field, tsl.NewNullLiteral()), operator.position)
+		if isNull, _ := args[0].AsBool(); !isNull {
+			n = at(tsl.NewUnaryExpr(tsl.OpNot, n), operator.position)
+		}
+		return n, nil
+
+	case cmp.operator == tsl.OpBetween && len(args) != 2:
+		return nil, SyntaxError{Message: operator.text + " requires two arguments", Position: operator.position}
+
+	case cmp.list:
+		n = at(tsl.NewBinaryExpr(cmp.operator, field, tsl.NewArrayLiteral(args...)), operator.position)
+
+	case len(args) != 1:
+		return nil, SyntaxError{Message: operator.text + " requires one argument", Position: operator.position}
+
+	default:
+		n = at(tsl.NewBinaryExpr(cmp.operator, field, args[0]), operator.position)
+	}
+
+	if cmp.negated {
+		n = at(tsl.NewUnaryExpr(tsl.OpNot, n), operator.position)
+	}
+	return n, nil
+}
+
+// arguments parses one argument or a parenthesized list of arguments
+func (c *converter) arguments() ([]*tsl.TSLNode, error) {
+	if !c.Peek().Is("(") {
+		arg, err := c.argument()
+		if err != nil {
+			return nil, err
+		}
+		return []*tsl.TSLNode{arg}, nil
+	}
+
+	c.Next()
+	args := []*tsl.TSLNode{}
+	for {
+		arg, err := c.argument()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if !c.Peek().Is(",") {
+			break
+		}
+		c.Next()
+	}
+	if err := c.Expect(")"); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// argument converts an argument into a literal, quoted arguments are
+// strings and unquoted arguments are typed by their format
+func (c *converter) argument() (*tsl.TSLNode, error) {
+	t := c.Next()
+
+	switch t.kind {
+	case tokenQuoted:
+		return at(tsl.NewStringLiteral(t.text), t.position), nil
+	case tokenString:
+		return at(literal(t.text), t.position), nil
+	}
+
+	return nil, SyntaxError{Message: "expected an argument, found " + convert.DescribeToken(t), Position: t.position}
+}
+
+// literal returns the typed literal of an unquoted argument
+func literal(s string) *tsl.TSLNode {
+	switch {
+	case s == "true" || s == "false":
+		return tsl.NewBooleanLiteral(s == "true")
+
+	case numberPattern.MatchString(s):
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			break
+		}
+		if value < 0 || strings.HasPrefix(s, "-") {
+			return tsl.NewUnaryExpr(tsl.OpUMinus, tsl.NewNumericLiteral(-value))
+		}
+		return tsl.NewNumericLiteral(value)
+
+	case datePattern.MatchString(s):
+		if _, err := time.Parse("2006-01-02", s); err == nil {
+			return tsl.NewDateLiteral(s)
+		}
+
+	case timestampPattern.MatchString(s):
+		if value, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return tsl.NewTimestampLiteral(value)
+		}
+	}
+
+	return tsl.NewStringLiteral(s)
+}
+
+// at sets the input position of a node
+func at(n *tsl.TSLNode, position int) *tsl.TSLNode {
+	n.SetPosition(position)
+	return n
+}
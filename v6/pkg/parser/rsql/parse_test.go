@@ -0,0 +1,172 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rsql
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/internal/tsltest"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestRSQL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RSQL parser")
+}
+
+var _ = Describe("Parse", func() {
+	// Examples from the RSQL grammar description and the FIQL draft
+	DescribeTable("Parses the specification examples",
+		func(expression string, expected string) {
+			tree, err := Parse(expression)
+			Expect(err).ToNot(HaveOccurred())
+			tsltest.ExpectTree(tree, expected)
+		},
+
+		Entry("and", `name=="Kill Bill";year=gt=2003`, "name = 'Kill Bill' AND year > 2003"),
+		Entry("and keyword", `name=="Kill Bill" and year>2003`, "name = 'Kill Bill' AND year > 2003"),
+		Entry("groups", `genres=in=(sci-fi,action);(director=='Christopher Nolan',actor==*Bale);year=ge=2000`,
+			"genres IN ['sci-fi', 'action'] AND (director = 'Christopher Nolan' OR actor = '*Bale') AND year >= 2000"),
+		Entry("nested selector", "director.lastName==Nolan;year=ge=2000;year=lt=2010",
+			"director.lastName = 'Nolan' AND year >= 2000 AND year < 2010"),
+		Entry("precedence", "genres=in=(sci-fi,action);genres=out=(romance,animated,horror),director==Que*Tarantino",
+			"genres IN ['sci-fi', 'action'] AND genres NOT IN ['romance', 'animated', 'horror'] OR director = 'Que*Tarantino'"),
+		Entry("or keyword", "a==1 or b==2 and c==3", "a = 1 OR b = 2 AND c = 3"),
+		Entry("fiql", "title==foo*;(updated=lt=-P1D,title==*bar)", "title = 'foo*' AND (updated < '-P1D' OR title = '*bar')"),
+
+		// Comparison operators
+		Entry("alternative operators", "a<1;b<=2;c>3;d>=4", "a < 1 AND b <= 2 AND c > 3 AND d >= 4"),
+		Entry("fiql operators", "a=lt=1;b=le=2;c=gt=3;d=ge=4;e!=5", "a < 1 AND b <= 2 AND c > 3 AND d >= 4 AND e != 5"),
+		Entry("in", "id=in=(1,2,3)", "id IN [1, 2, 3]"),
+		Entry("like", "name=like='jo%';name=notilike=%X", "name LIKE 'jo%' AND name NOT ILIKE '%X'"),
+		Entry("regex", `name=regex="^a.*";name=notregex=b`, "name ~= '^a.*' AND name ~! 'b'"),
+		Entry("between", "age=between=(20,30);age=notbetween=(1,2)", "age BETWEEN 20 AND 30 AND age NOT BETWEEN 1 AND 2"),
+		Entry("is null", "email=isnull=true;phone=isnull=false", "email IS NULL AND phone IS NOT NULL"),
+
+		// Arguments
+		Entry("numbers", "a==1.5;b==-2;c==1e3", "a = 1.5 AND b = -2 AND c = 1000"),
+		Entry("booleans", "active==true;deleted==false", "active = TRUE AND deleted = FALSE"),
+		Entry("dates", "day==2020-01-01;created=gt=2020-01-01T10:00:00Z", "day = 2020-01-01 AND created > 2020-01-01T10:00:00Z"),
+		Entry("quoted arguments", `a=="1";b=='true';c=="say \"hi\"";d=='it\'s'`,
+			`a = '1' AND b = 'true' AND c = 'say "hi"' AND d = 'it\'s'`),
+		Entry("whitespace", " a == 1 ; ( b == 2 , c == 3 ) ", "a = 1 AND (b = 2 OR c = 3)"),
+		Entry("array selector", "pods[0].status==ok", "pods[0].status = 'ok'"),
+	)
+
+	DescribeTable("Reports unsupported constructs with positions",
+		func(expression string, expected ...UnsupportedError) {
+			_, err := Parse(expression)
+			Expect(err).To(Equal(UnsupportedErrors(expected)))
+		},
+
+		Entry("operator", "a=near=1",
+			UnsupportedError{Construct: "comparison operator =near=", Position: 1}),
+		Entry("several constructs", "a=near=1;1a==2,b=all=(1,2)",
+			UnsupportedError{Construct: "comparison operator =near=", Position: 1},
+			UnsupportedError{Construct: "selector 1a", Position: 9},
+			UnsupportedError{Construct: "comparison operator =all=", Position: 16}),
+	)
+
+	DescribeTable("Returns syntax errors with positions",
+		func(expression string, position int) {
+			_, err := Parse(expression)
+			Expect(err).To(BeAssignableToTypeOf(SyntaxError{}))
+			Expect(err.(SyntaxError).Position).To(Equal(position))
+		},
+
+		Entry("unterminated string", `a=="x`, 3),
+		Entry("missing argument", "a==", 3),
+		Entry("missing operator", "a;b==1", 1),
+		Entry("missing parenthesis", "(a==1", 5),
+		Entry("empty list", "a=in=()", 6),
+		Entry("invalid operator", "a=1", 1),
+		Entry("between arguments", "a=between=(1)", 1),
+		Entry("is null argument", "a=isnull=yes", 1),
+		Entry("trailing tokens", "a==1 b==2", 5),
+	)
+
+	It("Sets positions of the RSQL input", func() {
+		tree, err := Parse("a==1;name=like=x%")
+		Expect(err).ToNot(HaveOccurred())
+
+		op, _ := tree.AsExprOp()
+		like, _ := op.Right.AsExprOp()
+		Expect(op.Right.Position()).To(Equal(9))
+		Expect(like.Left.Position()).To(Equal(5))
+		Expect(like.Right.Position()).To(Equal(15))
+	})
+})
+
+var _ = Describe("Serialize", func() {
+	DescribeTable("Serializes TSL trees and parses them back",
+		func(phrase string, expression string, back string) {
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := Serialize(tree)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expression))
+
+			parsed, err := Parse(actual)
+			Expect(err).ToNot(HaveOccurred())
+			tsltest.ExpectTree(parsed, back)
+		},
+
+		Entry("comparisons", "name = 'joe' AND age >= 21", "name==joe;age=ge=21", "name = 'joe' AND age >= 21"),
+		Entry("or inside and", "a = 1 AND (b = 2 OR c = 3)", "a==1;(b==2,c==3)", "a = 1 AND (b = 2 OR c = 3)"),
+		Entry("and inside or", "a = 1 OR b = 2 AND c = 3", "a==1,b==2;c==3", "a = 1 OR b = 2 AND c = 3"),
+		Entry("literal on the left", "21 < age", "age=gt=21", "age > 21"),
+		Entry("in", "city IN ['rome', 'new york']", `city=in=(rome,"new york")`, "city IN ['rome', 'new york']"),
+		Entry("not in", "id NOT IN [1, 2]", "id=out=(1,2)", "id NOT IN [1, 2]"),
+		Entry("between", "age BETWEEN 20 AND 30", "age=between=(20,30)", "age BETWEEN 20 AND 30"),
+		Entry("like", "name LIKE 'jo%' AND name NOT ILIKE '%x'", "name=like=jo%;name=notilike=%x", "name LIKE 'jo%' AND name NOT ILIKE '%x'"),
+		Entry("regex", "name ~= '^a' AND name ~! 'b$'", "name=regex=^a;name=notregex=b$", "name ~= '^a' AND name ~! 'b$'"),
+		Entry("is null", "email IS NULL OR phone IS NOT NULL", "email=isnull=true,phone=isnull=false", "email IS NULL OR phone IS NOT NULL"),
+		Entry("boolean field", "active AND NOT deleted", "active==true;deleted==false", "active = TRUE AND deleted = FALSE"),
+		Entry("negated comparisons", "NOT (a < 1 OR b = 2 AND c != 3)", "a=ge=1;(b!=2,c==3)", "a >= 1 AND (b != 2 OR c = 3)"),
+		Entry("double negation", "NOT NOT (a = 1)", "a==1", "a = 1"),
+		Entry("negated regex", "NOT (name ~! 'b')", "name=regex=b", "name ~= 'b'"),
+		Entry("typed strings", "a = '1' AND b = 'true' AND c = '-2' AND d = 'or' AND e = ''",
+			`a=="1";b=="true";c=="-2";d=="or";e==""`,
+			"a = '1' AND b = 'true' AND c = '-2' AND d = 'or' AND e = ''"),
+		Entry("reserved characters", `a = 'x;y' AND b = 'say "hi"'`, `a=="x;y";b=="say \"hi\""`, `a = 'x;y' AND b = 'say "hi"'`),
+		Entry("literals", "a = -1.5 AND b = TRUE AND c = 2020-01-01 AND d > 2020-01-01T10:00:00Z",
+			"a==-1.5;b==true;c==2020-01-01;d=gt=2020-01-01T10:00:00Z",
+			"a = -1.5 AND b = TRUE AND c = 2020-01-01 AND d > 2020-01-01T10:00:00Z"),
+	)
+
+	DescribeTable("Reports expressions RSQL can not express",
+		func(phrase string, expected error) {
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Serialize(tree)
+			Expect(err).To(Equal(expected))
+		},
+
+		Entry("arithmetic", "a + 1 > 2",
+			SerializeError{Expression: "a + 1 > 2", Reason: "expected a field on the left side"}),
+		Entry("field comparison", "a = b",
+			SerializeError{Expression: "a = b", Reason: "arguments must be literals"}),
+		Entry("length", "LEN name > 2",
+			SerializeError{Expression: "LEN name > 2", Reason: "expected a field on the left side"}),
+		Entry("any", "ANY (tags = 'a')",
+			SerializeError{Expression: "ANY (tags = 'a')", Reason: "ANY has no RSQL operator"}),
+	)
+})
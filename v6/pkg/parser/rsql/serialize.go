@@ -0,0 +1,221 @@
+package rsql
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// operatorNames maps TSL operators to RSQL comparison operators
+var operatorNames = map[tsl.Operator]string{
+	tsl.OpEQ:      "==",
+	tsl.OpNE:      "!=",
+	tsl.OpLT:      "=lt=",
+	tsl.OpLE:      "=le=",
+	tsl.OpGT:      "=gt=",
+	tsl.OpGE:      "=ge=",
+	tsl.OpIn:      "=in=",
+	tsl.OpLike:    "=like=",
+	tsl.OpILike:   "=ilike=",
+	tsl.OpREQ:     "=regex=",
+	tsl.OpRNE:     "=notregex=",
+	tsl.OpBetween: "=between=",
+}
+
+// negatedNames maps TSL operators to the RSQL operators of their negation
+var negatedNames = map[tsl.Operator]string{
+	tsl.OpEQ:      "!=",
+	tsl.OpNE:      "==",
+	tsl.OpLT:      "=ge=",
+	tsl.OpLE:      "=gt=",
+	tsl.OpGT:      "=le=",
+	tsl.OpGE:      "=lt=",
+	tsl.OpIn:      "=out=",
+	tsl.OpLike:    "=notlike=",
+	tsl.OpILike:   "=notilike=",
+	tsl.OpREQ:     "=notregex=",
+	tsl.OpRNE:     "=regex=",
+	tsl.OpBetween: "=notbetween=",
+}
+
+// Serialize writes a TSL tree as an RSQL expression, Parse reads it back
+// into an equivalent tree.
+//
+// AND and OR are written as ; and , and RSQL has no negation, so NOT is
+// pushed into the comparisons using De Morgan's laws and the negated
+// comparison operators. Strings that Parse would read as another type, or
+// that have reserved characters, are double quoted. Arithmetic, LEN, ANY,
+// ALL, SUM and comparisons of two fields return a SerializeError.
+//
+//	tree, _ := tsl.ParseTSL("name = 'Kill Bill' AND NOT (year < 2003 OR genre IN ['horror'])")
+//	expression, err := rsql.Serialize(tree)
+//	// name=="Kill Bill";year=ge=2003;genre=out=(horror)
+func Serialize(n *tsl.TSLNode) (string, error) {
+	s, _, err := serialize(n, false)
+	return s, err
+}
+
+// serialize returns the RSQL expression of a node, negated if not is
+// true, and whether it is an or expression
+func serialize(n *tsl.TSLNode, not bool) (string, bool, error) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		// A boolean field
+		return writeComparison(n, tsl.OpEQ, n, tsl.NewBooleanLiteral(!not), false)
+
+	case tsl.KindBinaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		switch op.Operator {
+		case tsl.OpAnd, tsl.OpOr:
+			return junction(op, not)
+		case tsl.OpIs:
+			if op.Right.Type() != tsl.KindNullLiteral {
+				return "", false, tsl.TypeMismatchError{Expected: "null", Got: op.Right.Type()}
+			}
+			field, err := selector(op.Left)
+			if err != nil {
+				return "", false, err
+			}
+			return field + "=isnull=" + strconv.FormatBool(!not), false, nil
+		}
+		return writeComparison(n, op.Operator, op.Left, op.Right, not)
+
+	case tsl.KindUnaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		if op.Operator == tsl.OpNot {
+			return serialize(op.Right, !not)
+		}
+		return "", false, SerializeError{Expression: n.String(), Reason: op.Operator.String() + " has no RSQL operator"}
+	}
+
+	return "", false, SerializeError{Expression: n.String(), Reason: "expected a comparison"}
+}
+
+// junction writes AND as ; and OR as , negated junctions swap the operators
+func junction(op tsl.TSLExpressionOp, not bool) (string, bool, error) {
+	or := (op.Operator == tsl.OpOr) != not
+
+	left, leftOr, err := serialize(op.Left, not)
+	if err != nil {
+		return "", false, err
+	}
+	right, rightOr, err := serialize(op.Right, not)
+	if err != nil {
+		return "", false, err
+	}
+
+	if or {
+		return left + "," + right, true, nil
+	}
+	if leftOr {
+		left = "(" + left + ")"
+	}
+	if rightOr {
+		right = "(" + right + ")"
+	}
+	return left + ";" + right, false, nil
+}
+
+// writeComparison writes a comparison of a field with literals
+func writeComparison(n *tsl.TSLNode, operator tsl.Operator, left, right *tsl.TSLNode, not bool) (string, bool, error) {
+	if left.Type() != tsl.KindIdentifier {
+		flipped, ok := tsl.FlippedComparisons[operator]
+		if !ok || right.Type() != tsl.KindIdentifier {
+			return "", false, SerializeError{Expression: n.String(), Reason: "expected a field on the left side"}
+		}
+		operator, left, right = flipped, right, left
+	}
+
+	names := operatorNames
+	if not {
+		names = negatedNames
+	}
+	name, ok := names[operator]
+	if !ok {
+		return "", false, SerializeError{Expression: n.String(), Reason: operator.String() + " has no RSQL operator"}
+	}
+
+	field, err := selector(left)
+	if err != nil {
+		return "", false, err
+	}
+
+	if array, ok := right.AsArray(); ok && right.Type() == tsl.KindArrayLiteral {
+		values := make([]string, len(array.Values))
+		for i, value := range array.Values {
+			if values[i], err = argument(n, value); err != nil {
+				return "", false, err
+			}
+		}
+		return field + name + "(" + strings.Join(values, ",") + ")", false, nil
+	}
+
+	value, err := argument(n, right)
+	if err != nil {
+		return "", false, err
+	}
+	return field + name + value, false, nil
+}
+
+// selector writes an identifier as a selector
+func selector(n *tsl.TSLNode) (string, error) {
+	name, ok := n.AsString()
+	if !ok || n.Type() != tsl.KindIdentifier || !identifierPattern.MatchString(name) || strings.ContainsAny(name, reservedChars) {
+		return "", SerializeError{Expression: n.String(), Reason: "expected a field"}
+	}
+	return name, nil
+}
+
+// argument writes a literal as an argument
+func argument(n, value *tsl.TSLNode) (string, error) {
+	switch value.Type() {
+	case tsl.KindStringLiteral:
+		s, _ := value.AsString()
+		return quote(s), nil
+
+	case tsl.KindNumericLiteral:
+		v, _ := value.AsFloat64()
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+
+	case tsl.KindBooleanLiteral:
+		b, _ := value.AsBool()
+		return strconv.FormatBool(b), nil
+
+	case tsl.KindDateLiteral:
+		s, _ := value.AsString()
+		return s, nil
+
+	case tsl.KindTimestampLiteral:
+		if t, ok := value.Value().(time.Time); ok {
+			return t.Format(time.RFC3339Nano), nil
+		}
+
+	case tsl.KindUnaryExpr:
+		// Negative numbers
+		op := value.Value().(tsl.TSLExpressionOp)
+		if v, ok := op.Right.AsFloat64(); ok && op.Operator == tsl.OpUMinus && op.Right.Type() == tsl.KindNumericLiteral {
+			return strconv.FormatFloat(-v, 'g', -1, 64), nil
+		}
+	}
+
+	return "", SerializeError{Expression: n.String(), Reason: "arguments must be literals"}
+}
+
+// quote writes a string argument, unquoted when Parse reads it back as the
+// same string
+func quote(s string) string {
+	unquoted := s != "" && s != "and" && s != "or" && literal(s).Type() == tsl.KindStringLiteral
+	for _, c := range s {
+		if !isUnreserved(c) {
+			unquoted = false
+			break
+		}
+	}
+	if unquoted {
+		return s
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the policy package.
+func Example() {
+	// Set the policy of a principal.
+	tenant, _ := tsl.ParseTSL("tenant = 'acme'")
+	p := Policy{
+		Fields: map[string][]tsl.Operator{
+			"title":  nil,
+			"author": {tsl.OpEQ, tsl.OpIn},
+		},
+		Mandatory: tenant,
+	}
+
+	// Apply the policy to user filters.
+	for _, input := range []string{"title = 'Go' or author = 'Joe'", "author like 'J%' or tenant = 'other'"} {
+		tree, _ := tsl.ParseTSL(input)
+
+		applied, err := p.Apply(tree)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(applied)
+	}
+
+	// Output:
+	// tenant = 'acme' AND (title = 'Go' OR author = 'Joe')
+	// forbidden operator LIKE on field author at position 0; forbidden field tenant at position 20
+}
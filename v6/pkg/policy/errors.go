@@ -0,0 +1,42 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// ForbiddenFieldError reports an identifier the principal may not filter on
+type ForbiddenFieldError struct {
+	Field    string
+	Position int
+}
+
+func (e ForbiddenFieldError) Error() string {
+	return fmt.Sprintf("forbidden field %s at position %d", e.Field, e.Position)
+}
+
+// ForbiddenOperatorError reports an operator the principal may not apply
+// to an identifier
+type ForbiddenOperatorError struct {
+	Field    string
+	Operator tsl.Operator
+	Position int
+}
+
+func (e ForbiddenOperatorError) Error() string {
+	return fmt.Sprintf("forbidden operator %s on field %s at position %d", e.Operator, e.Field, e.Position)
+}
+
+// ForbiddenErrors lists all the forbidden fields and operators of a tree,
+// ordered by position
+type ForbiddenErrors []error
+
+func (e ForbiddenErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
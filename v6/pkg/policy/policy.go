@@ -0,0 +1,168 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy enforces field level access policies on TSL trees.
+//
+// A Policy lists the identifiers a principal may filter on, with the
+// operators allowed on each, and a mandatory predicate, such as a tenant
+// condition, that every filter must satisfy. Apply rejects filters using
+// other identifiers or operators, and joins the mandatory predicate to the
+// filter with AND, so no OR in the user filter can bypass it.
+//
+// Usage:
+//
+//	tenant, _ := tsl.ParseTSL("tenant = 'acme'")
+//	p := policy.Policy{
+//		Fields: map[string][]tsl.Operator{
+//			"title":  nil, // any operator
+//			"author": {tsl.OpEQ, tsl.OpIn},
+//		},
+//		Mandatory: tenant,
+//	}
+//
+//	tree, err := p.Apply(userTree)
+//	if err != nil {
+//		// policy.ForbiddenErrors
+//	}
+//
+//	// tree is "tenant = 'acme' AND (<user filter>)", ready for sql.Walk
+//	filter, err := sql.Walk(tree)
+package policy
+
+import (
+	"sort"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Policy is the access policy of a principal.
+type Policy struct {
+	// Fields maps the identifiers the principal may filter on to the
+	// operators allowed on them, an empty list allows all operators
+	Fields map[string][]tsl.Operator
+
+	// Mandatory is joined with AND to every filter, nil for none
+	Mandatory *tsl.TSLNode
+}
+
+// Validate checks that a tree only uses the identifiers and operators the
+// policy allows, and returns ForbiddenErrors listing every violation.
+//
+// The operators of an identifier are the operators on the path from the
+// identifier to the nearest AND, OR or NOT expression, in "len tags > 2"
+// the identifier tags is used with LEN and GT. AND, OR and NOT are always
+// allowed, and a boolean identifier used as a condition has no operators.
+func (p Policy) Validate(n *tsl.TSLNode) error {
+	if n == nil {
+		return nil
+	}
+
+	var errs ForbiddenErrors
+	p.expression(n, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return position(errs[i]) < position(errs[j]) })
+	return errs
+}
+
+// Apply validates a tree and joins the mandatory predicate to it with
+// AND, the input tree is not modified.
+//
+//	// tenant = 'acme' AND (title = 'Book' OR author = 'Joe')
+//
+// A nil tree returns the mandatory predicate.
+func (p Policy) Apply(n *tsl.TSLNode) (*tsl.TSLNode, error) {
+	if err := p.Validate(n); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.Mandatory == nil:
+		return n, nil
+	case n == nil:
+		return p.Mandatory, nil
+	}
+	return tsl.NewBinaryExpr(tsl.OpAnd, p.Mandatory, n), nil
+}
+
+// expression validates a boolean expression
+func (p Policy) expression(n *tsl.TSLNode, errs *ForbiddenErrors) {
+	if op, ok := n.AsExprOp(); ok && (op.Operator == tsl.OpAnd || op.Operator == tsl.OpOr || op.Operator == tsl.OpNot) {
+		for _, child := range n.Children() {
+			p.expression(child, errs)
+		}
+		return
+	}
+	p.operand(n, nil, errs)
+}
+
+// operand validates the identifiers of a comparison, with the operators on
+// their path from the comparison
+func (p Policy) operand(n *tsl.TSLNode, path []tsl.Operator, errs *ForbiddenErrors) {
+	if n.Type() == tsl.KindIdentifier {
+		p.identifier(n, path, errs)
+		return
+	}
+
+	if op, ok := n.AsExprOp(); ok {
+		path = append(path[:len(path):len(path)], op.Operator)
+	}
+	for _, child := range n.Children() {
+		p.operand(child, path, errs)
+	}
+}
+
+// identifier validates one use of an identifier
+func (p Policy) identifier(n *tsl.TSLNode, path []tsl.Operator, errs *ForbiddenErrors) {
+	field := n.Value().(string)
+
+	allowed, ok := p.Fields[field]
+	if !ok {
+		*errs = append(*errs, ForbiddenFieldError{Field: field, Position: n.Position()})
+		return
+	}
+	if len(allowed) == 0 {
+		return
+	}
+
+	for _, op := range path {
+		if !contains(allowed, op) {
+			*errs = append(*errs, ForbiddenOperatorError{Field: field, Operator: op, Position: n.Position()})
+		}
+	}
+}
+
+// contains returns true if an operator is in a list
+func contains(operators []tsl.Operator, op tsl.Operator) bool {
+	for _, o := range operators {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// position returns the position of a forbidden field or operator error
+func position(err error) int {
+	switch e := err.(type) {
+	case ForbiddenFieldError:
+		return e.Position
+	case ForbiddenOperatorError:
+		return e.Position
+	}
+	return 0
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/sql"
+)
+
+func TestPolicy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Policy")
+}
+
+// books is the policy used in the tests
+func books() Policy {
+	tenant, err := tsl.ParseTSL("tenant = 'acme'")
+	Expect(err).ToNot(HaveOccurred())
+
+	return Policy{
+		Fields: map[string][]tsl.Operator{
+			"title":  nil,
+			"author": {tsl.OpEQ, tsl.OpIn},
+			"pages":  {tsl.OpLT, tsl.OpGT, tsl.OpBetween},
+			"tags":   {tsl.OpLen, tsl.OpGT},
+			"loaned": {},
+		},
+		Mandatory: tenant,
+	}
+}
+
+var _ = Describe("Validate", func() {
+	DescribeTable("Accepts allowed fields and operators",
+		func(input string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(books().Validate(tree)).To(Succeed())
+		},
+		Entry("any operator", "title ~= '^Go'"),
+		Entry("allowed operators", "author in ['Joe', 'Jane'] and pages between 100 and 200"),
+		Entry("logical operators", "not (author = 'Joe' or pages < 100)"),
+		Entry("operator path", "len tags > 2"),
+		Entry("boolean field", "loaned"),
+		Entry("constants", "true or 1 = 1"),
+	)
+
+	DescribeTable("Rejects forbidden fields and operators",
+		func(input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(books().Validate(tree)).To(MatchError(expected))
+		},
+		Entry("forbidden field", "tenant = 'other'", "forbidden field tenant at position 0"),
+		Entry("forbidden field under or", "title = 'Go' or tenant = 'other'", "forbidden field tenant at position 16"),
+		Entry("forbidden operator", "author like 'J%'", "forbidden operator LIKE on field author at position 0"),
+		Entry("forbidden operator on path", "len tags < 2", "forbidden operator LT on field tags at position 4"),
+		Entry("forbidden arithmetic", "pages * 2 > 100", "forbidden operator MUL on field pages at position 0"),
+		Entry("forbidden right operand", "title = secret", "forbidden field secret at position 8"),
+		Entry("all the errors by position", "salary > 5 and author != 'Joe'",
+			"forbidden field salary at position 0; forbidden operator NE on field author at position 15"),
+	)
+
+	It("Returns the positioned errors", func() {
+		tree, err := tsl.ParseTSL("pages = 5")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = books().Validate(tree)
+		Expect(err).To(Equal(ForbiddenErrors{ForbiddenOperatorError{Field: "pages", Operator: tsl.OpEQ, Position: 0}}))
+	})
+})
+
+var _ = Describe("Apply", func() {
+	It("Joins the mandatory predicate with AND", func() {
+		tree, err := tsl.ParseTSL("title = 'Go' or author = 'Joe'")
+		Expect(err).ToNot(HaveOccurred())
+
+		applied, err := books().Apply(tree)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied.String()).To(Equal("tenant = 'acme' AND (title = 'Go' OR author = 'Joe')"))
+		Expect(tree.String()).To(Equal("title = 'Go' OR author = 'Joe'"))
+	})
+
+	It("Can not be bypassed with OR", func() {
+		tree, err := tsl.ParseTSL("title = 'Go' or true")
+		Expect(err).ToNot(HaveOccurred())
+
+		applied, err := books().Apply(tree)
+		Expect(err).ToNot(HaveOccurred())
+
+		for tenant, expected := range map[string]bool{"acme": true, "other": false} {
+			record := map[string]interface{}{"tenant": tenant, "title": "Python"}
+			matched, err := semantics.Walk(applied, func(name string) (interface{}, bool) {
+				value, ok := record[name]
+				return value, ok
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matched).To(Equal(expected), tenant)
+		}
+	})
+
+	It("Feeds the sql walker", func() {
+		tree, err := tsl.ParseTSL("title = 'Go' or pages > 100")
+		Expect(err).ToNot(HaveOccurred())
+
+		applied, err := books().Apply(tree)
+		Expect(err).ToNot(HaveOccurred())
+
+		filter, err := sql.Walk(applied)
+		Expect(err).ToNot(HaveOccurred())
+		query, args, err := filter.ToSql()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(query).To(Equal("(tenant = ? AND (title = ? OR pages > ?))"))
+		Expect(args).To(Equal([]interface{}{"acme", "Go", float64(100)}))
+	})
+
+	It("Returns the mandatory predicate for an empty filter", func() {
+		applied, err := books().Apply(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied.String()).To(Equal("tenant = 'acme'"))
+	})
+
+	It("Keeps the filter without a mandatory predicate", func() {
+		tree, err := tsl.ParseTSL("title = 'Go'")
+		Expect(err).ToNot(HaveOccurred())
+
+		applied, err := Policy{Fields: map[string][]tsl.Operator{"title": nil}}.Apply(tree)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(Equal(tree))
+	})
+
+	It("Rejects forbidden filters", func() {
+		tree, err := tsl.ParseTSL("title = 'Go' or tenant = 'other'")
+		Expect(err).ToNot(HaveOccurred())
+
+		applied, err := books().Apply(tree)
+		Expect(err).To(MatchError("forbidden field tenant at position 16"))
+		Expect(applied).To(BeNil())
+	})
+})
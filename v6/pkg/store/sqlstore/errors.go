@@ -0,0 +1,40 @@
+package sqlstore
+
+import "fmt"
+
+// UnknownFieldError is returned when a query uses a field that is not in the column map
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field: %s", e.Field)
+}
+
+// InvalidOrderError is returned when a sort order is not a field name optionally followed by asc or desc
+type InvalidOrderError struct {
+	Order string
+}
+
+func (e InvalidOrderError) Error() string {
+	return fmt.Sprintf("invalid sort order: %q", e.Order)
+}
+
+// InvalidDestinationError is returned when a query destination is not a pointer to a slice of structs
+type InvalidDestinationError struct {
+	Type interface{}
+}
+
+func (e InvalidDestinationError) Error() string {
+	return fmt.Sprintf("invalid destination: expected pointer to slice of structs, got %v", e.Type)
+}
+
+// MissingFieldError is returned when QueryInto gets structs without a field for one of the user fields
+type MissingFieldError struct {
+	Field string
+	Type  interface{}
+}
+
+func (e MissingFieldError) Error() string {
+	return fmt.Sprintf("missing field: %v has no field for %q", e.Type, e.Field)
+}
@@ -0,0 +1,106 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// sliceTarget appends scanned rows to a slice of structs
+type sliceTarget struct {
+	slice     reflect.Value
+	elemType  reflect.Type
+	isPointer bool
+	fields    map[string][]int
+}
+
+// newSliceTarget validates dest and indexes the fields of its element struct
+func newSliceTarget(dest interface{}) (*sliceTarget, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return nil, InvalidDestinationError{Type: reflect.TypeOf(dest)}
+	}
+
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	isPointer := elemType.Kind() == reflect.Ptr
+	if isPointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, InvalidDestinationError{Type: reflect.TypeOf(dest)}
+	}
+
+	fields := map[string][]int{}
+	indexFields(elemType, "", nil, fields)
+
+	return &sliceTarget{
+		slice:     slice,
+		elemType:  elemType,
+		isPointer: isPointer,
+		fields:    fields,
+	}, nil
+}
+
+// fieldName returns the user field name of a struct field, or "-" to skip it
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"db", "json"} {
+		if tag, ok := f.Tag.Lookup(key); ok {
+			if name := strings.Split(tag, ",")[0]; name != "" {
+				return name
+			}
+		}
+	}
+	return strings.ToLower(f.Name)
+}
+
+// indexFields maps dotted user field names to struct field index paths
+func indexFields(t reflect.Type, prefix string, index []int, fields map[string][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := fieldName(f)
+		if name == "-" {
+			continue
+		}
+
+		path := append(append([]int{}, index...), i)
+
+		// Nested structs match dotted names, unless they can scan a column themselves
+		ft := f.Type
+		if ft.Kind() == reflect.Struct && ft != timeType && !reflect.PointerTo(ft).Implements(scannerType) {
+			indexFields(ft, prefix+name+".", path, fields)
+			continue
+		}
+
+		fields[prefix+name] = path
+	}
+}
+
+// newElem allocates a new slice element
+func (t *sliceTarget) newElem() reflect.Value {
+	return reflect.New(t.elemType)
+}
+
+// fieldAddr returns a scan destination for a user field of elem, the
+// field must match a struct field
+func (t *sliceTarget) fieldAddr(elem reflect.Value, field string) interface{} {
+	return elem.Elem().FieldByIndex(t.fields[field]).Addr().Interface()
+}
+
+// append adds elem to the destination slice
+func (t *sliceTarget) append(elem reflect.Value) {
+	if !t.isPointer {
+		elem = elem.Elem()
+	}
+	t.slice.Set(reflect.Append(t.slice, elem))
+}
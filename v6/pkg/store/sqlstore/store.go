@@ -0,0 +1,276 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlstore runs TSL queries against a database/sql table.
+//
+// A Store wraps a *sql.DB, a table name, a dialect of the sql walker and a
+// column map that maps user facing field names to table column names. Only
+// fields listed in the column map can be used in filters, sort orders and
+// results.
+//
+// Usage:
+//
+//	import walker "github.com/yaacov/tree-search-language/v6/pkg/walkers/sql"
+//
+//	store := sqlstore.NewStore(db, "books", walker.DialectSQLite, map[string]string{
+//		"title":      "title",
+//		"author":     "author",
+//		"spec.pages": "pages",
+//	})
+//
+//	rows, err := store.Query(ctx, sqlstore.Query{
+//		Filter:  "spec.pages > 100 and author = 'Joe'",
+//		OrderBy: []string{"spec.pages desc"},
+//		Limit:   10,
+//	})
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/ident"
+	walker "github.com/yaacov/tree-search-language/v6/pkg/walkers/sql"
+)
+
+// Query describes a TSL query against a store.
+type Query struct {
+	// Filter is a TSL phrase, an empty filter matches all rows.
+	Filter string
+	// OrderBy is a list of field names, each optionally followed by "asc" or "desc".
+	OrderBy []string
+	// Limit is the maximum number of rows to return, zero means no limit.
+	Limit uint64
+	// Offset is the number of rows to skip.
+	Offset uint64
+}
+
+// Store runs TSL queries against one database table.
+type Store struct {
+	db      *sql.DB
+	table   string
+	dialect walker.Dialect
+	columns map[string]string
+	fields  []string
+}
+
+// NewStore creates a new Store for a table.
+//
+// The dialect selects the placeholders, PostgreSQL uses numbered dollar
+// placeholders, and the SQL of operators databases spell differently,
+// filters using operators the dialect can not express, such as regular
+// expressions on SQLite, return a tsl.UnsupportedError. The columns map is
+// keyed by the field names users may query, and its values are the
+// matching table column names.
+func NewStore(db *sql.DB, table string, dialect walker.Dialect, columns map[string]string) *Store {
+	fields := make([]string, 0, len(columns))
+	for field := range columns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return &Store{
+		db:      db,
+		table:   table,
+		dialect: dialect,
+		columns: columns,
+		fields:  fields,
+	}
+}
+
+// column returns the table column name of a user field
+func (s *Store) column(field string) (string, error) {
+	if c, ok := s.columns[field]; ok {
+		return c, nil
+	}
+	return "", UnknownFieldError{Field: field}
+}
+
+// orderBy converts a user sort order into an SQL ORDER BY term
+func (s *Store) orderBy(order string) (string, error) {
+	parts := strings.Fields(order)
+	if len(parts) == 0 || len(parts) > 2 {
+		return "", InvalidOrderError{Order: order}
+	}
+
+	column, err := s.column(parts[0])
+	if err != nil {
+		return "", err
+	}
+
+	if len(parts) == 1 {
+		return column, nil
+	}
+
+	switch direction := strings.ToUpper(parts[1]); direction {
+	case "ASC", "DESC":
+		return column + " " + direction, nil
+	default:
+		return "", InvalidOrderError{Order: order}
+	}
+}
+
+// Builder returns the squirrel select builder for a query.
+func (s *Store) Builder(q Query) (sq.SelectBuilder, error) {
+	columns := make([]string, len(s.fields))
+	for i, field := range s.fields {
+		columns[i] = s.columns[field]
+	}
+
+	builder := sq.Select(columns...).
+		From(s.table).
+		PlaceholderFormat(s.dialect.PlaceholderFormat())
+
+	if strings.TrimSpace(q.Filter) != "" {
+		tree, err := tsl.ParseTSL(q.Filter)
+		if err != nil {
+			return builder, err
+		}
+
+		// Check and replace user identifiers with the table column names.
+		tree, err = ident.Walk(tree, s.column)
+		if err != nil {
+			return builder, err
+		}
+
+		filter, err := walker.WalkDialect(tree, s.dialect)
+		if err != nil {
+			return builder, err
+		}
+		builder = builder.Where(filter)
+	}
+
+	for _, order := range q.OrderBy {
+		term, err := s.orderBy(order)
+		if err != nil {
+			return builder, err
+		}
+		builder = builder.OrderBy(term)
+	}
+
+	if q.Limit > 0 {
+		builder = builder.Limit(q.Limit)
+	}
+	if q.Offset > 0 {
+		builder = builder.Offset(q.Offset)
+	}
+
+	return builder, nil
+}
+
+// rows runs a query and calls scan for each returned row
+func (s *Store) rows(ctx context.Context, q Query, scan func(*sql.Rows) error) error {
+	builder, err := s.Builder(q)
+	if err != nil {
+		return err
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Query runs a TSL query and returns the matching rows as maps keyed by
+// the user field names.
+func (s *Store) Query(ctx context.Context, q Query) ([]map[string]interface{}, error) {
+	results := []map[string]interface{}{}
+
+	err := s.rows(ctx, q, func(rows *sql.Rows) error {
+		values := make([]interface{}, len(s.fields))
+		dest := make([]interface{}, len(s.fields))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		row := make(map[string]interface{}, len(s.fields))
+		for i, field := range s.fields {
+			// Some drivers return text columns as raw bytes
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[field] = values[i]
+		}
+		results = append(results, row)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// QueryInto runs a TSL query and appends the matching rows to dest, dest
+// must be a pointer to a slice of structs or of struct pointers.
+//
+// Struct fields are matched to user field names using the `db` tag, the
+// `json` tag or the lower case field name, in that order. Nested structs
+// match dotted field names, for example the field Pages of a struct field
+// tagged `db:"spec"` matches the user field "spec.pages". Fields of nullable
+// columns should be pointers or sql.Null types. Each field of the column
+// map must match a struct field, otherwise QueryInto returns a
+// MissingFieldError before running the query.
+func (s *Store) QueryInto(ctx context.Context, q Query, dest interface{}) error {
+	target, err := newSliceTarget(dest)
+	if err != nil {
+		return err
+	}
+	for _, field := range s.fields {
+		if _, ok := target.fields[field]; !ok {
+			return MissingFieldError{Field: field, Type: target.elemType}
+		}
+	}
+
+	return s.rows(ctx, q, func(rows *sql.Rows) error {
+		elem := target.newElem()
+
+		scanDest := make([]interface{}, len(s.fields))
+		for i, field := range s.fields {
+			scanDest[i] = target.fieldAddr(elem, field)
+		}
+
+		if err := rows.Scan(scanDest...); err != nil {
+			return err
+		}
+
+		target.append(elem)
+		return nil
+	})
+}
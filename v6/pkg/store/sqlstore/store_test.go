@@ -0,0 +1,253 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	walker "github.com/yaacov/tree-search-language/v6/pkg/walkers/sql"
+)
+
+func TestStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SQL store")
+}
+
+const schema = `
+create table books (
+	id integer not null primary key,
+	title text,
+	author text,
+	pages integer,
+	rating integer,
+	onloan boolean
+);
+insert into books (title, author, pages, rating, onloan) values
+	('Book', 'Joe', 100, 4, 1),
+	('Other Book', 'Jane', 200, 3, 1),
+	('Some Book', 'Jane', 50, 5, 1),
+	('Some Other Book', 'Jane', 50, null, 0),
+	('Good Book', 'Joe', 150, 4, 1),
+	('My Big Book', 'Joe', 15, 5, 0);
+`
+
+var columns = map[string]string{
+	"title":       "title",
+	"author":      "author",
+	"spec.pages":  "pages",
+	"spec.rating": "rating",
+	"on_loan":     "onloan",
+}
+
+type bookSpec struct {
+	Pages  uint  `db:"pages"`
+	Rating *uint `db:"rating"`
+}
+
+type book struct {
+	Title  string
+	Author string
+	Spec   bookSpec `db:"spec"`
+	OnLoan bool     `db:"on_loan"`
+}
+
+var _ = Describe("Store", func() {
+	var (
+		ctx   context.Context
+		db    *sql.DB
+		store *Store
+	)
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+
+		db, err = sql.Open("sqlite", ":memory:")
+		Expect(err).ToNot(HaveOccurred())
+
+		// Each connection to an in-memory database opens a new database
+		db.SetMaxOpenConns(1)
+
+		_, err = db.ExecContext(ctx, schema)
+		Expect(err).ToNot(HaveOccurred())
+
+		store = NewStore(db, "books", walker.DialectSQLite, columns)
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).To(Succeed())
+	})
+
+	titles := func(rows []map[string]interface{}) []string {
+		out := make([]string, len(rows))
+		for i, row := range rows {
+			out[i] = row["title"].(string)
+		}
+		return out
+	}
+
+	DescribeTable("Query returns the matching rows",
+		func(q Query, expected []string) {
+			rows, err := store.Query(ctx, q)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(titles(rows)).To(Equal(expected))
+		},
+
+		Entry("empty filter", Query{OrderBy: []string{"title"}},
+			[]string{"Book", "Good Book", "My Big Book", "Other Book", "Some Book", "Some Other Book"}),
+		Entry("equality", Query{Filter: "author = 'Joe'", OrderBy: []string{"title"}},
+			[]string{"Book", "Good Book", "My Big Book"}),
+		Entry("mapped field", Query{Filter: "spec.pages > 100", OrderBy: []string{"spec.pages"}},
+			[]string{"Good Book", "Other Book"}),
+		Entry("descending order", Query{Filter: "spec.pages > 100", OrderBy: []string{"spec.pages desc"}},
+			[]string{"Other Book", "Good Book"}),
+		Entry("null check", Query{Filter: "spec.rating is null"},
+			[]string{"Some Other Book"}),
+		Entry("boolean field", Query{Filter: "on_loan = false", OrderBy: []string{"title"}},
+			[]string{"My Big Book", "Some Other Book"}),
+		Entry("in and like", Query{Filter: "spec.pages in [50, 15] and title like 'Some%'"},
+			[]string{"Some Book", "Some Other Book"}),
+		Entry("ilike", Query{Filter: "title ilike '%other%'", OrderBy: []string{"title"}},
+			[]string{"Other Book", "Some Other Book"}),
+		Entry("not ilike", Query{Filter: "title not ilike '%BOOK%'"},
+			[]string{}),
+		Entry("limit and offset", Query{OrderBy: []string{"title"}, Limit: 2, Offset: 1},
+			[]string{"Good Book", "My Big Book"}),
+	)
+
+	It("Returns rows keyed by user field names", func() {
+		rows, err := store.Query(ctx, Query{Filter: "title = 'Book'"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rows).To(HaveLen(1))
+		Expect(rows[0]).To(Equal(map[string]interface{}{
+			"title":       "Book",
+			"author":      "Joe",
+			"spec.pages":  int64(100),
+			"spec.rating": int64(4),
+			"on_loan":     int64(1),
+		}))
+	})
+
+	It("Scans rows into structs", func() {
+		var books []book
+		err := store.QueryInto(ctx, Query{Filter: "author = 'Jane' and spec.pages = 50", OrderBy: []string{"title"}}, &books)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(books).To(HaveLen(2))
+
+		Expect(books[0].Title).To(Equal("Some Book"))
+		Expect(books[0].Spec.Pages).To(Equal(uint(50)))
+		Expect(*books[0].Spec.Rating).To(Equal(uint(5)))
+		Expect(books[0].OnLoan).To(BeTrue())
+
+		Expect(books[1].Title).To(Equal("Some Other Book"))
+		Expect(books[1].Spec.Rating).To(BeNil())
+		Expect(books[1].OnLoan).To(BeFalse())
+	})
+
+	It("Scans rows into struct pointers", func() {
+		var books []*book
+		err := store.QueryInto(ctx, Query{Filter: "title = 'Good Book'"}, &books)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(books).To(HaveLen(1))
+		Expect(books[0].Author).To(Equal("Joe"))
+	})
+
+	It("Builds dialect specific placeholders", func() {
+		pg := NewStore(db, "books", walker.DialectPostgres, columns)
+		builder, err := pg.Builder(Query{Filter: "author = 'Joe' and spec.pages > 10", Limit: 5})
+		Expect(err).ToNot(HaveOccurred())
+
+		query, args, err := builder.ToSql()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(query).To(Equal("SELECT author, onloan, pages, rating, title FROM books WHERE (author = $1 AND pages > $2) LIMIT 5"))
+		Expect(args).To(Equal([]interface{}{"Joe", 10.0}))
+	})
+
+	DescribeTable("Builds dialect specific operators",
+		func(d walker.Dialect, filter string, expected string) {
+			builder, err := NewStore(db, "books", d, columns).Builder(Query{Filter: filter})
+			Expect(err).ToNot(HaveOccurred())
+
+			query, _, err := builder.ToSql()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(query).To(Equal("SELECT author, onloan, pages, rating, title FROM books WHERE " + expected))
+		},
+
+		Entry("sqlite ilike", walker.DialectSQLite, "title ilike 'b%'", "LOWER(title) LIKE LOWER(?)"),
+		Entry("mysql ilike", walker.DialectMySQL, "title ilike 'b%'", "LOWER(title) LIKE LOWER(?)"),
+		Entry("mysql regexp", walker.DialectMySQL, "title ~= '^B'", "title REGEXP ?"),
+		Entry("postgres ilike", walker.DialectPostgres, "title ilike 'b%'", "title ILIKE $1"),
+		Entry("postgres regexp", walker.DialectPostgres, "title ~! '^B'", "title !~ $1"),
+		Entry("postgres full-text search", walker.DialectPostgres, "title @@ 'book'", "to_tsvector(title) @@ plainto_tsquery($1)"),
+	)
+
+	DescribeTable("Rejects operators the dialect can not express",
+		func(d walker.Dialect, filter string) {
+			_, err := NewStore(db, "books", d, columns).Builder(Query{Filter: filter})
+			Expect(err).To(BeAssignableToTypeOf(tsl.UnsupportedError{}))
+		},
+
+		Entry("sqlite regexp", walker.DialectSQLite, "title ~= '^B'"),
+		Entry("sqlite full-text search", walker.DialectSQLite, "title @@ 'book'"),
+		Entry("mysql full-text search", walker.DialectMySQL, "title @@ 'book'"),
+	)
+
+	It("Rejects unknown fields in filters", func() {
+		_, err := store.Query(ctx, Query{Filter: "id = 1"})
+		Expect(err).To(MatchError(UnknownFieldError{Field: "id"}))
+	})
+
+	It("Rejects unknown fields in sort orders", func() {
+		_, err := store.Query(ctx, Query{OrderBy: []string{"id"}})
+		Expect(err).To(MatchError(UnknownFieldError{Field: "id"}))
+	})
+
+	It("Rejects invalid sort directions", func() {
+		_, err := store.Query(ctx, Query{OrderBy: []string{"title; drop table books"}})
+		Expect(err).To(BeAssignableToTypeOf(InvalidOrderError{}))
+	})
+
+	It("Rejects invalid destinations", func() {
+		var titles []string
+		err := store.QueryInto(ctx, Query{}, &titles)
+		Expect(err).To(BeAssignableToTypeOf(InvalidDestinationError{}))
+	})
+
+	It("Rejects structs without a field for a user field", func() {
+		type title struct {
+			Title string
+		}
+
+		var titles []title
+		err := store.QueryInto(ctx, Query{}, &titles)
+		Expect(err).To(MatchError(MissingFieldError{Field: "author", Type: reflect.TypeOf(title{})}))
+		Expect(titles).To(BeEmpty())
+	})
+
+	It("Returns syntax errors", func() {
+		_, err := store.Query(ctx, Query{Filter: "title ="})
+		Expect(err).To(HaveOccurred())
+	})
+})
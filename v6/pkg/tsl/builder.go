@@ -0,0 +1,99 @@
+package tsl
+
+import "time"
+
+// The New* functions build TSL trees without parsing a TSL phrase, for
+// example when converting a query written in another language. Expression
+// nodes take the position of their first operand, use SetPosition to
+// record positions of the original input.
+
+// NewNumericLiteral creates a numeric literal node
+func NewNumericLiteral(value float64) *TSLNode {
+	return &TSLNode{node: &Node{Kind: KindNumericLiteral, Value: value}}
+}
+
+// NewStringLiteral creates a string literal node
+func NewStringLiteral(value string) *TSLNode {
+	return &TSLNode{node: &Node{Kind: KindStringLiteral, Value: value}}
+}
+
+// NewIdentifier creates an identifier node
+func NewIdentifier(name string) *TSLNode {
+	return &TSLNode{node: &Node{Kind: KindIdentifier, Value: name}}
+}
+
+// NewBooleanLiteral creates a boolean literal node
+func NewBooleanLiteral(value bool) *TSLNode {
+	return &TSLNode{node: &Node{Kind: KindBooleanLiteral, Value: value}}
+}
+
+// NewNullLiteral creates a null literal node
+func NewNullLiteral() *TSLNode {
+	return &TSLNode{node: &Node{Kind: KindNullLiteral}}
+}
+
+// NewDateLiteral creates a date literal node, value is a YYYY-MM-DD date
+func NewDateLiteral(value string) *TSLNode {
+	return &TSLNode{node: &Node{Kind: KindDateLiteral, Value: value}}
+}
+
+// NewTimestampLiteral creates a timestamp literal node
+func NewTimestampLiteral(value time.Time) *TSLNode {
+	return &TSLNode{node: &Node{Kind: KindTimestampLiteral, Value: value}}
+}
+
+// NewArrayLiteral creates an array literal node
+func NewArrayLiteral(values ...*TSLNode) *TSLNode {
+	n := &TSLNode{node: &Node{Kind: KindArrayLiteral}}
+	n.SetArrayValues(values)
+	if len(values) > 0 {
+		n.SetPosition(values[0].Position())
+	}
+	return n
+}
+
+// NewBinaryExpr creates a binary expression node
+func NewBinaryExpr(op Operator, left, right *TSLNode) *TSLNode {
+	return &TSLNode{node: &Node{
+		Kind:     KindBinaryExpr,
+		Operator: op,
+		Left:     left.unwrap(),
+		Right:    right.unwrap(),
+		Position: left.Position(),
+	}}
+}
+
+// NewUnaryExpr creates a unary expression node, the operand is stored
+// as the right child
+func NewUnaryExpr(op Operator, right *TSLNode) *TSLNode {
+	return &TSLNode{node: &Node{
+		Kind:     KindUnaryExpr,
+		Operator: op,
+		Right:    right.unwrap(),
+		Position: right.Position(),
+	}}
+}
+
+// Position returns the position of the node in the parsed input
+func (n *TSLNode) Position() int {
+	if n == nil || n.node == nil {
+		return 0
+	}
+	return n.node.Position
+}
+
+// SetPosition sets the position of the node in the parsed input
+func (n *TSLNode) SetPosition(pos int) {
+	if n == nil || n.node == nil {
+		return
+	}
+	n.node.Position = pos
+}
+
+// unwrap returns the internal node, or nil for a nil TSLNode
+func (n *TSLNode) unwrap() *Node {
+	if n == nil {
+		return nil
+	}
+	return n.node
+}
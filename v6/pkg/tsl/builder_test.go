@@ -0,0 +1,40 @@
+package tsl
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TSL Node builders", func() {
+	It("builds the same tree as the parser", func() {
+		date := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+		built := NewBinaryExpr(OpAnd,
+			NewBinaryExpr(OpIn, NewIdentifier("name"), NewArrayLiteral(NewStringLiteral("a"), NewNumericLiteral(1))),
+			NewUnaryExpr(OpNot, NewBinaryExpr(OpOr,
+				NewBinaryExpr(OpIs, NewIdentifier("email"), NewNullLiteral()),
+				NewBinaryExpr(OpGT, NewIdentifier("created"), NewArrayLiteral(
+					NewDateLiteral("2020-01-01"), NewTimestampLiteral(date), NewBooleanLiteral(true)),
+				),
+			)),
+		)
+
+		parsed, err := ParseTSL("name in ['a', 1] and not (email is null or created > [2020-01-01, 2020-01-01T10:00:00Z, true])")
+		Expect(err).NotTo(HaveOccurred())
+
+		builtJSON, err := json.Marshal(built)
+		Expect(err).NotTo(HaveOccurred())
+		parsedJSON, err := json.Marshal(parsed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(builtJSON).To(MatchJSON(parsedJSON))
+	})
+
+	It("keeps positions", func() {
+		left := NewIdentifier("a")
+		left.SetPosition(4)
+		Expect(NewBinaryExpr(OpEQ, left, NewNumericLiteral(1)).Position()).To(Equal(4))
+		Expect(NewUnaryExpr(OpNot, left).Position()).To(Equal(4))
+	})
+})
@@ -0,0 +1,251 @@
+package tsl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// placeholder is the identifier that replaces literals in shapes
+const placeholder = "?"
+
+// FlippedComparisons maps comparison operators to their equivalents when
+// the operands are swapped, "5 < a" is "a > 5"
+var FlippedComparisons = map[Operator]Operator{
+	OpEQ: OpEQ,
+	OpNE: OpNE,
+	OpLT: OpGT,
+	OpLE: OpGE,
+	OpGT: OpLT,
+	OpGE: OpLE,
+}
+
+// commutativeOperators are the associative operators whose operands can
+// be reordered
+var commutativeOperators = map[Operator]bool{
+	OpAnd:  true,
+	OpOr:   true,
+	OpPlus: true,
+	OpStar: true,
+}
+
+// canonicalizer rewrites trees into their canonical form, in shape mode
+// literals are replaced by placeholders
+type canonicalizer struct {
+	shape bool
+}
+
+// Canonicalize returns the canonical form of a tree, trees that differ only
+// in the order of commutative operands, or in the way literals are written,
+// have the same canonical form. The input tree is not modified.
+//
+//	a, _ := tsl.ParseTSL("b = 2 AND a = 1")
+//	b, _ := tsl.ParseTSL("1 = a AND b = 2")
+//	tsl.Canonicalize(a).String() == tsl.Canonicalize(b).String()
+//	// a = 1 AND b = 2
+//
+// The canonical form:
+//
+//   - flattens chains of AND, OR, + and *, and sorts their operands,
+//     duplicated AND and OR operands are removed,
+//   - moves literals to the right side of comparisons, "1 < a" is "a > 1",
+//     and sorts the operands of = and != comparing two expressions,
+//   - sorts the values of IN lists and removes duplicated values,
+//   - folds negative number literals and converts timestamps to UTC.
+func Canonicalize(n *TSLNode) *TSLNode {
+	c := canonicalizer{}
+	canonical, _ := Rewrite(n, c.rewrite)
+	return canonical
+}
+
+// Fingerprint returns a stable hash of the canonical form of a tree, as a
+// hex string. Equivalent filters that differ only in operand order or in
+// the way literals are written have the same fingerprint.
+func Fingerprint(n *TSLNode) string {
+	return hash(Canonicalize(n).String())
+}
+
+// Shape returns the canonical phrase of a tree with the literals replaced
+// by "?", for grouping queries that differ only in their values.
+//
+//	tree, _ := tsl.ParseTSL("b > 5 AND a = 'Joe' AND c IN [1, 2, 3]")
+//	tsl.Shape(tree)
+//	// a = ? AND b > ? AND c IN [?]
+//
+// IN lists of any length have the same shape.
+func Shape(n *TSLNode) string {
+	c := canonicalizer{shape: true}
+	shape, _ := Rewrite(n, c.rewrite)
+	return shape.String()
+}
+
+// ShapeFingerprint returns a stable hash of the shape of a tree, as a hex
+// string.
+func ShapeFingerprint(n *TSLNode) string {
+	return hash(Shape(n))
+}
+
+// hash returns the hex SHA-256 hash of a phrase
+func hash(phrase string) string {
+	sum := sha256.Sum256([]byte(phrase))
+	return hex.EncodeToString(sum[:])
+}
+
+// isConstant returns true for literals and placeholders
+func isConstant(n *TSLNode) bool {
+	switch n.Type() {
+	case KindNumericLiteral, KindStringLiteral, KindBooleanLiteral, KindDateLiteral, KindTimestampLiteral, KindNullLiteral:
+		return true
+	case KindIdentifier:
+		return n.Value().(string) == placeholder
+	}
+	return false
+}
+
+// rewrite is called by Rewrite on each node, after the children of the
+// node were rewritten
+func (c canonicalizer) rewrite(n *TSLNode) (*TSLNode, error) {
+	switch n.Type() {
+	case KindNumericLiteral, KindStringLiteral, KindBooleanLiteral, KindDateLiteral:
+		if c.shape {
+			return NewIdentifier(placeholder), nil
+		}
+		return n, nil
+
+	case KindTimestampLiteral:
+		if c.shape {
+			return NewIdentifier(placeholder), nil
+		}
+		if t, ok := n.Value().(time.Time); ok && t.Location() != time.UTC {
+			return NewTimestampLiteral(t.UTC()), nil
+		}
+		return n, nil
+
+	case KindUnaryExpr:
+		op := n.Value().(TSLExpressionOp)
+		if op.Operator == OpUMinus && op.Right.Type() == KindNumericLiteral {
+			v, _ := op.Right.AsFloat64()
+			return NewNumericLiteral(-v), nil
+		}
+		if op.Operator == OpUMinus && c.shape && isConstant(op.Right) {
+			return op.Right, nil
+		}
+		return n, nil
+
+	case KindBinaryExpr:
+		return c.binary(n), nil
+	}
+
+	return n, nil
+}
+
+// binary rewrites a binary expression
+func (c canonicalizer) binary(n *TSLNode) *TSLNode {
+	op := n.Value().(TSLExpressionOp)
+
+	if commutativeOperators[op.Operator] {
+		// Repeated AND and OR operands do not change the result, in shape
+		// mode they may differ in their literals
+		unique := (op.Operator == OpAnd || op.Operator == OpOr) && !c.shape
+		return sortedChain(n, op.Operator, unique)
+	}
+
+	if flipped, ok := FlippedComparisons[op.Operator]; ok {
+		swap := isConstant(op.Left) && !isConstant(op.Right)
+		if (op.Operator == OpEQ || op.Operator == OpNE) && isConstant(op.Left) == isConstant(op.Right) {
+			swap = op.Right.String() < op.Left.String()
+		}
+		if swap {
+			return NewBinaryExpr(flipped, op.Right, op.Left)
+		}
+		return n
+	}
+
+	if op.Operator == OpIn {
+		if arr, ok := op.Right.AsArray(); ok {
+			values := sortedUnique(arr.Values, true)
+			if !sameNodes(values, arr.Values) {
+				return NewBinaryExpr(OpIn, op.Left, NewArrayLiteral(values...))
+			}
+		}
+	}
+
+	return n
+}
+
+// sortedChain flattens a chain of a commutative operator and sorts its
+// operands
+func sortedChain(n *TSLNode, operator Operator, unique bool) *TSLNode {
+	operands := chainOperands(n, operator)
+	sorted := sortedUnique(operands, unique)
+
+	// Keep the input tree if it already is a sorted left associative chain
+	if sameNodes(sorted, operands) && isLeftChain(n, operator) {
+		return n
+	}
+
+	result := sorted[0]
+	for _, operand := range sorted[1:] {
+		result = NewBinaryExpr(operator, result, operand)
+	}
+	return result
+}
+
+// chainOperands returns the operands of a chain of a binary operator
+func chainOperands(n *TSLNode, operator Operator) []*TSLNode {
+	if op, ok := n.AsExprOp(); ok && n.Type() == KindBinaryExpr && op.Operator == operator {
+		return append(chainOperands(op.Left, operator), chainOperands(op.Right, operator)...)
+	}
+	return []*TSLNode{n}
+}
+
+// isLeftChain returns true if no right operand of a chain is itself an
+// expression of the chain operator
+func isLeftChain(n *TSLNode, operator Operator) bool {
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != KindBinaryExpr || op.Operator != operator {
+		return true
+	}
+	if right, ok := op.Right.AsExprOp(); ok && op.Right.Type() == KindBinaryExpr && right.Operator == operator {
+		return false
+	}
+	return isLeftChain(op.Left, operator)
+}
+
+// sortedUnique returns the nodes sorted by their phrase, if unique is true
+// duplicated nodes are removed
+func sortedUnique(nodes []*TSLNode, unique bool) []*TSLNode {
+	type keyed struct {
+		key  string
+		node *TSLNode
+	}
+
+	items := make([]keyed, len(nodes))
+	for i, node := range nodes {
+		items[i] = keyed{key: node.String(), node: node}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].key < items[j].key })
+
+	result := make([]*TSLNode, 0, len(items))
+	for i, item := range items {
+		if unique && i > 0 && items[i-1].key == item.key {
+			continue
+		}
+		result = append(result, item.node)
+	}
+	return result
+}
+
+// sameNodes returns true if two lists hold the same nodes in the same order
+func sameNodes(a, b []*TSLNode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].node != b[i].node {
+			return false
+		}
+	}
+	return true
+}
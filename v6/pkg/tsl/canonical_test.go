@@ -0,0 +1,111 @@
+package tsl
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Canonicalize", func() {
+	DescribeTable("returns the canonical phrase",
+		func(input, expected string) {
+			tree, err := ParseTSL(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(Canonicalize(tree).String()).To(Equal(expected))
+		},
+		Entry("sorted", "a = 1 AND b = 2", "a = 1 AND b = 2"),
+		Entry("commutative and", "b = 2 and a = 1", "a = 1 AND b = 2"),
+		Entry("flattened chains", "c = 3 and (b = 2 and a = 1)", "a = 1 AND b = 2 AND c = 3"),
+		Entry("nested chains", "(d = 1 or c = 1) and (b = 1 or a = 1)", "(a = 1 OR b = 1) AND (c = 1 OR d = 1)"),
+		Entry("duplicated operands", "a = 1 or b = 2 or a = 1", "a = 1 OR b = 2"),
+		Entry("literal on the left", "1 < a", "a > 1"),
+		Entry("equality of fields", "b = a", "a = b"),
+		Entry("equality of literals", "2 = 1", "1 = 2"),
+		Entry("arithmetic", "pages * 2 + 1 > 3 * size", "1 + 2 * pages > 3 * size"),
+		Entry("in lists", "a in [3, 1, 2, 1]", "a IN [1, 2, 3]"),
+		Entry("not in lists", "a not in ['b', 'a']", "a NOT IN ['a', 'b']"),
+		Entry("negative numbers", "a > -(5)", "a > -5"),
+		Entry("quoted strings", `a = "joe"`, "a = 'joe'"),
+		Entry("timestamps", "a > 2020-01-01T02:00:00+02:00", "a > 2020-01-01T00:00:00Z"),
+		Entry("not commutative", "b - a > 1", "b - a > 1"),
+	)
+
+	DescribeTable("gives equivalent filters the same fingerprint",
+		func(a, b string) {
+			treeA, err := ParseTSL(a)
+			Expect(err).NotTo(HaveOccurred())
+			treeB, err := ParseTSL(b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(Fingerprint(treeA)).To(Equal(Fingerprint(treeB)))
+		},
+		Entry("operand order", "b = 2 AND a = 1", "a = 1 AND b = 2"),
+		Entry("comparison direction", "a >= 5 or b = 'x'", "'x' = b or 5 <= a"),
+		Entry("grouping", "(a = 1 and b = 2) and c = 3", "a = 1 and (c = 3 and b = 2)"),
+		Entry("list order", "a in ['x', 'y']", "a in ['y', 'x', 'x']"),
+		Entry("quotes", `name like "j%"`, "name like 'j%'"),
+	)
+
+	DescribeTable("gives different filters different fingerprints",
+		func(a, b string) {
+			treeA, err := ParseTSL(a)
+			Expect(err).NotTo(HaveOccurred())
+			treeB, err := ParseTSL(b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(Fingerprint(treeA)).NotTo(Equal(Fingerprint(treeB)))
+		},
+		Entry("values", "a = 1", "a = 2"),
+		Entry("operators", "a = 1 and b = 2", "a = 1 or b = 2"),
+		Entry("not commutative", "a - b > 1", "b - a > 1"),
+	)
+
+	It("returns a hex SHA-256 hash", func() {
+		tree, err := ParseTSL("a = 1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(Fingerprint(tree)).To(MatchRegexp(`^[0-9a-f]{64}$`))
+	})
+
+	It("does not modify the input tree", func() {
+		tree, err := ParseTSL("b = 2 and 1 < a")
+		Expect(err).NotTo(HaveOccurred())
+		Canonicalize(tree)
+		Shape(tree)
+		Expect(tree.String()).To(Equal("b = 2 AND 1 < a"))
+	})
+})
+
+var _ = Describe("FlippedComparisons", func() {
+	It("flips comparisons", func() {
+		for operator, flipped := range FlippedComparisons {
+			Expect(FlippedComparisons[flipped]).To(Equal(operator))
+		}
+		Expect(FlippedComparisons[OpLT]).To(Equal(OpGT))
+	})
+})
+
+var _ = Describe("Shape", func() {
+	DescribeTable("replaces literals",
+		func(input, expected string) {
+			tree, err := ParseTSL(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(Shape(tree)).To(Equal(expected))
+		},
+		Entry("comparisons", "b > 5 and a = 'Joe'", "a = ? AND b > ?"),
+		Entry("lists", "c in [1, 2, 3] and d not in ['x']", "c IN [?] AND d NOT IN [?]"),
+		Entry("between", "a between 1 and 10", "a BETWEEN ? AND ?"),
+		Entry("negative numbers", "a > -5", "a > ?"),
+		Entry("literal on the left", "5 < a", "a > ?"),
+		Entry("null checks", "a is not null", "a IS NOT NULL"),
+		Entry("repeated fields", "a = 1 or a = 2", "a = ? OR a = ?"),
+		Entry("dates", "a > 2020-01-01 and b < 2020-01-01T00:00:00Z", "a > ? AND b < ?"),
+	)
+
+	It("groups queries that differ in values", func() {
+		a, err := ParseTSL("(a = 1 or c = 1) and (a = 2 or b = 1)")
+		Expect(err).NotTo(HaveOccurred())
+		b, err := ParseTSL("(b = 5 or a = 9) and (c = 7 or a = 8)")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(Shape(a)).To(Equal("(a = ? OR b = ?) AND (a = ? OR c = ?)"))
+		Expect(ShapeFingerprint(a)).To(Equal(ShapeFingerprint(b)))
+		Expect(Fingerprint(a)).NotTo(Equal(Fingerprint(b)))
+	})
+})
@@ -0,0 +1,258 @@
+package tsl
+
+import "strings"
+
+// ChangeKind is the kind of a change between two trees
+type ChangeKind string
+
+const (
+	// ChangeAdded is an expression that is only in the new tree
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved is an expression that is only in the old tree
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeModified is an expression that was replaced by a similar one,
+	// such as a comparison of the same operand with another value
+	ChangeModified ChangeKind = "changed"
+	// ChangeOperator is an expression whose operator changed, while its
+	// operands did not
+	ChangeOperator ChangeKind = "operator"
+)
+
+// Change is one difference between two trees, expressions are written as
+// canonical TSL phrases.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+
+	// Before is the old expression, empty for added expressions
+	Before string `json:"before,omitempty"`
+	// After is the new expression, empty for removed expressions
+	After string `json:"after,omitempty"`
+
+	// From and To are the old and new operators of an operator change
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	// Within is the new AND or OR expression holding the change, empty for
+	// changes of top level AND operands
+	Within string `json:"within,omitempty"`
+}
+
+// String returns a human readable description of the change
+func (c Change) String() string {
+	var text string
+	switch c.Kind {
+	case ChangeAdded:
+		text = "added `" + c.After + "`"
+	case ChangeRemoved:
+		text = "removed `" + c.Before + "`"
+	case ChangeOperator:
+		text = "changed operator `" + c.From + "` → `" + c.To + "` in `" + c.Before + "`"
+	default:
+		text = "changed `" + c.Before + "` → `" + c.After + "`"
+	}
+
+	if c.Within != "" {
+		text += " within `" + c.Within + "`"
+	}
+	return text
+}
+
+// Changes is the list of differences between two trees
+type Changes []Change
+
+// String returns a human readable description of the changes, separated
+// by semicolons
+func (c Changes) String() string {
+	texts := make([]string, len(c))
+	for i, change := range c {
+		texts[i] = change.String()
+	}
+	return strings.Join(texts, "; ")
+}
+
+// Diff returns the differences between an old and a new tree, comparing
+// their canonical forms, so that reordered operands are not changes.
+//
+//	a, _ := tsl.ParseTSL("pages > 100 AND title = 'Book'")
+//	b, _ := tsl.ParseTSL("author = 'Joe' AND title = 'Book' AND pages > 200")
+//	tsl.Diff(a, b).String()
+//	// changed `pages > 100` → `pages > 200`; added `author = 'Joe'`
+//
+// The operands of AND and OR expressions are matched by their phrase, an
+// unmatched old operand is paired with a similar new operand: comparisons
+// are similar if their left operands are the same, and AND or OR
+// expressions are similar if they share an operand. Similar AND or OR
+// expressions with the same operator are compared operand by operand,
+// other similar expressions are reported as changed. Unpaired old operands
+// are removed and unpaired new operands are added.
+//
+// Changes marshal to JSON objects, with the kind of the change and the
+// phrases of the expressions.
+func Diff(a, b *TSLNode) Changes {
+	if a != nil && a.node == nil {
+		a = nil
+	}
+	if b != nil && b.node == nil {
+		b = nil
+	}
+	if a != nil {
+		a = Canonicalize(a)
+	}
+	if b != nil {
+		b = Canonicalize(b)
+	}
+
+	// A top level operator change, such as "x AND y" to "x OR y"
+	if a != nil && b != nil && isLogical(a) && isLogical(b) && a.String() != b.String() && sameOperands(a, b) {
+		return Changes{operatorChange(a, b)}
+	}
+
+	var before, after []*TSLNode
+	if a != nil {
+		before = chainOperands(a, OpAnd)
+	}
+	if b != nil {
+		after = chainOperands(b, OpAnd)
+	}
+	return diffOperands(before, after, "")
+}
+
+// diffOperands returns the differences between the operands of an old and
+// a new AND or OR expression
+func diffOperands(before, after []*TSLNode, within string) Changes {
+	matched := map[int]bool{}
+	phrases := make([]string, len(after))
+	for i, n := range after {
+		phrases[i] = n.String()
+	}
+
+	// Match identical operands first, then pair similar ones
+	var unmatched []*TSLNode
+	for _, n := range before {
+		phrase, found := n.String(), false
+		for i := range after {
+			if !matched[i] && phrases[i] == phrase {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, n)
+		}
+	}
+
+	var changes Changes
+	for _, n := range unmatched {
+		paired := false
+		for i, m := range after {
+			if !matched[i] && similar(n, m) {
+				matched[i], paired = true, true
+				changes = append(changes, diffPair(n, m, within)...)
+				break
+			}
+		}
+		if !paired {
+			changes = append(changes, Change{Kind: ChangeRemoved, Before: n.String(), Within: within})
+		}
+	}
+
+	for i := range after {
+		if !matched[i] {
+			changes = append(changes, Change{Kind: ChangeAdded, After: phrases[i], Within: within})
+		}
+	}
+	return changes
+}
+
+// diffPair returns the differences between two similar expressions
+func diffPair(a, b *TSLNode, within string) Changes {
+	opA, _ := a.AsExprOp()
+	opB, _ := b.AsExprOp()
+
+	switch {
+	case isLogical(a) && opA.Operator == opB.Operator:
+		return diffOperands(chainOperands(a, opA.Operator), chainOperands(b, opB.Operator), b.String())
+
+	case isLogical(a) && sameOperands(a, b),
+		!isLogical(a) && a.Type() == KindBinaryExpr && b.Type() == KindBinaryExpr &&
+			opA.Operator != opB.Operator && opA.Right.String() == opB.Right.String():
+		change := operatorChange(a, b)
+		change.Within = within
+		return Changes{change}
+	}
+
+	return Changes{{Kind: ChangeModified, Before: a.String(), After: b.String(), Within: within}}
+}
+
+// operatorChange returns the change of the operator of an expression
+func operatorChange(a, b *TSLNode) Change {
+	opA, _ := a.AsExprOp()
+	opB, _ := b.AsExprOp()
+	return Change{
+		Kind:   ChangeOperator,
+		Before: a.String(),
+		After:  b.String(),
+		From:   opA.Operator.symbol(),
+		To:     opB.Operator.symbol(),
+	}
+}
+
+// isLogical returns true for AND and OR expressions
+func isLogical(n *TSLNode) bool {
+	op, ok := n.AsExprOp()
+	return ok && n.Type() == KindBinaryExpr && (op.Operator == OpAnd || op.Operator == OpOr)
+}
+
+// sameOperands returns true if two AND or OR expressions have the same
+// operands
+func sameOperands(a, b *TSLNode) bool {
+	opA, _ := a.AsExprOp()
+	opB, _ := b.AsExprOp()
+	return operandPhrases(chainOperands(a, opA.Operator)) == operandPhrases(chainOperands(b, opB.Operator))
+}
+
+// operandPhrases joins the phrases of a list of operands
+func operandPhrases(operands []*TSLNode) string {
+	phrases := make([]string, len(operands))
+	for i, n := range operands {
+		phrases[i] = n.String()
+	}
+	return strings.Join(phrases, "\n")
+}
+
+// similar returns true if an old expression was probably edited into a
+// new one
+func similar(a, b *TSLNode) bool {
+	if isLogical(a) || isLogical(b) {
+		if !isLogical(a) || !isLogical(b) {
+			return false
+		}
+		opA, _ := a.AsExprOp()
+		opB, _ := b.AsExprOp()
+		for _, x := range chainOperands(a, opA.Operator) {
+			for _, y := range chainOperands(b, opB.Operator) {
+				if x.String() == y.String() {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	keyA, okA := subject(a)
+	keyB, okB := subject(b)
+	return okA && okB && keyA == keyB
+}
+
+// subject returns the phrase of the left operand of a comparison, NOT and
+// other unary operators are skipped
+func subject(n *TSLNode) (string, bool) {
+	op, ok := n.AsExprOp()
+	if !ok {
+		return "", false
+	}
+	if n.Type() == KindUnaryExpr {
+		return subject(op.Right)
+	}
+	return op.Left.String(), true
+}
@@ -0,0 +1,63 @@
+package tsl
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	DescribeTable("describes the changes",
+		func(a, b, expected string) {
+			before, err := ParseTSL(a)
+			Expect(err).NotTo(HaveOccurred())
+			after, err := ParseTSL(b)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(Diff(before, after).String()).To(Equal(expected))
+		},
+		Entry("no changes", "a = 1 and b = 2", "a = 1 and b = 2", ""),
+		Entry("reordered operands", "a = 1 and b = 2", "b = 2 and 1 = a", ""),
+		Entry("changed value", "pages > 100 and title = 'Book'", "author = 'Joe' and title = 'Book' and pages > 200",
+			"changed `pages > 100` → `pages > 200`; added `author = 'Joe'`"),
+		Entry("removed", "a = 1 and b = 2", "a = 1", "removed `b = 2`"),
+		Entry("changed operator", "pages > 100", "pages >= 100", "changed operator `>` → `>=` in `pages > 100`"),
+		Entry("changed top level operator", "a = 1 and b = 2", "a = 1 or b = 2",
+			"changed operator `AND` → `OR` in `a = 1 AND b = 2`"),
+		Entry("nested change", "a = 1 and (b = 1 or c = 1)", "a = 1 and (b = 1 or c = 2 or d = 1)",
+			"changed `c = 1` → `c = 2` within `b = 1 OR c = 2 OR d = 1`; added `d = 1` within `b = 1 OR c = 2 OR d = 1`"),
+		Entry("nested operator change", "a = 1 and (b = 1 or c = 1 or d = 1)", "a = 1 and (b = 1 or c = 1 and d = 1)",
+			"removed `c = 1` within `b = 1 OR c = 1 AND d = 1`; removed `d = 1` within `b = 1 OR c = 1 AND d = 1`; "+
+				"added `c = 1 AND d = 1` within `b = 1 OR c = 1 AND d = 1`"),
+		Entry("flattened groups", "a = 1 and (b = 1 or c = 1)", "a = 1 and (b = 1 and c = 1)",
+			"removed `b = 1 OR c = 1`; added `b = 1`; added `c = 1`"),
+		Entry("negation", "a in [1, 2]", "a not in [1, 2]", "changed `a IN [1, 2]` → `a NOT IN [1, 2]`"),
+		Entry("in list", "a in [1, 2]", "a in [2, 3]", "changed `a IN [1, 2]` → `a IN [2, 3]`"),
+		Entry("unrelated", "a = 1", "b = 1", "removed `a = 1`; added `b = 1`"),
+		Entry("replaced group", "a = 1 or b = 1", "c = 1 or d = 1", "removed `a = 1 OR b = 1`; added `c = 1 OR d = 1`"),
+	)
+
+	It("accepts nil trees", func() {
+		tree, err := ParseTSL("a = 1 and b = 2")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(Diff(nil, tree).String()).To(Equal("added `a = 1`; added `b = 2`"))
+		Expect(Diff(tree, nil).String()).To(Equal("removed `a = 1`; removed `b = 2`"))
+		Expect(Diff(nil, nil)).To(BeEmpty())
+	})
+
+	It("marshals to JSON", func() {
+		before, err := ParseTSL("pages > 100 and (b = 1 or c = 1)")
+		Expect(err).NotTo(HaveOccurred())
+		after, err := ParseTSL("pages >= 100 and (b = 1 or c = 1) and author = 'Joe'")
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := json.Marshal(Diff(before, after))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`[
+			{"kind": "operator", "before": "pages > 100", "after": "pages >= 100", "from": ">", "to": ">="},
+			{"kind": "added", "after": "author = 'Joe'"}
+		]`))
+	})
+})
@@ -85,3 +85,34 @@ type KeyNotFoundError struct {
 func (e KeyNotFoundError) Error() string {
 	return fmt.Sprintf("key not found: %s", e.Key)
 }
+
+// UnsupportedError is returned by walkers when a TSL expression has no
+// equivalent in their target query language
+type UnsupportedError struct {
+	Target     string
+	Expression string
+	Reason     string
+}
+
+func (e UnsupportedError) Error() string {
+	return fmt.Sprintf("%s can not express %s: %s", e.Target, e.Expression, e.Reason)
+}
+
+// LikePatternError is returned when a LIKE pattern ends with the escape character
+type LikePatternError struct {
+	Pattern string
+}
+
+func (e LikePatternError) Error() string {
+	return fmt.Sprintf("LIKE pattern must not end with the escape character: %q", e.Pattern)
+}
+
+// LikeEscapeError is returned when the ESCAPE of a LIKE pattern is not a
+// single character
+type LikeEscapeError struct {
+	Escape string
+}
+
+func (e LikeEscapeError) Error() string {
+	return fmt.Sprintf("LIKE escape must be a single character: %q", e.Escape)
+}
@@ -0,0 +1,246 @@
+package tsl
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operator precedence levels, matching the grammar (lowest to highest)
+const (
+	precOr = iota + 1
+	precAnd
+	precCompare
+	precAdditive
+	precMultiplicative
+	precPrefix
+	precUnary
+	precPrimary
+)
+
+// symbol returns the TSL source form of an operator
+func (op Operator) symbol() string {
+	switch op {
+	case OpEQ:
+		return "="
+	case OpNE:
+		return "!="
+	case OpLT:
+		return "<"
+	case OpLE:
+		return "<="
+	case OpGT:
+		return ">"
+	case OpGE:
+		return ">="
+	case OpREQ:
+		return "~="
+	case OpRNE:
+		return "~!"
+	case OpPlus:
+		return "+"
+	case OpMinus:
+		return "-"
+	case OpStar:
+		return "*"
+	case OpSlash:
+		return "/"
+	case OpPercent:
+		return "%"
+	case OpUMinus:
+		return "-"
+	default:
+		return op.String()
+	}
+}
+
+// precedence returns the binding strength of an operator when printed
+func (op Operator) precedence() int {
+	switch op {
+	case OpOr:
+		return precOr
+	case OpAnd:
+		return precAnd
+	case OpPlus, OpMinus:
+		return precAdditive
+	case OpStar, OpSlash, OpPercent:
+		return precMultiplicative
+	case OpUMinus:
+		return precUnary
+	case OpNot, OpLen, OpAny, OpAll, OpSum:
+		return precPrefix
+	default:
+		return precCompare
+	}
+}
+
+// precedence returns the binding strength of a node when printed
+func precedence(n *TSLNode) int {
+	op, ok := n.AsExprOp()
+	if !ok {
+		return precPrimary
+	}
+	if op.Operator == OpNot && negatedForm(op.Right) {
+		return precCompare
+	}
+	return op.Operator.precedence()
+}
+
+// negatedForm reports whether NOT over n prints as an infix negation
+// (e.g. `a NOT LIKE b`, `a IS NOT NULL`)
+func negatedForm(n *TSLNode) bool {
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != KindBinaryExpr {
+		return false
+	}
+	switch op.Operator {
+	case OpLike, OpILike, OpIn, OpBetween, OpIs, OpMatch:
+		return true
+	}
+	return false
+}
+
+// String returns the TSL phrase of the tree.
+//
+// The returned phrase parses back into an equivalent tree, parentheses
+// are only added where the operator precedence requires them.
+func (n *TSLNode) String() string {
+	var b strings.Builder
+	writeNode(&b, n)
+	return b.String()
+}
+
+// writeOperand writes n, wrapped in parentheses if it binds weaker than min
+func writeOperand(b *strings.Builder, n *TSLNode, min int) {
+	if precedence(n) < min {
+		b.WriteString("(")
+		writeNode(b, n)
+		b.WriteString(")")
+		return
+	}
+	writeNode(b, n)
+}
+
+func writeNode(b *strings.Builder, n *TSLNode) {
+	switch n.Type() {
+	case KindIdentifier:
+		b.WriteString(n.Value().(string))
+	case KindStringLiteral:
+		b.WriteString(quoteString(n.Value().(string)))
+	case KindNumericLiteral:
+		b.WriteString(strconv.FormatFloat(n.Value().(float64), 'g', -1, 64))
+	case KindBooleanLiteral:
+		if n.Value().(bool) {
+			b.WriteString("TRUE")
+		} else {
+			b.WriteString("FALSE")
+		}
+	case KindNullLiteral:
+		b.WriteString("NULL")
+	case KindDateLiteral:
+		b.WriteString(n.Value().(string))
+	case KindTimestampLiteral:
+		if t, ok := n.Value().(time.Time); ok {
+			b.WriteString(t.Format(time.RFC3339Nano))
+		} else {
+			b.WriteString(quoteString(n.Value().(string)))
+		}
+	case KindArrayLiteral:
+		b.WriteString("[")
+		for i, v := range n.Value().(TSLArrayLiteral).Values {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeNode(b, v)
+		}
+		b.WriteString("]")
+	case KindUnaryExpr:
+		writeUnary(b, n.Value().(TSLExpressionOp))
+	case KindBinaryExpr:
+		writeBinary(b, n.Value().(TSLExpressionOp), "")
+	}
+}
+
+func writeUnary(b *strings.Builder, op TSLExpressionOp) {
+	switch op.Operator {
+	case OpUMinus:
+		b.WriteString("-")
+		writeOperand(b, op.Right, precUnary)
+	case OpNot:
+		if negatedForm(op.Right) {
+			writeBinary(b, op.Right.Value().(TSLExpressionOp), "NOT")
+			return
+		}
+		b.WriteString("NOT ")
+		writeOperand(b, op.Right, precPrefix)
+	default:
+		b.WriteString(op.Operator.String())
+		b.WriteString(" ")
+		writeOperand(b, op.Right, precPrefix)
+	}
+}
+
+// writeBinary writes a binary expression, not is added to the keyword of
+// operators that have an infix negated form
+func writeBinary(b *strings.Builder, op TSLExpressionOp, not string) {
+	prec := op.Operator.precedence()
+
+	writeOperand(b, op.Left, prec)
+
+	switch op.Operator {
+	case OpIs:
+		if not != "" {
+			b.WriteString(" IS NOT NULL")
+		} else {
+			b.WriteString(" IS NULL")
+		}
+		return
+	case OpBetween:
+		if not != "" {
+			b.WriteString(" NOT")
+		}
+		b.WriteString(" BETWEEN ")
+		if arr, ok := op.Right.AsArray(); ok && len(arr.Values) == 2 {
+			writeOperand(b, arr.Values[0], precAdditive)
+			b.WriteString(" AND ")
+			writeOperand(b, arr.Values[1], precAdditive)
+			return
+		}
+		writeOperand(b, op.Right, prec+1)
+		return
+	}
+
+	if not != "" {
+		b.WriteString(" NOT")
+	}
+	b.WriteString(" ")
+	b.WriteString(op.Operator.symbol())
+	b.WriteString(" ")
+
+	// All binary operators are left associative
+	writeOperand(b, op.Right, prec+1)
+}
+
+// quoteString returns a single quoted TSL string literal
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteString("'")
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteString("'")
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package tsl
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TSL Node String", func() {
+	DescribeTable("formats the tree as a TSL phrase",
+		func(input string, expected string) {
+			node, err := ParseTSL(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(node.String()).To(Equal(expected))
+
+			// The formatted phrase must parse back into the same tree
+			again, err := ParseTSL(node.String())
+			Expect(err).NotTo(HaveOccurred())
+
+			againJSON, err := json.Marshal(again)
+			Expect(err).NotTo(HaveOccurred())
+			nodeJSON, err := json.Marshal(node)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(againJSON).To(MatchJSON(nodeJSON))
+		},
+		Entry("comparison", "name = 'joe'", "name = 'joe'"),
+		Entry("keywords", "a is not null and b not like 'x%' or c not in [1, 2]",
+			"a IS NOT NULL AND b NOT LIKE 'x%' OR c NOT IN [1, 2]"),
+		Entry("precedence", "(a = 1 or b = 2) and c = 3", "(a = 1 OR b = 2) AND c = 3"),
+		Entry("redundant parentheses", "((a = 1) and (b = 2))", "a = 1 AND b = 2"),
+		Entry("right associativity", "a - (b - c) = 1", "a - (b - c) = 1"),
+		Entry("left associativity", "(a - b) - c = 1", "a - b - c = 1"),
+		Entry("arithmetic", "(a + b) * 2 > -c", "(a + b) * 2 > -c"),
+		Entry("between", "a not between 1 + 1 and 2 * 3", "a NOT BETWEEN 1 + 1 AND 2 * 3"),
+		Entry("not", "not (a = 1 and b)", "NOT (a = 1 AND b)"),
+		Entry("array functions", "len(tags) > 2 and any (tags like 'a%')", "LEN tags > 2 AND ANY (tags LIKE 'a%')"),
+		Entry("string escapes", `name = 'it\'s a \\ test'`, `name = 'it\'s a \\ test'`),
+		Entry("literals", "a in [1.5, true, 2020-01-01, 2020-01-01T10:00:00Z]",
+			"a IN [1.5, TRUE, 2020-01-01, 2020-01-01T10:00:00Z]"),
+		Entry("regex", "name ~= '^a' and name ~! 'b$'", "name ~= '^a' AND name ~! 'b$'"),
+		Entry("match", "title @@ 'tree' and not (body match 'search')", "title MATCH 'tree' AND body NOT MATCH 'search'"),
+	)
+})
@@ -0,0 +1,145 @@
+package tsl
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/parser"
+)
+
+// LikeEscape is the escape character of LIKE and ILIKE patterns. An escaped
+// '%', '_' or escape character matches itself, patterns using a custom
+// ESCAPE clause are rewritten by the parser to use this character.
+const LikeEscape = parser.LikeEscape
+
+// EscapeLike escapes the wildcards and escape characters of s, so the
+// returned LIKE pattern only matches the string s.
+func EscapeLike(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if c == '%' || c == '_' || c == LikeEscape {
+			b.WriteRune(LikeEscape)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// LikeToRegexp converts a LIKE pattern into an anchored regular expression.
+// '%' matches any sequence of characters, '_' matches one character and
+// LikeEscape makes the next character match itself.
+func LikeToRegexp(pattern string) (string, error) {
+	var b strings.Builder
+	runes := []rune(pattern)
+
+	b.WriteString("^")
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '%':
+			b.WriteString("(?s:.*)")
+		case '_':
+			b.WriteString("(?s:.)")
+		case LikeEscape:
+			if i+1 == len(runes) {
+				return "", LikePatternError{Pattern: pattern}
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	return b.String(), nil
+}
+
+// AnchoredRegexp converts a TSL regular expression, that matches anywhere
+// in a value, into a pattern for engines where regular expressions must
+// match the whole value, such as Lucene and PromQL. Unanchored ends are
+// padded with ".*" and the '^' and '$' anchors are dropped, alternatives
+// are padded one by one and grouped.
+//
+//	tsl.AnchoredRegexp("^api|web$")
+//	// (api.*|.*web)
+//
+// Anchors inside nested groups are kept as is.
+func AnchoredRegexp(pattern string) string {
+	var branches []string
+	var branch strings.Builder
+	anchoredStart, anchoredEnd := false, false
+	depth, inClass, escaped := 0, false, false
+
+	// pad adds the alternative read so far to the branches
+	pad := func() {
+		b := branch.String()
+		if anchoredEnd {
+			b = b[:len(b)-1]
+		} else {
+			b += ".*"
+		}
+		if !anchoredStart {
+			b = ".*" + b
+		}
+		branches = append(branches, b)
+		branch.Reset()
+		anchoredStart, anchoredEnd = false, false
+	}
+
+	for _, c := range pattern {
+		literal := escaped || inClass
+
+		switch {
+		case c == '^' && branch.Len() == 0 && !anchoredStart:
+			anchoredStart = true
+			continue
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '|' && depth == 0:
+			pad()
+			continue
+		}
+
+		branch.WriteRune(c)
+		anchoredEnd = c == '$' && !literal && depth == 0
+	}
+	pad()
+
+	if len(branches) == 1 {
+		return branches[0]
+	}
+	return "(" + strings.Join(branches, "|") + ")"
+}
+
+// ConvertLikeEscape rewrites a LIKE pattern written with the escape
+// character escape into a pattern using LikeEscape, an empty escape means
+// the pattern has no escapes. It returns LikeEscapeError if escape is
+// longer than one character, and LikePatternError if the pattern ends with
+// the escape character.
+func ConvertLikeEscape(pattern, escape string) (string, error) {
+	runes := []rune(escape)
+	if len(runes) > 1 {
+		return "", LikeEscapeError{Escape: escape}
+	}
+
+	var escapeChar rune = -1
+	if len(runes) == 1 {
+		escapeChar = runes[0]
+	}
+
+	rewritten, ok := parser.RewriteLikePattern(pattern, escapeChar)
+	if !ok {
+		return "", LikePatternError{Pattern: pattern}
+	}
+	return rewritten, nil
+}
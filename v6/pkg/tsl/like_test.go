@@ -0,0 +1,97 @@
+package tsl
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LIKE patterns", func() {
+	DescribeTable("Matches like SQL",
+		func(pattern, value string, expected bool) {
+			expr, err := LikeToRegexp(pattern)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(regexp.MustCompile(expr).MatchString(value)).To(Equal(expected))
+		},
+		Entry("percent", "a%", "abc", true),
+		Entry("underscore", "a_c", "abc", true),
+		Entry("percent matches newline", "a%c", "a\nc", true),
+		Entry("regexp characters", "a.c", "abc", false),
+		Entry("escaped percent", `a\%`, "abc", false),
+		Entry("escaped percent match", `a\%`, "a%", true),
+		Entry("escaped underscore", `a\_c`, "abc", false),
+		Entry("escaped underscore match", `a\_c`, "a_c", true),
+		Entry("escaped backslash", `a\\c`, `a\c`, true),
+		Entry("escaped letter", `a\bc`, "abc", true),
+	)
+
+	It("Fails on a trailing escape", func() {
+		_, err := LikeToRegexp(`a\`)
+		Expect(err).To(Equal(LikePatternError{Pattern: `a\`}))
+	})
+
+	DescribeTable("Escapes strings",
+		func(s, expected string) {
+			Expect(EscapeLike(s)).To(Equal(expected))
+
+			expr, err := LikeToRegexp(expected)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(regexp.MustCompile(expr).MatchString(s)).To(BeTrue())
+		},
+		Entry("plain", "abc", "abc"),
+		Entry("wildcards", "100%_", `100\%\_`),
+		Entry("backslash", `a\b`, `a\\b`),
+	)
+})
+
+var _ = Describe("AnchoredRegexp", func() {
+	values := []string{"", "api", "api-v1", "my-api", "web", "webapp", "my-web", "a", "b", "ab", "ba", "cab", "a$", `a\`, "x|y"}
+
+	DescribeTable("Matches the values the regular expression finds anywhere",
+		func(pattern, expected string) {
+			anchored := AnchoredRegexp(pattern)
+			Expect(anchored).To(Equal(expected))
+
+			re := regexp.MustCompile(pattern)
+			whole := regexp.MustCompile("^(?:" + anchored + ")$")
+			for _, value := range values {
+				Expect(whole.MatchString(value)).To(Equal(re.MatchString(value)), value)
+			}
+		},
+		Entry("unanchored", "ab", ".*ab.*"),
+		Entry("start anchor", "^ab", "ab.*"),
+		Entry("end anchor", "ab$", ".*ab"),
+		Entry("both anchors", "^ab$", "ab"),
+		Entry("alternation", "a|b", "(.*a.*|.*b.*)"),
+		Entry("anchored alternatives", "^api|web$", "(api.*|.*web)"),
+		Entry("empty alternative", "a|", "(.*a.*|.*.*)"),
+		Entry("nested alternation", "c(a|b)", ".*c(a|b).*"),
+		Entry("escaped dollar", `a\$`, `.*a\$.*`),
+		Entry("escaped backslash before dollar", `a\\$`, `.*a\\`),
+		Entry("escaped bar", `x\|y`, `.*x\|y.*`),
+		Entry("bar in a class", "[|]", ".*[|].*"),
+		Entry("dollar in a class", "a[$]", ".*a[$].*"),
+	)
+})
+
+var _ = Describe("ConvertLikeEscape", func() {
+	DescribeTable("Rewrites patterns to use LikeEscape",
+		func(pattern, escape, expected string) {
+			converted, err := ConvertLikeEscape(pattern, escape)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(converted).To(Equal(expected))
+		},
+		Entry("custom escape", "100!%", "!", `100\%`),
+		Entry("no escape", `a\b`, "", `a\\b`),
+	)
+
+	DescribeTable("Returns typed errors",
+		func(pattern, escape string, expected error) {
+			_, err := ConvertLikeEscape(pattern, escape)
+			Expect(err).To(Equal(expected))
+		},
+		Entry("long escape", "a", "!!", LikeEscapeError{Escape: "!!"}),
+		Entry("trailing escape", "a!", "!", LikePatternError{Pattern: "a!"}),
+	)
+})
@@ -0,0 +1,57 @@
+package tsl
+
+import "time"
+
+// FieldName returns the name of an identifier node
+func FieldName(n *TSLNode) (string, bool) {
+	if n.Type() != KindIdentifier {
+		return "", false
+	}
+	return n.Value().(string), true
+}
+
+// LiteralValue returns the value of a literal node: a float64, a string, a
+// bool, or a time.Time for dates and timestamps, a date is midnight UTC.
+// A unary minus over a number literal is folded into the number. It returns
+// false for other nodes.
+func LiteralValue(n *TSLNode) (interface{}, bool) {
+	switch n.Type() {
+	case KindNumericLiteral, KindStringLiteral, KindBooleanLiteral:
+		return n.Value(), true
+	case KindDateLiteral:
+		if t, err := time.Parse("2006-01-02", n.Value().(string)); err == nil {
+			return t, true
+		}
+	case KindTimestampLiteral:
+		if t, ok := n.Value().(time.Time); ok {
+			return t, true
+		}
+	case KindUnaryExpr:
+		op := n.Value().(TSLExpressionOp)
+		if op.Operator == OpUMinus && op.Right.Type() == KindNumericLiteral {
+			v, _ := op.Right.AsFloat64()
+			return -v, true
+		}
+	}
+	return nil, false
+}
+
+// LiteralArrayValues returns the values of an array of literals, see
+// LiteralValue. It returns false if the node is not an array, or if one of
+// its values is not a literal.
+func LiteralArrayValues(n *TSLNode) ([]interface{}, bool) {
+	arr, ok := n.AsArray()
+	if !ok {
+		return nil, false
+	}
+
+	values := []interface{}{}
+	for _, item := range arr.Values {
+		v, ok := LiteralValue(item)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, v)
+	}
+	return values, true
+}
@@ -0,0 +1,62 @@
+package tsl
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Literal values", func() {
+	DescribeTable("returns the value of literals",
+		func(input string, expected interface{}) {
+			tree, err := ParseTSL("a = " + input)
+			Expect(err).NotTo(HaveOccurred())
+
+			value, ok := LiteralValue(tree.Value().(TSLExpressionOp).Right)
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal(expected))
+		},
+		Entry("number", "1.5", 1.5),
+		Entry("negative number", "-2", -2.0),
+		Entry("string", "'x'", "x"),
+		Entry("boolean", "true", true),
+		Entry("date", "2020-01-02", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)),
+		Entry("timestamp", "2020-01-02T10:00:00Z", time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC)),
+	)
+
+	DescribeTable("rejects other nodes",
+		func(input string) {
+			tree, err := ParseTSL("a = " + input)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, ok := LiteralValue(tree.Value().(TSLExpressionOp).Right)
+			Expect(ok).To(BeFalse())
+		},
+		Entry("identifier", "b"),
+		Entry("negative identifier", "-b"),
+		Entry("expression", "1 + 2"),
+	)
+
+	It("returns the values of literal arrays", func() {
+		tree, err := ParseTSL("a in [1, 'x', -3]")
+		Expect(err).NotTo(HaveOccurred())
+		values, ok := LiteralArrayValues(tree.Value().(TSLExpressionOp).Right)
+		Expect(ok).To(BeTrue())
+		Expect(values).To(Equal([]interface{}{1.0, "x", -3.0}))
+
+		tree, err = ParseTSL("a in [1, b]")
+		Expect(err).NotTo(HaveOccurred())
+		_, ok = LiteralArrayValues(tree.Value().(TSLExpressionOp).Right)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("returns field names of identifiers", func() {
+		name, ok := FieldName(NewIdentifier("spec.pages"))
+		Expect(ok).To(BeTrue())
+		Expect(name).To(Equal("spec.pages"))
+
+		_, ok = FieldName(NewStringLiteral("spec.pages"))
+		Expect(ok).To(BeFalse())
+	})
+})
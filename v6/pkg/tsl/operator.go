@@ -48,6 +48,9 @@ const (
 
 	// Unary minus
 	OpUMinus
+
+	// Full-text search operator
+	OpMatch
 )
 
 // String returns the string representation of an OperatorType
@@ -84,6 +87,8 @@ func (op Operator) String() string {
 		return "LIKE"
 	case OpILike:
 		return "ILIKE"
+	case OpMatch:
+		return "MATCH"
 
 	// Array Operators
 	case OpIn:
@@ -0,0 +1,172 @@
+package tsl
+
+// splitter splits trees into the parts a backend can evaluate and the
+// parts left for the caller
+type splitter struct {
+	canPush func(ident string, op Operator) bool
+}
+
+// Split splits a tree into a pushed tree that only uses identifiers and
+// operators accepted by canPush, and a residual tree, such that
+// "pushed AND residual" matches the same records as the input tree. A
+// backend evaluates the pushed tree, and the caller evaluates the residual
+// tree on the returned records, for example using the semantics walker.
+//
+//	tree, _ := tsl.ParseTSL("price < 10 AND (name = 'joe' OR score > 5)")
+//	pushed, residual := tsl.Split(tree, func(ident string, op tsl.Operator) bool {
+//		return ident != "score"
+//	})
+//	// pushed:   price < 10
+//	// residual: name = 'joe' OR score > 5
+//
+// canPush is called for each identifier of a comparison with the operators
+// between the identifier and the comparison, in "a + b > 1" it is called
+// with (a, OpPlus), (a, OpGT), (b, OpPlus) and (b, OpGT). A boolean
+// identifier is called with OpEQ, as in "done = true". AND, OR and NOT are
+// always pushed.
+//
+// AND operands that can not be pushed move to the residual tree. An OR
+// that mixes operands that can and can not be pushed is kept in the
+// residual tree, and a weaker OR of the pushed parts of all its operands is
+// pushed when every operand has such a part:
+//
+//	(a = 1 AND c = 1) OR (a = 2 AND c = 2)
+//	// pushed:   a = 1 OR a = 2
+//	// residual: (a = 1 AND c = 1) OR (a = 2 AND c = 2)
+//
+// pushed is nil if no part of the tree can be pushed, and residual is nil
+// if the whole tree is pushed. The input tree is not modified.
+func Split(n *TSLNode, canPush func(ident string, op Operator) bool) (pushed, residual *TSLNode) {
+	if n == nil || n.node == nil {
+		return nil, nil
+	}
+
+	s := splitter{canPush: canPush}
+	for _, conjunct := range s.conjuncts(n, false) {
+		if s.pushable(conjunct) {
+			pushed = and(pushed, conjunct)
+			continue
+		}
+
+		pushed = and(pushed, s.relax(conjunct, false))
+		residual = and(residual, conjunct)
+	}
+
+	// Keep the tree as is if nothing is pushed
+	if pushed == nil {
+		return nil, n
+	}
+	return pushed, residual
+}
+
+// and joins two trees by AND, nil trees are skipped
+func and(left, right *TSLNode) *TSLNode {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	return NewBinaryExpr(OpAnd, left, right)
+}
+
+// negate returns NOT over a tree if negated is true
+func negate(n *TSLNode, negated bool) *TSLNode {
+	if !negated {
+		return n
+	}
+	return NewUnaryExpr(OpNot, n)
+}
+
+// conjuncts returns the operands of a chain of AND expressions, or of the
+// negation of a chain of OR expressions
+func (s splitter) conjuncts(n *TSLNode, negated bool) []*TSLNode {
+	op, ok := n.AsExprOp()
+	if !ok {
+		return []*TSLNode{negate(n, negated)}
+	}
+
+	switch {
+	case op.Operator == OpNot:
+		return s.conjuncts(op.Right, !negated)
+	case op.Operator == OpAnd && !negated, op.Operator == OpOr && negated:
+		return append(s.conjuncts(op.Left, negated), s.conjuncts(op.Right, negated)...)
+	}
+	return []*TSLNode{negate(n, negated)}
+}
+
+// relax returns a tree that only uses pushed identifiers and operators,
+// and that matches every record the tree, or its negation, matches. It
+// returns nil when no such tree is found.
+func (s splitter) relax(n *TSLNode, negated bool) *TSLNode {
+	if s.pushable(n) {
+		return negate(n, negated)
+	}
+
+	op, ok := n.AsExprOp()
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case op.Operator == OpNot:
+		return s.relax(op.Right, !negated)
+
+	case op.Operator == OpAnd && !negated, op.Operator == OpOr && negated:
+		// Any pushed part of an operand is implied by the conjunction
+		return and(s.relax(op.Left, negated), s.relax(op.Right, negated))
+
+	case op.Operator == OpOr && !negated, op.Operator == OpAnd && negated:
+		// Each operand must have a pushed part, or any record may match
+		left, right := s.relax(op.Left, negated), s.relax(op.Right, negated)
+		if left == nil || right == nil {
+			return nil
+		}
+		return NewBinaryExpr(OpOr, left, right)
+	}
+
+	return nil
+}
+
+// pushable returns true if canPush accepts all the identifiers of a tree
+// with their operators
+func (s splitter) pushable(n *TSLNode) bool {
+	op, ok := n.AsExprOp()
+	if ok && (op.Operator == OpAnd || op.Operator == OpOr || op.Operator == OpNot) {
+		for _, child := range n.Children() {
+			if !s.pushable(child) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if n.Type() == KindIdentifier {
+		return s.canPush(n.Value().(string), OpEQ)
+	}
+	return s.pushableOperand(n, nil)
+}
+
+// pushableOperand returns true if canPush accepts all the identifiers of an
+// operand of a comparison, with the operators on their path from the
+// comparison
+func (s splitter) pushableOperand(n *TSLNode, path []Operator) bool {
+	if n.Type() == KindIdentifier {
+		for _, op := range path {
+			if !s.canPush(n.Value().(string), op) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if op, ok := n.AsExprOp(); ok {
+		path = append(path[:len(path):len(path)], op.Operator)
+	}
+	for _, child := range n.Children() {
+		if !s.pushableOperand(child, path) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package tsl
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Split", func() {
+	// canPush accepts comparisons of database fields, fields starting with
+	// "x" are computed by the caller, and the database has no LIKE
+	canPush := func(ident string, op Operator) bool {
+		return ident[0] != 'x' && op != OpLike
+	}
+
+	DescribeTable("splits the tree",
+		func(input, pushed, residual string) {
+			tree, err := ParseTSL(input)
+			Expect(err).NotTo(HaveOccurred())
+
+			p, r := Split(tree, canPush)
+			Expect(p.String()).To(Equal(pushed))
+			Expect(r.String()).To(Equal(residual))
+		},
+		Entry("all pushed", "a = 1 and b > 2", "a = 1 AND b > 2", ""),
+		Entry("nothing pushed", "x = 1", "", "x = 1"),
+		Entry("and operands", "a = 1 and x = 2 and b < 3", "a = 1 AND b < 3", "x = 2"),
+		Entry("unsupported operator", "a = 1 and b like 'x%'", "a = 1", "b LIKE 'x%'"),
+		Entry("arithmetic", "a + b > 1 and a + x > 1", "a + b > 1", "a + x > 1"),
+		Entry("pushed or", "(a = 1 or b = 2) and x = 3", "a = 1 OR b = 2", "x = 3"),
+		Entry("mixed or", "a = 1 and (b = 2 or x = 3)", "a = 1", "b = 2 OR x = 3"),
+		Entry("relaxed or", "(a = 1 and x = 1) or (a = 2 and x = 2)",
+			"a = 1 OR a = 2", "a = 1 AND x = 1 OR a = 2 AND x = 2"),
+		Entry("negated or", "not (a = 1 or x = 2)", "NOT (a = 1)", "NOT (x = 2)"),
+		Entry("negated and", "not (a = 1 and x = 2) and b = 3", "b = 3", "NOT (a = 1 AND x = 2)"),
+		Entry("relaxed negated and", "not ((a = 1 or x = 1) and (a = 2 or x = 2))",
+			"NOT (a = 1) OR NOT (a = 2)", "NOT ((a = 1 OR x = 1) AND (a = 2 OR x = 2))"),
+		Entry("boolean identifiers", "done and xdone", "done", "xdone"),
+		Entry("any", "any (tags = 'a') and any (xtags = 'b')", "ANY (tags = 'a')", "ANY (xtags = 'b')"),
+		Entry("constants", "true and x = 1", "TRUE", "x = 1"),
+	)
+
+	It("returns the input tree when nothing is pushed", func() {
+		tree, err := ParseTSL("x = 1 or a = 2")
+		Expect(err).NotTo(HaveOccurred())
+
+		pushed, residual := Split(tree, canPush)
+		Expect(pushed).To(BeNil())
+		Expect(residual).To(BeIdenticalTo(tree))
+	})
+
+	It("calls canPush with the operators of each identifier", func() {
+		tree, err := ParseTSL("-a + 1 > b")
+		Expect(err).NotTo(HaveOccurred())
+
+		var calls []string
+		Split(tree, func(ident string, op Operator) bool {
+			calls = append(calls, ident+" "+op.String())
+			return true
+		})
+		Expect(calls).To(Equal([]string{"a GT", "a ADD", "a NEG", "b GT"}))
+	})
+
+	It("accepts nil trees", func() {
+		pushed, residual := Split(nil, canPush)
+		Expect(pushed).To(BeNil())
+		Expect(residual).To(BeNil())
+	})
+})
@@ -0,0 +1,214 @@
+package tsl
+
+// Visitor holds the hooks Walk calls for each node of a tree, both hooks
+// are optional.
+type Visitor struct {
+	// Pre is called before the children of a node are visited, returning
+	// false skips the children of the node
+	Pre func(n *TSLNode) (bool, error)
+
+	// Post is called after the children of a node are visited, or skipped
+	Post func(n *TSLNode) error
+}
+
+// Children returns the child nodes of a node: the left and right operands
+// of a binary expression, the operand of a unary expression or the values
+// of an array literal. Literals and identifiers have no children.
+func (n *TSLNode) Children() []*TSLNode {
+	if n == nil || n.node == nil {
+		return nil
+	}
+
+	var children []*TSLNode
+	switch n.node.Kind {
+	case KindBinaryExpr:
+		for _, child := range []*Node{n.node.Left, n.node.Right} {
+			if child != nil {
+				children = append(children, &TSLNode{node: child})
+			}
+		}
+	case KindUnaryExpr:
+		if n.node.Right != nil {
+			children = append(children, &TSLNode{node: n.node.Right})
+		}
+	case KindArrayLiteral:
+		for _, child := range n.node.Children {
+			children = append(children, &TSLNode{node: child})
+		}
+	}
+	return children
+}
+
+// Flatten returns the operands of a chain of binary expressions with the
+// same operator, for example the AND operands of "a and (b and c)" are a,
+// b and c. A node that is not such an expression is a single operand, and
+// a nil tree has no operands.
+func Flatten(n *TSLNode, operator Operator) []*TSLNode {
+	if n == nil || n.node == nil {
+		return nil
+	}
+	if n.node.Kind == KindBinaryExpr && n.node.Operator == operator {
+		op := n.Value().(TSLExpressionOp)
+		return append(Flatten(op.Left, operator), Flatten(op.Right, operator)...)
+	}
+	return []*TSLNode{n}
+}
+
+// WalkChain calls walk on each operand of a chain of binary expressions
+// with the same operator, see Flatten, and returns the results in order.
+// It stops at the first error returned by walk.
+//
+// Example:
+//
+//	// Walk the operands of "a = 1 and (b = 2 and c = 3)" in one list.
+//	filters, err := tsl.WalkChain(tree, tsl.OpAnd, Walk)
+func WalkChain[T any](n *TSLNode, operator Operator, walk func(n *TSLNode) (T, error)) ([]T, error) {
+	operands := Flatten(n, operator)
+	results := make([]T, len(operands))
+	for i, operand := range operands {
+		result, err := walk(operand)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// Walk visits the nodes of a tree in depth first order, calling the Pre
+// hook of a node before visiting its children and the Post hook after.
+// The walk stops at the first error returned by a hook.
+//
+// Example:
+//
+//	// Collect the identifiers, skipping the operands of ANY and ALL.
+//	var identifiers []string
+//	err := tsl.Walk(tree, tsl.Visitor{
+//		Pre: func(n *tsl.TSLNode) (bool, error) {
+//			if op, ok := n.AsExprOp(); ok && (op.Operator == tsl.OpAny || op.Operator == tsl.OpAll) {
+//				return false, nil
+//			}
+//			if n.Type() == tsl.KindIdentifier {
+//				identifiers = append(identifiers, n.Value().(string))
+//			}
+//			return true, nil
+//		},
+//	})
+func Walk(n *TSLNode, v Visitor) error {
+	if n == nil || n.node == nil {
+		return nil
+	}
+
+	visitChildren := true
+	if v.Pre != nil {
+		var err error
+		if visitChildren, err = v.Pre(n); err != nil {
+			return err
+		}
+	}
+
+	if visitChildren {
+		for _, child := range n.Children() {
+			if err := Walk(child, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v.Post != nil {
+		return v.Post(n)
+	}
+	return nil
+}
+
+// Rewrite transforms a tree bottom up, calling fn on each node after its
+// children were rewritten, and returns the new tree. fn returns the node
+// that replaces n, returning n itself, or nil, keeps the node.
+//
+// The input tree is not modified: a node with rewritten children is
+// copied before fn is called, and subtrees that did not change are shared
+// between the input and the new tree.
+//
+// Example:
+//
+//	// Replace "a != b" with "NOT a = b".
+//	newTree, err := tsl.Rewrite(tree, func(n *tsl.TSLNode) (*tsl.TSLNode, error) {
+//		if op, ok := n.AsExprOp(); ok && op.Operator == tsl.OpNE {
+//			return tsl.NewUnaryExpr(tsl.OpNot, tsl.NewBinaryExpr(tsl.OpEQ, op.Left, op.Right)), nil
+//		}
+//		return n, nil
+//	})
+func Rewrite(n *TSLNode, fn func(n *TSLNode) (*TSLNode, error)) (*TSLNode, error) {
+	if n == nil || n.node == nil {
+		return n, nil
+	}
+
+	node, err := rewriteChildren(n.node, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	current := n
+	if node != n.node {
+		current = &TSLNode{node: node}
+	}
+
+	replacement, err := fn(current)
+	if err != nil {
+		return nil, err
+	}
+	if replacement == nil || replacement.node == nil {
+		return current, nil
+	}
+	return replacement, nil
+}
+
+// rewriteChildren rewrites the children of a node, and returns a copy of
+// the node if any child changed
+func rewriteChildren(node *Node, fn func(n *TSLNode) (*TSLNode, error)) (*Node, error) {
+	rewrite := func(child *Node) (*Node, error) {
+		if child == nil {
+			return nil, nil
+		}
+		rewritten, err := Rewrite(&TSLNode{node: child}, fn)
+		if err != nil {
+			return nil, err
+		}
+		return rewritten.node, nil
+	}
+
+	left, err := rewrite(node.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := rewrite(node.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := left != node.Left || right != node.Right
+	var children []*Node
+	if node.Children != nil {
+		children = make([]*Node, len(node.Children))
+		for i, child := range node.Children {
+			if children[i], err = rewrite(child); err != nil {
+				return nil, err
+			}
+			changed = changed || children[i] != child
+		}
+	}
+
+	if !changed {
+		return node, nil
+	}
+
+	return &Node{
+		Kind:     node.Kind,
+		Value:    node.Value,
+		Operator: node.Operator,
+		Position: node.Position,
+		Left:     left,
+		Right:    right,
+		Children: children,
+	}, nil
+}
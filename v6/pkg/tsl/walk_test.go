@@ -0,0 +1,213 @@
+package tsl
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Walk", func() {
+	It("calls the hooks in depth first order", func() {
+		tree, err := ParseTSL("a = 1 and not (b in [2, c])")
+		Expect(err).NotTo(HaveOccurred())
+
+		var events []string
+		err = Walk(tree, Visitor{
+			Pre: func(n *TSLNode) (bool, error) {
+				events = append(events, "pre "+n.String())
+				return true, nil
+			},
+			Post: func(n *TSLNode) error {
+				events = append(events, "post "+n.String())
+				return nil
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(Equal([]string{
+			"pre a = 1 AND b NOT IN [2, c]",
+			"pre a = 1",
+			"pre a", "post a",
+			"pre 1", "post 1",
+			"post a = 1",
+			"pre b NOT IN [2, c]",
+			"pre b IN [2, c]",
+			"pre b", "post b",
+			"pre [2, c]",
+			"pre 2", "post 2",
+			"pre c", "post c",
+			"post [2, c]",
+			"post b IN [2, c]",
+			"post b NOT IN [2, c]",
+			"post a = 1 AND b NOT IN [2, c]",
+		}))
+	})
+
+	It("skips children when Pre returns false", func() {
+		tree, err := ParseTSL("a = 1 or any (tags = 'x') or b in [c]")
+		Expect(err).NotTo(HaveOccurred())
+
+		var identifiers []string
+		var posts int
+		err = Walk(tree, Visitor{
+			Pre: func(n *TSLNode) (bool, error) {
+				if op, ok := n.AsExprOp(); ok && op.Operator == OpAny {
+					return false, nil
+				}
+				if n.Type() == KindIdentifier {
+					identifiers = append(identifiers, n.Value().(string))
+				}
+				return true, nil
+			},
+			Post: func(n *TSLNode) error {
+				posts++
+				return nil
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(identifiers).To(Equal([]string{"a", "b", "c"}))
+		Expect(posts).To(Equal(10))
+	})
+
+	It("stops at the first error", func() {
+		tree, err := ParseTSL("a = 1 and b = 2")
+		Expect(err).NotTo(HaveOccurred())
+
+		stop := errors.New("stop")
+		var visited []string
+		err = Walk(tree, Visitor{
+			Post: func(n *TSLNode) error {
+				visited = append(visited, n.String())
+				if n.Type() == KindIdentifier {
+					return stop
+				}
+				return nil
+			},
+		})
+		Expect(err).To(Equal(stop))
+		Expect(visited).To(Equal([]string{"a"}))
+	})
+
+	It("accepts nil trees", func() {
+		Expect(Walk(nil, Visitor{})).To(Succeed())
+	})
+})
+
+var _ = Describe("Flatten", func() {
+	DescribeTable("returns the operands of a chain",
+		func(input string, operator Operator, expected []string) {
+			tree, err := ParseTSL(input)
+			Expect(err).NotTo(HaveOccurred())
+
+			operands := []string{}
+			for _, n := range Flatten(tree, operator) {
+				operands = append(operands, n.String())
+			}
+			Expect(operands).To(Equal(expected))
+		},
+		Entry("nested chain", "a = 1 and (b = 2 and c = 3)", OpAnd, []string{"a = 1", "b = 2", "c = 3"}),
+		Entry("other operator", "a = 1 or b = 2 and c = 3", OpAnd, []string{"a = 1 OR b = 2 AND c = 3"}),
+		Entry("inner chain", "a = 1 or b = 2 and c = 3", OpOr, []string{"a = 1", "b = 2 AND c = 3"}),
+		Entry("single operand", "not (a = 1 and b = 2)", OpAnd, []string{"NOT (a = 1 AND b = 2)"}),
+	)
+
+	It("accepts nil trees", func() {
+		Expect(Flatten(nil, OpAnd)).To(BeEmpty())
+	})
+
+	It("walks the operands of a chain", func() {
+		tree, err := ParseTSL("a = 1 or (b = 2 or c = 3)")
+		Expect(err).NotTo(HaveOccurred())
+
+		phrases, err := WalkChain(tree, OpOr, func(n *TSLNode) (string, error) {
+			return n.String(), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phrases).To(Equal([]string{"a = 1", "b = 2", "c = 3"}))
+
+		_, err = WalkChain(tree, OpOr, func(n *TSLNode) (string, error) {
+			return "", errors.New("failed")
+		})
+		Expect(err).To(MatchError("failed"))
+	})
+})
+
+var _ = Describe("Rewrite", func() {
+	upper := func(n *TSLNode) (*TSLNode, error) {
+		if n.Type() == KindIdentifier {
+			return NewIdentifier(strings.ToUpper(n.Value().(string))), nil
+		}
+		return n, nil
+	}
+
+	It("rewrites the tree bottom up", func() {
+		tree, err := ParseTSL("a != 1 and not (b != c)")
+		Expect(err).NotTo(HaveOccurred())
+
+		var order []string
+		rewritten, err := Rewrite(tree, func(n *TSLNode) (*TSLNode, error) {
+			order = append(order, n.String())
+			if op, ok := n.AsExprOp(); ok && op.Operator == OpNE {
+				return NewUnaryExpr(OpNot, NewBinaryExpr(OpEQ, op.Left, op.Right)), nil
+			}
+			return n, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewritten.String()).To(Equal("NOT (a = 1) AND NOT NOT (b = c)"))
+		Expect(order[0]).To(Equal("a"))
+		Expect(order[len(order)-1]).To(Equal("NOT (a = 1) AND NOT NOT (b = c)"))
+	})
+
+	It("does not modify the input tree", func() {
+		tree, err := ParseTSL("a in [b, 1] and len c > 2")
+		Expect(err).NotTo(HaveOccurred())
+
+		rewritten, err := Rewrite(tree, upper)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewritten.String()).To(Equal("A IN [B, 1] AND LEN C > 2"))
+		Expect(tree.String()).To(Equal("a IN [b, 1] AND LEN c > 2"))
+	})
+
+	It("shares unchanged subtrees", func() {
+		tree, err := ParseTSL("a = 1 or 'x' = 'y'")
+		Expect(err).NotTo(HaveOccurred())
+
+		rewritten, err := Rewrite(tree, upper)
+		Expect(err).NotTo(HaveOccurred())
+
+		op := tree.Value().(TSLExpressionOp)
+		newOp := rewritten.Value().(TSLExpressionOp)
+		Expect(newOp.Left.node).NotTo(BeIdenticalTo(op.Left.node))
+		Expect(newOp.Right.node).To(BeIdenticalTo(op.Right.node))
+		Expect(newOp.Left.Value().(TSLExpressionOp).Right.node).To(BeIdenticalTo(op.Left.Value().(TSLExpressionOp).Right.node))
+	})
+
+	It("returns the input tree when nothing changes", func() {
+		tree, err := ParseTSL("a = 1")
+		Expect(err).NotTo(HaveOccurred())
+
+		rewritten, err := Rewrite(tree, func(n *TSLNode) (*TSLNode, error) { return nil, nil })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewritten.node).To(BeIdenticalTo(tree.node))
+	})
+
+	It("keeps positions of copied nodes", func() {
+		tree, err := ParseTSL("x = 1 and y = 2")
+		Expect(err).NotTo(HaveOccurred())
+
+		rewritten, err := Rewrite(tree, upper)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewritten.Value().(TSLExpressionOp).Right.Position()).To(Equal(tree.Value().(TSLExpressionOp).Right.Position()))
+	})
+
+	It("returns the error of fn", func() {
+		tree, err := ParseTSL("a = 1")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = Rewrite(tree, func(n *TSLNode) (*TSLNode, error) {
+			return nil, KeyNotFoundError{Key: "a"}
+		})
+		Expect(err).To(Equal(KeyNotFoundError{Key: "a"}))
+	})
+})
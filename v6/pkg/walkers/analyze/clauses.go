@@ -0,0 +1,309 @@
+package analyze
+
+import (
+	"math"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/normalize"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+)
+
+// literal is an expression that is not AND, OR or NOT, or its negation
+type literal struct {
+	node    *tsl.TSLNode
+	negated bool
+}
+
+// disjunction returns the clauses of the disjunctive normal form of a
+// tree, or of its negation, each clause is a list of literals
+func disjunction(n *tsl.TSLNode, negated bool, limit int) ([][]literal, error) {
+	op, ok := n.AsExprOp()
+	if !ok || (op.Operator != tsl.OpAnd && op.Operator != tsl.OpOr && op.Operator != tsl.OpNot) {
+		return [][]literal{{{node: n, negated: negated}}}, nil
+	}
+	if op.Operator == tsl.OpNot {
+		return disjunction(op.Right, !negated, limit)
+	}
+
+	left, err := disjunction(op.Left, negated, limit)
+	if err != nil {
+		return nil, err
+	}
+	right, err := disjunction(op.Right, negated, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// A negated AND is an OR of the negated operands, De Morgan's laws
+	if (op.Operator == tsl.OpOr) != negated {
+		if len(left)+len(right) > limit {
+			return nil, normalize.TooLargeError{Form: "DNF", Limit: limit}
+		}
+		return append(left, right...), nil
+	}
+
+	if len(left)*len(right) > limit {
+		return nil, normalize.TooLargeError{Form: "DNF", Limit: limit}
+	}
+	product := make([][]literal, 0, len(left)*len(right))
+	for _, l := range left {
+		for _, r := range right {
+			product = append(product, append(append([]literal{}, l...), r...))
+		}
+	}
+	return product, nil
+}
+
+// analyzer checks the clauses of a normal form, the domain of a field is
+// the values of the types of the literals it is compared with in any
+// clause
+type analyzer struct {
+	atoms   map[*tsl.TSLNode]atom
+	domains map[string]values
+}
+
+// newAnalyzer describes the literals of the clauses of a normal form
+func newAnalyzer(clauses [][]literal) analyzer {
+	a := analyzer{atoms: map[*tsl.TSLNode]atom{}, domains: map[string]values{}}
+	for _, clause := range clauses {
+		for _, l := range clause {
+			if _, ok := a.atoms[l.node]; ok {
+				continue
+			}
+			described := atomOf(l.node)
+			a.atoms[l.node] = described
+			if described.field != "" {
+				a.domains[described.field] = a.domains[described.field].union(described.domain)
+			}
+		}
+	}
+	return a
+}
+
+// universe returns the values a field may hold, null or a value of its
+// domain, fields with no known domain may hold any value
+func (a analyzer) universe(field string) values {
+	domain := a.domains[field]
+	if domain.empty() {
+		return anyValue()
+	}
+	domain.null = true
+	return domain
+}
+
+// satisfiable returns false if the literals of a clause can not all be
+// true for the same record
+func (a analyzer) satisfiable(clause []literal) bool {
+	fields := map[string]values{}
+	opaque := map[string]bool{}
+
+	for _, l := range clause {
+		described := a.atoms[l.node]
+		switch {
+		case described.constant:
+			if described.value == l.negated {
+				return false
+			}
+
+		case described.field != "":
+			set := described.values
+			if l.negated {
+				set = set.complement()
+			}
+			current, ok := fields[described.field]
+			if !ok {
+				current = a.universe(described.field)
+			}
+			current = current.intersect(set)
+			if current.empty() {
+				return false
+			}
+			fields[described.field] = current
+
+		default:
+			if negated, ok := opaque[described.key]; ok && negated != l.negated {
+				return false
+			}
+			opaque[described.key] = l.negated
+		}
+	}
+	return true
+}
+
+// atom describes the values that make an expression true, constant
+// expressions have a known value, expressions on one field have a set of
+// values, and other expressions are only known by their canonical phrase
+type atom struct {
+	constant bool
+	value    bool
+
+	field  string
+	values values
+	domain values
+
+	key string
+}
+
+// atomOf describes an expression that is not AND, OR or NOT
+func atomOf(n *tsl.TSLNode) atom {
+	if !hasIdentifier(n) {
+		if result, err := semantics.Walk(n, nil); err == nil {
+			if value, ok := result.(bool); ok {
+				return atom{constant: true, value: value}
+			}
+		}
+	}
+
+	if a, ok := fieldValues(n); ok {
+		return a
+	}
+
+	return atom{key: tsl.Canonicalize(n).String()}
+}
+
+// hasIdentifier returns true if a tree includes an identifier
+func hasIdentifier(n *tsl.TSLNode) bool {
+	found := false
+	_ = tsl.Walk(n, tsl.Visitor{
+		Pre: func(n *tsl.TSLNode) (bool, error) {
+			found = found || n.Type() == tsl.KindIdentifier
+			return !found, nil
+		},
+	})
+	return found
+}
+
+// fieldValues describes an expression that compares one field with
+// literals, by the values of the field that make it true, and the values
+// of the types of the literals
+func fieldValues(n *tsl.TSLNode) (atom, bool) {
+	if n.Type() == tsl.KindIdentifier {
+		// A boolean field is true only when it holds true
+		return typed(n.Value().(string), values{true: true}), true
+	}
+
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != tsl.KindBinaryExpr {
+		return atom{}, false
+	}
+
+	left, right, operator := op.Left, op.Right, op.Operator
+	if flipped, ok := tsl.FlippedComparisons[operator]; ok && left.Type() != tsl.KindIdentifier {
+		left, right, operator = right, left, flipped
+	}
+	if left.Type() != tsl.KindIdentifier {
+		return atom{}, false
+	}
+	field := left.Value().(string)
+
+	switch operator {
+	case tsl.OpEQ, tsl.OpNE:
+		set, ok := equalValues(right)
+		if !ok {
+			return atom{}, false
+		}
+		a := typed(field, set)
+		if operator == tsl.OpNE {
+			a.values = set.complement()
+		}
+		return a, true
+
+	case tsl.OpLT, tsl.OpLE, tsl.OpGT, tsl.OpGE:
+		number, ok := numberOf(right)
+		if !ok {
+			return atom{}, false
+		}
+		i := interval{lo: math.Inf(-1), loOpen: true, hi: number, hiOpen: operator == tsl.OpLT}
+		if operator == tsl.OpGT || operator == tsl.OpGE {
+			i = interval{lo: number, loOpen: operator == tsl.OpGT, hi: math.Inf(1), hiOpen: true}
+		}
+		return typed(field, numbers(i)), true
+
+	case tsl.OpBetween:
+		arr, ok := right.AsArray()
+		if !ok || len(arr.Values) != 2 {
+			return atom{}, false
+		}
+		lo, okLo := numberOf(arr.Values[0])
+		hi, okHi := numberOf(arr.Values[1])
+		if !okLo || !okHi {
+			return atom{}, false
+		}
+		return typed(field, numbers(interval{lo: lo, hi: hi})), true
+
+	case tsl.OpIn:
+		arr, ok := right.AsArray()
+		if !ok {
+			return atom{}, false
+		}
+		var set values
+		for _, item := range arr.Values {
+			itemSet, ok := equalValues(item)
+			if !ok {
+				return atom{}, false
+			}
+			set = set.union(itemSet)
+		}
+		return typed(field, set), true
+
+	case tsl.OpIs:
+		if right.Type() == tsl.KindNullLiteral {
+			// Null has no type
+			return atom{field: field, values: values{null: true}}, true
+		}
+	}
+
+	return atom{}, false
+}
+
+// typed describes an expression on a field by the values that make it true,
+// its domain includes all the values of the types of these values
+func typed(field string, set values) atom {
+	domain := values{true: set.true || set.false, false: set.true || set.false}
+	if len(set.numbers) > 0 {
+		domain.numbers = allNumbers
+	}
+	if !set.strings.empty() {
+		domain.strings = stringSet{except: true}
+	}
+	return atom{field: field, values: set, domain: domain}
+}
+
+// equalValues returns the values that are equal to a literal, strings that
+// look like dates are also equal to dates and are not supported
+func equalValues(n *tsl.TSLNode) (values, bool) {
+	if number, ok := numberOf(n); ok {
+		return numbers(interval{lo: number, hi: number}), true
+	}
+	if value, ok := n.AsBool(); ok && n.Type() == tsl.KindBooleanLiteral {
+		return values{true: value, false: !value}, true
+	}
+	if value, ok := n.AsString(); ok && n.Type() == tsl.KindStringLiteral && !isDate(value) {
+		return values{strings: stringSet{values: map[string]bool{value: true}}}, true
+	}
+	return values{}, false
+}
+
+// numberOf returns the value of a number literal, or of a negated number
+// literal
+func numberOf(n *tsl.TSLNode) (float64, bool) {
+	if n.Type() == tsl.KindNumericLiteral {
+		return n.AsFloat64()
+	}
+	if op, ok := n.AsExprOp(); ok && op.Operator == tsl.OpUMinus && op.Right.Type() == tsl.KindNumericLiteral {
+		number, ok := op.Right.AsFloat64()
+		return -number, ok
+	}
+	return 0, false
+}
+
+// isDate returns true for strings that the semantics walker compares as
+// dates
+func isDate(value string) bool {
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return true
+	}
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
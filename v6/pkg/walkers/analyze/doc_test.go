@@ -0,0 +1,43 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the analyze package.
+func Example() {
+	// Parse a filter that can never match.
+	tree, _ := tsl.ParseTSL("age > 30 and age < 20")
+
+	unsatisfiable, _ := Unsatisfiable(tree)
+	fmt.Println(unsatisfiable)
+
+	// Check if the results of a narrow filter are included in the results
+	// of a broader cached filter.
+	narrow, _ := tsl.ParseTSL("age between 21 and 25 and status = 'a'")
+	broad, _ := tsl.ParseTSL("age > 20 and status in ['a', 'b']")
+
+	implies, _ := Implies(narrow, broad)
+	fmt.Println(implies)
+
+	// Output:
+	// true
+	// true
+}
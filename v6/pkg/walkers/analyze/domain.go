@@ -0,0 +1,176 @@
+package analyze
+
+import (
+	"math"
+	"sort"
+)
+
+// interval is a range of numbers, open ends exclude their bound
+type interval struct {
+	lo, hi         float64
+	loOpen, hiOpen bool
+}
+
+// empty returns true if no number is in the interval
+func (i interval) empty() bool {
+	return i.lo > i.hi || (i.lo == i.hi && (i.loOpen || i.hiOpen))
+}
+
+// intervals is a sorted list of disjoint intervals
+type intervals []interval
+
+// allNumbers is the interval of all numbers
+var allNumbers = intervals{{lo: math.Inf(-1), hi: math.Inf(1), loOpen: true, hiOpen: true}}
+
+// intersect returns the numbers in both lists
+func (a intervals) intersect(b intervals) intervals {
+	var result intervals
+	for _, x := range a {
+		for _, y := range b {
+			i := interval{lo: x.lo, loOpen: x.loOpen, hi: x.hi, hiOpen: x.hiOpen}
+			if y.lo > i.lo || (y.lo == i.lo && y.loOpen) {
+				i.lo, i.loOpen = y.lo, y.loOpen
+			}
+			if y.hi < i.hi || (y.hi == i.hi && y.hiOpen) {
+				i.hi, i.hiOpen = y.hi, y.hiOpen
+			}
+			if !i.empty() {
+				result = append(result, i)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].lo < result[j].lo })
+	return result
+}
+
+// complement returns the numbers that are not in the list
+func (a intervals) complement() intervals {
+	var result intervals
+	lo, loOpen := math.Inf(-1), true
+	for _, i := range a {
+		gap := interval{lo: lo, loOpen: loOpen, hi: i.lo, hiOpen: !i.loOpen}
+		if !gap.empty() {
+			result = append(result, gap)
+		}
+		lo, loOpen = i.hi, !i.hiOpen
+	}
+	gap := interval{lo: lo, loOpen: loOpen, hi: math.Inf(1), hiOpen: true}
+	if !gap.empty() {
+		result = append(result, gap)
+	}
+	return result
+}
+
+// stringSet is a finite set of strings, or the set of all strings except a
+// finite set
+type stringSet struct {
+	except bool
+	values map[string]bool
+}
+
+// intersect returns the strings in both sets
+func (a stringSet) intersect(b stringSet) stringSet {
+	result := stringSet{except: a.except && b.except, values: map[string]bool{}}
+	switch {
+	case a.except && b.except:
+		for v := range a.values {
+			result.values[v] = true
+		}
+		for v := range b.values {
+			result.values[v] = true
+		}
+	case a.except:
+		for v := range b.values {
+			if !a.values[v] {
+				result.values[v] = true
+			}
+		}
+	case b.except:
+		for v := range a.values {
+			if !b.values[v] {
+				result.values[v] = true
+			}
+		}
+	default:
+		for v := range a.values {
+			if b.values[v] {
+				result.values[v] = true
+			}
+		}
+	}
+	return result
+}
+
+// complement returns the strings that are not in the set
+func (a stringSet) complement() stringSet {
+	return stringSet{except: !a.except, values: a.values}
+}
+
+// empty returns true if no string is in the set
+func (a stringSet) empty() bool {
+	return !a.except && len(a.values) == 0
+}
+
+// values is a set of field values: null, numbers, strings, booleans and
+// values of other types, such as dates
+type values struct {
+	null        bool
+	numbers     intervals
+	strings     stringSet
+	true, false bool
+	other       bool
+}
+
+// anyValue is the set of all values
+func anyValue() values {
+	return values{
+		null:    true,
+		numbers: allNumbers,
+		strings: stringSet{except: true},
+		true:    true,
+		false:   true,
+		other:   true,
+	}
+}
+
+// intersect returns the values in both sets
+func (a values) intersect(b values) values {
+	return values{
+		null:    a.null && b.null,
+		numbers: a.numbers.intersect(b.numbers),
+		strings: a.strings.intersect(b.strings),
+		true:    a.true && b.true,
+		false:   a.false && b.false,
+		other:   a.other && b.other,
+	}
+}
+
+// complement returns the values that are not in the set
+func (a values) complement() values {
+	return values{
+		null:    !a.null,
+		numbers: a.numbers.complement(),
+		strings: a.strings.complement(),
+		true:    !a.true,
+		false:   !a.false,
+		other:   !a.other,
+	}
+}
+
+// empty returns true if the set holds no value
+func (a values) empty() bool {
+	return !a.null && len(a.numbers) == 0 && a.strings.empty() && !a.true && !a.false && !a.other
+}
+
+// union returns the values in either set
+func (a values) union(b values) values {
+	return a.complement().intersect(b.complement()).complement()
+}
+
+// numbers returns the set of the numbers in an interval
+func numbers(i interval) values {
+	if i.empty() {
+		return values{}
+	}
+	return values{numbers: intervals{i}}
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package analyze proves facts about TSL filters, such as a filter that
+// can never match, using interval and set reasoning on each identifier.
+package analyze
+
+import (
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/normalize"
+)
+
+// Unsatisfiable returns true if no record can match a filter.
+//
+//	tree, _ := tsl.ParseTSL("age > 30 and age < 20")
+//	analyze.Unsatisfiable(tree)
+//	// true
+//
+// The analysis is conservative, false means the filter was not proven to
+// be unsatisfiable. The filter is converted into disjunctive normal form,
+// and each clause is checked for conflicting literals: literals comparing
+// one identifier with number, string or boolean literals are reduced to
+// the set of values they accept, using intervals for numbers and sets for
+// strings and booleans, other expressions are only compared with their own
+// negation. Literals with no identifiers are evaluated.
+//
+// Values follow the semantics walker: a missing identifier is null,
+// comparisons with null are false, and != is true. The analysis assumes
+// that identifiers are null or hold values of the types of the literals
+// they are compared with, and that evaluating the filter does not fail.
+//
+// If the normal form has more than normalize.DefaultMaxClauses clauses,
+// Unsatisfiable returns a normalize.TooLargeError.
+func Unsatisfiable(n *tsl.TSLNode) (bool, error) {
+	if n == nil {
+		return false, nil
+	}
+	return unsatisfiable(n, false)
+}
+
+// Tautology returns true if every record matches a filter.
+//
+//	tree, _ := tsl.ParseTSL("status = 'a' or status != 'a'")
+//	analyze.Tautology(tree)
+//	// true
+//
+// A filter is a tautology if its negation is unsatisfiable, the analysis
+// works as in Unsatisfiable.
+func Tautology(n *tsl.TSLNode) (bool, error) {
+	if n == nil {
+		return false, nil
+	}
+	return unsatisfiable(n, true)
+}
+
+// Implies returns true if every record that matches filter a also matches
+// filter b, the results of a are a subset of the results of b.
+//
+//	a, _ := tsl.ParseTSL("age > 30 and status = 'a'")
+//	b, _ := tsl.ParseTSL("age > 20")
+//	analyze.Implies(a, b)
+//	// true
+//
+// A cache holding the results of b can serve a by filtering them with a.
+// a implies b if "a AND NOT b" is unsatisfiable, the analysis works as in
+// Unsatisfiable.
+func Implies(a, b *tsl.TSLNode) (bool, error) {
+	if a == nil || b == nil {
+		return false, nil
+	}
+	return unsatisfiable(tsl.NewBinaryExpr(tsl.OpAnd, a, tsl.NewUnaryExpr(tsl.OpNot, b)), false)
+}
+
+// unsatisfiable returns true if no clause of the disjunctive normal form
+// of a tree, or of its negation, is satisfiable
+func unsatisfiable(n *tsl.TSLNode, negated bool) (bool, error) {
+	clauses, err := disjunction(n, negated, normalize.DefaultMaxClauses)
+	if err != nil {
+		return false, err
+	}
+	a := newAnalyzer(clauses)
+	for _, clause := range clauses {
+		if a.satisfiable(clause) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
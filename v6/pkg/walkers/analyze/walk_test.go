@@ -0,0 +1,204 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/internal/testgen"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/normalize"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+)
+
+func TestAnalyzeWalker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Analyze walker")
+}
+
+// parse parses a TSL phrase, failing the test on errors
+func parse(input string) *tsl.TSLNode {
+	tree, err := tsl.ParseTSL(input)
+	Expect(err).ToNot(HaveOccurred(), input)
+	return tree
+}
+
+var _ = Describe("Unsatisfiable", func() {
+	DescribeTable("Analyzes filters",
+		func(input string, expected bool) {
+			actual, err := Unsatisfiable(parse(input))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("empty range", "age > 30 and age < 20", true),
+		Entry("open bounds", "age > 30 and age < 30", true),
+		Entry("touching bounds", "age >= 30 and age <= 30", false),
+		Entry("open and closed bounds", "age >= 30 and age < 30", true),
+		Entry("flipped literal", "30 < age and age < 20", true),
+		Entry("negative numbers", "age < -5 and age > -6", false),
+		Entry("two strings", "status = 'a' and status = 'b'", true),
+		Entry("string and excluded string", "status = 'a' and status != 'a'", true),
+		Entry("string and other excluded string", "status = 'a' and status != 'b'", false),
+		Entry("in list and string", "status in ['a', 'b'] and status = 'c'", true),
+		Entry("in lists", "status in ['a', 'b'] and status in ['b', 'c']", false),
+		Entry("disjoint in lists", "status in ['a', 'b'] and status not in ['a', 'b']", true),
+		Entry("empty in list", "status in []", true),
+		Entry("between", "age between 10 and 20 and age > 20", true),
+		Entry("reversed between", "age between 20 and 10", true),
+		Entry("number and string", "age = 1 and age = 'a'", true),
+		Entry("null and comparison", "age is null and age > 1", true),
+		Entry("null and not equal", "age is null and age != 1", false),
+		Entry("not null and null", "age is not null and age is null", true),
+		Entry("booleans", "done = true and done = false", true),
+		Entry("boolean identifier", "done and not done", true),
+		Entry("boolean identifier and false", "done and done = false", true),
+		Entry("excluded booleans", "done != true and done != false", false),
+		Entry("negated comparison keeps null", "not (age < 5) and age is null", false),
+		Entry("negated comparison", "not (age < 5) and age = 1", true),
+		Entry("expression and its negation", "name like 'a%' and name not like 'a%'", true),
+		Entry("canonical expressions", "a + b > 1 and not (1 < b + a)", true),
+		Entry("different expressions", "name like 'a%' and name not like 'b%'", false),
+		Entry("false literal", "age = 1 and false", true),
+		Entry("constant expression", "age = 1 and 1 > 2", true),
+		Entry("true literal", "true", false),
+		Entry("one satisfiable clause", "(age > 30 and age < 20) or status = 'a'", false),
+		Entry("all clauses unsatisfiable", "(age > 30 or age < 10) and age between 15 and 20", true),
+		Entry("date strings", "day = '2020-01-01' and day = '2020-01-02'", false),
+		Entry("different identifiers", "a > 30 and b < 20", false),
+	)
+
+	It("Fails on large normal forms", func() {
+		var clauses []string
+		for i := 0; i < 11; i++ {
+			clauses = append(clauses, fmt.Sprintf("(a%d = 1 or b%d = 1)", i, i))
+		}
+
+		_, err := Unsatisfiable(parse(strings.Join(clauses, " and ")))
+		Expect(errors.As(err, &normalize.TooLargeError{})).To(BeTrue())
+	})
+})
+
+var _ = Describe("Tautology", func() {
+	DescribeTable("Analyzes filters",
+		func(input string, expected bool) {
+			actual, err := Tautology(parse(input))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("equal or not equal", "status = 'a' or status != 'a'", true),
+		Entry("ranges miss null", "age > 5 or age <= 5", false),
+		Entry("ranges and null", "age > 5 or age <= 5 or age is null", true),
+		Entry("null or not null", "age is null or age is not null", true),
+		Entry("expression or its negation", "name like 'a%' or not (name like 'a%')", true),
+		Entry("true literal", "true", true),
+		Entry("single comparison", "age > 5", false),
+		Entry("not in", "status not in ['a'] or status = 'a'", true),
+	)
+})
+
+var _ = Describe("Implies", func() {
+	DescribeTable("Analyzes filters",
+		func(a, b string, expected bool) {
+			actual, err := Implies(parse(a), parse(b))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("narrower range", "age > 30", "age > 20", true),
+		Entry("broader range", "age > 20", "age > 30", false),
+		Entry("extra conjunct", "age > 30 and status = 'a'", "age > 20", true),
+		Entry("missing conjunct", "age > 30", "age > 20 and status = 'a'", false),
+		Entry("value in list", "status = 'a'", "status in ['a', 'b']", true),
+		Entry("list in list", "status in ['a']", "status in ['a', 'b']", true),
+		Entry("list not in list", "status in ['a', 'c']", "status in ['a', 'b']", false),
+		Entry("equal and not equal", "status = 'a'", "status != 'b'", true),
+		Entry("between", "age between 21 and 25", "age > 20 and age < 30", true),
+		Entry("disjunction", "age = 1 or age = 2", "age < 3", true),
+		Entry("same expression", "name like 'a%'", "name like 'a%' or age = 1", true),
+		Entry("unsatisfiable filter", "age > 3 and age < 1", "status = 'a'", true),
+		Entry("not equal and null", "age != 1", "age is not null", false),
+	)
+
+	It("Returns false on nil trees", func() {
+		actual, err := Implies(nil, parse("a = 1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(BeFalse())
+	})
+})
+
+// result is the evaluation of a filter on a record
+type result int
+
+const (
+	failed result = iota
+	matched
+	missed
+)
+
+// evaluate returns the results of a filter on the records
+func evaluate(tree *tsl.TSLNode, all []map[string]interface{}) []result {
+	results := make([]result, len(all))
+	for i, record := range all {
+		value, err := semantics.Walk(tree, testgen.Eval(record))
+		switch {
+		case err != nil:
+			results[i] = failed
+		case value == true:
+			results[i] = matched
+		default:
+			results[i] = missed
+		}
+	}
+	return results
+}
+
+var _ = Describe("Analyze properties", func() {
+	It("Agrees with the semantics walker", func() {
+		g := testgen.NewGenerator(42)
+		all := testgen.Records()
+		proven := map[string]int{}
+
+		for i := 0; i < 500; i++ {
+			a, b := parse(g.Boolean(3)), parse(g.Boolean(3))
+			resultsA, resultsB := evaluate(a, all), evaluate(b, all)
+
+			// Records where evaluating a filter fails are not analyzed
+			if unsatisfiable, _ := Unsatisfiable(a); unsatisfiable {
+				proven["unsatisfiable"]++
+				Expect(resultsA).ToNot(ContainElement(matched), a.String())
+			}
+			if tautology, _ := Tautology(a); tautology {
+				proven["tautology"]++
+				Expect(resultsA).ToNot(ContainElement(missed), a.String())
+			}
+			if implies, _ := Implies(a, b); implies {
+				proven["implies"]++
+				for j := range all {
+					Expect(resultsA[j] == matched && resultsB[j] == missed).To(BeFalse(), "%s => %s on %v", a, b, all[j])
+				}
+			}
+		}
+
+		Expect(proven["unsatisfiable"]).To(BeNumerically(">", 10))
+		Expect(proven["tautology"]).To(BeNumerically(">", 10))
+		Expect(proven["implies"]).To(BeNumerically(">", 10))
+	})
+})
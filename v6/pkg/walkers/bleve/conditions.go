@@ -0,0 +1,245 @@
+package bleve
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// fieldQuery creates a query for a predicate comparing a field with literals
+func fieldQuery(n *tsl.TSLNode) (query.Query, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+	unsupported := func(reason string) error {
+		return tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: reason}
+	}
+
+	field, isField := tsl.FieldName(op.Left)
+	operator := op.Operator
+
+	// Literal on the left, identifier on the right
+	if !isField {
+		flipped, canFlip := tsl.FlippedComparisons[operator]
+		if !canFlip {
+			return nil, unsupported("expected a field on the left side")
+		}
+		if field, isField = tsl.FieldName(op.Right); !isField {
+			return nil, unsupported("expected a comparison between a field and a literal")
+		}
+		op.Left, op.Right, operator = op.Right, op.Left, flipped
+	}
+
+	switch operator {
+	case tsl.OpEQ, tsl.OpNE:
+		v, isLiteral := tsl.LiteralValue(op.Right)
+		if !isLiteral {
+			return nil, unsupported("fields can only be compared with literals")
+		}
+		q := equalQuery(field, v)
+		if operator == tsl.OpNE {
+			return notQuery(q), nil
+		}
+		return q, nil
+
+	case tsl.OpLT, tsl.OpLE, tsl.OpGT, tsl.OpGE:
+		v, isLiteral := tsl.LiteralValue(op.Right)
+		if !isLiteral {
+			return nil, unsupported("fields can only be compared with literals")
+		}
+		inclusive := operator == tsl.OpLE || operator == tsl.OpGE
+		if operator == tsl.OpLT || operator == tsl.OpLE {
+			q, ok := rangeQuery(field, nil, v, true, inclusive)
+			if !ok {
+				return nil, unsupported("boolean values can not be ordered")
+			}
+			return q, nil
+		}
+		q, ok := rangeQuery(field, v, nil, inclusive, true)
+		if !ok {
+			return nil, unsupported("boolean values can not be ordered")
+		}
+		return q, nil
+
+	case tsl.OpIn:
+		values, isLiteral := tsl.LiteralArrayValues(op.Right)
+		if !isLiteral {
+			return nil, unsupported("IN requires a list of literals")
+		}
+		if len(values) == 0 {
+			return query.NewMatchNoneQuery(), nil
+		}
+		queries := make([]query.Query, len(values))
+		for i, v := range values {
+			queries[i] = equalQuery(field, v)
+		}
+		return query.NewDisjunctionQuery(queries), nil
+
+	case tsl.OpBetween:
+		values, isLiteral := tsl.LiteralArrayValues(op.Right)
+		if !isLiteral || len(values) != 2 {
+			return nil, unsupported("BETWEEN requires two literals")
+		}
+		q, ok := rangeQuery(field, values[0], values[1], true, true)
+		if !ok {
+			return nil, unsupported("BETWEEN requires two literals of the same type")
+		}
+		return q, nil
+
+	case tsl.OpLike:
+		pattern, isString := op.Right.AsString()
+		if !isString || op.Right.Type() != tsl.KindStringLiteral {
+			return nil, unsupported("LIKE requires a string pattern")
+		}
+		return likeQuery(field, pattern)
+
+	case tsl.OpREQ, tsl.OpRNE:
+		pattern, isString := op.Right.AsString()
+		if !isString || op.Right.Type() != tsl.KindStringLiteral {
+			return nil, unsupported("regular expressions must be strings")
+		}
+		q := query.NewRegexpQuery(tsl.AnchoredRegexp(pattern))
+		q.SetField(field)
+		if operator == tsl.OpRNE {
+			return notQuery(q), nil
+		}
+		return q, nil
+
+	case tsl.OpMatch:
+		text, isString := op.Right.AsString()
+		if !isString || op.Right.Type() != tsl.KindStringLiteral {
+			return nil, unsupported("MATCH requires a string query")
+		}
+		q := query.NewMatchQuery(text)
+		q.SetField(field)
+		q.SetOperator(query.MatchQueryOperatorAnd)
+		return q, nil
+
+	case tsl.OpILike:
+		return nil, unsupported("wildcard queries are case sensitive, use MATCH or LIKE")
+
+	case tsl.OpIs:
+		return nil, unsupported("queries on missing fields are not supported")
+	}
+
+	return nil, unsupported("operator " + operator.String() + " is not supported")
+}
+
+// equalQuery creates a query matching a field equal to a literal value
+func equalQuery(field string, v interface{}) query.Query {
+	switch value := v.(type) {
+	case bool:
+		q := query.NewBoolFieldQuery(value)
+		q.SetField(field)
+		return q
+	case string:
+		q := query.NewTermQuery(value)
+		q.SetField(field)
+		return q
+	}
+
+	q, _ := rangeQuery(field, v, v, true, true)
+	return q
+}
+
+// rangeQuery creates a numeric, date or term range query, a nil bound is
+// open. It returns false if the bounds are booleans or of different types.
+func rangeQuery(field string, min, max interface{}, minInclusive, maxInclusive bool) (query.Query, bool) {
+	switch firstBound(min, max).(type) {
+	case float64:
+		minValue, okMin := min.(float64)
+		maxValue, okMax := max.(float64)
+		if (min != nil && !okMin) || (max != nil && !okMax) {
+			return nil, false
+		}
+		var minPtr, maxPtr *float64
+		if okMin {
+			minPtr = &minValue
+		}
+		if okMax {
+			maxPtr = &maxValue
+		}
+		q := query.NewNumericRangeInclusiveQuery(minPtr, maxPtr, &minInclusive, &maxInclusive)
+		q.SetField(field)
+		return q, true
+
+	case time.Time:
+		// A zero time is an open bound
+		minValue, okMin := min.(time.Time)
+		maxValue, okMax := max.(time.Time)
+		if (min != nil && !okMin) || (max != nil && !okMax) {
+			return nil, false
+		}
+		q := query.NewDateRangeInclusiveQuery(minValue, maxValue, &minInclusive, &maxInclusive)
+		q.SetField(field)
+		return q, true
+
+	case string:
+		// An empty term is an open bound
+		minValue, okMin := min.(string)
+		maxValue, okMax := max.(string)
+		if (min != nil && !okMin) || (max != nil && !okMax) {
+			return nil, false
+		}
+		q := query.NewTermRangeInclusiveQuery(minValue, maxValue, &minInclusive, &maxInclusive)
+		q.SetField(field)
+		return q, true
+	}
+
+	return nil, false
+}
+
+// firstBound returns the first bound that is not nil
+func firstBound(min, max interface{}) interface{} {
+	if min != nil {
+		return min
+	}
+	return max
+}
+
+// likeQuery converts a LIKE pattern into a wildcard query, '%' becomes '*'
+// and '_' becomes '?'. Wildcard queries have no escape character, so
+// patterns matching a literal '*' or '?' use a regexp query.
+func likeQuery(field, pattern string) (query.Query, error) {
+	var wildcard, re strings.Builder
+
+	literal := true
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case tsl.LikeEscape:
+			i++
+			if i == len(runes) {
+				return nil, tsl.LikePatternError{Pattern: pattern}
+			}
+			r = runes[i]
+		case '%':
+			wildcard.WriteRune('*')
+			re.WriteString(".*")
+			continue
+		case '_':
+			wildcard.WriteRune('?')
+			re.WriteRune('.')
+			continue
+		}
+
+		if r == '*' || r == '?' {
+			literal = false
+		}
+		wildcard.WriteRune(r)
+		re.WriteString(regexp.QuoteMeta(string(r)))
+	}
+
+	if !literal {
+		q := query.NewRegexpQuery(re.String())
+		q.SetField(field)
+		return q, nil
+	}
+
+	q := query.NewWildcardQuery(wildcard.String())
+	q.SetField(field)
+	return q, nil
+}
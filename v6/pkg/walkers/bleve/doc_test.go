@@ -0,0 +1,42 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the bleve package.
+func Example() {
+	// Set a TSL input string.
+	input := "description match 'fast red car' and price < 20000"
+
+	// Parse input string into a TSL tree.
+	tree, _ := tsl.ParseTSL(input)
+
+	// Set query
+	query, _ := Walk(tree)
+
+	// Print the query.
+	body, _ := json.Marshal(query)
+	fmt.Println(string(body))
+
+	// Output:
+	// {"conjuncts":[{"match":"fast red car","field":"description","prefix_length":0,"fuzziness":0,"operator":"and"},{"max":20000,"inclusive_min":true,"inclusive_max":false,"field":"price"}]}
+}
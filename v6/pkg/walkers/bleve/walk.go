@@ -0,0 +1,118 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bleve helps to create Bleve full-text search queries using the
+// TSL package.
+package bleve
+
+import (
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// target names Bleve queries in unsupported expression errors
+const target = "bleve query"
+
+// Walk travel the TSL tree to create a Bleve query.
+//
+// Users can use the query in a search request of a Bleve index.
+//
+//	q, _ := bleve.Walk(tree)
+//	result, _ := index.Search(blevesearch.NewSearchRequest(q))
+//
+// MATCH maps to a match query that requires all the query tokens,
+// comparisons between a field and literals map to term, numeric range,
+// date range, term range, boolean field, wildcard (LIKE) and regexp
+// queries, AND / OR map to conjunction / disjunction queries and NOT
+// maps to a boolean query with a must not clause. Multi valued fields
+// match if any value matches, so ANY of a condition is the condition
+// itself. Expressions Bleve queries can not express, such as arithmetic,
+// ILIKE or IS NULL, return a tsl.UnsupportedError.
+//
+// Bleve: https://blevesearch.com/docs/Query/
+func Walk(n *tsl.TSLNode) (query.Query, error) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		// A boolean field
+		q := query.NewBoolFieldQuery(true)
+		q.SetField(n.Value().(string))
+		return q, nil
+	case tsl.KindBooleanLiteral:
+		if b, _ := n.AsBool(); b {
+			return query.NewMatchAllQuery(), nil
+		}
+		return query.NewMatchNoneQuery(), nil
+	case tsl.KindBinaryExpr:
+		return binaryStep(n)
+	case tsl.KindUnaryExpr:
+		return unaryStep(n)
+	}
+
+	return nil, tsl.UnexpectedLiteralError{Literal: n.Type()}
+}
+
+// notQuery creates a query matching the documents q does not match
+func notQuery(q query.Query) query.Query {
+	return query.NewBooleanQuery(nil, nil, []query.Query{q})
+}
+
+// binaryStep handles logical operators and predicates
+func binaryStep(n *tsl.TSLNode) (query.Query, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpAnd:
+		queries, err := tsl.WalkChain(n, op.Operator, Walk)
+		if err != nil {
+			return nil, err
+		}
+		return query.NewConjunctionQuery(queries), nil
+	case tsl.OpOr:
+		queries, err := tsl.WalkChain(n, op.Operator, Walk)
+		if err != nil {
+			return nil, err
+		}
+		return query.NewDisjunctionQuery(queries), nil
+	}
+
+	return fieldQuery(n)
+}
+
+// unaryStep handles NOT, ANY and ALL operators
+func unaryStep(n *tsl.TSLNode) (query.Query, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpNot:
+		q, err := Walk(op.Right)
+		if err != nil {
+			return nil, err
+		}
+		return notQuery(q), nil
+
+	case tsl.OpAny:
+		// A multi valued field matches if any of its values matches
+		if op.Right.Type() == tsl.KindBinaryExpr {
+			return fieldQuery(op.Right)
+		}
+		return nil, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "ANY requires a condition on a field"}
+
+	case tsl.OpAll:
+		return nil, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "conditions on all values of a field are not supported"}
+	}
+
+	return nil, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a condition"}
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestWalk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bleve walker")
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Generates the expected query",
+		func(input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			query, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := json.Marshal(query)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(actual)).To(MatchJSON(expected))
+		},
+
+		// Full-text search
+		Entry("match", "description match 'fast red car'",
+			`{"match": "fast red car", "field": "description", "operator": "and", "prefix_length": 0, "fuzziness": 0}`),
+		Entry("match operator", "description @@ 'car'",
+			`{"match": "car", "field": "description", "operator": "and", "prefix_length": 0, "fuzziness": 0}`),
+		Entry("not match", "description not match 'slow'",
+			`{"must_not": {"disjuncts": [{"match": "slow", "field": "description", "operator": "and", "prefix_length": 0, "fuzziness": 0}], "min": 0}}`),
+
+		// Comparisons
+		Entry("equal", "name = 'joe'", `{"term": "joe", "field": "name"}`),
+		Entry("not equal", "name != 'joe'", `{"must_not": {"disjuncts": [{"term": "joe", "field": "name"}], "min": 0}}`),
+		Entry("equal number", "age = 20",
+			`{"min": 20, "max": 20, "inclusive_min": true, "inclusive_max": true, "field": "age"}`),
+		Entry("less than", "age < 20", `{"max": 20, "inclusive_min": true, "inclusive_max": false, "field": "age"}`),
+		Entry("less or equal", "age <= 20", `{"max": 20, "inclusive_min": true, "inclusive_max": true, "field": "age"}`),
+		Entry("greater than", "age > 20", `{"min": 20, "inclusive_min": false, "inclusive_max": true, "field": "age"}`),
+		Entry("greater or equal", "age >= 20", `{"min": 20, "inclusive_min": true, "inclusive_max": true, "field": "age"}`),
+		Entry("literal on the left", "20 < age", `{"min": 20, "inclusive_min": false, "inclusive_max": true, "field": "age"}`),
+		Entry("negative number", "age > -5", `{"min": -5, "inclusive_min": false, "inclusive_max": true, "field": "age"}`),
+		Entry("string range", "name >= 'm'", `{"min": "m", "inclusive_min": true, "inclusive_max": true, "field": "name"}`),
+		Entry("boolean", "active = false", `{"bool": false, "field": "active"}`),
+		Entry("boolean field", "active", `{"bool": true, "field": "active"}`),
+		Entry("date", "created >= 2020-01-01",
+			`{"start": "2020-01-01T00:00:00Z", "end": "0001-01-01T00:00:00Z", "inclusive_start": true, "inclusive_end": true, "field": "created"}`),
+		Entry("timestamp", "created < 2020-01-01T10:00:00Z",
+			`{"start": "0001-01-01T00:00:00Z", "end": "2020-01-01T10:00:00Z", "inclusive_start": true, "inclusive_end": false, "field": "created"}`),
+		Entry("in", "city in ['rome', 'paris']",
+			`{"disjuncts": [{"term": "rome", "field": "city"}, {"term": "paris", "field": "city"}], "min": 0}`),
+		Entry("empty in", "city in []", `{"boost": null, "match_none": {}}`),
+		Entry("between", "age between 20 and 30",
+			`{"min": 20, "max": 30, "inclusive_min": true, "inclusive_max": true, "field": "age"}`),
+
+		// Logical operators
+		Entry("and", "a = 'x' and b = 'y' and c = 'z'",
+			`{"conjuncts": [{"term": "x", "field": "a"}, {"term": "y", "field": "b"}, {"term": "z", "field": "c"}]}`),
+		Entry("or", "a = 'x' or b = 'y'",
+			`{"disjuncts": [{"term": "x", "field": "a"}, {"term": "y", "field": "b"}], "min": 0}`),
+		Entry("true", "true", `{"boost": null, "match_all": {}}`),
+		Entry("any", "any (tags = 'go')", `{"term": "go", "field": "tags"}`),
+
+		// Pattern matching
+		Entry("like", "name like 'jo%'", `{"wildcard": "jo*", "field": "name"}`),
+		Entry("like single character", "name like 'j_e'", `{"wildcard": "j?e", "field": "name"}`),
+		Entry("like escaped percent", `name like '100\%'`, `{"wildcard": "100%", "field": "name"}`),
+		Entry("like wildcard characters", "name like 'a*b?%'", `{"regexp": "a\\*b\\?.*", "field": "name"}`),
+		Entry("regexp", "name ~= '^jo'", `{"regexp": "jo.*", "field": "name"}`),
+		Entry("regexp alternation", "name ~= 'a|b'", `{"regexp": "(.*a.*|.*b.*)", "field": "name"}`),
+		Entry("anchored regexp alternation", "name ~= '^jo|oe$'", `{"regexp": "(jo.*|.*oe)", "field": "name"}`),
+		Entry("not regexp", "name ~! 'oe$'",
+			`{"must_not": {"disjuncts": [{"regexp": ".*oe", "field": "name"}], "min": 0}}`),
+	)
+
+	DescribeTable("Returns errors",
+		func(input string, expected error) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Walk(tree)
+			Expect(err).To(BeAssignableToTypeOf(expected))
+		},
+
+		Entry("not a filter", "'joe'", tsl.UnexpectedLiteralError{}),
+		Entry("like with trailing escape", `name like 'a\\'`, tsl.LikePatternError{}),
+		Entry("match of a field", "description match title", tsl.UnsupportedError{}),
+		Entry("ilike", "name ilike 'JO%'", tsl.UnsupportedError{}),
+		Entry("is null", "name is null", tsl.UnsupportedError{}),
+		Entry("arithmetic", "a + 1 > 2", tsl.UnsupportedError{}),
+		Entry("field comparison", "a = b", tsl.UnsupportedError{}),
+		Entry("ordered boolean", "active > true", tsl.UnsupportedError{}),
+		Entry("mixed between", "age between 1 and 'x'", tsl.UnsupportedError{}),
+		Entry("all", "all (scores >= 50)", tsl.UnsupportedError{}),
+		Entry("length", "len tags > 2", tsl.UnsupportedError{}),
+	)
+
+	It("Names the offending expression", func() {
+		tree, err := tsl.ParseTSL("a = 'x' and name ilike 'JO%'")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = Walk(tree)
+		Expect(err).To(MatchError("bleve query can not express name ILIKE 'JO%': wildcard queries are case sensitive, use MATCH or LIKE"))
+	})
+})
@@ -0,0 +1,38 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the cel package.
+func Example() {
+	// Set a TSL input string.
+	input := "name LIKE 'jo%' AND ANY (tags = 'admin')"
+
+	// Parse input string into a TSL tree.
+	tree, _ := tsl.ParseTSL(input)
+
+	// Create the CEL expression.
+	expression, _ := Walk(tree)
+	fmt.Println(expression)
+
+	// Output:
+	// name.matches("^jo(?s:.*)$") && tags.exists(x, x == "admin")
+}
@@ -0,0 +1,66 @@
+package cel
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// celName matches CEL identifiers
+var celName = regexp.MustCompile(`^[_a-zA-Z][_a-zA-Z0-9]*$`)
+
+// index matches numeric array indexes
+var index = regexp.MustCompile(`^[0-9]+$`)
+
+// reserved are the CEL reserved words that can not be used as identifiers
+var reserved = map[string]bool{
+	"true": true, "false": true, "null": true, "in": true, "as": true,
+	"break": true, "const": true, "continue": true, "else": true,
+	"for": true, "function": true, "if": true, "import": true, "let": true,
+	"loop": true, "package": true, "namespace": true, "return": true,
+	"var": true, "void": true, "while": true,
+}
+
+// identifier converts a TSL identifier into a CEL field selection, e.g.
+// "pods[0].status" into `pods[0].status` and "services[my.service].ip"
+// into `services["my.service"].ip`
+func identifier(name string) (string, error) {
+	var b strings.Builder
+
+	for rest := name; rest != ""; {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return "", tsl.UnsupportedError{Target: target, Expression: name, Reason: "unterminated index"}
+			}
+			key := rest[1:end]
+			if index.MatchString(key) {
+				b.WriteString("[" + key + "]")
+			} else {
+				b.WriteString("[" + strconv.Quote(key) + "]")
+			}
+			rest = rest[end+1:]
+
+		case rest[0] == '.' && b.Len() > 0 && len(rest) > 1:
+			rest = rest[1:]
+			b.WriteString(".")
+
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			part := rest[:end]
+			if !celName.MatchString(part) || reserved[part] {
+				return "", tsl.UnsupportedError{Target: target, Expression: name, Reason: "invalid CEL identifier " + strconv.Quote(part)}
+			}
+			b.WriteString(part)
+			rest = rest[end:]
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,347 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cel helps to create Common Expression Language (CEL) expressions
+// using the TSL package.
+package cel
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// target names CEL expressions in unsupported expression errors
+const target = "CEL"
+
+// Operator precedence levels of CEL (lowest to highest)
+const (
+	precOr = iota + 1
+	precAnd
+	precRelation
+	precAdditive
+	precMultiplicative
+	precUnary
+	precMember
+)
+
+// operators maps TSL binary operators to CEL operators and precedence
+var operators = map[tsl.Operator]struct {
+	symbol string
+	prec   int
+}{
+	tsl.OpOr:      {"||", precOr},
+	tsl.OpAnd:     {"&&", precAnd},
+	tsl.OpEQ:      {"==", precRelation},
+	tsl.OpNE:      {"!=", precRelation},
+	tsl.OpLT:      {"<", precRelation},
+	tsl.OpLE:      {"<=", precRelation},
+	tsl.OpGT:      {">", precRelation},
+	tsl.OpGE:      {">=", precRelation},
+	tsl.OpIn:      {"in", precRelation},
+	tsl.OpPlus:    {"+", precAdditive},
+	tsl.OpMinus:   {"-", precAdditive},
+	tsl.OpStar:    {"*", precMultiplicative},
+	tsl.OpSlash:   {"/", precMultiplicative},
+	tsl.OpPercent: {"%", precMultiplicative},
+}
+
+// Walk travel the TSL tree to create a CEL expression.
+//
+// Comparisons, AND, OR, NOT and arithmetic use the CEL operators, IN uses
+// the in operator, IS NULL compares with null, BETWEEN becomes two
+// comparisons, LIKE and ILIKE call matches() with an anchored regular
+// expression, ~= and ~! call matches(), LEN calls size(), and ANY and ALL
+// use the exists and all macros over the array of the condition, ALL also
+// checks that the array is not empty. Dates and timestamps become
+// timestamp() calls.
+//
+// Numbers without a fraction are written as int literals. CEL compares int
+// and double values, but arithmetic operators require operands of the same
+// type, and dividing ints truncates the result. Expressions CEL can not
+// express, such as SUM, return a tsl.UnsupportedError.
+//
+//	expression, err := cel.Walk(tree)
+//	// name.matches("^jo(?s:.*)$") && age >= 21
+//
+// CEL: https://github.com/google/cel-spec/blob/master/doc/langdef.md
+func Walk(n *tsl.TSLNode) (string, error) {
+	s, _, err := walk(n)
+	return s, err
+}
+
+// walk returns the CEL source of a node and its precedence
+func walk(n *tsl.TSLNode) (string, int, error) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		s, err := identifier(n.Value().(string))
+		return s, precMember, err
+	case tsl.KindStringLiteral:
+		return strconv.Quote(n.Value().(string)), precMember, nil
+	case tsl.KindNumericLiteral:
+		return number(n.Value().(float64)), precMember, nil
+	case tsl.KindBooleanLiteral:
+		return strconv.FormatBool(n.Value().(bool)), precMember, nil
+	case tsl.KindNullLiteral:
+		return "null", precMember, nil
+	case tsl.KindDateLiteral:
+		s, _ := n.AsString()
+		return "timestamp(" + strconv.Quote(s+"T00:00:00Z") + ")", precMember, nil
+	case tsl.KindTimestampLiteral:
+		if t, ok := n.Value().(time.Time); ok {
+			return "timestamp(" + strconv.Quote(t.Format(time.RFC3339Nano)) + ")", precMember, nil
+		}
+	case tsl.KindArrayLiteral:
+		arr, _ := n.AsArray()
+		items := make([]string, len(arr.Values))
+		for i, v := range arr.Values {
+			s, _, err := walk(v)
+			if err != nil {
+				return "", 0, err
+			}
+			items[i] = s
+		}
+		return "[" + strings.Join(items, ", ") + "]", precMember, nil
+	case tsl.KindBinaryExpr:
+		return binaryStep(n)
+	case tsl.KindUnaryExpr:
+		return unaryStep(n)
+	}
+
+	return "", 0, tsl.UnexpectedLiteralError{Literal: n.Type()}
+}
+
+// operand returns the CEL source of a node, wrapped in parentheses if it
+// binds weaker than min
+func operand(n *tsl.TSLNode, min int) (string, error) {
+	s, prec, err := walk(n)
+	if err != nil {
+		return "", err
+	}
+	if prec < min {
+		return "(" + s + ")", nil
+	}
+	return s, nil
+}
+
+// binaryStep handles binary operators
+func binaryStep(n *tsl.TSLNode) (string, int, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpLike, tsl.OpILike, tsl.OpREQ, tsl.OpRNE:
+		return matchesStep(n, op)
+
+	case tsl.OpBetween:
+		arr, ok := op.Right.AsArray()
+		if !ok || len(arr.Values) != 2 {
+			return "", 0, tsl.BetweenOperatorError{Message: "BETWEEN requires two values"}
+		}
+		l, err := operand(op.Left, precRelation+1)
+		if err != nil {
+			return "", 0, err
+		}
+		low, err := operand(arr.Values[0], precRelation+1)
+		if err != nil {
+			return "", 0, err
+		}
+		high, err := operand(arr.Values[1], precRelation+1)
+		if err != nil {
+			return "", 0, err
+		}
+		return l + " >= " + low + " && " + l + " <= " + high, precAnd, nil
+
+	case tsl.OpIs:
+		if op.Right.Type() != tsl.KindNullLiteral {
+			return "", 0, tsl.TypeMismatchError{Expected: "null", Got: op.Right.Type()}
+		}
+		l, err := operand(op.Left, precRelation+1)
+		if err != nil {
+			return "", 0, err
+		}
+		return l + " == null", precRelation, nil
+	}
+
+	o, ok := operators[op.Operator]
+	if !ok {
+		return "", 0, tsl.UnexpectedOperatorError{Operator: op.Operator}
+	}
+
+	// AND and OR are associative, the other operators are left associative
+	// and relations do not chain
+	leftMin, rightMin := o.prec, o.prec+1
+	switch o.prec {
+	case precOr, precAnd:
+		rightMin = o.prec
+	case precRelation:
+		leftMin = o.prec + 1
+	}
+
+	l, err := operand(op.Left, leftMin)
+	if err != nil {
+		return "", 0, err
+	}
+	r, err := operand(op.Right, rightMin)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return l + " " + o.symbol + " " + r, o.prec, nil
+}
+
+// matchesStep handles LIKE, ILIKE and regular expression operators
+func matchesStep(n *tsl.TSLNode, op tsl.TSLExpressionOp) (string, int, error) {
+	pattern, ok := op.Right.AsString()
+	if !ok || op.Right.Type() != tsl.KindStringLiteral {
+		return "", 0, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "patterns must be string literals"}
+	}
+
+	switch op.Operator {
+	case tsl.OpLike, tsl.OpILike:
+		re, err := tsl.LikeToRegexp(pattern)
+		if err != nil {
+			return "", 0, err
+		}
+		if op.Operator == tsl.OpILike {
+			re = "(?i)" + re
+		}
+		pattern = re
+	}
+
+	l, err := operand(op.Left, precMember)
+	if err != nil {
+		return "", 0, err
+	}
+	s := l + ".matches(" + strconv.Quote(pattern) + ")"
+
+	if op.Operator == tsl.OpRNE {
+		return "!" + s, precUnary, nil
+	}
+	return s, precMember, nil
+}
+
+// unaryStep handles unary operators
+func unaryStep(n *tsl.TSLNode) (string, int, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpNot:
+		// IS NOT NULL
+		if inner, ok := op.Right.AsExprOp(); ok && op.Right.Type() == tsl.KindBinaryExpr &&
+			inner.Operator == tsl.OpIs && inner.Right.Type() == tsl.KindNullLiteral {
+			l, err := operand(inner.Left, precRelation+1)
+			if err != nil {
+				return "", 0, err
+			}
+			return l + " != null", precRelation, nil
+		}
+
+		r, err := operand(op.Right, precUnary)
+		if err != nil {
+			return "", 0, err
+		}
+		return "!" + r, precUnary, nil
+
+	case tsl.OpUMinus:
+		r, err := operand(op.Right, precUnary)
+		if err != nil {
+			return "", 0, err
+		}
+		return "-" + r, precUnary, nil
+
+	case tsl.OpLen:
+		r, _, err := walk(op.Right)
+		if err != nil {
+			return "", 0, err
+		}
+		return "size(" + r + ")", precMember, nil
+
+	case tsl.OpAny, tsl.OpAll:
+		return macroStep(n, op)
+	}
+
+	return "", 0, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: op.Operator.String() + " has no CEL equivalent"}
+}
+
+// macroStep handles ANY and ALL using the exists and all macros, the
+// array is the left operand of the condition, and the macro variable
+// replaces it in the predicate.
+func macroStep(n *tsl.TSLNode, op tsl.TSLExpressionOp) (string, int, error) {
+	cond, ok := op.Right.AsExprOp()
+	if !ok || op.Right.Type() != tsl.KindBinaryExpr || cond.Left.Type() != tsl.KindIdentifier {
+		return "", 0, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a condition on an array identifier"}
+	}
+
+	array, err := identifier(cond.Left.Value().(string))
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Pick a variable name that the condition does not use
+	used := map[string]bool{}
+	collectIdentifiers(op.Right, used)
+	variable := "x"
+	for i := 1; used[variable]; i++ {
+		variable = "x" + strconv.Itoa(i)
+	}
+
+	predicate := op.Right.Clone()
+	predicate.SetLeft(tsl.NewIdentifier(variable))
+	p, _, err := walk(predicate)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if op.Operator == tsl.OpAny {
+		return array + ".exists(" + variable + ", " + p + ")", precMember, nil
+	}
+
+	// ALL is false for empty arrays, the all macro is true
+	return "size(" + array + ") > 0 && " + array + ".all(" + variable + ", " + p + ")", precAnd, nil
+}
+
+// collectIdentifiers adds the root names of the identifiers in the tree
+func collectIdentifiers(n *tsl.TSLNode, names map[string]bool) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		name := n.Value().(string)
+		if i := strings.IndexAny(name, ".["); i >= 0 {
+			name = name[:i]
+		}
+		names[name] = true
+	case tsl.KindArrayLiteral:
+		arr, _ := n.AsArray()
+		for _, v := range arr.Values {
+			collectIdentifiers(v, names)
+		}
+	case tsl.KindBinaryExpr, tsl.KindUnaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		if op.Left != nil {
+			collectIdentifiers(op.Left, names)
+		}
+		collectIdentifiers(op.Right, names)
+	}
+}
+
+// number returns a CEL number literal, numbers without a fraction are
+// written as int literals
+func number(v float64) string {
+	if v == float64(int64(v)) && v > -1e15 && v < 1e15 {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestWalk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CEL walker")
+}
+
+// goldenEntries reads the table entries of a golden file, blocks of a name
+// comment, a TSL phrase and the expected CEL expression
+func goldenEntries(path string) []TableEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	entries := []TableEntry{}
+	for _, block := range strings.Split(strings.TrimSpace(string(data)), "\n\n") {
+		lines := strings.Split(block, "\n")
+		if len(lines) != 3 || !strings.HasPrefix(lines[0], "# ") {
+			continue
+		}
+		entries = append(entries, Entry(strings.TrimPrefix(lines[0], "# "), lines[1], lines[2]))
+	}
+	return entries
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Generates the golden CEL expressions",
+		func(input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		goldenEntries("testdata/operators.golden"),
+	)
+
+	DescribeTable("Returns errors",
+		func(input string, expected error) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Walk(tree)
+			Expect(err).To(BeAssignableToTypeOf(expected))
+		},
+
+		Entry("sum", "sum scores > 100", tsl.UnsupportedError{}),
+		Entry("like with identifier pattern", "name like pattern", tsl.UnsupportedError{}),
+		Entry("like with trailing escape", `name like 'a\\'`, tsl.LikePatternError{}),
+		Entry("any of an expression", "any (a + b > 1)", tsl.UnsupportedError{}),
+		Entry("reserved identifier", "in.x = 1", tsl.UnsupportedError{}),
+		Entry("identifier with slash", "labels.app.kubernetes.io/name = 'web'", tsl.UnsupportedError{}),
+	)
+})
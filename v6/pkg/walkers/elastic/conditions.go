@@ -0,0 +1,167 @@
+package elastic
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// rangeOperators maps TSL comparison operators to range query parameters
+var rangeOperators = map[tsl.Operator]string{
+	tsl.OpLT: "lt",
+	tsl.OpLE: "lte",
+	tsl.OpGT: "gt",
+	tsl.OpGE: "gte",
+}
+
+// jsonValue returns the JSON value of a literal value, times are
+// formatted as RFC3339
+func jsonValue(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+// jsonValues returns the JSON values of a list of literal values
+func jsonValues(values []interface{}) []interface{} {
+	for i, v := range values {
+		values[i] = jsonValue(v)
+	}
+	return values
+}
+
+// fieldQuery creates a query for a predicate comparing a field with
+// literals, ok is false if the predicate needs a script query.
+func fieldQuery(op tsl.TSLExpressionOp) (q Query, ok bool, err error) {
+	field, isField := tsl.FieldName(op.Left)
+	operator := op.Operator
+
+	// Literal on the left, identifier on the right
+	if !isField {
+		flipped, canFlip := tsl.FlippedComparisons[operator]
+		if !canFlip {
+			return nil, false, nil
+		}
+		if field, isField = tsl.FieldName(op.Right); !isField {
+			return nil, false, nil
+		}
+		if _, isLiteral := tsl.LiteralValue(op.Left); !isLiteral {
+			return nil, false, nil
+		}
+		op.Left, op.Right, operator = op.Right, op.Left, flipped
+	}
+
+	switch operator {
+	case tsl.OpEQ, tsl.OpNE:
+		v, isLiteral := tsl.LiteralValue(op.Right)
+		if !isLiteral {
+			return nil, false, nil
+		}
+		q = Query{"term": Query{field: jsonValue(v)}}
+		if operator == tsl.OpNE {
+			q = boolQuery("must_not", q)
+		}
+		return q, true, nil
+
+	case tsl.OpLT, tsl.OpLE, tsl.OpGT, tsl.OpGE:
+		v, isLiteral := tsl.LiteralValue(op.Right)
+		if !isLiteral {
+			return nil, false, nil
+		}
+		return Query{"range": Query{field: Query{rangeOperators[operator]: jsonValue(v)}}}, true, nil
+
+	case tsl.OpIn:
+		values, isLiteral := tsl.LiteralArrayValues(op.Right)
+		if !isLiteral {
+			return nil, false, nil
+		}
+		return Query{"terms": Query{field: jsonValues(values)}}, true, nil
+
+	case tsl.OpBetween:
+		values, isLiteral := tsl.LiteralArrayValues(op.Right)
+		if !isLiteral || len(values) != 2 {
+			return nil, false, nil
+		}
+		return Query{"range": Query{field: Query{"gte": jsonValue(values[0]), "lte": jsonValue(values[1])}}}, true, nil
+
+	case tsl.OpLike, tsl.OpILike:
+		pattern, isString := op.Right.AsString()
+		if !isString || op.Right.Type() != tsl.KindStringLiteral {
+			return nil, false, nil
+		}
+		wildcard, err := wildcardPattern(pattern)
+		if err != nil {
+			return nil, false, err
+		}
+		params := Query{"value": wildcard}
+		if operator == tsl.OpILike {
+			params["case_insensitive"] = true
+		}
+		return Query{"wildcard": Query{field: params}}, true, nil
+
+	case tsl.OpREQ, tsl.OpRNE:
+		pattern, isString := op.Right.AsString()
+		if !isString || op.Right.Type() != tsl.KindStringLiteral {
+			return nil, false, nil
+		}
+		q = Query{"regexp": Query{field: Query{"value": tsl.AnchoredRegexp(pattern)}}}
+		if operator == tsl.OpRNE {
+			q = boolQuery("must_not", q)
+		}
+		return q, true, nil
+
+	case tsl.OpMatch:
+		text, isString := op.Right.AsString()
+		if !isString || op.Right.Type() != tsl.KindStringLiteral {
+			return nil, false, nil
+		}
+		return Query{"match": Query{field: Query{"query": text, "operator": "and"}}}, true, nil
+
+	case tsl.OpIs:
+		if op.Right.Type() != tsl.KindNullLiteral {
+			return nil, false, nil
+		}
+		return boolQuery("must_not", Query{"exists": Query{"field": field}}), true, nil
+	}
+
+	return nil, false, nil
+}
+
+// wildcardPattern converts a LIKE pattern into a wildcard query pattern,
+// '%' becomes '*', '_' becomes '?', and escaped or literal wildcard
+// characters are escaped.
+func wildcardPattern(pattern string) (string, error) {
+	var b strings.Builder
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case tsl.LikeEscape:
+			i++
+			if i == len(runes) {
+				return "", tsl.LikePatternError{Pattern: pattern}
+			}
+			writeWildcardLiteral(&b, runes[i])
+		case '%':
+			b.WriteRune('*')
+		case '_':
+			b.WriteRune('?')
+		default:
+			writeWildcardLiteral(&b, r)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// writeWildcardLiteral writes a rune that must match itself in a wildcard
+// query pattern
+func writeWildcardLiteral(b *strings.Builder, r rune) {
+	if r == '*' || r == '?' || r == '\\' {
+		b.WriteRune('\\')
+	}
+	b.WriteRune(r)
+}
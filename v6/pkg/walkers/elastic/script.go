@@ -0,0 +1,169 @@
+package elastic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// comparisonOperators maps TSL comparison operators to painless operators
+var comparisonOperators = map[tsl.Operator]string{
+	tsl.OpEQ: "==",
+	tsl.OpNE: "!=",
+	tsl.OpLT: "<",
+	tsl.OpLE: "<=",
+	tsl.OpGT: ">",
+	tsl.OpGE: ">=",
+}
+
+// arithmeticOperators maps TSL arithmetic operators to painless operators
+var arithmeticOperators = map[tsl.Operator]string{
+	tsl.OpPlus:    "+",
+	tsl.OpMinus:   "-",
+	tsl.OpStar:    "*",
+	tsl.OpSlash:   "/",
+	tsl.OpPercent: "%",
+}
+
+// script collects the parameters and the accessed fields of a painless
+// script while its source is built
+type script struct {
+	params map[string]interface{}
+	fields map[string]bool
+}
+
+// scriptQuery creates a painless script query for a predicate that
+// compares computed values, fields that are missing in a document make
+// the predicate false.
+func scriptQuery(n *tsl.TSLNode) (Query, error) {
+	s := &script{params: map[string]interface{}{}, fields: map[string]bool{}}
+
+	source, err := s.predicate(n)
+	if err != nil {
+		return nil, err
+	}
+
+	// Guard against documents without a value
+	fields := make([]string, 0, len(s.fields))
+	for field := range s.fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	guards := make([]string, 0, len(fields)+1)
+	for _, field := range fields {
+		guards = append(guards, fmt.Sprintf("doc[%s].size() != 0", quote(field)))
+	}
+	source = strings.Join(append(guards, source), " && ")
+
+	return Query{"script": Query{"script": Query{
+		"source": source,
+		"lang":   "painless",
+		"params": s.params,
+	}}}, nil
+}
+
+// predicate returns the painless source of a comparison
+func (s *script) predicate(n *tsl.TSLNode) (string, error) {
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != tsl.KindBinaryExpr {
+		return "", tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "script queries require a comparison"}
+	}
+
+	switch op.Operator {
+	case tsl.OpEQ, tsl.OpNE, tsl.OpLT, tsl.OpLE, tsl.OpGT, tsl.OpGE:
+		l, err := s.value(op.Left)
+		if err != nil {
+			return "", err
+		}
+		r, err := s.value(op.Right)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", l, comparisonOperators[op.Operator], r), nil
+
+	case tsl.OpIn, tsl.OpBetween:
+		arr, ok := op.Right.AsArray()
+		if !ok {
+			return "", tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a list of values"}
+		}
+		l, err := s.value(op.Left)
+		if err != nil {
+			return "", err
+		}
+		items := make([]string, len(arr.Values))
+		for i, item := range arr.Values {
+			if items[i], err = s.value(item); err != nil {
+				return "", err
+			}
+		}
+		if op.Operator == tsl.OpBetween {
+			if len(items) != 2 {
+				return "", tsl.BetweenOperatorError{Message: "BETWEEN requires exactly two values"}
+			}
+			return fmt.Sprintf("(%s >= %s && %s <= %s)", l, items[0], l, items[1]), nil
+		}
+		for i, item := range items {
+			items[i] = fmt.Sprintf("%s == %s", l, item)
+		}
+		return "(" + strings.Join(items, " || ") + ")", nil
+	}
+
+	return "", tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: fmt.Sprintf("%s is not supported in script queries", op.Operator)}
+}
+
+// value returns the painless source of a value expression
+func (s *script) value(n *tsl.TSLNode) (string, error) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		field := n.Value().(string)
+		s.fields[field] = true
+		return fmt.Sprintf("doc[%s].value", quote(field)), nil
+
+	case tsl.KindNumericLiteral, tsl.KindStringLiteral, tsl.KindBooleanLiteral:
+		name := fmt.Sprintf("p%d", len(s.params))
+		s.params[name] = n.Value()
+		return "params." + name, nil
+
+	case tsl.KindBinaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		operator, ok := arithmeticOperators[op.Operator]
+		if !ok {
+			break
+		}
+		l, err := s.value(op.Left)
+		if err != nil {
+			return "", err
+		}
+		r, err := s.value(op.Right)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s %s %s)", l, operator, r), nil
+
+	case tsl.KindUnaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		switch op.Operator {
+		case tsl.OpUMinus:
+			r, err := s.value(op.Right)
+			if err != nil {
+				return "", err
+			}
+			return "(-" + r + ")", nil
+		case tsl.OpLen:
+			// LEN counts the values of a field, missing fields have no values
+			if field, ok := tsl.FieldName(op.Right); ok {
+				return fmt.Sprintf("doc[%s].size()", quote(field)), nil
+			}
+		}
+	}
+
+	return "", tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "value can not be computed in a script query"}
+}
+
+// quote returns a painless string literal
+func quote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package elastic helps to create Elasticsearch and OpenSearch Query DSL
+// filters using the TSL package.
+package elastic
+
+import (
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// target names Query DSL queries in unsupported expression errors
+const target = "elasticsearch query"
+
+// Query is a Query DSL query, it marshals into the query JSON
+type Query = map[string]interface{}
+
+// Walk travel the TSL tree to create an Elasticsearch / OpenSearch Query DSL
+// query.
+//
+// Users can marshal the query into the body of a search request.
+//
+//	query, _ := elastic.Walk(tree)
+//	body, _ := json.Marshal(map[string]interface{}{"query": query})
+//
+// Comparisons between a field and literals map to term, terms, range,
+// wildcard (LIKE, ILIKE with case_insensitive), regexp, match (MATCH,
+// requiring all the query tokens) and exists queries, AND / OR / NOT map to bool must / should / must_not clauses.
+// Multi valued fields match if any value matches, so ANY of a condition is
+// the condition itself. Arithmetic, LEN and field to field comparisons are
+// emitted as painless script queries. Expressions the Query DSL can not
+// express, such as ALL or SUM, return a tsl.UnsupportedError.
+//
+// Query DSL: https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl.html
+func Walk(n *tsl.TSLNode) (Query, error) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		// A boolean field
+		return Query{"term": Query{n.Value().(string): true}}, nil
+	case tsl.KindBooleanLiteral:
+		if b, _ := n.AsBool(); b {
+			return Query{"match_all": Query{}}, nil
+		}
+		return Query{"match_none": Query{}}, nil
+	case tsl.KindBinaryExpr:
+		return binaryStep(n)
+	case tsl.KindUnaryExpr:
+		return unaryStep(n)
+	}
+
+	return nil, tsl.UnexpectedLiteralError{Literal: n.Type()}
+}
+
+// boolQuery creates a bool query with one kind of clauses
+func boolQuery(occur string, clauses ...Query) Query {
+	q := Query{occur: clauses}
+	if occur == "should" {
+		q["minimum_should_match"] = 1
+	}
+	return Query{"bool": q}
+}
+
+// binaryStep handles logical operators and predicates
+func binaryStep(n *tsl.TSLNode) (Query, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpAnd, tsl.OpOr:
+		clauses, err := tsl.WalkChain(n, op.Operator, Walk)
+		if err != nil {
+			return nil, err
+		}
+		if op.Operator == tsl.OpAnd {
+			return boolQuery("must", clauses...), nil
+		}
+		return boolQuery("should", clauses...), nil
+	}
+
+	q, ok, err := fieldQuery(op)
+	if err != nil || ok {
+		return q, err
+	}
+
+	return scriptQuery(n)
+}
+
+// unaryStep handles NOT, ANY and ALL operators
+func unaryStep(n *tsl.TSLNode) (Query, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpNot:
+		// IS NOT NULL
+		if inner, ok := op.Right.AsExprOp(); ok && op.Right.Type() == tsl.KindBinaryExpr && inner.Operator == tsl.OpIs {
+			if field, ok := tsl.FieldName(inner.Left); ok && inner.Right.Type() == tsl.KindNullLiteral {
+				return Query{"exists": Query{"field": field}}, nil
+			}
+		}
+
+		q, err := Walk(op.Right)
+		if err != nil {
+			return nil, err
+		}
+		return boolQuery("must_not", q), nil
+
+	case tsl.OpAny:
+		// A multi valued field matches if any of its values matches
+		if inner, ok := op.Right.AsExprOp(); ok && op.Right.Type() == tsl.KindBinaryExpr {
+			q, ok, err := fieldQuery(inner)
+			if err != nil || ok {
+				return q, err
+			}
+		}
+		return nil, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "ANY requires a condition on a field"}
+
+	case tsl.OpAll:
+		return nil, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "conditions on all values of a field are not supported"}
+	}
+
+	return scriptQuery(n)
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastic
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestWalk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Elastic walker")
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Generates the expected query",
+		func(input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			query, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := json.Marshal(query)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(actual)).To(MatchJSON(expected))
+		},
+
+		// Comparisons
+		Entry("equal", "name = 'joe'", `{"term": {"name": "joe"}}`),
+		Entry("not equal", "name != 'joe'", `{"bool": {"must_not": [{"term": {"name": "joe"}}]}}`),
+		Entry("less than", "age < 20", `{"range": {"age": {"lt": 20}}}`),
+		Entry("less or equal", "age <= 20", `{"range": {"age": {"lte": 20}}}`),
+		Entry("greater than", "age > 20", `{"range": {"age": {"gt": 20}}}`),
+		Entry("greater or equal", "age >= 20", `{"range": {"age": {"gte": 20}}}`),
+		Entry("literal on the left", "20 < age", `{"range": {"age": {"gt": 20}}}`),
+		Entry("negative number", "age > -5", `{"range": {"age": {"gt": -5}}}`),
+		Entry("boolean", "active = true", `{"term": {"active": true}}`),
+		Entry("boolean field", "active", `{"term": {"active": true}}`),
+		Entry("date", "created >= 2020-01-01", `{"range": {"created": {"gte": "2020-01-01T00:00:00Z"}}}`),
+		Entry("timestamp", "created < 2020-01-01T10:00:00Z",
+			`{"range": {"created": {"lt": "2020-01-01T10:00:00Z"}}}`),
+
+		// Logical operators
+		Entry("and", "a = 1 and b = 2", `{"bool": {"must": [{"term": {"a": 1}}, {"term": {"b": 2}}]}}`),
+		Entry("flattened and", "a = 1 and b = 2 and c = 3",
+			`{"bool": {"must": [{"term": {"a": 1}}, {"term": {"b": 2}}, {"term": {"c": 3}}]}}`),
+		Entry("or of and", "a = 1 or (b = 2 and c = 3)",
+			`{"bool": {"minimum_should_match": 1, "should": [
+				{"term": {"a": 1}},
+				{"bool": {"must": [{"term": {"b": 2}}, {"term": {"c": 3}}]}}
+			]}}`),
+		Entry("not", "not (a > 1)", `{"bool": {"must_not": [{"range": {"a": {"gt": 1}}}]}}`),
+
+		// Pattern matching
+		Entry("like", "name like 'jo%'", `{"wildcard": {"name": {"value": "jo*"}}}`),
+		Entry("like single character", "name like 'j_e'", `{"wildcard": {"name": {"value": "j?e"}}}`),
+		Entry("like escapes wildcard characters", "name like 'a*b?%'",
+			`{"wildcard": {"name": {"value": "a\\*b\\?*"}}}`),
+		Entry("like escaped percent", `name like '100\%'`, `{"wildcard": {"name": {"value": "100%"}}}`),
+		Entry("like escape clause", "name like '100!%' escape '!'", `{"wildcard": {"name": {"value": "100%"}}}`),
+		Entry("match", "description match 'fast red car'",
+			`{"match": {"description": {"query": "fast red car", "operator": "and"}}}`),
+		Entry("not match", "description not match 'slow'",
+			`{"bool": {"must_not": [{"match": {"description": {"query": "slow", "operator": "and"}}}]}}`),
+		Entry("ilike", "name ilike 'JO%'",
+			`{"wildcard": {"name": {"value": "JO*", "case_insensitive": true}}}`),
+		Entry("not like", "name not like 'jo%'",
+			`{"bool": {"must_not": [{"wildcard": {"name": {"value": "jo*"}}}]}}`),
+		Entry("regex", "email ~= 'jo.*@gmail'", `{"regexp": {"email": {"value": ".*jo.*@gmail.*"}}}`),
+		Entry("anchored regex", "email ~= '^jo.*com$'", `{"regexp": {"email": {"value": "jo.*com"}}}`),
+		Entry("regex alternation", "host ~= 'a|b'", `{"regexp": {"host": {"value": "(.*a.*|.*b.*)"}}}`),
+		Entry("anchored regex alternation", "host ~= '^api|web$'", `{"regexp": {"host": {"value": "(api.*|.*web)"}}}`),
+		Entry("regex escaped backslash before end", `path ~= 'a\\\\$'`, `{"regexp": {"path": {"value": ".*a\\\\"}}}`),
+		Entry("not regex", "email ~! '@gmail'",
+			`{"bool": {"must_not": [{"regexp": {"email": {"value": ".*@gmail.*"}}}]}}`),
+
+		// Membership and ranges
+		Entry("in", "city in ['rome', 'paris']", `{"terms": {"city": ["rome", "paris"]}}`),
+		Entry("not in", "city not in ['rome', 'paris']",
+			`{"bool": {"must_not": [{"terms": {"city": ["rome", "paris"]}}]}}`),
+		Entry("between", "age between 20 and 30", `{"range": {"age": {"gte": 20, "lte": 30}}}`),
+		Entry("not between", "age not between 20 and 30",
+			`{"bool": {"must_not": [{"range": {"age": {"gte": 20, "lte": 30}}}]}}`),
+
+		// Null checks
+		Entry("is null", "email is null", `{"bool": {"must_not": [{"exists": {"field": "email"}}]}}`),
+		Entry("is not null", "email is not null", `{"exists": {"field": "email"}}`),
+
+		// Array operators
+		Entry("any", "any (tags like 'fic%')", `{"wildcard": {"tags": {"value": "fic*"}}}`),
+		Entry("any comparison", "any (scores > 90)", `{"range": {"scores": {"gt": 90}}}`),
+		Entry("len", "len tags > 2", `{"script": {"script": {
+			"source": "doc['tags'].size() > params.p0", "lang": "painless", "params": {"p0": 2}}}}`),
+
+		// Script queries
+		Entry("arithmetic", "(salary * 12) + bonus > 100000", `{"script": {"script": {
+			"source": "doc['bonus'].size() != 0 && doc['salary'].size() != 0 && ((doc['salary'].value * params.p0) + doc['bonus'].value) > params.p1",
+			"lang": "painless", "params": {"p0": 12, "p1": 100000}}}}`),
+		Entry("unary minus", "-balance > 10", `{"script": {"script": {
+			"source": "doc['balance'].size() != 0 && (-doc['balance'].value) > params.p0",
+			"lang": "painless", "params": {"p0": 10}}}}`),
+		Entry("field to field", "spent > budget", `{"script": {"script": {
+			"source": "doc['budget'].size() != 0 && doc['spent'].size() != 0 && doc['spent'].value > doc['budget'].value",
+			"lang": "painless", "params": {}}}}`),
+		Entry("expression between", "a - b between 1 and 2", `{"script": {"script": {
+			"source": "doc['a'].size() != 0 && doc['b'].size() != 0 && ((doc['a'].value - doc['b'].value) >= params.p0 && (doc['a'].value - doc['b'].value) <= params.p1)",
+			"lang": "painless", "params": {"p0": 1, "p1": 2}}}}`),
+		Entry("expression in", "a % 2 in [0, 1]", `{"script": {"script": {
+			"source": "doc['a'].size() != 0 && ((doc['a'].value % params.p0) == params.p1 || (doc['a'].value % params.p0) == params.p2)",
+			"lang": "painless", "params": {"p0": 2, "p1": 0, "p2": 1}}}}`),
+		Entry("nested field", "user.age + 1 = 2", `{"script": {"script": {
+			"source": "doc['user.age'].size() != 0 && (doc['user.age'].value + params.p0) == params.p1",
+			"lang": "painless", "params": {"p0": 1, "p1": 2}}}}`),
+	)
+
+	DescribeTable("Returns errors",
+		func(input string, expected error) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Walk(tree)
+			Expect(err).To(BeAssignableToTypeOf(expected))
+		},
+
+		Entry("not a filter", "'joe'", tsl.UnexpectedLiteralError{}),
+		Entry("like with trailing escape", `name like 'a\\'`, tsl.LikePatternError{}),
+		Entry("all", "all (scores >= 50)", tsl.UnsupportedError{}),
+		Entry("sum", "sum scores > 100", tsl.UnsupportedError{}),
+		Entry("any of an expression", "any (a + b > 1)", tsl.UnsupportedError{}),
+		Entry("expression like", "first + last like 'jo%'", tsl.UnsupportedError{}),
+		Entry("date in a script", "created + 1 > 2020-01-01", tsl.UnsupportedError{}),
+		Entry("arithmetic without comparison", "a + 1", tsl.UnsupportedError{}),
+		Entry("match of a field", "description match title", tsl.UnsupportedError{}),
+	)
+
+	It("Rejects BETWEEN without two values", func() {
+		for _, values := range [][]*tsl.TSLNode{{}, {tsl.NewNumericLiteral(1)}} {
+			tree := tsl.NewBinaryExpr(tsl.OpBetween,
+				tsl.NewBinaryExpr(tsl.OpMinus, tsl.NewIdentifier("a"), tsl.NewIdentifier("b")),
+				tsl.NewArrayLiteral(values...))
+
+			_, err := Walk(tree)
+			Expect(err).To(BeAssignableToTypeOf(tsl.BetweenOperatorError{}))
+		}
+	})
+
+	It("Names the offending expression", func() {
+		tree, err := tsl.ParseTSL("a = 1 and all (scores >= 50)")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = Walk(tree)
+		Expect(err).To(MatchError(ContainSubstring("ALL (scores >= 50)")))
+	})
+})
@@ -0,0 +1,58 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gocode
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the gocode package.
+func Example() {
+	// Set a TSL input string.
+	input := "spec.pages > 100 and author = 'Joe' and title like 'The %'"
+
+	// Parse input string into a TSL tree.
+	tree, _ := tsl.ParseTSL(input)
+
+	// Read the fields of the Book struct type.
+	_, book, _ := LoadStruct("testdata", "Book")
+
+	// Compile the tree into a Go function.
+	f, _ := Walk(tree, "BigBooks", book)
+	src, _ := File("books", f)
+
+	fmt.Print(string(src))
+
+	// Output:
+	// // Code generated by tsl_gen. DO NOT EDIT.
+	//
+	// package books
+	//
+	// import (
+	// 	"regexp"
+	// )
+	//
+	// var (
+	// 	bigBooksRegexp0 = regexp.MustCompile("^The (?s:.*)$")
+	// )
+	//
+	// // BigBooks reports whether b matches the TSL filter "spec.pages > 100 AND author = 'Joe' AND title LIKE 'The %'".
+	// func BigBooks(b *Book) bool {
+	// 	return (((float64(b.Spec.Pages) > 100) && (b.Author == "Joe")) && bigBooksRegexp0.MatchString(b.Title))
+	// }
+}
@@ -0,0 +1,594 @@
+package gocode
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// value is the Go code of a TSL expression
+type value struct {
+	// code is the Go expression, numbers are float64 expressions
+	code string
+
+	// kind is the kind of the value, 0 for null
+	kind Kind
+
+	// slice is true for slice fields
+	slice bool
+
+	// guards are the nil checks that must hold before code is evaluated
+	guards []string
+
+	// text is the literal text of a date literal
+	text string
+
+	// constant is true for untyped numeric constants
+	constant bool
+}
+
+// loop binds a slice field to the element variable of an ANY / ALL loop
+type loop struct {
+	identifier string
+	variable   string
+}
+
+// generator compiles a TSL tree into Go code
+type generator struct {
+	s        Struct
+	receiver string
+	prefix   string
+
+	vars    []string
+	imports map[string]bool
+	loops   []loop
+}
+
+// unsupported returns a tsl.UnsupportedError for a node
+func unsupported(n *tsl.TSLNode, reason string) error {
+	return tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: reason}
+}
+
+// addVar adds a package level variable and returns its name
+func (g *generator) addVar(kind string, expr string) string {
+	name := fmt.Sprintf("%s%s%d", g.prefix, kind, len(g.vars))
+	g.vars = append(g.vars, name+" = "+expr)
+	return name
+}
+
+// predicate returns the code of a boolean value with its guards
+func predicate(v value) string {
+	if len(v.guards) == 0 {
+		return v.code
+	}
+	return "(" + strings.Join(v.guards, " && ") + " && " + v.code + ")"
+}
+
+// guarded returns the code of a predicate on guarded values, if negated is
+// true the predicate holds when a value is nil
+func guarded(code string, negated bool, values ...value) value {
+	var guards []string
+	for _, v := range values {
+		guards = append(guards, v.guards...)
+	}
+	if len(guards) == 0 {
+		return value{code: code, kind: Bool}
+	}
+
+	if negated {
+		return value{code: "(!(" + strings.Join(guards, " && ") + ") || " + code + ")", kind: Bool}
+	}
+	return value{code: "(" + strings.Join(guards, " && ") + " && " + code + ")", kind: Bool}
+}
+
+// boolean compiles a node that must be a boolean expression
+func (g *generator) boolean(n *tsl.TSLNode) (string, error) {
+	v, err := g.expr(n)
+	if err != nil {
+		return "", err
+	}
+	if v.kind != Bool || v.slice {
+		return "", tsl.TypeMismatchError{Expected: "boolean", Got: kindName(v)}
+	}
+	return predicate(v), nil
+}
+
+// kindName returns the name of the kind of a value
+func kindName(v value) string {
+	switch {
+	case v.slice:
+		return "slice of " + v.kind.String()
+	case v.kind == 0:
+		return "null"
+	}
+	return v.kind.String()
+}
+
+// expr compiles a node
+func (g *generator) expr(n *tsl.TSLNode) (value, error) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		return g.identifier(n)
+	case tsl.KindNumericLiteral:
+		f, _ := n.AsFloat64()
+		return value{code: strconv.FormatFloat(f, 'g', -1, 64), kind: Float, constant: true}, nil
+	case tsl.KindStringLiteral:
+		s, _ := n.AsString()
+		return value{code: strconv.Quote(s), kind: String}, nil
+	case tsl.KindBooleanLiteral:
+		b, _ := n.AsBool()
+		return value{code: strconv.FormatBool(b), kind: Bool}, nil
+	case tsl.KindDateLiteral:
+		s, _ := n.AsString()
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return value{}, unsupported(n, "invalid date")
+		}
+		return value{code: g.timeVar(t), kind: Time, text: s}, nil
+	case tsl.KindTimestampLiteral:
+		return value{code: g.timeVar(n.Value().(time.Time)), kind: Time}, nil
+	case tsl.KindNullLiteral:
+		return value{code: "nil"}, nil
+	case tsl.KindBinaryExpr:
+		return g.binary(n)
+	case tsl.KindUnaryExpr:
+		return g.unary(n)
+	}
+
+	return value{}, unsupported(n, "arrays are only supported in IN and BETWEEN")
+}
+
+// timeVar adds a package level time variable
+func (g *generator) timeVar(t time.Time) string {
+	g.imports["time"] = true
+	t = t.UTC()
+	return g.addVar("Time", fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, time.UTC)",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond()))
+}
+
+// regexpVar adds a package level compiled regular expression variable
+func (g *generator) regexpVar(pattern string) string {
+	g.imports["regexp"] = true
+	return g.addVar("Regexp", "regexp.MustCompile("+strconv.Quote(pattern)+")")
+}
+
+// identifier compiles a field reference
+func (g *generator) identifier(n *tsl.TSLNode) (value, error) {
+	name := n.Value().(string)
+	field, ok := g.s.Fields[name]
+	if !ok {
+		return value{}, tsl.KeyNotFoundError{Key: name}
+	}
+
+	// A slice field bound to the element of an ANY / ALL loop
+	for i := len(g.loops) - 1; i >= 0; i-- {
+		if g.loops[i].identifier == name {
+			return scalar(g.loops[i].variable, field), nil
+		}
+	}
+
+	code := g.receiver
+	var guards []string
+	for i, step := range field.Path {
+		code += "." + step.Name
+		if step.Pointer {
+			guards = append(guards, code+" != nil")
+			if i == len(field.Path)-1 {
+				code = "(*" + code + ")"
+			}
+		}
+	}
+
+	if field.Slice {
+		return value{code: code, kind: field.Kind, slice: true, guards: guards}, nil
+	}
+
+	v := scalar(code, field)
+	v.guards = guards
+	return v, nil
+}
+
+// scalar returns the value of a field, numbers are converted to float64
+// and named string types to string
+func scalar(code string, field Field) value {
+	switch {
+	case field.Kind == Int || field.Kind == Float:
+		if field.GoType != "float64" {
+			code = "float64(" + code + ")"
+		}
+	case field.Kind == String && field.GoType != "string":
+		code = "string(" + code + ")"
+	case field.Kind == Bool && field.GoType != "bool":
+		code = "bool(" + code + ")"
+	}
+	return value{code: code, kind: field.Kind}
+}
+
+// isNumber returns true for integer and floating point values
+func isNumber(v value) bool {
+	return !v.slice && (v.kind == Int || v.kind == Float)
+}
+
+// scalarOperand compiles an operand that must not be a slice
+func (g *generator) scalarOperand(n, operand *tsl.TSLNode) (value, error) {
+	v, err := g.expr(operand)
+	if err != nil {
+		return value{}, err
+	}
+	if v.slice {
+		return value{}, unsupported(n, "slice fields can only be used in ANY, ALL, LEN and SUM")
+	}
+	return v, nil
+}
+
+// binary compiles a binary expression
+func (g *generator) binary(n *tsl.TSLNode) (value, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpAnd, tsl.OpOr:
+		l, err := g.boolean(op.Left)
+		if err != nil {
+			return value{}, err
+		}
+		r, err := g.boolean(op.Right)
+		if err != nil {
+			return value{}, err
+		}
+		symbol := " && "
+		if op.Operator == tsl.OpOr {
+			symbol = " || "
+		}
+		return value{code: "(" + l + symbol + r + ")", kind: Bool}, nil
+
+	case tsl.OpIn:
+		return g.in(n, op)
+
+	case tsl.OpBetween:
+		return g.between(n, op)
+
+	case tsl.OpIs:
+		if op.Right.Type() != tsl.KindNullLiteral {
+			return value{}, unsupported(n, "IS requires NULL")
+		}
+		l, err := g.expr(op.Left)
+		if err != nil {
+			return value{}, err
+		}
+		switch len(l.guards) {
+		case 0:
+			return value{code: "false", kind: Bool}, nil
+		case 1:
+			return value{code: "(" + strings.TrimSuffix(l.guards[0], " != nil") + " == nil)", kind: Bool}, nil
+		}
+		return value{code: "!(" + strings.Join(l.guards, " && ") + ")", kind: Bool}, nil
+	}
+
+	l, err := g.scalarOperand(n, op.Left)
+	if err != nil {
+		return value{}, err
+	}
+	r, err := g.scalarOperand(n, op.Right)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch op.Operator {
+	case tsl.OpPlus, tsl.OpMinus, tsl.OpStar, tsl.OpSlash, tsl.OpPercent:
+		if !isNumber(l) || !isNumber(r) {
+			return value{}, tsl.TypeMismatchError{Expected: "number", Got: kindName(l) + " and " + kindName(r)}
+		}
+		left := l.code
+		if l.constant && r.constant {
+			// Untyped integer constants would use integer division
+			left = "float64(" + left + ")"
+		}
+		code := "(" + left + " " + arithmeticSymbols[op.Operator] + " " + r.code + ")"
+		if op.Operator == tsl.OpPercent {
+			// The remainder of the integer parts, NaN if the divisor is zero
+			g.imports["math"] = true
+			code = "math.Mod(math.Trunc(" + l.code + "), math.Trunc(" + r.code + "))"
+		}
+		return value{code: code, kind: Float, guards: append(l.guards, r.guards...)}, nil
+
+	case tsl.OpEQ, tsl.OpNE, tsl.OpLT, tsl.OpLE, tsl.OpGT, tsl.OpGE:
+		return g.compare(n, op.Operator, l, r)
+
+	case tsl.OpLike, tsl.OpILike, tsl.OpREQ, tsl.OpRNE:
+		return g.match(n, op, l)
+	}
+
+	return value{}, unsupported(n, op.Operator.String()+" is not supported")
+}
+
+// arithmeticSymbols are the Go operators of TSL arithmetic operators
+var arithmeticSymbols = map[tsl.Operator]string{
+	tsl.OpPlus:  "+",
+	tsl.OpMinus: "-",
+	tsl.OpStar:  "*",
+	tsl.OpSlash: "/",
+}
+
+// comparisonSymbols are the Go operators of TSL comparisons
+var comparisonSymbols = map[tsl.Operator]string{
+	tsl.OpEQ: "==",
+	tsl.OpNE: "!=",
+	tsl.OpLT: "<",
+	tsl.OpLE: "<=",
+	tsl.OpGT: ">",
+	tsl.OpGE: ">=",
+}
+
+// timeComparisons are the time.Time method calls of TSL comparisons
+var timeComparisons = map[tsl.Operator]string{
+	tsl.OpEQ: "%s.Equal(%s)",
+	tsl.OpNE: "!%s.Equal(%s)",
+	tsl.OpLT: "%s.Before(%s)",
+	tsl.OpLE: "!%s.After(%s)",
+	tsl.OpGT: "%s.After(%s)",
+	tsl.OpGE: "!%s.Before(%s)",
+}
+
+// compare compiles a comparison
+func (g *generator) compare(n *tsl.TSLNode, operator tsl.Operator, l, r value) (value, error) {
+	// A date literal compared with a string is a string
+	if l.kind == String && r.text != "" {
+		r = value{code: strconv.Quote(r.text), kind: String}
+	}
+	if r.kind == String && l.text != "" {
+		l = value{code: strconv.Quote(l.text), kind: String}
+	}
+
+	negated := operator == tsl.OpNE
+	symbol := comparisonSymbols[operator]
+
+	switch {
+	case isNumber(l) && isNumber(r), l.kind == String && r.kind == String:
+		return guarded("("+l.code+" "+symbol+" "+r.code+")", negated, l, r), nil
+
+	case l.kind == Time && r.kind == Time:
+		return guarded(fmt.Sprintf(timeComparisons[operator], l.code, r.code), negated, l, r), nil
+
+	case l.kind == Bool && r.kind == Bool && (operator == tsl.OpEQ || operator == tsl.OpNE):
+		return guarded("("+l.code+" "+symbol+" "+r.code+")", negated, l, r), nil
+	}
+
+	if l.kind == 0 || r.kind == 0 {
+		return value{}, unsupported(n, "use IS NULL to compare with null")
+	}
+	return value{}, tsl.TypeMismatchError{Expected: "matching types", Got: kindName(l) + " and " + kindName(r)}
+}
+
+// match compiles LIKE, ILIKE and regular expression operators
+func (g *generator) match(n *tsl.TSLNode, op tsl.TSLExpressionOp, l value) (value, error) {
+	if l.kind != String {
+		return value{}, tsl.TypeMismatchError{Expected: "string", Got: kindName(l)}
+	}
+	pattern, ok := op.Right.AsString()
+	if !ok || op.Right.Type() != tsl.KindStringLiteral {
+		return value{}, unsupported(n, "patterns must be string literals")
+	}
+
+	subject := l.code
+	switch op.Operator {
+	case tsl.OpILike:
+		g.imports["strings"] = true
+		subject = "strings.ToLower(" + subject + ")"
+		pattern = strings.ToLower(pattern)
+		fallthrough
+	case tsl.OpLike:
+		re, err := tsl.LikeToRegexp(pattern)
+		if err != nil {
+			return value{}, err
+		}
+		pattern = re
+	}
+
+	if _, err := regexp.Compile(pattern); err != nil {
+		return value{}, unsupported(n, "invalid regular expression")
+	}
+
+	code := g.regexpVar(pattern) + ".MatchString(" + subject + ")"
+	if op.Operator == tsl.OpRNE {
+		return guarded("!"+code, true, l), nil
+	}
+	return guarded(code, false, l), nil
+}
+
+// literals compiles the values of an array literal
+func (g *generator) literals(n *tsl.TSLNode, array *tsl.TSLNode) ([]value, error) {
+	arr, ok := array.AsArray()
+	if !ok {
+		return nil, unsupported(n, "expected a list of values")
+	}
+
+	values := make([]value, len(arr.Values))
+	for i, item := range arr.Values {
+		v, err := g.scalarOperand(n, item)
+		if err != nil {
+			return nil, err
+		}
+		if len(v.guards) != 0 {
+			return nil, unsupported(n, "list values must not be nullable fields")
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// in compiles an IN expression, list values of another type never match
+func (g *generator) in(n *tsl.TSLNode, op tsl.TSLExpressionOp) (value, error) {
+	l, err := g.scalarOperand(n, op.Left)
+	if err != nil {
+		return value{}, err
+	}
+	values, err := g.literals(n, op.Right)
+	if err != nil {
+		return value{}, err
+	}
+
+	var terms []string
+	for _, v := range values {
+		if l.kind == String && v.text != "" {
+			v = value{code: strconv.Quote(v.text), kind: String}
+		}
+		switch {
+		case isNumber(l) && isNumber(v), l.kind == String && v.kind == String, l.kind == Bool && v.kind == Bool:
+			terms = append(terms, l.code+" == "+v.code)
+		case l.kind == Time && v.kind == Time:
+			terms = append(terms, l.code+".Equal("+v.code+")")
+		}
+	}
+
+	if len(terms) == 0 {
+		return value{code: "false", kind: Bool}, nil
+	}
+	return guarded("("+strings.Join(terms, " || ")+")", false, l), nil
+}
+
+// between compiles a BETWEEN expression
+func (g *generator) between(n *tsl.TSLNode, op tsl.TSLExpressionOp) (value, error) {
+	l, err := g.scalarOperand(n, op.Left)
+	if err != nil {
+		return value{}, err
+	}
+	values, err := g.literals(n, op.Right)
+	if err != nil {
+		return value{}, err
+	}
+	if len(values) != 2 {
+		return value{}, tsl.BetweenOperatorError{Message: "BETWEEN requires exactly two values"}
+	}
+	min, max := values[0], values[1]
+
+	switch {
+	case isNumber(l) && isNumber(min) && isNumber(max):
+		return guarded("("+l.code+" >= "+min.code+" && "+l.code+" <= "+max.code+")", false, l), nil
+	case l.kind == Time && min.kind == Time && max.kind == Time:
+		return guarded("(!"+l.code+".Before("+min.code+") && !"+l.code+".After("+max.code+"))", false, l), nil
+	}
+
+	return value{}, tsl.TypeMismatchError{Expected: "numeric or time values", Got: kindName(l)}
+}
+
+// unary compiles a unary expression
+func (g *generator) unary(n *tsl.TSLNode) (value, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpNot:
+		v, err := g.boolean(op.Right)
+		if err != nil {
+			return value{}, err
+		}
+		return value{code: "!" + v, kind: Bool}, nil
+
+	case tsl.OpUMinus:
+		v, err := g.scalarOperand(n, op.Right)
+		if err != nil {
+			return value{}, err
+		}
+		if !isNumber(v) {
+			return value{}, tsl.TypeMismatchError{Expected: "number", Got: kindName(v)}
+		}
+		if op.Right.Type() == tsl.KindNumericLiteral {
+			return value{code: "-" + v.code, kind: Float, constant: true}, nil
+		}
+		return value{code: "-(" + v.code + ")", kind: Float, guards: v.guards}, nil
+
+	case tsl.OpLen, tsl.OpSum:
+		v, err := g.expr(op.Right)
+		if err != nil {
+			return value{}, err
+		}
+		if !v.slice {
+			return value{}, tsl.TypeMismatchError{Expected: "slice", Got: kindName(v)}
+		}
+		if op.Operator == tsl.OpLen {
+			return value{code: "float64(len(" + v.code + "))", kind: Int, guards: v.guards}, nil
+		}
+		if v.kind != Int && v.kind != Float {
+			return value{}, tsl.TypeMismatchError{Expected: "slice of numbers", Got: kindName(v)}
+		}
+		return value{
+			code:   "func() (sum float64) {\nfor _, item := range " + v.code + " {\nsum += float64(item)\n}\nreturn sum\n}()",
+			kind:   Float,
+			guards: v.guards,
+		}, nil
+
+	case tsl.OpAny, tsl.OpAll:
+		return g.quantifier(n, op)
+	}
+
+	return value{}, unsupported(n, op.Operator.String()+" is not supported")
+}
+
+// quantifier compiles ANY and ALL as a loop over the one slice field of
+// the condition
+func (g *generator) quantifier(n *tsl.TSLNode, op tsl.TSLExpressionOp) (value, error) {
+	identifiers := map[string]bool{}
+	g.sliceIdentifiers(op.Right, identifiers)
+	if len(identifiers) != 1 {
+		return value{}, unsupported(n, "the condition must use exactly one slice field")
+	}
+
+	var identifier string
+	for name := range identifiers {
+		identifier = name
+	}
+
+	slice, err := g.identifier(tsl.NewIdentifier(identifier))
+	if err != nil {
+		return value{}, err
+	}
+
+	variable := "elem"
+	if len(g.loops) > 0 {
+		variable = fmt.Sprintf("elem%d", len(g.loops))
+	}
+	g.loops = append(g.loops, loop{identifier: identifier, variable: variable})
+	condition, err := g.boolean(op.Right)
+	g.loops = g.loops[:len(g.loops)-1]
+	if err != nil {
+		return value{}, err
+	}
+
+	var code string
+	if op.Operator == tsl.OpAny {
+		code = "func() bool {\nfor _, " + variable + " := range " + slice.code + " {\nif " + condition + " {\nreturn true\n}\n}\nreturn false\n}()"
+	} else {
+		code = "func() bool {\nfor _, " + variable + " := range " + slice.code + " {\nif !(" + condition + ") {\nreturn false\n}\n}\nreturn len(" + slice.code + ") > 0\n}()"
+	}
+	return guarded(code, false, slice), nil
+}
+
+// sliceIdentifiers collects the slice fields used in a condition that are
+// not bound to an enclosing loop
+func (g *generator) sliceIdentifiers(n *tsl.TSLNode, identifiers map[string]bool) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		name := n.Value().(string)
+		if field, ok := g.s.Fields[name]; ok && field.Slice {
+			for _, l := range g.loops {
+				if l.identifier == name {
+					return
+				}
+			}
+			identifiers[name] = true
+		}
+	case tsl.KindBinaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		g.sliceIdentifiers(op.Left, identifiers)
+		g.sliceIdentifiers(op.Right, identifiers)
+	case tsl.KindUnaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		// Slices of nested aggregates are not bound to this loop
+		if op.Operator != tsl.OpLen && op.Operator != tsl.OpSum && op.Operator != tsl.OpAny && op.Operator != tsl.OpAll {
+			g.sliceIdentifiers(op.Right, identifiers)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package gocode
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// basicKinds are the kinds of the predeclared Go types
+var basicKinds = map[string]Kind{
+	"string": String,
+	"bool":   Bool,
+	"int":    Int, "int8": Int, "int16": Int, "int32": Int, "int64": Int, "rune": Int,
+	"uint": Int, "uint8": Int, "uint16": Int, "uint32": Int, "uint64": Int, "byte": Int,
+	"float32": Float, "float64": Float,
+}
+
+// source holds the type declarations of a package
+type source struct {
+	types map[string]ast.Expr
+	files map[string]*ast.File
+}
+
+// LoadStruct describes a struct type declared in the Go package in dir,
+// and returns the package name. It reads the source files without
+// compiling them, so it can run from go:generate before the package
+// builds. Fields are matched as in StructOf, types declared in other
+// packages are skipped, except time.Time.
+func LoadStruct(dir, typeName string) (string, Struct, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", Struct{}, err
+	}
+
+	src := source{types: map[string]ast.Expr{}, files: map[string]*ast.File{}}
+	pkg := ""
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", Struct{}, err
+		}
+		pkg = file.Name.Name
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				src.types[typeSpec.Name.Name] = typeSpec.Type
+				src.files[typeSpec.Name.Name] = file
+			}
+		}
+	}
+
+	st, ok := src.types[typeName].(*ast.StructType)
+	if !ok {
+		return "", Struct{}, fmt.Errorf("struct type %s not found in %s", typeName, dir)
+	}
+
+	s := Struct{Name: typeName, Fields: map[string]Field{}}
+	src.addFields(s.Fields, st, src.files[typeName], "", nil)
+	return pkg, s, nil
+}
+
+// addFields adds the fields of a struct type
+func (src source) addFields(fields map[string]Field, st *ast.StructType, file *ast.File, prefix string, path []Step) {
+	for _, f := range st.Fields.List {
+		jsonTag := ""
+		if f.Tag != nil {
+			tag, _ := strconv.Unquote(f.Tag.Value)
+			jsonTag = reflect.StructTag(tag).Get("json")
+		}
+
+		t := f.Type
+		pointer := false
+		if star, ok := t.(*ast.StarExpr); ok {
+			pointer = true
+			t = star.X
+		}
+
+		names := f.Names
+		if len(names) == 0 {
+			// An embedded field is named by its type
+			if ident, ok := t.(*ast.Ident); ok {
+				names = []*ast.Ident{ident}
+			}
+		}
+
+		for _, ident := range names {
+			embedded := len(f.Names) == 0
+			if !ident.IsExported() && !embedded {
+				continue
+			}
+
+			name := fieldName(ident.Name, jsonTag)
+			if name == "-" {
+				continue
+			}
+			stepPath := append(append([]Step{}, path...), Step{Name: ident.Name, Pointer: pointer})
+
+			// Nested and embedded structs
+			if nested, nestedFile, ok := src.structType(t, file); ok {
+				if embedded {
+					src.addFields(fields, nested, nestedFile, prefix, stepPath)
+				} else {
+					src.addFields(fields, nested, nestedFile, prefix+name+".", stepPath)
+				}
+				continue
+			}
+			if !ident.IsExported() {
+				continue
+			}
+
+			field := Field{Path: stepPath}
+			elem := t
+			if array, ok := t.(*ast.ArrayType); ok && array.Len == nil && !pointer {
+				field.Slice = true
+				elem = array.Elt
+			}
+			if field.Kind, field.GoType = src.kind(elem, file); field.Kind == 0 {
+				continue
+			}
+			fields[prefix+name] = field
+		}
+	}
+}
+
+// structType returns the declaration of an anonymous struct type, or of a
+// named struct type of the package
+func (src source) structType(t ast.Expr, file *ast.File) (*ast.StructType, *ast.File, bool) {
+	switch t := t.(type) {
+	case *ast.StructType:
+		return t, file, true
+	case *ast.Ident:
+		st, ok := src.types[t.Name].(*ast.StructType)
+		return st, src.files[t.Name], ok
+	}
+	return nil, nil, false
+}
+
+// kind returns the kind and the Go type of a value type, or 0 if the type
+// is not supported
+func (src source) kind(t ast.Expr, file *ast.File) (Kind, string) {
+	switch t := t.(type) {
+	case *ast.Ident:
+		if kind, ok := basicKinds[t.Name]; ok {
+			return kind, t.Name
+		}
+		// A named type of the package, e.g. type Status string
+		if underlying, ok := src.types[t.Name]; ok {
+			if kind, _ := src.kind(underlying, src.files[t.Name]); kind != 0 {
+				return kind, t.Name
+			}
+		}
+
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && t.Sel.Name == "Time" && importPath(file, pkg.Name) == "time" {
+			return Time, "time.Time"
+		}
+	}
+
+	return 0, ""
+}
+
+// importPath returns the path of the package imported by a file with name
+func importPath(file *ast.File, name string) string {
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil && spec.Name.Name == name {
+			return path
+		}
+		if spec.Name == nil && filepath.Base(path) == name {
+			return path
+		}
+	}
+	return ""
+}
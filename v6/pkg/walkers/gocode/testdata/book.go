@@ -0,0 +1,86 @@
+package main
+
+import "time"
+
+// Status is the loan status of a book
+type Status string
+
+// Audit holds the record history
+type Audit struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+// Spec holds the physical properties of a book
+type Spec struct {
+	Pages  int     `json:"pages"`
+	Weight float64 `json:"weight"`
+}
+
+// Book is the record type the generated predicates filter
+type Book struct {
+	Audit
+
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Price     float64   `json:"price"`
+	Loaned    bool      `json:"loaned"`
+	Status    Status    `json:"status"`
+	Published time.Time `json:"published"`
+	Rating    *float64  `json:"rating"`
+	Spec      Spec      `json:"spec"`
+	Tags      []string  `json:"tags"`
+	Scores    []int     `json:"scores"`
+
+	internal string
+}
+
+func rating(r float64) *float64 {
+	return &r
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+var books = []Book{
+	{
+		Audit:     Audit{CreatedBy: "admin"},
+		ID:        1,
+		Title:     "Dune",
+		Author:    "Frank Herbert",
+		Price:     9.99,
+		Loaned:    true,
+		Status:    "loaned",
+		Published: date(1965, time.August, 1),
+		Rating:    rating(4.5),
+		Spec:      Spec{Pages: 412, Weight: 0.6},
+		Tags:      []string{"classic", "science fiction"},
+		Scores:    []int{5, 4, 5},
+	},
+	{
+		Audit:     Audit{CreatedBy: "joe"},
+		ID:        2,
+		Title:     "The Left Hand of Darkness",
+		Author:    "Ursula K. Le Guin",
+		Price:     14.5,
+		Status:    "available",
+		Published: date(1969, time.March, 1),
+		Spec:      Spec{Pages: 304, Weight: 0.4},
+		Tags:      []string{"classic"},
+		Scores:    []int{},
+	},
+	{
+		Audit:     Audit{CreatedBy: "admin"},
+		ID:        3,
+		Title:     "The Martian",
+		Author:    "Andy Weir",
+		Price:     17.25,
+		Status:    "available",
+		Published: date(2011, time.September, 27),
+		Rating:    rating(5),
+		Spec:      Spec{Pages: 369, Weight: 0.5},
+		Tags:      []string{},
+		Scores:    []int{3, 2},
+	},
+}
@@ -0,0 +1,171 @@
+package gocode
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Kind is the kind of value a struct field holds
+type Kind int
+
+const (
+	// String is a string field
+	String Kind = iota + 1
+	// Int is a signed or unsigned integer field
+	Int
+	// Float is a floating point field
+	Float
+	// Bool is a boolean field
+	Bool
+	// Time is a time.Time field
+	Time
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case String:
+		return "string"
+	case Int:
+		return "integer"
+	case Float:
+		return "number"
+	case Bool:
+		return "boolean"
+	case Time:
+		return "time"
+	}
+	return "unknown"
+}
+
+// Step is one field selector on the path from the receiver to a field
+type Step struct {
+	// Name is the Go field name
+	Name string
+
+	// Pointer is true if the field is a pointer
+	Pointer bool
+}
+
+// Field is a struct field a TSL identifier refers to
+type Field struct {
+	// Path are the field selectors from the receiver, e.g. Spec, Pages
+	Path []Step
+
+	// Kind is the kind of the field value, or of the slice elements
+	Kind Kind
+
+	// Slice is true for slice fields
+	Slice bool
+
+	// GoType is the Go type of the value, or of the slice elements
+	GoType string
+}
+
+// Struct describes the fields of a Go struct type
+type Struct struct {
+	// Name is the Go type name
+	Name string
+
+	// Fields maps TSL identifiers to struct fields
+	Fields map[string]Field
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// StructOf describes a struct type using reflection, t may also be a
+// pointer to a struct type.
+//
+// Struct fields are matched to TSL identifiers using the `json` tag or the
+// lower case field name, in that order. Nested structs match dotted names,
+// for example the field Pages of a struct field tagged `json:"spec"`
+// matches the identifier "spec.pages", and the fields of embedded structs
+// are promoted. Fields of other types are skipped.
+func StructOf(t reflect.Type) Struct {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	s := Struct{Name: t.Name(), Fields: map[string]Field{}}
+	if t.Kind() == reflect.Struct {
+		addReflectFields(s.Fields, t, "", nil)
+	}
+	return s
+}
+
+// addReflectFields adds the fields of a struct type
+func addReflectFields(fields map[string]Field, t reflect.Type, prefix string, path []Step) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		name := fieldName(f.Name, f.Tag.Get("json"))
+		if name == "-" {
+			continue
+		}
+
+		ft := f.Type
+		step := Step{Name: f.Name}
+		if ft.Kind() == reflect.Pointer {
+			step.Pointer = true
+			ft = ft.Elem()
+		}
+		stepPath := append(append([]Step{}, path...), step)
+
+		// Nested and embedded structs, the fields of unexported embedded
+		// structs are promoted as in encoding/json
+		if ft.Kind() == reflect.Struct && ft != timeType {
+			if f.Anonymous {
+				addReflectFields(fields, ft, prefix, stepPath)
+			} else {
+				addReflectFields(fields, ft, prefix+name+".", stepPath)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		field := Field{Path: stepPath}
+		if ft.Kind() == reflect.Slice && !step.Pointer {
+			field.Slice = true
+			ft = ft.Elem()
+		}
+		if field.Kind = reflectKind(ft); field.Kind == 0 {
+			continue
+		}
+		field.GoType = ft.String()
+		fields[prefix+name] = field
+	}
+}
+
+// reflectKind returns the kind of a value type, or 0 if it is not supported
+func reflectKind(t reflect.Type) Kind {
+	if t == timeType {
+		return Time
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return String
+	case reflect.Bool:
+		return Bool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Int
+	case reflect.Float32, reflect.Float64:
+		return Float
+	}
+	return 0
+}
+
+// fieldName returns the TSL name of a struct field, or "-" to skip it
+func fieldName(goName, jsonTag string) string {
+	if name := strings.Split(jsonTag, ",")[0]; name != "" {
+		return name
+	}
+	return strings.ToLower(goName)
+}
@@ -0,0 +1,166 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gocode compiles TSL trees into typed Go predicates.
+package gocode
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// target names generated Go code in unsupported expression errors
+const target = "go code"
+
+// Func is the Go source of a predicate function
+type Func struct {
+	// Name is the function name
+	Name string
+
+	// Source is the function declaration
+	Source string
+
+	// Vars are the package level variables the function uses, e.g. the
+	// compiled regular expressions, as "name = expression"
+	Vars []string
+
+	// Imports are the import paths of the packages the function uses
+	Imports []string
+}
+
+// Walk travel the TSL tree to create a Go predicate function named name,
+// that reports whether a struct of type s matches the tree.
+//
+//	pkg, book, _ := gocode.LoadStruct(".", "Book")
+//	f, _ := gocode.Walk(tree, "BigBooks", book)
+//	src, _ := gocode.File(pkg, f)
+//
+//	// func BigBooks(b *Book) bool {
+//	//	return (float64(b.Spec.Pages) > 100 && b.Author == "Joe")
+//	// }
+//
+// The function reads the struct fields directly, without reflection or
+// interface{} values. Numbers are compared as float64, LIKE, ILIKE and
+// regular expressions use package level compiled regular expressions, and
+// ANY / ALL loop over the one slice field used in their condition. As in
+// the semantics walker, comparisons on nil pointer fields are false,
+// except != and ~! that are true, and IS NULL checks for nil pointers.
+// Division by zero follows the float64 rules instead of failing.
+//
+// Identifiers must be fields of s, and the types of the compared values
+// must match, other trees return a KeyNotFoundError, TypeMismatchError or
+// tsl.UnsupportedError.
+func Walk(n *tsl.TSLNode, name string, s Struct) (Func, error) {
+	if !isIdentifier(name) {
+		return Func{}, fmt.Errorf("invalid function name %q", name)
+	}
+
+	g := &generator{
+		s:        s,
+		receiver: receiverName(s.Name),
+		prefix:   lowerFirst(name),
+		imports:  map[string]bool{},
+	}
+
+	body, err := g.boolean(n)
+	if err != nil {
+		return Func{}, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s reports whether %s matches the TSL filter %s.\n", name, g.receiver, strconv.Quote(n.String()))
+	fmt.Fprintf(&b, "func %s(%s *%s) bool {\nreturn %s\n}\n", name, g.receiver, s.Name, body)
+
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+
+	return Func{Name: name, Source: b.String(), Vars: g.vars, Imports: imports}, nil
+}
+
+// File creates a formatted Go source file of package pkg holding the
+// functions.
+func File(pkg string, funcs ...Func) ([]byte, error) {
+	imports := map[string]bool{}
+	var vars []string
+	for _, f := range funcs {
+		for _, path := range f.Imports {
+			imports[path] = true
+		}
+		vars = append(vars, f.Vars...)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by tsl_gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for path := range imports {
+			paths = append(paths, strconv.Quote(path))
+		}
+		sort.Strings(paths)
+		fmt.Fprintf(&b, "import (\n%s\n)\n\n", strings.Join(paths, "\n"))
+	}
+
+	if len(vars) > 0 {
+		fmt.Fprintf(&b, "var (\n%s\n)\n\n", strings.Join(vars, "\n"))
+	}
+
+	for _, f := range funcs {
+		b.WriteString(f.Source + "\n")
+	}
+
+	return format.Source(b.Bytes())
+}
+
+// receiverName returns the receiver variable name of a type, the lower
+// case first letter of the type name
+func receiverName(typeName string) string {
+	for _, r := range typeName {
+		if unicode.IsLetter(r) {
+			return string(unicode.ToLower(r))
+		}
+		break
+	}
+	return "r"
+}
+
+// lowerFirst returns s with a lower case first letter
+func lowerFirst(s string) string {
+	for i, r := range s {
+		return string(unicode.ToLower(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+// isIdentifier returns true if s is a Go identifier
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,325 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gocode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+)
+
+func TestGoCodeWalker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Go code walker")
+}
+
+// filters are compiled into Go functions and compared with the semantics
+// walker on the books of testdata/book.go
+var filters = []string{
+	"title = 'Dune'",
+	"spec.pages > 350",
+	"spec.pages > 350 and author = 'Frank Herbert'",
+	"(title = 'Dune' or spec.pages < 350) and not loaned",
+	"loaned = true",
+	"loaned != false or id = 3",
+	"id in [1, 3]",
+	"author in ['Andy Weir', 'Ursula K. Le Guin']",
+	"author not in []",
+	"price * 2 < 30",
+	"spec.pages % 7 = 6",
+	"spec.pages / 4 >= 92",
+	"-spec.pages < -400",
+	"price + spec.weight between 10 and 15",
+	"price not between 9 and 15",
+	"title like 'The %'",
+	"title not like '%a%'",
+	"title ilike '%DARK%'",
+	"title ~= '^D'",
+	"title ~! 'e'",
+	"status = 'available'",
+	"createdBy = 'admin'",
+	"title < 'M'",
+	"published > 1968-01-01",
+	"published <= 1969-03-01",
+	"published = '1969-03-01T00:00:00Z'",
+	"published between 1960-01-01 and 2000-01-01T00:00:00Z",
+	"rating > 4",
+	"rating = 5",
+	"rating != 5",
+	"rating is null",
+	"rating is not null",
+	"len tags > 1",
+	"len scores = 0",
+	"sum scores >= 5",
+	"any (tags = 'classic')",
+	"any (tags like '%fiction')",
+	"all (scores >= 3)",
+	"not all (scores > 2)",
+}
+
+// program prints the books as JSON, and the result of each generated
+// function on each book
+const program = `package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+func main() {
+	results := [][]bool{}
+	for _, f := range filters {
+		var row []bool
+		for i := range books {
+			row = append(row, f(&books[i]))
+		}
+		results = append(results, row)
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(map[string]interface{}{"books": books, "results": results})
+}
+`
+
+// flatten adds the values of a JSON object using dotted keys
+func flatten(record map[string]interface{}, prefix string, object map[string]interface{}) {
+	for key, value := range object {
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(record, prefix+key+".", nested)
+			continue
+		}
+		record[prefix+key] = value
+	}
+}
+
+var _ = Describe("Walk", func() {
+	It("Generates functions that agree with the semantics walker", func() {
+		if _, err := exec.LookPath("go"); err != nil {
+			Skip("the go command is not available")
+		}
+
+		pkg, book, err := LoadStruct("testdata", "Book")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pkg).To(Equal("main"))
+
+		funcs := make([]Func, len(filters))
+		names := make([]string, len(filters))
+		for i, filter := range filters {
+			tree, err := tsl.ParseTSL(filter)
+			Expect(err).ToNot(HaveOccurred())
+
+			names[i] = fmt.Sprintf("Filter%d", i)
+			funcs[i], err = Walk(tree, names[i], book)
+			Expect(err).ToNot(HaveOccurred(), filter)
+		}
+		funcs = append(funcs, Func{
+			Name:   "filters",
+			Source: "var filters = []func(*Book) bool{" + strings.Join(names, ", ") + "}\n",
+		})
+
+		src, err := File(pkg, funcs...)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Build and run a program using the generated functions
+		dir := GinkgoT().TempDir()
+		testdata, err := os.ReadFile(filepath.Join("testdata", "book.go"))
+		Expect(err).ToNot(HaveOccurred())
+		files := map[string]string{
+			"go.mod":       "module gocodetest\n\ngo 1.23\n",
+			"book.go":      string(testdata),
+			"generated.go": string(src),
+			"main.go":      program,
+		}
+		for name, content := range files {
+			Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)).To(Succeed())
+		}
+
+		cmd := exec.Command("go", "run", ".")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local", "GOFLAGS=")
+		out, err := cmd.CombinedOutput()
+		Expect(err).ToNot(HaveOccurred(), string(out))
+
+		var output struct {
+			Books   []map[string]interface{} `json:"books"`
+			Results [][]bool                 `json:"results"`
+		}
+		Expect(json.Unmarshal(out, &output)).To(Succeed())
+		Expect(output.Results).To(HaveLen(len(filters)))
+
+		// Compare with the semantics walker on the JSON records
+		for i, filter := range filters {
+			tree, err := tsl.ParseTSL(filter)
+			Expect(err).ToNot(HaveOccurred())
+
+			for j, object := range output.Books {
+				record := map[string]interface{}{}
+				flatten(record, "", object)
+				eval := func(name string) (interface{}, bool) {
+					value, ok := record[name]
+					return value, ok
+				}
+
+				expected, err := semantics.Walk(tree, eval)
+				Expect(err).ToNot(HaveOccurred(), filter)
+				Expect(output.Results[i][j]).To(Equal(expected), "%s on book %d", filter, j)
+			}
+		}
+	})
+
+	DescribeTable("Generates the expected function",
+		func(input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, book, err := LoadStruct("testdata", "Book")
+			Expect(err).ToNot(HaveOccurred())
+
+			f, err := Walk(tree, "Match", book)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Source).To(ContainSubstring("return " + expected + "\n"))
+		},
+
+		Entry("string comparison", "title = 'Dune'", `(b.Title == "Dune")`),
+		Entry("integer field", "spec.pages > 100", `(float64(b.Spec.Pages) > 100)`),
+		Entry("float field", "price <= 10", `(b.Price <= 10)`),
+		Entry("named string type", "status != 'loaned'", `(string(b.Status) != "loaned")`),
+		Entry("embedded field", "createdBy = 'joe'", `(b.Audit.CreatedBy == "joe")`),
+		Entry("logical operators", "loaned and not (price > 10)", `(b.Loaned && !(b.Price > 10))`),
+		Entry("pointer field", "rating > 4", `(b.Rating != nil && ((*b.Rating) > 4))`),
+		Entry("negated pointer field", "rating != 4", `(!(b.Rating != nil) || ((*b.Rating) != 4))`),
+		Entry("is null", "rating is null", `(b.Rating == nil)`),
+		Entry("is null on a value field", "title is null", `false`),
+		Entry("in", "id in [1, 2]", `(float64(b.ID) == 1 || float64(b.ID) == 2)`),
+		Entry("empty in", "id in []", `false`),
+		Entry("between", "price between 1 and 2", `(b.Price >= 1 && b.Price <= 2)`),
+		Entry("time comparison", "published < 2000-01-01", `b.Published.Before(matchTime0)`),
+		Entry("like", "title like 'D%'", `matchRegexp0.MatchString(b.Title)`),
+		Entry("len", "len tags = 2", `(float64(len(b.Tags)) == 2)`),
+		Entry("constant arithmetic", "price > 7 / 2", `(b.Price > (float64(7) / 2))`),
+		Entry("remainder", "id % 2 = 1", `(math.Mod(math.Trunc(float64(b.ID)), math.Trunc(2)) == 1)`),
+	)
+
+	DescribeTable("Fails on unsupported filters",
+		func(input string, expected error) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, book, err := LoadStruct("testdata", "Book")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Walk(tree, "Match", book)
+			Expect(err).To(HaveOccurred())
+			Expect(reflect.TypeOf(err)).To(Equal(reflect.TypeOf(expected)))
+		},
+
+		Entry("unknown field", "isbn = '123'", tsl.KeyNotFoundError{}),
+		Entry("unexported field", "internal = 'x'", tsl.KeyNotFoundError{}),
+		Entry("type mismatch", "title > 5", tsl.TypeMismatchError{}),
+		Entry("not a boolean", "price + 1", tsl.TypeMismatchError{}),
+		Entry("ordered booleans", "loaned > true", tsl.TypeMismatchError{}),
+		Entry("like on a number", "price like '1%'", tsl.TypeMismatchError{}),
+		Entry("slice outside of a loop", "tags = 'classic'", tsl.UnsupportedError{}),
+		Entry("loop over two slices", "any (tags = 'a' and scores > 1)", tsl.UnsupportedError{}),
+		Entry("full-text search", "title match 'dune'", tsl.UnsupportedError{}),
+		Entry("pattern field", "title like author", tsl.UnsupportedError{}),
+	)
+
+	It("Rejects invalid function names", func() {
+		tree, err := tsl.ParseTSL("title = 'Dune'")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = Walk(tree, "big books", Struct{Name: "Book"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("File", func() {
+	It("Declares the imports and variables of the functions", func() {
+		tree, err := tsl.ParseTSL("title ilike 'd%' and published > 2000-01-01")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, book, err := LoadStruct("testdata", "Book")
+		Expect(err).ToNot(HaveOccurred())
+
+		f, err := Walk(tree, "Recent", book)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Imports).To(Equal([]string{"regexp", "strings", "time"}))
+		Expect(f.Vars).To(HaveLen(2))
+
+		src, err := File("books", f)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(src)).To(HavePrefix("// Code generated by tsl_gen. DO NOT EDIT.\n\npackage books\n"))
+		Expect(string(src)).To(ContainSubstring("time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)"))
+		Expect(string(src)).To(ContainSubstring("func Recent(b *Book) bool {"))
+	})
+})
+
+// reflectBook mirrors the Book type of testdata/book.go
+type reflectBook struct {
+	reflectAudit
+
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Price     float64   `json:"price"`
+	Loaned    bool      `json:"loaned"`
+	Status    string    `json:"status"`
+	Published time.Time `json:"published"`
+	Rating    *float64  `json:"rating"`
+	Spec      struct {
+		Pages  int     `json:"pages"`
+		Weight float64 `json:"weight"`
+	} `json:"spec"`
+	Tags   []string `json:"tags"`
+	Scores []int    `json:"scores"`
+
+	internal string
+}
+
+type reflectAudit struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+var _ = Describe("StructOf", func() {
+	It("Finds the fields found in the source", func() {
+		_, book, err := LoadStruct("testdata", "Book")
+		Expect(err).ToNot(HaveOccurred())
+
+		s := StructOf(reflect.TypeOf(&reflectBook{}))
+		Expect(s.Name).To(Equal("reflectBook"))
+		Expect(s.Fields).To(HaveLen(len(book.Fields)))
+		for name, field := range book.Fields {
+			Expect(s.Fields).To(HaveKey(name))
+			Expect(s.Fields[name].Kind).To(Equal(field.Kind), name)
+			Expect(s.Fields[name].Slice).To(Equal(field.Slice), name)
+		}
+		Expect(s.Fields["rating"].Path).To(Equal([]Step{{Name: "Rating", Pointer: true}}))
+		Expect(s.Fields["spec.pages"].Path).To(Equal([]Step{{Name: "Spec"}, {Name: "Pages"}}))
+		Expect(s.Fields["createdBy"].Path).To(Equal([]Step{{Name: "reflectAudit"}, {Name: "CreatedBy"}}))
+	})
+})
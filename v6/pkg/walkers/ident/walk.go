@@ -17,16 +17,14 @@
 package ident
 
 import (
-	"fmt"
-
 	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
 )
 
 // Walk traverses the TSL tree and replaces identifiers using the check function.
 //
 // Users can call the Walk method to check and replace identifiers.
-// The function returns the modified tree, a list of all identifiers found in the tree,
-// and any error encountered.
+// The function returns the modified tree and any error encountered, use the
+// inspect package to list the identifiers of a tree and how they are used.
 //
 // Example:
 //
@@ -58,18 +56,13 @@ func Walk(n *tsl.TSLNode, check func(s string) (string, error)) (*tsl.TSLNode, e
 		return nil, nil
 	}
 
-	// Create a deep copy of the input tree to avoid mutations
-	treeCopy := n.Clone()
-	if treeCopy == nil {
-		return nil, fmt.Errorf("failed to clone input tree")
-	}
-
-	newTree, err := walkAndReplace(treeCopy, check)
-	if err != nil {
-		return nil, err
-	}
-
-	return newTree, nil
+	// Rewrite copies the changed nodes, so the input tree is not mutated
+	return tsl.Rewrite(n, func(n *tsl.TSLNode) (*tsl.TSLNode, error) {
+		if n.Type() != tsl.KindIdentifier {
+			return n, nil
+		}
+		return processIdentifier(n, check)
+	})
 }
 
 // processIdentifier handles the common logic for processing identifier nodes
@@ -83,89 +76,3 @@ func processIdentifier(n *tsl.TSLNode, check func(s string) (string, error)) (*t
 
 	return tsl.ParseTSL(newIdent)
 }
-
-func walkAndReplace(n *tsl.TSLNode, check func(s string) (string, error)) (*tsl.TSLNode, error) {
-	if n == nil {
-		return nil, nil
-	}
-
-	switch n.Type() {
-	case tsl.KindBinaryExpr:
-		op := n.Value().(tsl.TSLExpressionOp)
-
-		// Process both sides of the binary expression
-		var processedLeft, processedRight *tsl.TSLNode
-		var err error
-
-		if op.Left.Type() == tsl.KindIdentifier {
-			processedLeft, err = processIdentifier(op.Left, check)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			processedLeft, err = walkAndReplace(op.Left, check)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		if op.Right.Type() == tsl.KindIdentifier {
-			processedRight, err = processIdentifier(op.Right, check)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			processedRight, err = walkAndReplace(op.Right, check)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		// Update the binary expression with the processed children
-		n.SetLeft(processedLeft)
-		n.SetRight(processedRight)
-		return n, nil
-
-	case tsl.KindUnaryExpr:
-		op := n.Value().(tsl.TSLExpressionOp)
-
-		// Process the operand
-		var processedRight *tsl.TSLNode
-		var err error
-
-		if op.Right.Type() == tsl.KindIdentifier {
-			processedRight, err = processIdentifier(op.Right, check)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			processedRight, err = walkAndReplace(op.Right, check)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		// Update the unary expression with the processed child
-		n.SetRight(processedRight)
-		return n, nil
-
-	case tsl.KindIdentifier:
-		return processIdentifier(n, check)
-
-	case tsl.KindArrayLiteral:
-		arr := n.Value().(tsl.TSLArrayLiteral)
-		newValues := make([]*tsl.TSLNode, len(arr.Values))
-		for i, child := range arr.Values {
-			processed, err := walkAndReplace(child, check)
-			if err != nil {
-				return nil, err
-			}
-			newValues[i] = processed
-		}
-		n.SetArrayValues(newValues)
-		return n, nil
-
-	default:
-		return n, nil
-	}
-}
@@ -0,0 +1,205 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inspect reports how a TSL tree uses its identifiers.
+package inspect
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Field describes how a tree uses one identifier.
+type Field struct {
+	// Name is the identifier
+	Name string
+
+	// Operators are the operators applied to the identifier, between it
+	// and the AND, OR or NOT expression holding it, sorted
+	Operators []tsl.Operator
+
+	// LiteralTypes are the kinds of the literals the identifier is
+	// compared with, sorted, array literals report the kinds of their values
+	LiteralTypes []tsl.Kind
+
+	// Negated is true if the identifier appears under NOT
+	Negated bool
+
+	// InOr is true if the identifier appears under OR
+	InOr bool
+
+	// Wildcards are the array paths the identifier iterates with a "[*]"
+	// suffix, "items[*].tags[*]" iterates "items" and "items[*].tags"
+	Wildcards []string
+
+	// Positions are the positions of the identifier in the input phrase
+	Positions []int
+}
+
+// inspector collects the fields of a tree
+type inspector struct {
+	fields map[string]*Field
+}
+
+// context is the logical context of an expression
+type context struct {
+	negated, inOr bool
+}
+
+// Walk returns the identifiers of a tree and how they are used, sorted by
+// name.
+//
+//	tree, _ := tsl.ParseTSL("author = 'Joe' or not (pages > 100 and items[*].tag in ['a', 1])")
+//	fields, err := inspect.Walk(tree)
+//	// author:       Operators [EQ], LiteralTypes [STRING], InOr
+//	// items[*].tag: Operators [IN], LiteralTypes [NUMBER STRING], Negated, InOr, Wildcards [items]
+//	// pages:        Operators [GT], LiteralTypes [NUMBER], Negated, InOr
+//
+// Operators are collected on the path from the identifier to the nearest
+// AND, OR or NOT expression, in "len tags > 2" the identifier tags has
+// the operators LEN and GT. A boolean identifier used as a condition has
+// no operators.
+func Walk(n *tsl.TSLNode) ([]Field, error) {
+	if n == nil {
+		return nil, nil
+	}
+
+	i := inspector{fields: map[string]*Field{}}
+	i.expression(n, context{})
+
+	names := make([]string, 0, len(i.fields))
+	for name := range i.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]Field, len(names))
+	for j, name := range names {
+		f := i.fields[name]
+		sort.Slice(f.Operators, func(a, b int) bool { return f.Operators[a] < f.Operators[b] })
+		sort.Slice(f.LiteralTypes, func(a, b int) bool { return f.LiteralTypes[a] < f.LiteralTypes[b] })
+		sort.Ints(f.Positions)
+		fields[j] = *f
+	}
+	return fields, nil
+}
+
+// expression inspects a boolean expression
+func (i inspector) expression(n *tsl.TSLNode, ctx context) {
+	op, ok := n.AsExprOp()
+	if ok {
+		switch op.Operator {
+		case tsl.OpNot:
+			i.expression(op.Right, context{negated: true, inOr: ctx.inOr})
+			return
+		case tsl.OpAnd, tsl.OpOr:
+			inner := context{negated: ctx.negated, inOr: ctx.inOr || op.Operator == tsl.OpOr}
+			i.expression(op.Left, inner)
+			i.expression(op.Right, inner)
+			return
+		}
+	}
+
+	kinds := literalKinds(n)
+	i.operand(n, nil, kinds, ctx)
+}
+
+// operand records the identifiers of a comparison, with the operators on
+// their path from the comparison
+func (i inspector) operand(n *tsl.TSLNode, path []tsl.Operator, kinds []tsl.Kind, ctx context) {
+	if n.Type() == tsl.KindIdentifier {
+		f := i.field(n.Value().(string))
+		for _, op := range path {
+			f.Operators = appendOperator(f.Operators, op)
+		}
+		for _, kind := range kinds {
+			f.LiteralTypes = appendKind(f.LiteralTypes, kind)
+		}
+		f.Negated = f.Negated || ctx.negated
+		f.InOr = f.InOr || ctx.inOr
+		f.Positions = append(f.Positions, n.Position())
+		return
+	}
+
+	if op, ok := n.AsExprOp(); ok {
+		path = append(path[:len(path):len(path)], op.Operator)
+	}
+	for _, child := range n.Children() {
+		i.operand(child, path, kinds, ctx)
+	}
+}
+
+// field returns the description of an identifier
+func (i inspector) field(name string) *Field {
+	f, ok := i.fields[name]
+	if !ok {
+		f = &Field{Name: name, Wildcards: wildcards(name)}
+		i.fields[name] = f
+	}
+	return f
+}
+
+// literalKinds returns the kinds of the literals of a comparison, values
+// of array literals are reported instead of the array
+func literalKinds(n *tsl.TSLNode) []tsl.Kind {
+	var kinds []tsl.Kind
+	_ = tsl.Walk(n, tsl.Visitor{
+		Pre: func(n *tsl.TSLNode) (bool, error) {
+			switch n.Type() {
+			case tsl.KindNumericLiteral, tsl.KindStringLiteral, tsl.KindBooleanLiteral,
+				tsl.KindDateLiteral, tsl.KindTimestampLiteral, tsl.KindNullLiteral:
+				kinds = appendKind(kinds, n.Type())
+			}
+			return true, nil
+		},
+	})
+	return kinds
+}
+
+// wildcards returns the array paths a name iterates
+func wildcards(name string) []string {
+	var paths []string
+	for i := strings.Index(name, "[*]"); i >= 0; {
+		paths = append(paths, name[:i])
+		next := strings.Index(name[i+3:], "[*]")
+		if next < 0 {
+			break
+		}
+		i += 3 + next
+	}
+	return paths
+}
+
+// appendOperator appends an operator that is not in a list
+func appendOperator(operators []tsl.Operator, op tsl.Operator) []tsl.Operator {
+	for _, o := range operators {
+		if o == op {
+			return operators
+		}
+	}
+	return append(operators, op)
+}
+
+// appendKind appends a kind that is not in a list
+func appendKind(kinds []tsl.Kind, kind tsl.Kind) []tsl.Kind {
+	for _, k := range kinds {
+		if k == kind {
+			return kinds
+		}
+	}
+	return append(kinds, kind)
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestInspectWalker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Inspect walker")
+}
+
+// describe returns a short description of a field
+func describe(f Field) string {
+	parts := []string{f.Name, fmt.Sprint(f.Operators), fmt.Sprint(f.LiteralTypes)}
+	if f.Negated {
+		parts = append(parts, "negated")
+	}
+	if f.InOr {
+		parts = append(parts, "in or")
+	}
+	if len(f.Wildcards) > 0 {
+		parts = append(parts, fmt.Sprint(f.Wildcards))
+	}
+	return strings.Join(parts, " ")
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Reports the identifiers",
+		func(input string, expected ...string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			fields, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+
+			descriptions := make([]string, len(fields))
+			for i, f := range fields {
+				descriptions[i] = describe(f)
+			}
+			Expect(descriptions).To(Equal(expected))
+		},
+		Entry("comparison", "author = 'Joe'", "author [EQ] [STRING]"),
+		Entry("sorted by name", "title like 'A%' and author = 'Joe'", "author [EQ] [STRING]", "title [LIKE] [STRING]"),
+		Entry("merged uses", "pages > 10 and pages in [1, 'x'] and pages is null",
+			"pages [GT IN IS] [NUMBER STRING NULL]"),
+		Entry("negated", "not (a = 1) and b != 2", "a [EQ] [NUMBER] negated", "b [NE] [NUMBER]"),
+		Entry("negated operators", "a not in [1] and b not like 'x%'",
+			"a [IN] [NUMBER] negated", "b [LIKE] [STRING] negated"),
+		Entry("or", "a = 1 or (b = true and c = 2020-01-01)",
+			"a [EQ] [NUMBER] in or", "b [EQ] [BOOLEAN] in or", "c [EQ] [DATE] in or"),
+		Entry("arithmetic", "a + b * 2 > -1", "a [GT ADD] [NUMBER]", "b [GT ADD MUL] [NUMBER]"),
+		Entry("array operators", "any (tags = 'x') and len items > 2",
+			"items [GT LEN] [NUMBER]", "tags [EQ ANY] [STRING]"),
+		Entry("identifiers compared", "a < b", "a [LT] []", "b [LT] []"),
+		Entry("boolean identifier", "loaned or not archived", "archived [] [] negated in or", "loaned [] [] in or"),
+		Entry("wildcards", "items[*].tags[*].name = 'a' and nodes[0].name = 'b'",
+			"items[*].tags[*].name [EQ] [STRING] [items items[*].tags]", "nodes[0].name [EQ] [STRING]"),
+	)
+
+	It("Reports the positions of the identifiers", func() {
+		tree, err := tsl.ParseTSL("a = 1 and b = 2 or a = 3")
+		Expect(err).ToNot(HaveOccurred())
+
+		fields, err := Walk(tree)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fields[0].Positions).To(Equal([]int{0, 19}))
+		Expect(fields[1].Positions).To(Equal([]int{10}))
+	})
+
+	It("Accepts nil trees", func() {
+		fields, err := Walk(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fields).To(BeEmpty())
+	})
+})
@@ -0,0 +1,51 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonlogic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the jsonlogic package.
+func Example() {
+	// Set a TSL input string.
+	input := "name = 'joe' AND age BETWEEN 20 AND 30"
+
+	// Parse input string into a TSL tree.
+	tree, _ := tsl.ParseTSL(input)
+
+	// Create the JSONLogic rule.
+	rule, _ := Walk(tree)
+
+	// Print the rule, JSONLogic operators are not HTML escaped.
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(rule)
+	fmt.Print(data.String())
+
+	// Parse the rule back into a TSL tree.
+	parsed, _ := ParseJSON(data.Bytes())
+	fmt.Println(parsed)
+
+	// Output:
+	// {"and":[{"==":[{"var":"name"},"joe"]},{"<=":[20,{"var":"age"},30]}]}
+	// name = 'joe' AND age BETWEEN 20 AND 30
+}
@@ -0,0 +1,357 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// comparisons maps JSONLogic comparison operations to TSL operators
+var comparisons = map[string]tsl.Operator{
+	"==":  tsl.OpEQ,
+	"===": tsl.OpEQ,
+	"!=":  tsl.OpNE,
+	"!==": tsl.OpNE,
+	"<":   tsl.OpLT,
+	"<=":  tsl.OpLE,
+	">":   tsl.OpGT,
+	">=":  tsl.OpGE,
+}
+
+// arithmetic maps JSONLogic arithmetic operations to TSL operators
+var arithmetic = map[string]tsl.Operator{
+	"+": tsl.OpPlus,
+	"-": tsl.OpMinus,
+	"*": tsl.OpStar,
+	"/": tsl.OpSlash,
+	"%": tsl.OpPercent,
+}
+
+// identifierPattern matches var paths that can be written as TSL identifiers
+var identifierPattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_./\[\]]*$`)
+
+// pathIndexPattern matches numeric path segments, e.g. ".0"
+var pathIndexPattern = regexp.MustCompile(`\.([0-9]+)(\.|$)`)
+
+// ParseJSON parses a JSONLogic rule in JSON format into a TSL tree.
+func ParseJSON(data []byte) (*tsl.TSLNode, error) {
+	var rule interface{}
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return nil, err
+	}
+	return Parse(rule)
+}
+
+// Parse converts a decoded JSONLogic rule into a TSL tree.
+//
+// Supported operations are the comparisons (== and === are both =), the
+// three argument < and <= (between), and, or, !, in with a list, the
+// arithmetic operations, var with a path and no default, some, all and
+// none with a comparison of the array elements, and reduce adding the
+// array values (SUM). Comparisons with null become IS [NOT] NULL. Other
+// operations return a tsl.UnsupportedError.
+//
+//	tree, err := jsonlogic.ParseJSON([]byte(`{"and": [{"==": [{"var": "a"}, 1]}]}`))
+//	fmt.Println(tree) // a = 1
+func Parse(rule interface{}) (*tsl.TSLNode, error) {
+	p := &parser{}
+	return p.parse(rule)
+}
+
+// parser converts JSONLogic rules, element holds the array identifier
+// while parsing the predicate of an array operation
+type parser struct {
+	element string
+}
+
+func (p *parser) parse(rule interface{}) (*tsl.TSLNode, error) {
+	switch v := rule.(type) {
+	case nil:
+		return tsl.NewNullLiteral(), nil
+	case bool:
+		return tsl.NewBooleanLiteral(v), nil
+	case string:
+		return tsl.NewStringLiteral(v), nil
+	case float64:
+		return number(v), nil
+	case int:
+		return number(float64(v)), nil
+	case int64:
+		return number(float64(v)), nil
+	case []interface{}:
+		values, err := p.parseAll(v)
+		if err != nil {
+			return nil, err
+		}
+		return tsl.NewArrayLiteral(values...), nil
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return nil, unsupported(rule, "a rule must have one operation")
+		}
+		for name, args := range v {
+			return p.operation(rule, name, arguments(args))
+		}
+	}
+
+	return nil, unsupported(rule, fmt.Sprintf("unexpected %T value", rule))
+}
+
+// parseAll parses a list of rules
+func (p *parser) parseAll(rules []interface{}) ([]*tsl.TSLNode, error) {
+	nodes := make([]*tsl.TSLNode, len(rules))
+	for i, rule := range rules {
+		node, err := p.parse(rule)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node
+	}
+	return nodes, nil
+}
+
+// arguments returns the arguments of an operation, a single argument may
+// be written without a list
+func arguments(args interface{}) []interface{} {
+	if list, ok := args.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{args}
+}
+
+// number returns a numeric literal, negative numbers are written as in TSL
+func number(v float64) *tsl.TSLNode {
+	if v < 0 {
+		return tsl.NewUnaryExpr(tsl.OpUMinus, tsl.NewNumericLiteral(-v))
+	}
+	return tsl.NewNumericLiteral(v)
+}
+
+// unsupported returns a tsl.UnsupportedError for a rule
+func unsupported(rule interface{}, reason string) error {
+	data, _ := json.Marshal(rule)
+	return tsl.UnsupportedError{Target: "TSL", Expression: string(data), Reason: reason}
+}
+
+// operation converts one JSONLogic operation
+func (p *parser) operation(rule interface{}, name string, args []interface{}) (*tsl.TSLNode, error) {
+	switch name {
+	case "var":
+		return p.variable(rule, args)
+	case "some", "all", "none":
+		return p.arrayOperation(rule, name, args)
+	case "reduce":
+		return p.reduce(rule, args)
+	}
+
+	nodes, err := p.parseAll(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if op, ok := comparisons[name]; ok {
+		switch {
+		case len(nodes) == 2 && (op == tsl.OpEQ || op == tsl.OpNE):
+			return equality(op, nodes[0], nodes[1]), nil
+		case len(nodes) == 2:
+			return tsl.NewBinaryExpr(op, nodes[0], nodes[1]), nil
+		case len(nodes) == 3 && op == tsl.OpLE:
+			return tsl.NewBinaryExpr(tsl.OpBetween, nodes[1], tsl.NewArrayLiteral(nodes[0], nodes[2])), nil
+		case len(nodes) == 3 && op == tsl.OpLT:
+			return tsl.NewBinaryExpr(tsl.OpAnd,
+				tsl.NewBinaryExpr(tsl.OpGT, nodes[1], nodes[0]),
+				tsl.NewBinaryExpr(tsl.OpLT, nodes[1].Clone(), nodes[2])), nil
+		}
+		return nil, unsupported(rule, fmt.Sprintf("%s with %d arguments", name, len(nodes)))
+	}
+
+	switch name {
+	case "and", "or":
+		if len(nodes) == 0 {
+			return nil, unsupported(rule, name+" without arguments")
+		}
+		op := tsl.OpAnd
+		if name == "or" {
+			op = tsl.OpOr
+		}
+		node := nodes[0]
+		for _, right := range nodes[1:] {
+			node = tsl.NewBinaryExpr(op, node, right)
+		}
+		return node, nil
+
+	case "!":
+		if len(nodes) == 1 {
+			return tsl.NewUnaryExpr(tsl.OpNot, nodes[0]), nil
+		}
+
+	case "in":
+		if len(nodes) == 2 && nodes[1].Type() == tsl.KindArrayLiteral {
+			return tsl.NewBinaryExpr(tsl.OpIn, nodes[0], nodes[1]), nil
+		}
+		return nil, unsupported(rule, "in requires a list")
+
+	case "+", "*":
+		if len(nodes) == 0 {
+			break
+		}
+		node := nodes[0]
+		for _, right := range nodes[1:] {
+			node = tsl.NewBinaryExpr(arithmetic[name], node, right)
+		}
+		return node, nil
+
+	case "-":
+		if len(nodes) == 1 {
+			return tsl.NewUnaryExpr(tsl.OpUMinus, nodes[0]), nil
+		}
+		fallthrough
+
+	case "/", "%":
+		if len(nodes) == 2 {
+			return tsl.NewBinaryExpr(arithmetic[name], nodes[0], nodes[1]), nil
+		}
+
+	default:
+		return nil, unsupported(rule, "unsupported operation "+name)
+	}
+
+	return nil, unsupported(rule, fmt.Sprintf("%s with %d arguments", name, len(nodes)))
+}
+
+// equality converts == and !=, comparisons with null become IS NULL
+func equality(op tsl.Operator, left, right *tsl.TSLNode) *tsl.TSLNode {
+	if left.Type() == tsl.KindNullLiteral {
+		left, right = right, left
+	}
+	if right.Type() != tsl.KindNullLiteral {
+		return tsl.NewBinaryExpr(op, left, right)
+	}
+
+	node := tsl.NewBinaryExpr(tsl.OpIs, left, right)
+	if op == tsl.OpNE {
+		return tsl.NewUnaryExpr(tsl.OpNot, node)
+	}
+	return node
+}
+
+// variable converts a var operation into an identifier, numeric path
+// segments become indexes, e.g. "pods.0.status" becomes "pods[0].status"
+func (p *parser) variable(rule interface{}, args []interface{}) (*tsl.TSLNode, error) {
+	if len(args) != 1 {
+		return nil, unsupported(rule, "var with a default value")
+	}
+
+	var path string
+	switch v := args[0].(type) {
+	case string:
+		path = v
+	case float64:
+		path = fmt.Sprint(v)
+	default:
+		return nil, unsupported(rule, "var path must be a string")
+	}
+
+	// Inside array operations paths are relative to the element
+	switch {
+	case p.element != "" && path == "":
+		path = p.element
+	case p.element != "":
+		path = p.element + "." + path
+	case path == "":
+		return nil, unsupported(rule, "var of the data object")
+	}
+
+	name := path
+	for pathIndexPattern.MatchString(name) {
+		name = pathIndexPattern.ReplaceAllString(name, "[$1]$2")
+	}
+	if !identifierPattern.MatchString(name) {
+		return nil, unsupported(rule, fmt.Sprintf("invalid identifier %q", path))
+	}
+	return tsl.NewIdentifier(name), nil
+}
+
+// arrayOperation converts some, all and none into ANY and ALL
+func (p *parser) arrayOperation(rule interface{}, name string, args []interface{}) (*tsl.TSLNode, error) {
+	if len(args) != 2 {
+		return nil, unsupported(rule, name+" requires an array and a predicate")
+	}
+
+	array, err := p.parse(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if array.Type() != tsl.KindIdentifier {
+		return nil, unsupported(rule, name+" requires an array var")
+	}
+	arrayName := array.Value().(string)
+
+	element := &parser{element: arrayName}
+	predicate, err := element.parse(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := predicate.AsExprOp()
+	if !ok || predicate.Type() != tsl.KindBinaryExpr || op.Left.Type() != tsl.KindIdentifier ||
+		op.Operator == tsl.OpAnd || op.Operator == tsl.OpOr || !isElement(op.Left.Value().(string), arrayName) {
+		return nil, unsupported(rule, name+" requires a comparison of the array elements")
+	}
+
+	switch name {
+	case "some":
+		return tsl.NewUnaryExpr(tsl.OpAny, predicate), nil
+	case "none":
+		return tsl.NewUnaryExpr(tsl.OpNot, tsl.NewUnaryExpr(tsl.OpAny, predicate)), nil
+	}
+	return tsl.NewUnaryExpr(tsl.OpAll, predicate), nil
+}
+
+// isElement returns true if name is the array identifier or a field of
+// its elements
+func isElement(name, array string) bool {
+	return name == array || strings.HasPrefix(name, array+".") || strings.HasPrefix(name, array+"[")
+}
+
+// reduce converts a reduce operation adding the array values into SUM
+func (p *parser) reduce(rule interface{}, args []interface{}) (*tsl.TSLNode, error) {
+	if len(args) != 3 || !isSumReducer(args[1]) || args[2] != 0.0 {
+		return nil, unsupported(rule, "reduce is only supported for sums")
+	}
+
+	array, err := p.parse(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if array.Type() != tsl.KindIdentifier {
+		return nil, unsupported(rule, "reduce requires an array var")
+	}
+	return tsl.NewUnaryExpr(tsl.OpSum, array), nil
+}
+
+// isSumReducer returns true if the reduce logic adds the current value to
+// the accumulator
+func isSumReducer(logic interface{}) bool {
+	m, ok := logic.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return false
+	}
+	args, ok := m["+"].([]interface{})
+	if !ok || len(args) != 2 {
+		return false
+	}
+
+	names := map[string]bool{}
+	for _, arg := range args {
+		v, ok := arg.(map[string]interface{})
+		if !ok || len(v) != 1 {
+			return false
+		}
+		name, _ := v["var"].(string)
+		names[name] = true
+	}
+	return names["current"] && names["accumulator"]
+}
@@ -0,0 +1,278 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonlogic helps to convert TSL trees into JSONLogic rules, and
+// JSONLogic rules into TSL trees.
+package jsonlogic
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// target names JSONLogic rules in unsupported expression errors
+const target = "JSONLogic"
+
+// Rule is a JSONLogic rule, it marshals into the rule JSON
+type Rule = map[string]interface{}
+
+// operators maps TSL operators to JSONLogic operators
+var operators = map[tsl.Operator]string{
+	tsl.OpEQ:      "==",
+	tsl.OpNE:      "!=",
+	tsl.OpLT:      "<",
+	tsl.OpLE:      "<=",
+	tsl.OpGT:      ">",
+	tsl.OpGE:      ">=",
+	tsl.OpAnd:     "and",
+	tsl.OpOr:      "or",
+	tsl.OpIn:      "in",
+	tsl.OpPlus:    "+",
+	tsl.OpMinus:   "-",
+	tsl.OpStar:    "*",
+	tsl.OpSlash:   "/",
+	tsl.OpPercent: "%",
+}
+
+// indexPattern matches numeric indexes of identifiers, e.g. "[0]"
+var indexPattern = regexp.MustCompile(`\[([0-9]+)\]`)
+
+// Walk travel the TSL tree to create a JSONLogic rule.
+//
+// Users can marshal the rule into JSON and evaluate it with a JSONLogic
+// library.
+//
+//	rule, err := jsonlogic.Walk(tree)
+//	data, err := json.Marshal(rule)
+//	// {"and":[{"==":[{"var":"a"},1]},{"in":[{"var":"b"},["x","y"]]}]}
+//
+// Identifiers become var operations with dotted paths, "pods[0].status"
+// becomes the path "pods.0.status". Comparisons, AND, OR, NOT (!), IN and
+// arithmetic use the JSONLogic operators, BETWEEN uses the three argument
+// <=, IS NULL compares with null, ANY and ALL use the some and all
+// operations, and SUM reduces the array with +. Dates and timestamps become
+// strings in the format of the TSL literal, and compare as strings.
+// Expressions JSONLogic can not express, such as LIKE, regular expressions
+// and LEN, return a tsl.UnsupportedError.
+//
+// JSONLogic: https://jsonlogic.com/operations.html
+func Walk(n *tsl.TSLNode) (interface{}, error) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		return variable(n.Value().(string))
+	case tsl.KindStringLiteral, tsl.KindNumericLiteral, tsl.KindBooleanLiteral, tsl.KindDateLiteral:
+		return n.Value(), nil
+	case tsl.KindNullLiteral:
+		return nil, nil
+	case tsl.KindTimestampLiteral:
+		if t, ok := n.Value().(time.Time); ok {
+			return t.Format(time.RFC3339Nano), nil
+		}
+	case tsl.KindArrayLiteral:
+		arr, _ := n.AsArray()
+		return walkAll(arr.Values...)
+	case tsl.KindBinaryExpr:
+		return binaryStep(n)
+	case tsl.KindUnaryExpr:
+		return unaryStep(n)
+	}
+
+	return nil, tsl.UnexpectedLiteralError{Literal: n.Type()}
+}
+
+// walkAll walks a list of nodes
+func walkAll(nodes ...*tsl.TSLNode) ([]interface{}, error) {
+	values := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		v, err := Walk(node)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// variable returns the var operation of an identifier
+func variable(name string) (Rule, error) {
+	path := indexPattern.ReplaceAllString(name, ".$1")
+	if strings.ContainsAny(path, "[]") {
+		return nil, tsl.UnsupportedError{Target: target, Expression: name, Reason: "var paths can not hold keys with dots"}
+	}
+	return Rule{"var": path}, nil
+}
+
+// binaryStep handles binary operators
+func binaryStep(n *tsl.TSLNode) (interface{}, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpAnd, tsl.OpOr:
+		// Flatten nested AND / OR operators
+		args, err := walkAll(junction(n, op.Operator)...)
+		if err != nil {
+			return nil, err
+		}
+		return Rule{operators[op.Operator]: args}, nil
+
+	case tsl.OpBetween:
+		arr, ok := op.Right.AsArray()
+		if !ok || len(arr.Values) != 2 {
+			return nil, tsl.BetweenOperatorError{Message: "BETWEEN requires two values"}
+		}
+		args, err := walkAll(arr.Values[0], op.Left, arr.Values[1])
+		if err != nil {
+			return nil, err
+		}
+		return Rule{"<=": args}, nil
+
+	case tsl.OpIs:
+		if op.Right.Type() != tsl.KindNullLiteral {
+			return nil, tsl.TypeMismatchError{Expected: "null", Got: op.Right.Type()}
+		}
+		l, err := Walk(op.Left)
+		if err != nil {
+			return nil, err
+		}
+		return Rule{"==": []interface{}{l, nil}}, nil
+	}
+
+	name, ok := operators[op.Operator]
+	if !ok {
+		return nil, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: op.Operator.String() + " has no JSONLogic operation"}
+	}
+
+	args, err := walkAll(op.Left, op.Right)
+	if err != nil {
+		return nil, err
+	}
+	return Rule{name: args}, nil
+}
+
+// junction flattens nested AND / OR operators into one list of operands
+func junction(n *tsl.TSLNode, operator tsl.Operator) []*tsl.TSLNode {
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != tsl.KindBinaryExpr || op.Operator != operator {
+		return []*tsl.TSLNode{n}
+	}
+	return append(junction(op.Left, operator), junction(op.Right, operator)...)
+}
+
+// unaryStep handles unary operators
+func unaryStep(n *tsl.TSLNode) (interface{}, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpNot:
+		// IS NOT NULL
+		if inner, ok := op.Right.AsExprOp(); ok && op.Right.Type() == tsl.KindBinaryExpr &&
+			inner.Operator == tsl.OpIs && inner.Right.Type() == tsl.KindNullLiteral {
+			l, err := Walk(inner.Left)
+			if err != nil {
+				return nil, err
+			}
+			return Rule{"!=": []interface{}{l, nil}}, nil
+		}
+
+		r, err := Walk(op.Right)
+		if err != nil {
+			return nil, err
+		}
+		return Rule{"!": []interface{}{r}}, nil
+
+	case tsl.OpUMinus:
+		// Negative numbers are literals
+		if v, ok := op.Right.AsFloat64(); ok && op.Right.Type() == tsl.KindNumericLiteral {
+			return -v, nil
+		}
+		r, err := Walk(op.Right)
+		if err != nil {
+			return nil, err
+		}
+		return Rule{"-": []interface{}{r}}, nil
+
+	case tsl.OpAny, tsl.OpAll:
+		return arrayStep(n, op)
+
+	case tsl.OpSum:
+		array, err := Walk(op.Right)
+		if err != nil {
+			return nil, err
+		}
+		return Rule{"reduce": []interface{}{array, sumReducer, 0.0}}, nil
+	}
+
+	return nil, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: op.Operator.String() + " has no JSONLogic operation"}
+}
+
+// sumReducer is the reduce logic adding the array values
+var sumReducer = Rule{"+": []interface{}{Rule{"var": "current"}, Rule{"var": "accumulator"}}}
+
+// arrayStep handles ANY and ALL using the some and all operations, the
+// array is the left operand of the condition, and the elements replace it
+// in the predicate.
+func arrayStep(n *tsl.TSLNode, op tsl.TSLExpressionOp) (interface{}, error) {
+	cond, ok := op.Right.AsExprOp()
+	if !ok || op.Right.Type() != tsl.KindBinaryExpr || cond.Left.Type() != tsl.KindIdentifier {
+		return nil, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a condition on an array identifier"}
+	}
+
+	// Inside the predicate the data is the array element, other
+	// identifiers can not be accessed
+	if hasIdentifiers(cond.Right) {
+		return nil, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "the condition can only use the array elements"}
+	}
+
+	array, err := Walk(cond.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	// The empty var path is the element
+	element := op.Right.Clone()
+	element.SetLeft(tsl.NewIdentifier(""))
+	predicate, err := Walk(element)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "some"
+	if op.Operator == tsl.OpAll {
+		name = "all"
+	}
+	return Rule{name: []interface{}{array, predicate}}, nil
+}
+
+// hasIdentifiers returns true if the tree uses identifiers
+func hasIdentifiers(n *tsl.TSLNode) bool {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		return true
+	case tsl.KindArrayLiteral:
+		arr, _ := n.AsArray()
+		for _, v := range arr.Values {
+			if hasIdentifiers(v) {
+				return true
+			}
+		}
+	case tsl.KindBinaryExpr, tsl.KindUnaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		return (op.Left != nil && hasIdentifiers(op.Left)) || hasIdentifiers(op.Right)
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonlogic
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/internal/tsltest"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestWalk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "JSONLogic walker")
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Converts TSL to JSONLogic and back",
+		func(phrase string, rule string) {
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+			data, err := json.Marshal(actual)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(MatchJSON(rule))
+
+			parsed, err := ParseJSON([]byte(rule))
+			Expect(err).ToNot(HaveOccurred())
+			tsltest.ExpectTree(parsed, tree.String())
+		},
+
+		// Comparisons
+		Entry("equal", "name = 'joe'", `{"==": [{"var": "name"}, "joe"]}`),
+		Entry("not equal", "name != 'joe'", `{"!=": [{"var": "name"}, "joe"]}`),
+		Entry("less than", "age < 20", `{"<": [{"var": "age"}, 20]}`),
+		Entry("less or equal", "age <= 20", `{"<=": [{"var": "age"}, 20]}`),
+		Entry("greater than", "age > 20", `{">": [{"var": "age"}, 20]}`),
+		Entry("greater or equal", "age >= 20.5", `{">=": [{"var": "age"}, 20.5]}`),
+		Entry("literal on the left", "20 < age", `{"<": [20, {"var": "age"}]}`),
+		Entry("negative number", "age > -5", `{">": [{"var": "age"}, -5]}`),
+		Entry("boolean", "active = TRUE", `{"==": [{"var": "active"}, true]}`),
+		Entry("boolean field", "active", `{"var": "active"}`),
+		Entry("dotted path", "spec.pages > 100", `{">": [{"var": "spec.pages"}, 100]}`),
+		Entry("array index", "pods[0].status = 'ok'", `{"==": [{"var": "pods.0.status"}, "ok"]}`),
+
+		// Logical operators
+		Entry("and", "a = 1 AND b = 2 AND c = 3",
+			`{"and": [{"==": [{"var": "a"}, 1]}, {"==": [{"var": "b"}, 2]}, {"==": [{"var": "c"}, 3]}]}`),
+		Entry("or of and", "a = 1 OR b = 2 AND c = 3",
+			`{"or": [{"==": [{"var": "a"}, 1]}, {"and": [{"==": [{"var": "b"}, 2]}, {"==": [{"var": "c"}, 3]}]}]}`),
+		Entry("not", "NOT (a = 1 OR b)", `{"!": [{"or": [{"==": [{"var": "a"}, 1]}, {"var": "b"}]}]}`),
+
+		// Membership and ranges
+		Entry("in", "city IN ['rome', 'paris']", `{"in": [{"var": "city"}, ["rome", "paris"]]}`),
+		Entry("not in", "id NOT IN [1, 2]", `{"!": [{"in": [{"var": "id"}, [1, 2]]}]}`),
+		Entry("between", "age BETWEEN 20 AND 30", `{"<=": [20, {"var": "age"}, 30]}`),
+		Entry("not between", "age NOT BETWEEN 20 AND 30", `{"!": [{"<=": [20, {"var": "age"}, 30]}]}`),
+
+		// Null checks
+		Entry("is null", "email IS NULL", `{"==": [{"var": "email"}, null]}`),
+		Entry("is not null", "email IS NOT NULL", `{"!=": [{"var": "email"}, null]}`),
+
+		// Arithmetic
+		Entry("arithmetic", "salary * 12 + bonus > 100000",
+			`{">": [{"+": [{"*": [{"var": "salary"}, 12]}, {"var": "bonus"}]}, 100000]}`),
+		Entry("arithmetic precedence", "a - (b - c) / 2 % 3 = 1",
+			`{"==": [{"-": [{"var": "a"}, {"%": [{"/": [{"-": [{"var": "b"}, {"var": "c"}]}, 2]}, 3]}]}, 1]}`),
+		Entry("unary minus", "-balance > 10", `{">": [{"-": [{"var": "balance"}]}, 10]}`),
+
+		// Array operators
+		Entry("any", "ANY (tags = 'admin')", `{"some": [{"var": "tags"}, {"==": [{"var": ""}, "admin"]}]}`),
+		Entry("any in", "ANY (tags IN ['a', 'b'])", `{"some": [{"var": "tags"}, {"in": [{"var": ""}, ["a", "b"]]}]}`),
+		Entry("all", "ALL (scores >= 50)", `{"all": [{"var": "scores"}, {">=": [{"var": ""}, 50]}]}`),
+		Entry("all between", "ALL (scores BETWEEN 1 AND 5)", `{"all": [{"var": "scores"}, {"<=": [1, {"var": ""}, 5]}]}`),
+		Entry("sum", "SUM scores > 100", `{">": [{"reduce": [{"var": "scores"},
+			{"+": [{"var": "current"}, {"var": "accumulator"}]}, 0]}, 100]}`),
+	)
+
+	DescribeTable("Converts dates into strings",
+		func(phrase string, rule string) {
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+			data, err := json.Marshal(actual)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(MatchJSON(rule))
+		},
+
+		Entry("date", "created >= 2020-01-01", `{">=": [{"var": "created"}, "2020-01-01"]}`),
+		Entry("timestamp", "created < 2020-01-01T10:00:00Z", `{"<": [{"var": "created"}, "2020-01-01T10:00:00Z"]}`),
+	)
+
+	DescribeTable("Returns errors",
+		func(phrase string, expected error) {
+			tree, err := tsl.ParseTSL(phrase)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Walk(tree)
+			Expect(err).To(BeAssignableToTypeOf(expected))
+		},
+
+		Entry("like", "name LIKE 'jo%'", tsl.UnsupportedError{}),
+		Entry("regex", "name ~= 'jo'", tsl.UnsupportedError{}),
+		Entry("len", "LEN tags > 2", tsl.UnsupportedError{}),
+		Entry("key with dots", "services[my.service].ip = '1'", tsl.UnsupportedError{}),
+		Entry("any using other identifiers", "ANY (scores > min)", tsl.UnsupportedError{}),
+		Entry("any of an expression", "ANY (a + 1 > 2)", tsl.UnsupportedError{}),
+	)
+})
+
+var _ = Describe("Parse", func() {
+	DescribeTable("Converts JSONLogic into the expected TSL tree",
+		func(rule string, expected string) {
+			tree, err := ParseJSON([]byte(rule))
+			Expect(err).ToNot(HaveOccurred())
+			tsltest.ExpectTree(tree, expected)
+		},
+
+		Entry("strict equal", `{"===": [{"var": "a"}, 1]}`, "a = 1"),
+		Entry("strict not equal", `{"!==": [{"var": "a"}, 1]}`, "a != 1"),
+		Entry("single argument", `{"!": {"var": "a"}}`, "NOT a"),
+		Entry("exclusive between", `{"<": [1, {"var": "a"}, 5]}`, "a > 1 AND a < 5"),
+		Entry("null on the left", `{"==": [null, {"var": "a"}]}`, "a IS NULL"),
+		Entry("n-ary addition", `{"+": [{"var": "a"}, 1, 2]}`, "a + 1 + 2"),
+		Entry("single and", `{"and": [{"var": "a"}]}`, "a"),
+		Entry("none", `{"none": [{"var": "tags"}, {"==": [{"var": ""}, "x"]}]}`, "NOT ANY (tags = 'x')"),
+		Entry("element fields", `{"some": [{"var": "pods"}, {"==": [{"var": "status"}, "ok"]}]}`, "ANY (pods.status = 'ok')"),
+	)
+
+	It("Parses decoded rules", func() {
+		tree, err := Parse(map[string]interface{}{"<": []interface{}{map[string]interface{}{"var": "a"}, 3}})
+		Expect(err).ToNot(HaveOccurred())
+		tsltest.ExpectTree(tree, "a < 3")
+	})
+
+	DescribeTable("Returns errors",
+		func(rule string) {
+			_, err := ParseJSON([]byte(rule))
+			Expect(err).To(BeAssignableToTypeOf(tsl.UnsupportedError{}))
+		},
+
+		Entry("unknown operation", `{"cat": ["a", "b"]}`),
+		Entry("two operations", `{"==": [1, 1], "!=": [1, 2]}`),
+		Entry("var default", `{"var": ["a", 1]}`),
+		Entry("data object", `{"var": ""}`),
+		Entry("in a string", `{"in": ["a", {"var": "name"}]}`),
+		Entry("comparison arguments", `{">": [1, 2, 3]}`),
+		Entry("some without comparison", `{"some": [{"var": "a"}, {"var": ""}]}`),
+		Entry("other reduce", `{"reduce": [{"var": "a"}, {"*": [{"var": "current"}, {"var": "accumulator"}]}, 1]}`),
+		Entry("invalid identifier", `{"var": "first name"}`),
+		Entry("index of the data array", `{"var": 1}`),
+	)
+
+	It("Returns JSON errors", func() {
+		_, err := ParseJSON([]byte(`{"==": [`))
+		Expect(err).To(HaveOccurred())
+	})
+})
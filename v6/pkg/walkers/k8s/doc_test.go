@@ -0,0 +1,44 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the k8s package.
+func Example() {
+	// Set a TSL input string.
+	input := "labels.app = 'web' and metadata.namespace = 'default' and status.restarts > 3"
+
+	// Parse input string into a TSL tree.
+	tree, _ := tsl.ParseTSL(input)
+
+	// Split the tree into selectors for the API server, and a residual
+	// tree to evaluate on the listed resources.
+	selector, residual := Split(tree)
+
+	fmt.Println(selector.Labels)
+	fmt.Println(selector.Fields)
+	fmt.Println(residual)
+
+	// Output:
+	// app=web
+	// metadata.namespace=default
+	// status.restarts > 3
+}
@@ -0,0 +1,213 @@
+package k8s
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// labelPrefixes are the identifier prefixes of label keys
+var labelPrefixes = []string{"metadata.labels", "labels"}
+
+// labelName matches label names and label values
+var labelName = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+
+// labelKeyPrefix matches the DNS subdomain prefix of label keys
+var labelKeyPrefix = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// requirement is one requirement of a label or field selector
+type requirement struct {
+	label bool
+	text  string
+}
+
+// converter converts TSL expressions into selector requirements
+type converter struct {
+	fields map[string]bool
+}
+
+// newConverter creates a converter for the given field paths
+func newConverter(fields []string) *converter {
+	if len(fields) == 0 {
+		fields = DefaultFields
+	}
+
+	c := &converter{fields: map[string]bool{}}
+	for _, field := range fields {
+		c.fields[field] = true
+	}
+	return c
+}
+
+// requirement converts one TSL expression into a selector requirement
+func (c *converter) requirement(n *tsl.TSLNode) (requirement, error) {
+	op, ok := n.AsExprOp()
+	if !ok {
+		return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a comparison"}
+	}
+
+	not := false
+	if n.Type() == tsl.KindUnaryExpr && op.Operator == tsl.OpNot {
+		not = true
+		if op, ok = op.Right.AsExprOp(); !ok {
+			return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a comparison"}
+		}
+	}
+
+	// Literal on the left, identifier on the right
+	if _, isIdentifier := identifier(op.Left); !isIdentifier && (op.Operator == tsl.OpEQ || op.Operator == tsl.OpNE) {
+		op.Left, op.Right = op.Right, op.Left
+	}
+
+	name, isIdentifier := identifier(op.Left)
+	if !isIdentifier {
+		return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a label or field identifier"}
+	}
+
+	if key, isLabel := labelKey(name); isLabel {
+		return labelRequirement(n, key, op.Operator, not, op.Right)
+	}
+	return c.fieldRequirement(n, name, op.Operator, not, op.Right)
+}
+
+// labelRequirement creates a label selector requirement
+func labelRequirement(n *tsl.TSLNode, key string, operator tsl.Operator, not bool, value *tsl.TSLNode) (requirement, error) {
+	if !validLabelKey(key) {
+		return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "invalid label key " + strconv.Quote(key)}
+	}
+
+	switch operator {
+	case tsl.OpEQ, tsl.OpNE:
+		v, err := labelValue(n, value)
+		if err != nil {
+			return requirement{}, err
+		}
+		return requirement{label: true, text: key + equality(operator, not) + v}, nil
+
+	case tsl.OpIn:
+		arr, ok := value.AsArray()
+		if !ok {
+			return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a list of values"}
+		}
+		values := make([]string, len(arr.Values))
+		for i, item := range arr.Values {
+			v, err := labelValue(n, item)
+			if err != nil {
+				return requirement{}, err
+			}
+			values[i] = v
+		}
+		// Kubernetes sorts set values, do the same for stable selectors
+		sort.Strings(values)
+
+		set := "in"
+		if not {
+			set = "notin"
+		}
+		return requirement{label: true, text: key + " " + set + " (" + strings.Join(values, ",") + ")"}, nil
+
+	case tsl.OpIs:
+		if value.Type() != tsl.KindNullLiteral {
+			return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a null check"}
+		}
+		if not {
+			return requirement{label: true, text: key}, nil
+		}
+		return requirement{label: true, text: "!" + key}, nil
+	}
+
+	return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "label selectors support =, !=, IN, NOT IN and IS NULL"}
+}
+
+// fieldRequirement creates a field selector requirement
+func (c *converter) fieldRequirement(n *tsl.TSLNode, field string, operator tsl.Operator, not bool, value *tsl.TSLNode) (requirement, error) {
+	if !c.fields[field] {
+		return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "field " + strconv.Quote(field) + " is not supported in field selectors"}
+	}
+	if operator != tsl.OpEQ && operator != tsl.OpNE {
+		return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "field selectors support = and !="}
+	}
+
+	var v string
+	switch value.Type() {
+	case tsl.KindStringLiteral, tsl.KindDateLiteral:
+		v, _ = value.AsString()
+	case tsl.KindNumericLiteral:
+		f, _ := value.AsFloat64()
+		v = strconv.FormatFloat(f, 'f', -1, 64)
+	case tsl.KindBooleanLiteral:
+		b, _ := value.AsBool()
+		v = strconv.FormatBool(b)
+	default:
+		return requirement{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a literal value"}
+	}
+
+	// Escape the field selector special characters
+	v = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `=`, `\=`).Replace(v)
+
+	return requirement{text: field + equality(operator, not) + v}, nil
+}
+
+// equality returns the selector operator of an = or != comparison
+func equality(operator tsl.Operator, not bool) string {
+	if (operator == tsl.OpEQ) != not {
+		return "="
+	}
+	return "!="
+}
+
+// identifier returns the name of an identifier node
+func identifier(n *tsl.TSLNode) (string, bool) {
+	if n == nil || n.Type() != tsl.KindIdentifier {
+		return "", false
+	}
+	return n.Value().(string), true
+}
+
+// labelKey returns the label key of an identifier, e.g. "app" for
+// "metadata.labels.app" or "app.kubernetes.io/name" for
+// "labels[app.kubernetes.io/name]"
+func labelKey(name string) (string, bool) {
+	for _, prefix := range labelPrefixes {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if key, ok := strings.CutPrefix(rest, "."); ok {
+			return key, true
+		}
+		if strings.HasPrefix(rest, "[") && strings.HasSuffix(rest, "]") {
+			return rest[1 : len(rest)-1], true
+		}
+	}
+	return "", false
+}
+
+// validLabelKey checks a label key, an optional DNS subdomain prefix and a
+// name of up to 63 characters
+func validLabelKey(key string) bool {
+	if prefix, name, ok := strings.Cut(key, "/"); ok {
+		if len(prefix) > 253 || !labelKeyPrefix.MatchString(prefix) {
+			return false
+		}
+		key = name
+	}
+	return len(key) <= 63 && labelName.MatchString(key)
+}
+
+// labelValue returns a label value of a literal node, label values are
+// strings of up to 63 characters, or empty.
+func labelValue(n *tsl.TSLNode, value *tsl.TSLNode) (string, error) {
+	if value.Type() != tsl.KindStringLiteral && value.Type() != tsl.KindDateLiteral {
+		return "", tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "label values must be strings"}
+	}
+
+	v, _ := value.AsString()
+	if v != "" && (len(v) > 63 || !labelName.MatchString(v)) {
+		return "", tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "invalid label value " + strconv.Quote(v)}
+	}
+	return v, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8s helps to create Kubernetes label and field selectors using
+// the TSL package.
+package k8s
+
+import (
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// target names label and field selectors in unsupported expression errors
+const target = "kubernetes selectors"
+
+// DefaultFields are the field paths that all resources support in field
+// selectors
+var DefaultFields = []string{"metadata.name", "metadata.namespace"}
+
+// Selector holds the label and field selectors of a list request
+type Selector struct {
+	// Labels is a label selector, e.g. "app=web,tier in (front,back)"
+	Labels string
+
+	// Fields is a field selector, e.g. "metadata.namespace=default"
+	Fields string
+}
+
+// IsEmpty returns true if the selector selects everything
+func (s Selector) IsEmpty() bool {
+	return s.Labels == "" && s.Fields == ""
+}
+
+// Walk travel the TSL tree to create Kubernetes label and field selectors.
+//
+// Identifiers prefixed with "metadata.labels." or "labels." (or using the
+// "labels[key]" form) are label keys, and the other identifiers are field
+// paths. Field selectors are only created for the given fields, if no
+// fields are given DefaultFields are used.
+//
+// Label selectors support =, !=, IN, NOT IN and IS [NOT] NULL (existence),
+// field selectors support = and !=, both must be joined using AND. As in
+// Kubernetes, != and NOT IN also match resources without the label.
+//
+//	selector, err := k8s.Walk(tree)
+//	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+//		LabelSelector: selector.Labels,
+//		FieldSelector: selector.Fields,
+//	})
+//
+// Users can get a labels.Selector using labels.Parse(selector.Labels).
+func Walk(n *tsl.TSLNode, fields ...string) (Selector, error) {
+	c := newConverter(fields)
+
+	var requirements []requirement
+	for _, conjunct := range tsl.Flatten(n, tsl.OpAnd) {
+		r, err := c.requirement(conjunct)
+		if err != nil {
+			return Selector{}, err
+		}
+		requirements = append(requirements, r)
+	}
+
+	return newSelector(requirements), nil
+}
+
+// Split splits the TSL tree into a selector the API server can evaluate and
+// a residual tree that must be evaluated on the client, for example using
+// the semantics walker, on the listed resources.
+//
+// The tree is split at the top level AND operators, the residual tree
+// holds the parts that can not be expressed as selectors and is nil if the
+// selector holds all the tree. Fields are handled as in Walk.
+//
+//	selector, residual := k8s.Split(tree)
+//	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+//		LabelSelector: selector.Labels,
+//		FieldSelector: selector.Fields,
+//	})
+//
+//	// Filter the listed pods using the residual tree.
+//	if residual != nil {
+//		for _, pod := range pods.Items {
+//			matches, err := semantics.Walk(residual, evalFactory(pod))
+//			...
+//		}
+//	}
+func Split(n *tsl.TSLNode, fields ...string) (Selector, *tsl.TSLNode) {
+	c := newConverter(fields)
+
+	var requirements []requirement
+	var residual *tsl.TSLNode
+	for _, conjunct := range tsl.Flatten(n, tsl.OpAnd) {
+		if r, err := c.requirement(conjunct); err == nil {
+			requirements = append(requirements, r)
+			continue
+		}
+
+		if residual == nil {
+			residual = conjunct
+		} else {
+			residual = tsl.NewBinaryExpr(tsl.OpAnd, residual, conjunct)
+		}
+	}
+
+	// Keep the tree as is if nothing was selected by the server
+	if len(requirements) == 0 {
+		return Selector{}, n
+	}
+
+	return newSelector(requirements), residual
+}
+
+// newSelector joins requirements into label and field selectors
+func newSelector(requirements []requirement) Selector {
+	var labelRequirements, fieldRequirements []string
+	for _, r := range requirements {
+		if r.label {
+			labelRequirements = append(labelRequirements, r.text)
+		} else {
+			fieldRequirements = append(fieldRequirements, r.text)
+		}
+	}
+
+	return Selector{
+		Labels: strings.Join(labelRequirements, ","),
+		Fields: strings.Join(fieldRequirements, ","),
+	}
+}
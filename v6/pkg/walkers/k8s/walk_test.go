@@ -0,0 +1,137 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestWalk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "K8s walker")
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Generates the expected selectors",
+		func(input string, labels string, fields string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			selector, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selector.Labels).To(Equal(labels))
+			Expect(selector.Fields).To(Equal(fields))
+		},
+
+		// Label selectors
+		Entry("equal", "labels.app = 'web'", "app=web", ""),
+		Entry("metadata labels", "metadata.labels.app = 'web'", "app=web", ""),
+		Entry("bracket key", "metadata.labels[app.kubernetes.io/name] = 'web'", "app.kubernetes.io/name=web", ""),
+		Entry("prefixed key", "labels.example.com/tier = 'front'", "example.com/tier=front", ""),
+		Entry("literal on the left", "'web' = labels.app", "app=web", ""),
+		Entry("not equal", "labels.app != 'web'", "app!=web", ""),
+		Entry("not of equal", "not (labels.app = 'web')", "app!=web", ""),
+		Entry("not of not equal", "not (labels.app != 'web')", "app=web", ""),
+		Entry("empty value", "labels.app = ''", "app=", ""),
+		Entry("in", "labels.tier in ['front', 'back']", "tier in (back,front)", ""),
+		Entry("not in", "labels.tier not in ['front', 'back']", "tier notin (back,front)", ""),
+		Entry("exists", "labels.app is not null", "app", ""),
+		Entry("does not exist", "labels.app is null", "!app", ""),
+		Entry("and", "labels.app = 'web' and labels.tier in ['front'] and labels.canary is null",
+			"app=web,tier in (front),!canary", ""),
+
+		// Field selectors
+		Entry("field equal", "metadata.name = 'nginx'", "", "metadata.name=nginx"),
+		Entry("field not equal", "metadata.namespace != 'kube-system'", "", "metadata.namespace!=kube-system"),
+		Entry("field escaping", "metadata.name = 'a,b=c'", "", `metadata.name=a\,b\=c`),
+		Entry("labels and fields", "metadata.namespace = 'default' and labels.app = 'web'",
+			"app=web", "metadata.namespace=default"),
+	)
+
+	It("Uses the given fields", func() {
+		tree, err := tsl.ParseTSL("status.phase = 'Running' and spec.replicas != 3")
+		Expect(err).ToNot(HaveOccurred())
+
+		selector, err := Walk(tree, "status.phase", "spec.replicas")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selector).To(Equal(Selector{Fields: "status.phase=Running,spec.replicas!=3"}))
+
+		_, err = Walk(tree)
+		Expect(err).To(MatchError(ContainSubstring(`field "status.phase" is not supported`)))
+	})
+
+	DescribeTable("Returns errors",
+		func(input string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Walk(tree)
+			Expect(err).To(BeAssignableToTypeOf(tsl.UnsupportedError{}))
+		},
+
+		Entry("or", "labels.app = 'web' or labels.app = 'api'"),
+		Entry("not of and", "not (labels.app = 'web' and labels.tier = 'front')"),
+		Entry("like", "labels.app like 'web%'"),
+		Entry("label range", "labels.version > '2'"),
+		Entry("numeric label value", "labels.replicas = 3"),
+		Entry("invalid label value", "labels.app = 'web server'"),
+		Entry("invalid label key", "labels[-app] = 'web'"),
+		Entry("field in", "metadata.name in ['a', 'b']"),
+		Entry("unknown field", "spec.nodeName = 'node-1'"),
+		Entry("identifier value", "labels.app = labels.name"),
+		Entry("not a comparison", "labels.enabled"),
+	)
+})
+
+var _ = Describe("Split", func() {
+	DescribeTable("Splits the tree into a selector and a residual tree",
+		func(input string, selector Selector, residual string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			actualSelector, actualResidual := Split(tree)
+			Expect(actualSelector).To(Equal(selector))
+			if residual == "" {
+				Expect(actualResidual).To(BeNil())
+			} else {
+				Expect(actualResidual.String()).To(Equal(residual))
+			}
+		},
+
+		Entry("all pushed", "labels.app = 'web' and metadata.namespace = 'default'",
+			Selector{Labels: "app=web", Fields: "metadata.namespace=default"}, ""),
+		Entry("nothing pushed", "spec.replicas > 2 or labels.app = 'web'",
+			Selector{}, "spec.replicas > 2 OR labels.app = 'web'"),
+		Entry("mixed", "labels.app = 'web' and spec.replicas > 2 and metadata.name != 'x' and status.ready",
+			Selector{Labels: "app=web", Fields: "metadata.name!=x"}, "spec.replicas > 2 AND status.ready"),
+		Entry("nested or stays", "labels.app in ['web', 'api'] and (labels.tier = 'front' or labels.tier = 'back')",
+			Selector{Labels: "app in (api,web)"}, "labels.tier = 'front' OR labels.tier = 'back'"),
+	)
+
+	It("Keeps the tree if nothing is pushed", func() {
+		tree, err := tsl.ParseTSL("a > 1 and b > 2")
+		Expect(err).ToNot(HaveOccurred())
+
+		selector, residual := Split(tree)
+		Expect(selector.IsEmpty()).To(BeTrue())
+		Expect(residual).To(BeIdenticalTo(tree))
+	})
+})
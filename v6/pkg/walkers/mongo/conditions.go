@@ -0,0 +1,159 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// logicalOperators maps TSL logical operators to mongo operators
+var logicalOperators = map[tsl.Operator]string{
+	tsl.OpAnd: "$and",
+	tsl.OpOr:  "$or",
+}
+
+// comparisonOperators maps TSL comparison operators to mongo operators
+var comparisonOperators = map[tsl.Operator]string{
+	tsl.OpEQ: "$eq",
+	tsl.OpNE: "$ne",
+	tsl.OpLT: "$lt",
+	tsl.OpLE: "$lte",
+	tsl.OpGT: "$gt",
+	tsl.OpGE: "$gte",
+}
+
+// regexCondition creates a $regex condition, LIKE patterns are converted
+// to anchored regular expressions and ILIKE adds the "i" option.
+func regexCondition(operator tsl.Operator, pattern string) (bson.D, error) {
+	var options string
+
+	switch operator {
+	case tsl.OpLike, tsl.OpILike:
+		expr, err := tsl.LikeToRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		pattern = expr
+		if operator == tsl.OpILike {
+			options = "i"
+		}
+	}
+
+	cond := bson.D{{Key: "$regex", Value: pattern}}
+	if options != "" {
+		cond = append(cond, bson.E{Key: "$options", Value: options})
+	}
+	return cond, nil
+}
+
+// fieldCondition creates the condition on a field for a comparison between
+// an identifier and literals, for example "age > 5" gives the field "age"
+// and the condition {$gt: 5}. It returns false if the operation is not
+// a field condition.
+func fieldCondition(op tsl.TSLExpressionOp) (string, bson.D, bool, error) {
+	field, ok := tsl.FieldName(op.Left)
+
+	switch op.Operator {
+	case tsl.OpEQ, tsl.OpNE, tsl.OpLT, tsl.OpLE, tsl.OpGT, tsl.OpGE:
+		operator := op.Operator
+		val, okVal := tsl.LiteralValue(op.Right)
+		if !ok || !okVal {
+			// Try the swapped form: literal on the left, identifier on the right
+			field, ok = tsl.FieldName(op.Right)
+			val, okVal = tsl.LiteralValue(op.Left)
+			if !ok || !okVal {
+				return "", nil, false, nil
+			}
+			operator = tsl.FlippedComparisons[operator]
+		}
+		return field, bson.D{{Key: comparisonOperators[operator], Value: val}}, true, nil
+
+	case tsl.OpLike, tsl.OpILike, tsl.OpREQ:
+		pattern, okPattern := op.Right.AsString()
+		if !ok || !okPattern || op.Right.Type() != tsl.KindStringLiteral {
+			return "", nil, false, nil
+		}
+		cond, err := regexCondition(op.Operator, pattern)
+		return field, cond, err == nil, err
+
+	case tsl.OpRNE:
+		pattern, okPattern := op.Right.AsString()
+		if !ok || !okPattern || op.Right.Type() != tsl.KindStringLiteral {
+			return "", nil, false, nil
+		}
+		cond, err := regexCondition(tsl.OpREQ, pattern)
+		return field, bson.D{{Key: "$not", Value: cond}}, err == nil, err
+
+	case tsl.OpIn:
+		values, okValues := tsl.LiteralArrayValues(op.Right)
+		if !ok || !okValues {
+			return "", nil, false, nil
+		}
+		return field, bson.D{{Key: "$in", Value: values}}, true, nil
+
+	case tsl.OpBetween:
+		values, okValues := tsl.LiteralArrayValues(op.Right)
+		if !ok || !okValues {
+			return "", nil, false, nil
+		}
+		if len(values) != 2 {
+			return "", nil, false, tsl.BetweenOperatorError{Message: "BETWEEN requires exactly two values"}
+		}
+		// BETWEEN is inclusive: begin and end values are included
+		return field, bson.D{{Key: "$gte", Value: values[0]}, {Key: "$lte", Value: values[1]}}, true, nil
+
+	case tsl.OpIs:
+		if !ok {
+			return "", nil, false, nil
+		}
+		// Matches null values and missing fields
+		return field, bson.D{{Key: "$eq", Value: nil}}, true, nil
+	}
+
+	return "", nil, false, nil
+}
+
+// negatedStep creates the negated filter of a field condition, IN, BETWEEN,
+// IS NULL and regular expressions have dedicated negated forms, other
+// conditions use $not. It returns false if the operation is not a field
+// condition.
+func negatedStep(op tsl.TSLExpressionOp) (bson.D, bool, error) {
+	field, cond, ok, err := fieldCondition(op)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	switch op.Operator {
+	case tsl.OpIn:
+		return bson.D{{Key: field, Value: bson.D{{Key: "$nin", Value: cond[0].Value}}}}, true, nil
+	case tsl.OpBetween:
+		return bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: field, Value: bson.D{{Key: "$lt", Value: cond[0].Value}}}},
+			bson.D{{Key: field, Value: bson.D{{Key: "$gt", Value: cond[1].Value}}}},
+		}}}, true, nil
+	case tsl.OpIs:
+		return bson.D{{Key: field, Value: bson.D{{Key: "$exists", Value: true}, {Key: "$ne", Value: nil}}}}, true, nil
+	case tsl.OpRNE:
+		// The condition is {$not: {$regex: ...}}
+		return bson.D{{Key: field, Value: cond[0].Value}}, true, nil
+	}
+
+	return bson.D{{Key: field, Value: bson.D{{Key: "$not", Value: cond}}}}, true, nil
+}
+
+// sizeCondition returns the field and size for "LEN field = n"
+func sizeCondition(op tsl.TSLExpressionOp) (string, int64, bool) {
+	left, ok := op.Left.AsExprOp()
+	if !ok || left.Operator != tsl.OpLen {
+		return "", 0, false
+	}
+	field, ok := tsl.FieldName(left.Right)
+	if !ok {
+		return "", 0, false
+	}
+	size, ok := op.Right.AsFloat64()
+	if !ok || op.Right.Type() != tsl.KindNumericLiteral || size != float64(int64(size)) || size < 0 {
+		return "", 0, false
+	}
+	return field, int64(size), true
+}
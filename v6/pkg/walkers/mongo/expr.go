@@ -0,0 +1,168 @@
+package mongo
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// exprOperators maps TSL binary operators to mongo aggregation operators
+var exprOperators = map[tsl.Operator]string{
+	tsl.OpAnd:     "$and",
+	tsl.OpOr:      "$or",
+	tsl.OpEQ:      "$eq",
+	tsl.OpNE:      "$ne",
+	tsl.OpLT:      "$lt",
+	tsl.OpLE:      "$lte",
+	tsl.OpGT:      "$gt",
+	tsl.OpGE:      "$gte",
+	tsl.OpIn:      "$in",
+	tsl.OpPlus:    "$add",
+	tsl.OpMinus:   "$subtract",
+	tsl.OpStar:    "$multiply",
+	tsl.OpSlash:   "$divide",
+	tsl.OpPercent: "$mod",
+}
+
+// exprStep creates an aggregation expression, used inside $expr for
+// expressions that can not be written as field conditions.
+func exprStep(n *tsl.TSLNode) (interface{}, error) {
+	if v, ok := tsl.LiteralValue(n); ok {
+		if s, ok := v.(string); ok && strings.HasPrefix(s, "$") {
+			// Strings starting with $ are field paths in expressions
+			return bson.D{{Key: "$literal", Value: s}}, nil
+		}
+		return v, nil
+	}
+
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		return "$" + n.Value().(string), nil
+	case tsl.KindNullLiteral:
+		return nil, nil
+	case tsl.KindArrayLiteral:
+		arr := n.Value().(tsl.TSLArrayLiteral)
+		values := bson.A{}
+		for _, item := range arr.Values {
+			v, err := exprStep(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case tsl.KindBinaryExpr:
+		return binaryExprStep(n.Value().(tsl.TSLExpressionOp))
+	case tsl.KindUnaryExpr:
+		return unaryExprStep(n.Value().(tsl.TSLExpressionOp))
+	}
+
+	return nil, tsl.UnexpectedLiteralError{Literal: n.Type()}
+}
+
+func binaryExprStep(op tsl.TSLExpressionOp) (interface{}, error) {
+	l, err := exprStep(op.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Operator {
+	case tsl.OpIs:
+		// $ifNull turns missing fields into null
+		return bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{l, nil}}}, nil}}}, nil
+
+	case tsl.OpLike, tsl.OpILike, tsl.OpREQ, tsl.OpRNE:
+		return regexExprStep(op, l)
+
+	case tsl.OpBetween:
+		arr, ok := op.Right.AsArray()
+		if !ok || len(arr.Values) != 2 {
+			return nil, tsl.BetweenOperatorError{Message: "BETWEEN requires exactly two values"}
+		}
+		low, err := exprStep(arr.Values[0])
+		if err != nil {
+			return nil, err
+		}
+		high, err := exprStep(arr.Values[1])
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: "$and", Value: bson.A{
+			bson.D{{Key: "$gte", Value: bson.A{l, low}}},
+			bson.D{{Key: "$lte", Value: bson.A{l, high}}},
+		}}}, nil
+	}
+
+	operator, ok := exprOperators[op.Operator]
+	if !ok {
+		return nil, tsl.UnexpectedOperatorError{Operator: op.Operator}
+	}
+
+	r, err := exprStep(op.Right)
+	if err != nil {
+		return nil, err
+	}
+	return bson.D{{Key: operator, Value: bson.A{l, r}}}, nil
+}
+
+// regexExprStep creates a $regexMatch expression
+func regexExprStep(op tsl.TSLExpressionOp, input interface{}) (interface{}, error) {
+	var regex interface{}
+	options := ""
+
+	switch op.Operator {
+	case tsl.OpLike, tsl.OpILike:
+		// LIKE patterns are converted to regular expressions, so they must be literals
+		pattern, ok := op.Right.AsString()
+		if !ok || op.Right.Type() != tsl.KindStringLiteral {
+			return nil, tsl.TypeMismatchError{Expected: "string literal pattern", Got: op.Right.Type()}
+		}
+		expr, err := tsl.LikeToRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		regex = expr
+		if op.Operator == tsl.OpILike {
+			options = "i"
+		}
+	default:
+		r, err := exprStep(op.Right)
+		if err != nil {
+			return nil, err
+		}
+		regex = r
+	}
+
+	match := bson.D{{Key: "input", Value: input}, {Key: "regex", Value: regex}}
+	if options != "" {
+		match = append(match, bson.E{Key: "options", Value: options})
+	}
+
+	expr := bson.D{{Key: "$regexMatch", Value: match}}
+	if op.Operator == tsl.OpRNE {
+		return bson.D{{Key: "$not", Value: bson.A{expr}}}, nil
+	}
+	return expr, nil
+}
+
+func unaryExprStep(op tsl.TSLExpressionOp) (interface{}, error) {
+	r, err := exprStep(op.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Operator {
+	case tsl.OpNot:
+		return bson.D{{Key: "$not", Value: bson.A{r}}}, nil
+	case tsl.OpUMinus:
+		return bson.D{{Key: "$multiply", Value: bson.A{-1.0, r}}}, nil
+	case tsl.OpLen:
+		return bson.D{{Key: "$size", Value: r}}, nil
+	case tsl.OpSum:
+		return bson.D{{Key: "$sum", Value: r}}, nil
+	}
+
+	return nil, tsl.UnexpectedOperatorError{Operator: op.Operator}
+}
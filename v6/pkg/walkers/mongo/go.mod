@@ -0,0 +1,24 @@
+module github.com/yaacov/tree-search-language/v6/pkg/walkers/mongo
+
+go 1.23
+
+require (
+	github.com/onsi/ginkgo/v2 v2.22.1
+	github.com/onsi/gomega v1.36.2
+	github.com/yaacov/tree-search-language/v6 v6.0.0-00010101000000-000000000000
+	go.mongodb.org/mongo-driver/v2 v2.1.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-task/slim-sprig/v3 v3.0.0 // indirect
+	github.com/google/go-cmp v0.7.0 // indirect
+	github.com/google/pprof v0.0.0-20241210010833-40e02aabc2ad // indirect
+	golang.org/x/net v0.33.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	golang.org/x/tools v0.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/yaacov/tree-search-language/v6 => ../../..
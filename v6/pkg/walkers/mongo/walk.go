@@ -0,0 +1,140 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mongo helps to create mongo BSON filters using the TSL package.
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Walk travel the TSL tree to create mongo-go-driver bson filters.
+//
+// Users can call the Walk method to filter a mongo Find.
+//
+//	// Prepare filter
+//	filter, _ := mongo.Walk(tree)
+//
+//	// Run query
+//	cur, _ := collection.Find(ctx, filter)
+//
+// Comparisons between an identifier and a literal are emitted as field
+// conditions ($eq, $ne, $lt, $in, $nin, ...), LIKE, ILIKE and regular
+// expressions as $regex, and nested AND / OR operators are flattened into a
+// single $and / $or. IS NULL matches null and missing fields, IS NOT NULL
+// matches existing non null fields. LEN, ANY and ALL over an array field
+// use $size and $elemMatch. Other expressions, such as arithmetic or
+// identifier to identifier comparisons, are emitted as $expr aggregation
+// expressions.
+//
+// mongo-go-driver: https://go.mongodb.org/mongo-driver/v2
+func Walk(n *tsl.TSLNode) (bson.D, error) {
+	switch n.Type() {
+	case tsl.KindIdentifier:
+		// A boolean field
+		return bson.D{{Key: n.Value().(string), Value: true}}, nil
+	case tsl.KindBooleanLiteral:
+		return bson.D{{Key: "$expr", Value: n.Value()}}, nil
+	case tsl.KindBinaryExpr:
+		return binaryStep(n)
+	case tsl.KindUnaryExpr:
+		return unaryStep(n)
+	}
+
+	return nil, tsl.UnexpectedLiteralError{Literal: n.Type()}
+}
+
+// binaryStep handles logical operators and predicates
+func binaryStep(n *tsl.TSLNode) (bson.D, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpAnd, tsl.OpOr:
+		parts, err := tsl.WalkChain(n, op.Operator, Walk)
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: logicalOperators[op.Operator], Value: parts}}, nil
+	}
+
+	// Field conditions on identifiers
+	if field, cond, ok, err := fieldCondition(op); err != nil || ok {
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: field, Value: cond}}, nil
+	}
+
+	// LEN of an array field compared to a number
+	if op.Operator == tsl.OpEQ {
+		if field, size, ok := sizeCondition(op); ok {
+			return bson.D{{Key: field, Value: bson.D{{Key: "$size", Value: size}}}}, nil
+		}
+	}
+
+	expr, err := exprStep(n)
+	if err != nil {
+		return nil, err
+	}
+	return bson.D{{Key: "$expr", Value: expr}}, nil
+}
+
+// unaryStep handles NOT, ANY and ALL operators
+func unaryStep(n *tsl.TSLNode) (bson.D, error) {
+	op := n.Value().(tsl.TSLExpressionOp)
+
+	switch op.Operator {
+	case tsl.OpNot:
+		// Use the negated form of a field condition when there is one
+		if inner, ok := op.Right.AsExprOp(); ok && op.Right.Type() == tsl.KindBinaryExpr {
+			d, ok, err := negatedStep(inner)
+			if err != nil || ok {
+				return d, err
+			}
+		}
+
+		d, err := Walk(op.Right)
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: "$nor", Value: bson.A{d}}}, nil
+
+	case tsl.OpAny, tsl.OpAll:
+		// ANY and ALL of a condition on the elements of an array field
+		if inner, ok := op.Right.AsExprOp(); ok && op.Right.Type() == tsl.KindBinaryExpr {
+			field, cond, ok, err := fieldCondition(inner)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				if op.Operator == tsl.OpAny {
+					return bson.D{{Key: field, Value: bson.D{{Key: "$elemMatch", Value: cond}}}}, nil
+				}
+				// All elements match if no element fails the condition
+				return bson.D{{Key: field, Value: bson.D{{Key: "$not", Value: bson.D{
+					{Key: "$elemMatch", Value: bson.D{{Key: "$not", Value: cond}}},
+				}}}}}, nil
+			}
+		}
+	}
+
+	expr, err := exprStep(n)
+	if err != nil {
+		return nil, err
+	}
+	return bson.D{{Key: "$expr", Value: expr}}, nil
+}
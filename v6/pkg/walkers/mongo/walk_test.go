@@ -0,0 +1,135 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongo
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestWalk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Mongo walker")
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Generates the expected extended JSON filter",
+		func(input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			filter, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := bson.MarshalExtJSON(filter, false, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(actual)).To(MatchJSON(expected))
+		},
+
+		// Comparisons
+		Entry("equal", "name = 'joe'", `{"name": {"$eq": "joe"}}`),
+		Entry("not equal", "name != 'joe'", `{"name": {"$ne": "joe"}}`),
+		Entry("less than", "age < 20", `{"age": {"$lt": 20.0}}`),
+		Entry("less or equal", "age <= 20", `{"age": {"$lte": 20.0}}`),
+		Entry("greater than", "age > 20", `{"age": {"$gt": 20.0}}`),
+		Entry("greater or equal", "age >= 20", `{"age": {"$gte": 20.0}}`),
+		Entry("literal on the left", "20 < age", `{"age": {"$gt": 20.0}}`),
+		Entry("negative number", "age > -5", `{"age": {"$gt": -5.0}}`),
+		Entry("boolean", "active = true", `{"active": {"$eq": true}}`),
+		Entry("boolean field", "active", `{"active": true}`),
+		Entry("date", "created >= 2020-01-01",
+			`{"created": {"$gte": {"$date": "2020-01-01T00:00:00Z"}}}`),
+		Entry("timestamp", "created < 2020-01-01T10:00:00Z",
+			`{"created": {"$lt": {"$date": "2020-01-01T10:00:00Z"}}}`),
+
+		// Logical operators
+		Entry("and", "a = 1 and b = 2", `{"$and": [{"a": {"$eq": 1.0}}, {"b": {"$eq": 2.0}}]}`),
+		Entry("flattened and", "a = 1 and b = 2 and c = 3",
+			`{"$and": [{"a": {"$eq": 1.0}}, {"b": {"$eq": 2.0}}, {"c": {"$eq": 3.0}}]}`),
+		Entry("or of and", "a = 1 or (b = 2 and c = 3)",
+			`{"$or": [{"a": {"$eq": 1.0}}, {"$and": [{"b": {"$eq": 2.0}}, {"c": {"$eq": 3.0}}]}]}`),
+		Entry("not comparison", "not (a > 1)", `{"a": {"$not": {"$gt": 1.0}}}`),
+		Entry("not and", "not (a = 1 and b = 2)",
+			`{"$nor": [{"$and": [{"a": {"$eq": 1.0}}, {"b": {"$eq": 2.0}}]}]}`),
+
+		// Pattern matching
+		Entry("like", "name like 'jo%'", `{"name": {"$regex": "^jo(?s:.*)$"}}`),
+		Entry("like escapes regex characters", "name like 'a.b_'", `{"name": {"$regex": "^a\\.b(?s:.)$"}}`),
+		Entry("like escaped wildcard", `name like '100\%'`, `{"name": {"$regex": "^100%$"}}`),
+		Entry("ilike", "name ilike 'JO%'", `{"name": {"$regex": "^JO(?s:.*)$", "$options": "i"}}`),
+		Entry("not like", "name not like 'jo%'", `{"name": {"$not": {"$regex": "^jo(?s:.*)$"}}}`),
+		Entry("regex", "email ~= '^jo.*@gmail'", `{"email": {"$regex": "^jo.*@gmail"}}`),
+		Entry("not regex", "email ~! '@gmail'", `{"email": {"$not": {"$regex": "@gmail"}}}`),
+		Entry("not not regex", "not (email ~! '@gmail')", `{"email": {"$regex": "@gmail"}}`),
+
+		// Membership and ranges
+		Entry("in", "city in ['rome', 'paris']", `{"city": {"$in": ["rome", "paris"]}}`),
+		Entry("not in", "city not in ['rome', 'paris']", `{"city": {"$nin": ["rome", "paris"]}}`),
+		Entry("between", "age between 20 and 30", `{"age": {"$gte": 20.0, "$lte": 30.0}}`),
+		Entry("not between", "age not between 20 and 30",
+			`{"$or": [{"age": {"$lt": 20.0}}, {"age": {"$gt": 30.0}}]}`),
+
+		// Null checks
+		Entry("is null", "email is null", `{"email": {"$eq": null}}`),
+		Entry("is not null", "email is not null", `{"email": {"$exists": true, "$ne": null}}`),
+
+		// Array operators
+		Entry("len equal", "len tags = 2", `{"tags": {"$size": 2}}`),
+		Entry("len greater", "len tags > 2", `{"$expr": {"$gt": [{"$size": "$tags"}, 2.0]}}`),
+		Entry("any", "any (tags like 'fic%')", `{"tags": {"$elemMatch": {"$regex": "^fic(?s:.*)$"}}}`),
+		Entry("any comparison", "any (scores > 90)", `{"scores": {"$elemMatch": {"$gt": 90.0}}}`),
+		Entry("all", "all (scores >= 50)",
+			`{"scores": {"$not": {"$elemMatch": {"$not": {"$gte": 50.0}}}}}`),
+		Entry("sum", "sum scores > 100", `{"$expr": {"$gt": [{"$sum": "$scores"}, 100.0]}}`),
+
+		// Aggregation expressions
+		Entry("arithmetic", "(salary * 12) + bonus > 100000",
+			`{"$expr": {"$gt": [{"$add": [{"$multiply": ["$salary", 12.0]}, "$bonus"]}, 100000.0]}}`),
+		Entry("modulus", "age % 2 = 0", `{"$expr": {"$eq": [{"$mod": ["$age", 2.0]}, 0.0]}}`),
+		Entry("unary minus", "-balance > 10", `{"$expr": {"$gt": [{"$multiply": [-1.0, "$balance"]}, 10.0]}}`),
+		Entry("field to field", "spent > budget", `{"$expr": {"$gt": ["$spent", "$budget"]}}`),
+		Entry("string literal with dollar", "a + 1 = '$x'",
+			`{"$expr": {"$eq": [{"$add": ["$a", 1.0]}, {"$literal": "$x"}]}}`),
+		Entry("expression like", "first + last like 'jo%'",
+			`{"$expr": {"$regexMatch": {"input": {"$add": ["$first", "$last"]}, "regex": "^jo(?s:.*)$"}}}`),
+		Entry("expression between", "a - b between 1 and 2",
+			`{"$expr": {"$and": [{"$gte": [{"$subtract": ["$a", "$b"]}, 1.0]}, {"$lte": [{"$subtract": ["$a", "$b"]}, 2.0]}]}}`),
+		Entry("expression is null", "a / 2 is null",
+			`{"$expr": {"$eq": [{"$ifNull": [{"$divide": ["$a", 2.0]}, null]}, null]}}`),
+		Entry("expression in", "a * 2 in [2, 4]", `{"$expr": {"$in": [{"$multiply": ["$a", 2.0]}, [2.0, 4.0]]}}`),
+		Entry("not expression", "not (a > b)", `{"$nor": [{"$expr": {"$gt": ["$a", "$b"]}}]}`),
+	)
+
+	DescribeTable("Returns errors",
+		func(input string, expected error) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Walk(tree)
+			Expect(err).To(BeAssignableToTypeOf(expected))
+		},
+
+		Entry("not a filter", "'joe'", tsl.UnexpectedLiteralError{}),
+		Entry("like with identifier pattern", "name + 1 like pattern", tsl.TypeMismatchError{}),
+		Entry("like with trailing escape", `name like 'a\\'`, tsl.LikePatternError{}),
+		Entry("any of an expression", "any (a + b > 1)", tsl.UnexpectedOperatorError{}),
+	)
+})
@@ -0,0 +1,82 @@
+package normalize
+
+import (
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// converter collects the clauses of a normal form
+type converter struct {
+	outer, inner tsl.Operator
+	form         string
+	limit        int
+}
+
+// clauses returns the clauses of a tree in negation normal form, the
+// clauses are joined by the outer operator and their expressions by the
+// inner operator
+func (c converter) clauses(n *tsl.TSLNode) ([][]*tsl.TSLNode, error) {
+	op, ok := n.AsExprOp()
+	if !ok || (op.Operator != c.outer && op.Operator != c.inner) {
+		return [][]*tsl.TSLNode{{n}}, nil
+	}
+
+	left, err := c.clauses(op.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.clauses(op.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	if op.Operator == c.outer {
+		return c.unique(append(left, right...))
+	}
+
+	// Distribute the inner operator over the outer one, each clause of
+	// the left side is joined with each clause of the right side
+	if len(left)*len(right) > c.limit {
+		return nil, TooLargeError{Form: c.form, Limit: c.limit}
+	}
+	product := make([][]*tsl.TSLNode, 0, len(left)*len(right))
+	for _, l := range left {
+		for _, r := range right {
+			product = append(product, uniqueTerms(append(append([]*tsl.TSLNode{}, l...), r...)))
+		}
+	}
+	return c.unique(product)
+}
+
+// unique removes duplicated clauses, and checks the clause limit
+func (c converter) unique(clauses [][]*tsl.TSLNode) ([][]*tsl.TSLNode, error) {
+	seen := map[string]bool{}
+	result := clauses[:0]
+	for _, clause := range clauses {
+		key := join(clause, c.inner).String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, clause)
+	}
+
+	if len(result) > c.limit {
+		return nil, TooLargeError{Form: c.form, Limit: c.limit}
+	}
+	return result, nil
+}
+
+// uniqueTerms removes duplicated expressions from a clause
+func uniqueTerms(terms []*tsl.TSLNode) []*tsl.TSLNode {
+	seen := map[string]bool{}
+	result := terms[:0]
+	for _, term := range terms {
+		key := term.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, term)
+	}
+	return result
+}
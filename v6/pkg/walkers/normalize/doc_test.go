@@ -0,0 +1,45 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalize
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the normalize package.
+func Example() {
+	// Set a TSL input string.
+	input := "author = 'Joe' and not (pages < 100 and title not like '%Book%')"
+
+	// Parse input string into a TSL tree.
+	tree, _ := tsl.ParseTSL(input)
+
+	// Convert the tree into normal forms.
+	nnf, _ := NNF(tree)
+	cnf, _ := CNF(tree, 0)
+	dnf, _ := DNF(tree, 0)
+
+	fmt.Println(nnf)
+	fmt.Println(cnf)
+	fmt.Println(dnf)
+
+	// Output:
+	// author = 'Joe' AND (NOT (pages < 100) OR title LIKE '%Book%')
+	// author = 'Joe' AND (NOT (pages < 100) OR title LIKE '%Book%')
+	// author = 'Joe' AND NOT (pages < 100) OR author = 'Joe' AND title LIKE '%Book%'
+}
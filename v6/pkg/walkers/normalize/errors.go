@@ -0,0 +1,14 @@
+package normalize
+
+import "fmt"
+
+// TooLargeError is returned when the normal form of a tree has more
+// clauses than the limit
+type TooLargeError struct {
+	Form  string
+	Limit int
+}
+
+func (e TooLargeError) Error() string {
+	return fmt.Sprintf("%s has more than %d clauses", e.Form, e.Limit)
+}
@@ -0,0 +1,245 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package normalize converts TSL trees into boolean normal forms.
+//
+// The normal forms keep the results of the semantics walker, where a
+// comparison with a null field is false. So NOT over ordering comparisons
+// is kept, "NOT a < 1" is not "a >= 1", unless the Options say the fields
+// are never null.
+package normalize
+
+import (
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// DefaultMaxClauses is the clause limit of CNF and DNF when the limit
+// argument is not positive
+const DefaultMaxClauses = 1024
+
+// invertedComparisons maps comparison operators to their negation, the
+// ordering comparisons are not inverted, "NOT a < 1" is true when a is
+// null while "a >= 1" is false
+var invertedComparisons = map[tsl.Operator]tsl.Operator{
+	tsl.OpEQ:  tsl.OpNE,
+	tsl.OpNE:  tsl.OpEQ,
+	tsl.OpREQ: tsl.OpRNE,
+	tsl.OpRNE: tsl.OpREQ,
+}
+
+// invertedOrderings maps ordering comparisons to their negation when the
+// compared fields are never null
+var invertedOrderings = map[tsl.Operator]tsl.Operator{
+	tsl.OpLT: tsl.OpGE,
+	tsl.OpLE: tsl.OpGT,
+	tsl.OpGT: tsl.OpLE,
+	tsl.OpGE: tsl.OpLT,
+}
+
+// Options changes how NOT is pushed down to the comparisons
+type Options struct {
+	// NotNull inverts negated ordering comparisons, "NOT a < 1" is
+	// "a >= 1", it should only be set when no compared field is null
+	NotNull bool
+}
+
+// dualOperators maps AND to OR and OR to AND
+var dualOperators = map[tsl.Operator]tsl.Operator{
+	tsl.OpAnd: tsl.OpOr,
+	tsl.OpOr:  tsl.OpAnd,
+}
+
+// NNF returns the negation normal form of a tree, where NOT is only
+// applied to expressions that are not AND, OR or NOT. The input tree is not
+// modified.
+//
+//	tree, _ := tsl.ParseTSL("not (a = 1 or not (b = 2 and c like 'x%'))")
+//	nnf, _ := normalize.NNF(tree)
+//	// a != 1 AND (b = 2 AND c LIKE 'x%')
+//
+// NOT is pushed down using De Morgan's laws, double negations are removed,
+// negated equality and regular expression comparisons are inverted, "NOT
+// a = 1" is "a != 1", and negated boolean literals are folded. Other negated
+// expressions, such as NOT IN, NOT LIKE, NOT BETWEEN or NOT over ordering
+// comparisons, are kept as NOT over the expression, so the normal form
+// keeps the results of the semantics walker when fields are null, see
+// Options to invert ordering comparisons.
+func NNF(n *tsl.TSLNode) (*tsl.TSLNode, error) {
+	return Options{}.NNF(n)
+}
+
+// NNF returns the negation normal form of a tree using the options.
+//
+//	tree, _ := tsl.ParseTSL("not (a < 1 or b = 2)")
+//	nnf, _ := normalize.Options{NotNull: true}.NNF(tree)
+//	// a >= 1 AND b != 2
+func (o Options) NNF(n *tsl.TSLNode) (*tsl.TSLNode, error) {
+	if n == nil {
+		return nil, nil
+	}
+	return o.nnf(n, false), nil
+}
+
+// inverted returns the negation of a comparison operator
+func (o Options) inverted(operator tsl.Operator) (tsl.Operator, bool) {
+	if inverted, ok := invertedComparisons[operator]; ok {
+		return inverted, true
+	}
+	if o.NotNull {
+		inverted, ok := invertedOrderings[operator]
+		return inverted, ok
+	}
+	return 0, false
+}
+
+// nnf returns the negation normal form of a node, or of its negation
+func (o Options) nnf(n *tsl.TSLNode, negated bool) *tsl.TSLNode {
+	op, ok := n.AsExprOp()
+	if !ok {
+		if value, ok := n.AsBool(); ok && n.Type() == tsl.KindBooleanLiteral && negated {
+			literal := tsl.NewBooleanLiteral(!value)
+			literal.SetPosition(n.Position())
+			return literal
+		}
+		return negate(n, negated)
+	}
+
+	switch op.Operator {
+	case tsl.OpNot:
+		return o.nnf(op.Right, !negated)
+
+	case tsl.OpAnd, tsl.OpOr:
+		operator := op.Operator
+		if negated {
+			// De Morgan's laws
+			operator = dualOperators[operator]
+		}
+		left, right := o.nnf(op.Left, negated), o.nnf(op.Right, negated)
+		if !negated && left == op.Left && right == op.Right {
+			return n
+		}
+		result := tsl.NewBinaryExpr(operator, left, right)
+		result.SetPosition(n.Position())
+		return result
+	}
+
+	if inverted, ok := o.inverted(op.Operator); ok && negated && n.Type() == tsl.KindBinaryExpr {
+		result := tsl.NewBinaryExpr(inverted, op.Left, op.Right)
+		result.SetPosition(n.Position())
+		return result
+	}
+
+	return negate(n, negated)
+}
+
+// negate returns NOT over a node if negated is true
+func negate(n *tsl.TSLNode, negated bool) *tsl.TSLNode {
+	if !negated {
+		return n
+	}
+	result := tsl.NewUnaryExpr(tsl.OpNot, n)
+	result.SetPosition(n.Position())
+	return result
+}
+
+// Conjuncts returns the operands of a chain of AND expressions, a tree that
+// is not an AND expression is a single operand.
+//
+//	tree, _ := tsl.ParseTSL("a = 1 and (b = 2 and c = 3)")
+//	normalize.Conjuncts(tree)
+//	// [a = 1, b = 2, c = 3]
+func Conjuncts(n *tsl.TSLNode) []*tsl.TSLNode {
+	return tsl.Flatten(n, tsl.OpAnd)
+}
+
+// Disjuncts returns the operands of a chain of OR expressions, a tree that
+// is not an OR expression is a single operand.
+func Disjuncts(n *tsl.TSLNode) []*tsl.TSLNode {
+	return tsl.Flatten(n, tsl.OpOr)
+}
+
+// CNF returns the conjunctive normal form of a tree, an AND of clauses
+// where each clause is an OR of expressions in negation normal form.
+//
+//	tree, _ := tsl.ParseTSL("a = 1 or (b = 2 and c = 3)")
+//	cnf, _ := normalize.CNF(tree, 0)
+//	// (a = 1 OR b = 2) AND (a = 1 OR c = 3)
+//
+// Converting a tree may multiply the number of clauses, if the result has
+// more than maxClauses clauses, or DefaultMaxClauses when maxClauses is
+// not positive, CNF returns a TooLargeError. Duplicated clauses, and
+// duplicated expressions in a clause, are removed.
+func CNF(n *tsl.TSLNode, maxClauses int) (*tsl.TSLNode, error) {
+	return Options{}.CNF(n, maxClauses)
+}
+
+// CNF returns the conjunctive normal form of a tree using the options.
+func (o Options) CNF(n *tsl.TSLNode, maxClauses int) (*tsl.TSLNode, error) {
+	return o.normalForm(n, tsl.OpAnd, tsl.OpOr, "CNF", maxClauses)
+}
+
+// DNF returns the disjunctive normal form of a tree, an OR of clauses
+// where each clause is an AND of expressions in negation normal form.
+//
+//	tree, _ := tsl.ParseTSL("a = 1 and (b = 2 or c = 3)")
+//	dnf, _ := normalize.DNF(tree, 0)
+//	// a = 1 AND b = 2 OR a = 1 AND c = 3
+//
+// The clause limit works as in CNF.
+func DNF(n *tsl.TSLNode, maxClauses int) (*tsl.TSLNode, error) {
+	return Options{}.DNF(n, maxClauses)
+}
+
+// DNF returns the disjunctive normal form of a tree using the options.
+func (o Options) DNF(n *tsl.TSLNode, maxClauses int) (*tsl.TSLNode, error) {
+	return o.normalForm(n, tsl.OpOr, tsl.OpAnd, "DNF", maxClauses)
+}
+
+// normalForm converts a tree into clauses joined by outer, where each
+// clause joins expressions by inner
+func (o Options) normalForm(n *tsl.TSLNode, outer, inner tsl.Operator, form string, maxClauses int) (*tsl.TSLNode, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if maxClauses <= 0 {
+		maxClauses = DefaultMaxClauses
+	}
+
+	c := converter{outer: outer, inner: inner, form: form, limit: maxClauses}
+	clauses, err := c.clauses(o.nnf(n, false))
+	if err != nil {
+		return nil, err
+	}
+
+	var result *tsl.TSLNode
+	for _, clause := range clauses {
+		node := join(clause, inner)
+		if result == nil {
+			result = node
+			continue
+		}
+		result = tsl.NewBinaryExpr(outer, result, node)
+	}
+	return result, nil
+}
+
+// join joins expressions by an operator
+func join(terms []*tsl.TSLNode, operator tsl.Operator) *tsl.TSLNode {
+	result := terms[0]
+	for _, term := range terms[1:] {
+		result = tsl.NewBinaryExpr(operator, result, term)
+	}
+	return result
+}
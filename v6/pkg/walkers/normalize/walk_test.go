@@ -0,0 +1,233 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalize
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/internal/testgen"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+)
+
+func TestNormalizeWalker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Normalize walker")
+}
+
+// transform is NNF, CNF or DNF
+type transform func(n *tsl.TSLNode) (*tsl.TSLNode, error)
+
+var (
+	cnf transform = func(n *tsl.TSLNode) (*tsl.TSLNode, error) { return CNF(n, 0) }
+	dnf transform = func(n *tsl.TSLNode) (*tsl.TSLNode, error) { return DNF(n, 0) }
+
+	notNull              = Options{NotNull: true}
+	notNullNNF transform = notNull.NNF
+	notNullCNF transform = func(n *tsl.TSLNode) (*tsl.TSLNode, error) { return notNull.CNF(n, 0) }
+)
+
+var _ = Describe("Normal forms", func() {
+	DescribeTable("Converts the tree",
+		func(f transform, input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual, err := f(tree)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual.String()).To(Equal(expected))
+		},
+
+		// Negation normal form
+		Entry("nnf of a comparison", NNF, "a = 1", "a = 1"),
+		Entry("nnf inverts equality", NNF, "not (a = 1) and not (b != 2)", "a != 1 AND b = 2"),
+		Entry("nnf keeps not over ordering comparisons", NNF, "not (b < 2) or not (c >= 3)", "NOT (b < 2) OR NOT (c >= 3)"),
+		Entry("nnf inverts regular expressions", NNF, "not (a ~= 'x') or not (b ~! 'y')", "a ~! 'x' OR b ~= 'y'"),
+		Entry("nnf applies De Morgan", NNF, "not (a = 1 or b = 2)", "a != 1 AND b != 2"),
+		Entry("nnf applies De Morgan deeply", NNF, "not (a = 1 or not (b < 2 and c like 'x%'))", "a != 1 AND (b < 2 AND c LIKE 'x%')"),
+		Entry("nnf removes double negation", NNF, "not not (a in [1, 2])", "a IN [1, 2]"),
+		Entry("nnf keeps not in", NNF, "not (a in [1, 2] and b between 1 and 2)", "a NOT IN [1, 2] OR b NOT BETWEEN 1 AND 2"),
+		Entry("nnf keeps not like", NNF, "not (a like 'x%' or b is null)", "a NOT LIKE 'x%' AND b IS NOT NULL"),
+		Entry("nnf folds literals", NNF, "not (true and a = 1)", "FALSE OR a != 1"),
+		Entry("nnf keeps quantifiers", NNF, "not any (tags = 'x')", "NOT ANY (tags = 'x')"),
+		Entry("nnf of not null fields inverts ordering comparisons", notNullNNF,
+			"not (a < 1 or b <= 2) or not (c > 3 and d >= 4)", "a >= 1 AND b > 2 OR (c <= 3 OR d < 4)"),
+		Entry("nnf of not null fields keeps not in", notNullNNF, "not (a in [1, 2])", "a NOT IN [1, 2]"),
+
+		// Conjunctive normal form
+		Entry("cnf of a clause", cnf, "a = 1 or b = 2", "a = 1 OR b = 2"),
+		Entry("cnf distributes or", cnf, "a = 1 or (b = 2 and c = 3)", "(a = 1 OR b = 2) AND (a = 1 OR c = 3)"),
+		Entry("cnf of two conjunctions", cnf, "(a = 1 and b = 2) or (c = 3 and d = 4)",
+			"(a = 1 OR c = 3) AND (a = 1 OR d = 4) AND (b = 2 OR c = 3) AND (b = 2 OR d = 4)"),
+		Entry("cnf of a negation", cnf, "not (a = 1 and (b = 2 or c = 3))", "(a != 1 OR b != 2) AND (a != 1 OR c != 3)"),
+		Entry("cnf removes duplicates", cnf, "(a = 1 and b = 2) or a = 1", "a = 1 AND (b = 2 OR a = 1)"),
+		Entry("cnf removes duplicated clauses", cnf, "(a = 1 or b = 2) and (b = 2 or a = 1) and (a = 1 or b = 2)",
+			"(a = 1 OR b = 2) AND (b = 2 OR a = 1)"),
+
+		Entry("cnf of not null fields", notNullCNF, "not (a < 1 and b = 2)", "a >= 1 OR b != 2"),
+
+		// Disjunctive normal form
+		Entry("dnf distributes and", dnf, "a = 1 and (b = 2 or c = 3)", "a = 1 AND b = 2 OR a = 1 AND c = 3"),
+		Entry("dnf of a negation", dnf, "not (a = 1 or b = 2) and c = 3", "a != 1 AND b != 2 AND c = 3"),
+		Entry("dnf of nested expressions", dnf, "(a = 1 or b = 2) and (c = 3 or d = 4)",
+			"a = 1 AND c = 3 OR a = 1 AND d = 4 OR b = 2 AND c = 3 OR b = 2 AND d = 4"),
+	)
+
+	It("Flattens chains", func() {
+		tree, err := tsl.ParseTSL("a = 1 and (b = 2 and (c = 3 or d = 4)) and e = 5")
+		Expect(err).ToNot(HaveOccurred())
+
+		var conjuncts []string
+		for _, n := range Conjuncts(tree) {
+			conjuncts = append(conjuncts, n.String())
+		}
+		Expect(conjuncts).To(Equal([]string{"a = 1", "b = 2", "c = 3 OR d = 4", "e = 5"}))
+		Expect(Disjuncts(tree)).To(HaveLen(1))
+		Expect(Disjuncts(Conjuncts(tree)[2])).To(HaveLen(2))
+	})
+
+	It("Keeps positions", func() {
+		tree, err := tsl.ParseTSL("x = 1 and not (y = 2)")
+		Expect(err).ToNot(HaveOccurred())
+
+		nnf, err := NNF(tree)
+		Expect(err).ToNot(HaveOccurred())
+		conjuncts := Conjuncts(nnf)
+		Expect(conjuncts[0].Position()).To(Equal(0))
+		Expect(conjuncts[1].String()).To(Equal("y != 2"))
+		Expect(conjuncts[1].Position()).To(Equal(Conjuncts(tree)[1].Value().(tsl.TSLExpressionOp).Right.Position()))
+	})
+
+	It("Does not modify the input tree", func() {
+		tree, err := tsl.ParseTSL("not (a = 1 or (b = 2 and c = 3))")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = CNF(tree, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tree.String()).To(Equal("NOT (a = 1 OR b = 2 AND c = 3)"))
+	})
+
+	It("Limits the number of clauses", func() {
+		var pairs []string
+		for i := 0; i < 10; i++ {
+			pairs = append(pairs, fmt.Sprintf("(a%d = 1 and b%d = 1)", i, i))
+		}
+		tree, err := tsl.ParseTSL(strings.Join(pairs, " or "))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = CNF(tree, 100)
+		Expect(err).To(Equal(TooLargeError{Form: "CNF", Limit: 100}))
+		Expect(err.Error()).To(Equal("CNF has more than 100 clauses"))
+
+		cnf, err := CNF(tree, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(Conjuncts(cnf)).To(HaveLen(1024))
+
+		dnf, err := DNF(tree, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(Disjuncts(dnf)).To(HaveLen(10))
+	})
+})
+
+// isLiteral returns true for expressions that are not AND or OR, and for
+// NOT over such expressions
+func isLiteral(n *tsl.TSLNode) bool {
+	op, ok := n.AsExprOp()
+	if !ok {
+		return true
+	}
+	switch op.Operator {
+	case tsl.OpAnd, tsl.OpOr:
+		return false
+	case tsl.OpNot:
+		inner, ok := op.Right.AsExprOp()
+		return !ok || (inner.Operator != tsl.OpAnd && inner.Operator != tsl.OpOr && inner.Operator != tsl.OpNot)
+	}
+	return true
+}
+
+// hasNull returns true if one of the fields of a record is null
+func hasNull(record map[string]interface{}) bool {
+	for _, v := range record {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+var _ = Describe("Normal form properties", func() {
+	records := testgen.Records()
+
+	DescribeTable("Keeps the results of the semantics walker",
+		func(f transform, outer, inner tsl.Operator, notNull bool) {
+			g := testgen.NewGenerator(42)
+
+			for i := 0; i < 300; i++ {
+				input := g.Boolean(4)
+				tree, err := tsl.ParseTSL(input)
+				Expect(err).ToNot(HaveOccurred(), input)
+
+				normal, err := f(tree)
+				Expect(err).ToNot(HaveOccurred(), input)
+
+				// Check the shape of the normal form
+				Expect(tsl.Walk(normal, tsl.Visitor{
+					Pre: func(n *tsl.TSLNode) (bool, error) {
+						if op, ok := n.AsExprOp(); ok && op.Operator == tsl.OpNot && !isLiteral(n) {
+							return false, fmt.Errorf("NOT over %s", op.Right)
+						}
+						return true, nil
+					},
+				})).To(Succeed(), input)
+				if outer != 0 {
+					for _, clause := range tsl.Flatten(normal, outer) {
+						for _, term := range tsl.Flatten(clause, inner) {
+							Expect(isLiteral(term)).To(BeTrue(), "%s => %s", input, normal)
+						}
+					}
+				}
+
+				for _, record := range records {
+					if notNull && hasNull(record) {
+						continue
+					}
+					eval := testgen.Eval(record)
+
+					expected, err := semantics.Walk(tree, eval)
+					if err != nil {
+						continue
+					}
+					actual, err := semantics.Walk(normal, eval)
+					Expect(err).ToNot(HaveOccurred(), "%s => %s", input, normal)
+					Expect(reflect.DeepEqual(actual, expected)).To(BeTrue(), "%s => %s on %v", input, normal, record)
+				}
+			}
+		},
+
+		Entry("NNF", NNF, tsl.Operator(0), tsl.Operator(0), false),
+		Entry("CNF", cnf, tsl.OpAnd, tsl.OpOr, false),
+		Entry("DNF", dnf, tsl.OpOr, tsl.OpAnd, false),
+		Entry("NNF of not null fields", notNullNNF, tsl.Operator(0), tsl.Operator(0), true),
+		Entry("CNF of not null fields", notNullCNF, tsl.OpAnd, tsl.OpOr, true),
+	)
+})
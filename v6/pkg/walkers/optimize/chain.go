@@ -0,0 +1,147 @@
+package optimize
+
+import (
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// bound is a comparison of a number field with a number literal
+type bound struct {
+	field     string
+	value     float64
+	inclusive bool
+	lower     bool
+}
+
+// key identifies the field and the direction of a bound
+func (b bound) key() string {
+	if b.lower {
+		return b.field + " >"
+	}
+	return b.field + " <"
+}
+
+// chain simplifies a chain of AND or OR expressions
+func (o optimizer) chain(n *tsl.TSLNode, operator tsl.Operator) *tsl.TSLNode {
+	// TRUE is the identity of AND and absorbs OR, FALSE is the identity of
+	// OR and absorbs AND
+	identity := operator == tsl.OpAnd
+
+	changed := false
+	absorbed := false
+	seen := map[string]bool{}
+	bounds := map[string]int{}
+	var terms []*tsl.TSLNode
+
+	for _, term := range tsl.Flatten(n, operator) {
+		if value, ok := term.AsBool(); ok && term.Type() == tsl.KindBooleanLiteral {
+			changed = true
+			if value != identity {
+				absorbed = true
+			}
+			continue
+		}
+
+		key := term.String()
+		if seen[key] {
+			changed = true
+			continue
+		}
+		seen[key] = true
+
+		// Merge bounds of the same field and direction
+		if b, ok := o.bound(term); ok {
+			if i, ok := bounds[b.key()]; ok {
+				prev, _ := o.bound(terms[i])
+				if keepNew(prev, b, operator == tsl.OpAnd) {
+					terms[i] = term
+				}
+				changed = true
+				continue
+			}
+			bounds[b.key()] = len(terms)
+		}
+
+		terms = append(terms, term)
+	}
+
+	if !changed {
+		return n
+	}
+
+	if absorbed {
+		for _, term := range terms {
+			if !o.isBoolean(term) {
+				return n
+			}
+		}
+		literal := tsl.NewBooleanLiteral(!identity)
+		literal.SetPosition(n.Position())
+		return literal
+	}
+
+	switch len(terms) {
+	case 0:
+		literal := tsl.NewBooleanLiteral(identity)
+		literal.SetPosition(n.Position())
+		return literal
+	case 1:
+		// Without the AND / OR the term must be a boolean by itself
+		if !o.isBoolean(terms[0]) {
+			return n
+		}
+		return terms[0]
+	}
+
+	result := terms[0]
+	for _, term := range terms[1:] {
+		result = tsl.NewBinaryExpr(operator, result, term)
+	}
+	return result
+}
+
+// bound returns the bound of a comparison of a field with a number
+func (o optimizer) bound(n *tsl.TSLNode) (bound, bool) {
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != tsl.KindBinaryExpr || op.Left.Type() != tsl.KindIdentifier {
+		return bound{}, false
+	}
+	value, ok := op.Right.AsFloat64()
+	if !ok || op.Right.Type() != tsl.KindNumericLiteral {
+		return bound{}, false
+	}
+	field := op.Left.Value().(string)
+	if o.arrays[field] {
+		return bound{}, false
+	}
+
+	b := bound{field: field, value: value}
+	switch op.Operator {
+	case tsl.OpGT:
+		b.lower = true
+	case tsl.OpGE:
+		b.lower, b.inclusive = true, true
+	case tsl.OpLT:
+	case tsl.OpLE:
+		b.inclusive = true
+	default:
+		return bound{}, false
+	}
+	return b, true
+}
+
+// keepNew returns true if the bound b replaces the bound prev, in an AND
+// chain the tighter bound is kept, in an OR chain the looser one
+func keepNew(prev, b bound, tighter bool) bool {
+	if prev.value == b.value {
+		// At the same value the exclusive bound is tighter
+		if tighter {
+			return prev.inclusive && !b.inclusive
+		}
+		return !prev.inclusive && b.inclusive
+	}
+
+	// For lower bounds a larger value is tighter, for upper bounds a
+	// smaller one
+	tighterValue := (b.value > prev.value) == b.lower
+	return tighterValue == tighter
+}
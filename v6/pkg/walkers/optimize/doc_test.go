@@ -0,0 +1,39 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the optimize package.
+func Example() {
+	// Set a TSL input string.
+	input := "pages > 10 * 10 and not not (author = 'Joe') and pages > 50 and tags in ['new'] and true"
+
+	// Parse input string into a TSL tree.
+	tree, _ := tsl.ParseTSL(input)
+
+	// Simplify the tree.
+	newTree, _ := Walk(tree)
+
+	fmt.Println(newTree)
+
+	// Output:
+	// pages > 100 AND author = 'Joe' AND tags = 'new'
+}
@@ -0,0 +1,167 @@
+package optimize
+
+import (
+	"math"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+)
+
+// optimizer holds the fields of a tree that are used as arrays
+type optimizer struct {
+	arrays map[string]bool
+}
+
+// booleanOperators are the operators that evaluate to a boolean when their
+// operands are not arrays
+var booleanOperators = map[tsl.Operator]bool{
+	tsl.OpAnd: true, tsl.OpOr: true, tsl.OpNot: true,
+	tsl.OpEQ: true, tsl.OpNE: true, tsl.OpLT: true, tsl.OpLE: true, tsl.OpGT: true, tsl.OpGE: true,
+	tsl.OpIn: true, tsl.OpBetween: true, tsl.OpLike: true, tsl.OpILike: true,
+	tsl.OpREQ: true, tsl.OpRNE: true, tsl.OpIs: true, tsl.OpMatch: true,
+}
+
+// arrayIdentifiers returns the identifiers used by ANY, ALL, LEN and SUM
+func arrayIdentifiers(n *tsl.TSLNode) map[string]bool {
+	arrays := map[string]bool{}
+	_ = tsl.Walk(n, tsl.Visitor{
+		Pre: func(n *tsl.TSLNode) (bool, error) {
+			op, ok := n.AsExprOp()
+			if !ok {
+				return true, nil
+			}
+			switch op.Operator {
+			case tsl.OpAny, tsl.OpAll, tsl.OpLen, tsl.OpSum:
+				for _, name := range identifiers(op.Right) {
+					arrays[name] = true
+				}
+				return false, nil
+			}
+			return true, nil
+		},
+	})
+	return arrays
+}
+
+// identifiers returns the identifiers used in a tree
+func identifiers(n *tsl.TSLNode) []string {
+	var names []string
+	_ = tsl.Walk(n, tsl.Visitor{
+		Pre: func(n *tsl.TSLNode) (bool, error) {
+			if n.Type() == tsl.KindIdentifier {
+				names = append(names, n.Value().(string))
+			}
+			return true, nil
+		},
+	})
+	return names
+}
+
+// isBoolean returns true if a node evaluates to a boolean, or fails
+func (o optimizer) isBoolean(n *tsl.TSLNode) bool {
+	switch n.Type() {
+	case tsl.KindBooleanLiteral:
+		return true
+	case tsl.KindBinaryExpr, tsl.KindUnaryExpr:
+		op := n.Value().(tsl.TSLExpressionOp)
+		if op.Operator == tsl.OpAny || op.Operator == tsl.OpAll {
+			return true
+		}
+		if !booleanOperators[op.Operator] {
+			return false
+		}
+		for _, name := range identifiers(n) {
+			if o.arrays[name] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// simplify is called by tsl.Rewrite on each node, after the children of
+// the node were simplified
+func (o optimizer) simplify(n *tsl.TSLNode) (*tsl.TSLNode, error) {
+	if folded, ok := fold(n); ok {
+		return folded, nil
+	}
+
+	op, ok := n.AsExprOp()
+	if !ok {
+		return n, nil
+	}
+
+	switch op.Operator {
+	case tsl.OpNot:
+		// NOT NOT x is x
+		if inner, ok := op.Right.AsExprOp(); ok && inner.Operator == tsl.OpNot && o.isBoolean(inner.Right) {
+			return inner.Right, nil
+		}
+
+	case tsl.OpIn:
+		// x IN [v] is x = v
+		if arr, ok := op.Right.AsArray(); ok && len(arr.Values) == 1 && isEqualityLiteral(arr.Values[0]) {
+			return tsl.NewBinaryExpr(tsl.OpEQ, op.Left, arr.Values[0]), nil
+		}
+
+	case tsl.OpAnd, tsl.OpOr:
+		return o.chain(n, op.Operator), nil
+	}
+
+	return n, nil
+}
+
+// fold evaluates an expression that uses no identifiers into a literal
+func fold(n *tsl.TSLNode) (*tsl.TSLNode, bool) {
+	if n.Type() != tsl.KindBinaryExpr && n.Type() != tsl.KindUnaryExpr {
+		return nil, false
+	}
+	if len(identifiers(n)) > 0 {
+		return nil, false
+	}
+
+	// Expressions that fail, e.g. division by zero, are left as is
+	value, err := semantics.Walk(n, func(string) (interface{}, bool) { return nil, false })
+	if err != nil {
+		return nil, false
+	}
+
+	var literal *tsl.TSLNode
+	switch v := value.(type) {
+	case bool:
+		literal = tsl.NewBooleanLiteral(v)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		literal = tsl.NewNumericLiteral(v)
+	default:
+		return nil, false
+	}
+
+	literal.SetPosition(n.Position())
+	return literal, true
+}
+
+// isEqualityLiteral returns true if comparing a value with the literal
+// using = gives the same result as finding the value in a list holding the
+// literal. Strings that look like dates are compared as dates by =, so
+// they are excluded.
+func isEqualityLiteral(n *tsl.TSLNode) bool {
+	switch n.Type() {
+	case tsl.KindNumericLiteral, tsl.KindBooleanLiteral:
+		return true
+	case tsl.KindStringLiteral:
+		s := n.Value().(string)
+		if _, err := time.Parse(time.RFC3339, s); err == nil {
+			return false
+		}
+		if _, err := time.Parse("2006-01-02", s); err == nil {
+			return false
+		}
+		return true
+	}
+	return false
+}
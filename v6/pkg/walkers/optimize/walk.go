@@ -0,0 +1,53 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package optimize simplifies TSL trees.
+package optimize
+
+import (
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Walk returns a simplified tree that is equivalent to the input tree, the
+// input tree is not modified.
+//
+//	tree, _ := tsl.ParseTSL("pages > 10 * 10 and not not (author = 'Joe') and pages > 50")
+//	newTree, _ := optimize.Walk(tree)
+//	// pages > 100 AND author = 'Joe'
+//
+// The simplifications are:
+//
+//   - constant expressions, such as "10 * 10", "-(-3)" or "1 < 2", are
+//     folded into literals,
+//   - double negations are removed,
+//   - TRUE and FALSE operands of AND and OR are removed or absorb the
+//     expression,
+//   - duplicated operands of AND and OR are removed,
+//   - single value IN lists become equality checks, "x IN [5]" is "x = 5",
+//   - number bounds of the same field are merged, "a > 5 AND a > 7" is
+//     "a > 7" and "a < 5 OR a < 7" is "a < 7".
+//
+// Rules that drop an operand only apply when the remaining expression is
+// known to be boolean, so a filter that evaluates without error has the
+// same result before and after the optimization. Fields used by ANY, ALL,
+// LEN or SUM are arrays, and expressions using them are left as is.
+func Walk(n *tsl.TSLNode) (*tsl.TSLNode, error) {
+	if n == nil {
+		return nil, nil
+	}
+
+	o := optimizer{arrays: arrayIdentifiers(n)}
+	return tsl.Rewrite(n, o.simplify)
+}
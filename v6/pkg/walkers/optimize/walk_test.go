@@ -0,0 +1,159 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/internal/testgen"
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+)
+
+func TestOptimizeWalker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Optimize walker")
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Simplifies the tree",
+		func(input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			optimized, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(optimized.String()).To(Equal(expected))
+		},
+
+		// Constant folding
+		Entry("constant arithmetic", "pages > 10 * 10", "pages > 100"),
+		Entry("nested constant arithmetic", "pages > (1 + 2) * 3 - 4 / 2", "pages > 7"),
+		Entry("double minus", "pages = -(-3)", "pages = 3"),
+		Entry("remainder", "pages = 7 % 3", "pages = 1"),
+		Entry("constant comparison", "1 < 2 and pages > 1", "pages > 1"),
+		Entry("constant string comparison", "'a' = 'b' or pages > 1", "pages > 1"),
+		Entry("constant date comparison", "2020-01-01 < 2021-01-01T00:00:00Z", "TRUE"),
+		Entry("division by zero is kept", "pages > 1 / 0", "pages > 1 / 0"),
+		Entry("constants in lists", "pages in [1 + 1, 3]", "pages IN [2, 3]"),
+
+		// Negation
+		Entry("double negation", "not not (pages > 1)", "pages > 1"),
+		Entry("triple negation", "not not not (pages > 1)", "NOT (pages > 1)"),
+		Entry("double negation of a field", "not not loaned", "NOT NOT loaned"),
+		Entry("negated constant", "not true or pages > 1", "pages > 1"),
+
+		// Boolean identities
+		Entry("and true", "pages > 1 and true", "pages > 1"),
+		Entry("and false", "pages > 1 and false", "FALSE"),
+		Entry("or true", "pages > 1 or (a = 2 and true) or true", "TRUE"),
+		Entry("or false", "false or pages > 1", "pages > 1"),
+		Entry("and true of a field", "loaned and true", "loaned AND TRUE"),
+		Entry("and false of a field", "loaned and false", "loaned AND FALSE"),
+		Entry("and true of fields", "loaned and sold and true", "loaned AND sold"),
+		Entry("duplicated operands", "a = 1 and b = 2 and a = 1", "a = 1 AND b = 2"),
+		Entry("duplicated nested operands", "a = 1 and (b = 2 and a = 1)", "a = 1 AND b = 2"),
+		Entry("duplicated or operands", "a = 1 or a = 1", "a = 1"),
+
+		// Lists
+		Entry("single value in", "pages in [5]", "pages = 5"),
+		Entry("single value not in", "name not in ['joe']", "NOT (name = 'joe')"),
+		Entry("single date in is kept", "date in ['2020-01-01']", "date IN [2020-01-01]"),
+		Entry("two values in", "pages in [5, 6]", "pages IN [5, 6]"),
+
+		// Ranges
+		Entry("lower bounds", "a > 5 and a > 7", "a > 7"),
+		Entry("upper bounds", "a < 5 and b = 1 and a <= 7", "a < 5 AND b = 1"),
+		Entry("inclusive and exclusive bounds", "a >= 5 and a > 5", "a > 5"),
+		Entry("negative bounds", "a > -5 and a > -7", "a > -5"),
+		Entry("or bounds", "a > 5 or a >= 5 or a > 7", "a >= 5"),
+		Entry("or upper bounds", "a < 5 or a < 7", "a < 7"),
+		Entry("opposite bounds", "a > 5 and a < 7", "a > 5 AND a < 7"),
+		Entry("bounds of fields", "a > 5 and b > 7", "a > 5 AND b > 7"),
+		Entry("bounds of arrays are kept", "any (a > 5) and a > 7 and a > 8", "ANY (a > 5) AND a > 7 AND a > 8"),
+		Entry("bounds after folding", "a > 2 * 3 and a > 5", "a > 6"),
+
+		// Arrays
+		Entry("array and true is kept", "any ((tags = 'a') and true)", "ANY (tags = 'a' AND TRUE)"),
+		Entry("array fields are kept", "all (not not (scores > 1))", "ALL NOT NOT (scores > 1)"),
+	)
+
+	It("Does not modify the input tree", func() {
+		tree, err := tsl.ParseTSL("pages > 10 * 10 and true")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = Walk(tree)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tree.String()).To(Equal("pages > 10 * 10 AND TRUE"))
+	})
+
+	It("Keeps positions", func() {
+		tree, err := tsl.ParseTSL("pages > 10 * 10")
+		Expect(err).ToNot(HaveOccurred())
+
+		optimized, err := Walk(tree)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(optimized.Value().(tsl.TSLExpressionOp).Right.Position()).To(Equal(8))
+	})
+})
+
+var _ = Describe("Walk properties", func() {
+	It("Keeps the results of the semantics walker", func() {
+		g := testgen.NewGenerator(42)
+		all := testgen.Records()
+
+		for i := 0; i < 500; i++ {
+			input := g.Boolean(4)
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred(), input)
+
+			optimized, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred(), input)
+
+			for _, record := range all {
+				eval := testgen.Eval(record)
+
+				expected, err := semantics.Walk(tree, eval)
+				if err != nil {
+					continue
+				}
+				actual, err := semantics.Walk(optimized, eval)
+				Expect(err).ToNot(HaveOccurred(), "%s => %s on %v", input, optimized, record)
+				Expect(reflect.DeepEqual(actual, expected)).To(BeTrue(), "%s => %s on %v: %v != %v", input, optimized, record, actual, expected)
+			}
+		}
+	})
+
+	It("Is idempotent", func() {
+		g := testgen.NewGenerator(7)
+
+		for i := 0; i < 200; i++ {
+			tree, err := tsl.ParseTSL(g.Boolean(4))
+			Expect(err).ToNot(HaveOccurred())
+
+			once, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+			twice, err := Walk(once)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(strings.TrimSpace(twice.String())).To(Equal(once.String()))
+		}
+	})
+})
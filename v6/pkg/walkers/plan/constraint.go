@@ -0,0 +1,301 @@
+package plan
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// extractor collects the constraints of AND operands on each field
+type extractor struct {
+	constraints map[string]*Constraint
+}
+
+func newExtractor() extractor {
+	return extractor{constraints: map[string]*Constraint{}}
+}
+
+// constraint returns the constraint of a field
+func (e extractor) constraint(field string) *Constraint {
+	c, ok := e.constraints[field]
+	if !ok {
+		c = &Constraint{Field: field}
+		e.constraints[field] = c
+	}
+	return c
+}
+
+// add adds the constraint of an AND operand, operands that are not
+// constraints are skipped
+func (e extractor) add(n *tsl.TSLNode) error {
+	if n.Type() == tsl.KindIdentifier {
+		e.addValues(n, n.Value().(string), []interface{}{true})
+		return nil
+	}
+
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != tsl.KindBinaryExpr {
+		return nil
+	}
+
+	left, right, operator := op.Left, op.Right, op.Operator
+	if flipped, ok := tsl.FlippedComparisons[operator]; ok && left.Type() != tsl.KindIdentifier {
+		left, right, operator = right, left, flipped
+	}
+	if left.Type() != tsl.KindIdentifier {
+		return nil
+	}
+	field := left.Value().(string)
+
+	switch operator {
+	case tsl.OpEQ:
+		if value, ok := value(right); ok {
+			e.addValues(n, field, []interface{}{value})
+		}
+
+	case tsl.OpIn:
+		arr, ok := right.AsArray()
+		if !ok {
+			return nil
+		}
+		values := []interface{}{}
+		for _, item := range arr.Values {
+			value, ok := value(item)
+			if !ok {
+				return nil
+			}
+			values = append(values, value)
+		}
+		e.addValues(n, field, unique(values))
+
+	case tsl.OpLT, tsl.OpLE, tsl.OpGT, tsl.OpGE:
+		value, ok := value(right)
+		if _, isBool := value.(bool); !ok || isBool {
+			return nil
+		}
+		bound := &Bound{Value: value, Inclusive: operator == tsl.OpLE || operator == tsl.OpGE}
+		if operator == tsl.OpLT || operator == tsl.OpLE {
+			e.addRange(n, field, nil, bound, "")
+		} else {
+			e.addRange(n, field, bound, nil, "")
+		}
+
+	case tsl.OpBetween:
+		arr, ok := right.AsArray()
+		if !ok || len(arr.Values) != 2 {
+			return nil
+		}
+		lo, okLo := number(arr.Values[0])
+		hi, okHi := number(arr.Values[1])
+		if okLo && okHi {
+			e.addRange(n, field, &Bound{Value: lo, Inclusive: true}, &Bound{Value: hi, Inclusive: true}, "")
+		}
+
+	case tsl.OpLike:
+		pattern, ok := right.AsString()
+		if !ok {
+			return nil
+		}
+		prefix, rest, err := likePrefix(pattern)
+		if err != nil {
+			return err
+		}
+		switch {
+		case rest == "":
+			// A pattern with no wildcards is an equality
+			e.addValues(n, field, []interface{}{prefix})
+		case rest == "%" && prefix != "":
+			e.addRange(n, field, nil, nil, prefix)
+		case prefix != "":
+			// The probe finds the prefix, the pattern stays in the residual
+			e.addRange(nil, field, nil, nil, prefix)
+		}
+	}
+
+	return nil
+}
+
+// addValues intersects the accepted values of a field with a list of
+// values
+func (e extractor) addValues(n *tsl.TSLNode, field string, values []interface{}) {
+	c := e.constraint(field)
+	if c.Values != nil {
+		accepted := map[interface{}]bool{}
+		for _, value := range c.Values {
+			accepted[value] = true
+		}
+		intersection := []interface{}{}
+		for _, value := range values {
+			if accepted[value] {
+				intersection = append(intersection, value)
+			}
+		}
+		values = intersection
+	}
+	c.Values = values
+	c.valueNodes = append(c.valueNodes, n)
+}
+
+// addRange narrows the range of a field, a nil node marks a range that
+// does not replace its operand
+func (e extractor) addRange(n *tsl.TSLNode, field string, lower, upper *Bound, prefix string) {
+	c := e.constraint(field)
+
+	// Ranges of different types can not be merged
+	kind := rangeKind(lower, upper, prefix)
+	if c.HasRange() && rangeKind(c.Lower, c.Upper, c.Prefix) != kind {
+		return
+	}
+
+	if prefix != "" {
+		switch {
+		case c.Prefix == "" || strings.HasPrefix(prefix, c.Prefix):
+			c.Prefix = prefix
+		case !strings.HasPrefix(c.Prefix, prefix):
+			// Two unrelated prefixes can not be merged
+			return
+		}
+	}
+	if lower != nil && (c.Lower == nil || greater(lower, c.Lower, true)) {
+		c.Lower = lower
+	}
+	if upper != nil && (c.Upper == nil || greater(c.Upper, upper, false)) {
+		c.Upper = upper
+	}
+
+	if n != nil {
+		c.rangeNodes = append(c.rangeNodes, n)
+	}
+}
+
+// rangeKind returns "string" for string ranges and "number" for number
+// ranges
+func rangeKind(lower, upper *Bound, prefix string) string {
+	for _, b := range []*Bound{lower, upper} {
+		if b == nil {
+			continue
+		}
+		if _, ok := b.Value.(string); ok {
+			return "string"
+		}
+		return "number"
+	}
+	if prefix != "" {
+		return "string"
+	}
+	return ""
+}
+
+// greater returns true if bound a is tighter than bound b, for lower
+// bounds a tighter bound is greater, for upper bounds it is smaller
+func greater(a, b *Bound, lower bool) bool {
+	if less(b.Value, a.Value) {
+		return true
+	}
+	if less(a.Value, b.Value) {
+		return false
+	}
+	// Equal values, an exclusive bound is tighter
+	if lower {
+		return !a.Inclusive && b.Inclusive
+	}
+	return a.Inclusive && !b.Inclusive
+}
+
+// less compares two float64 or two string values
+func less(a, b interface{}) bool {
+	if x, ok := a.(float64); ok {
+		y, _ := b.(float64)
+		return x < y
+	}
+	x, _ := a.(string)
+	y, _ := b.(string)
+	return x < y
+}
+
+// unique removes duplicated values
+func unique(values []interface{}) []interface{} {
+	seen := map[interface{}]bool{}
+	result := values[:0]
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// value returns the value of a number, string or boolean literal, strings
+// that look like dates are compared as dates and are not values
+func value(n *tsl.TSLNode) (interface{}, bool) {
+	if number, ok := number(n); ok {
+		return number, true
+	}
+	if b, ok := n.AsBool(); ok && n.Type() == tsl.KindBooleanLiteral {
+		return b, true
+	}
+	if s, ok := n.AsString(); ok && n.Type() == tsl.KindStringLiteral && !isDate(s) {
+		return s, true
+	}
+	return nil, false
+}
+
+// number returns the value of a number literal, or of a negated number
+// literal
+func number(n *tsl.TSLNode) (float64, bool) {
+	if n.Type() == tsl.KindNumericLiteral {
+		return n.AsFloat64()
+	}
+	if op, ok := n.AsExprOp(); ok && op.Operator == tsl.OpUMinus && op.Right.Type() == tsl.KindNumericLiteral {
+		v, ok := op.Right.AsFloat64()
+		return -v, ok
+	}
+	return 0, false
+}
+
+// isDate returns true for strings that the semantics walker compares as
+// dates
+func isDate(s string) bool {
+	if _, err := time.Parse(time.RFC3339, s); err == nil {
+		return true
+	}
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+// likePrefix splits a LIKE pattern into the unescaped text before the
+// first wildcard, and the rest of the pattern
+func likePrefix(pattern string) (string, string, error) {
+	var prefix strings.Builder
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '%', '_':
+			return prefix.String(), string(runes[i:]), nil
+		case tsl.LikeEscape:
+			if i+1 == len(runes) {
+				return "", "", tsl.LikePatternError{Pattern: pattern}
+			}
+			i++
+			prefix.WriteRune(runes[i])
+		default:
+			prefix.WriteRune(c)
+		}
+	}
+	return prefix.String(), "", nil
+}
+
+// literal returns the literal node of a value
+func literal(value interface{}) *tsl.TSLNode {
+	switch v := value.(type) {
+	case float64:
+		return tsl.NewNumericLiteral(v)
+	case bool:
+		return tsl.NewBooleanLiteral(v)
+	default:
+		s, _ := v.(string)
+		return tsl.NewStringLiteral(s)
+	}
+}
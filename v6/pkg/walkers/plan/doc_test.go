@@ -0,0 +1,43 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"fmt"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// Example for the plan package.
+func Example() {
+	// Set a TSL input string.
+	input := "pages >= 100 and pages < 200 and author = 'Joe' and title like 'Go%'"
+
+	// Parse input string into a TSL tree.
+	tree, _ := tsl.ParseTSL(input)
+
+	// Plan the access path on a store with a sorted index on pages and
+	// title, and no index on author.
+	p, _ := Walk(tree, map[string]IndexKind{
+		"pages": SortedIndex,
+		"title": SortedIndex,
+	})
+
+	fmt.Println(p)
+
+	// Output:
+	// probe sorted index on pages: pages >= 100 AND pages < 200; filter: author = 'Joe' AND title LIKE 'Go%'
+}
@@ -0,0 +1,270 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plan chooses how to read the records matching a TSL filter from
+// a store with indexes.
+//
+// Walk extracts the constraints the top level AND operands of a filter put
+// on each identifier, such as equalities, IN lists, ranges and LIKE
+// prefixes, and returns an AccessPlan: the index to probe, and the residual
+// filter to evaluate on the probed records, for example using the semantics
+// walker. The index package is a reference in-memory store using plans.
+package plan
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// IndexKind is the kind of an index on a field.
+type IndexKind int
+
+const (
+	// NoIndex means the records are scanned.
+	NoIndex IndexKind = iota
+	// HashIndex finds the records holding one of a list of values.
+	HashIndex
+	// SortedIndex finds the records holding one of a list of values, or a
+	// value in a range or with a prefix.
+	SortedIndex
+)
+
+// String returns the name of the index kind
+func (k IndexKind) String() string {
+	switch k {
+	case HashIndex:
+		return "hash"
+	case SortedIndex:
+		return "sorted"
+	default:
+		return "scan"
+	}
+}
+
+// Bound is one end of a range of values.
+type Bound struct {
+	// Value is a float64 or a string
+	Value     interface{}
+	Inclusive bool
+}
+
+// Constraint is the set of values of one field accepted by a filter.
+// Values are float64, string or bool.
+type Constraint struct {
+	Field string
+
+	// Values lists the accepted values of equalities and IN lists, nil if
+	// the field has no such constraint, and empty if no value is accepted
+	Values []interface{}
+
+	// Lower and Upper are the bounds of range constraints, nil bounds are
+	// not limited
+	Lower, Upper *Bound
+
+	// Prefix is the accepted prefix of strings, from LIKE 'prefix%'
+	Prefix string
+
+	// nodes are the filter operands a probe with the constraint replaces
+	valueNodes, rangeNodes []*tsl.TSLNode
+}
+
+// HasRange returns true if the constraint has bounds or a prefix.
+func (c Constraint) HasRange() bool {
+	return c.Lower != nil || c.Upper != nil || c.Prefix != ""
+}
+
+// String returns the constraint as a TSL phrase.
+func (c Constraint) String() string {
+	var operands []*tsl.TSLNode
+	field := tsl.NewIdentifier(c.Field)
+
+	switch {
+	case c.Values != nil && len(c.Values) == 1:
+		operands = append(operands, tsl.NewBinaryExpr(tsl.OpEQ, field, literal(c.Values[0])))
+	case c.Values != nil:
+		values := make([]*tsl.TSLNode, len(c.Values))
+		for i, value := range c.Values {
+			values[i] = literal(value)
+		}
+		operands = append(operands, tsl.NewBinaryExpr(tsl.OpIn, field, tsl.NewArrayLiteral(values...)))
+	}
+
+	if c.Lower != nil {
+		op := tsl.OpGT
+		if c.Lower.Inclusive {
+			op = tsl.OpGE
+		}
+		operands = append(operands, tsl.NewBinaryExpr(op, field, literal(c.Lower.Value)))
+	}
+	if c.Upper != nil {
+		op := tsl.OpLT
+		if c.Upper.Inclusive {
+			op = tsl.OpLE
+		}
+		operands = append(operands, tsl.NewBinaryExpr(op, field, literal(c.Upper.Value)))
+	}
+	if c.Prefix != "" {
+		pattern := tsl.NewStringLiteral(tsl.EscapeLike(c.Prefix) + "%")
+		operands = append(operands, tsl.NewBinaryExpr(tsl.OpLike, field, pattern))
+	}
+
+	phrases := make([]string, len(operands))
+	for i, operand := range operands {
+		phrases[i] = operand.String()
+	}
+	return strings.Join(phrases, " AND ")
+}
+
+// AccessPlan describes how to read the records matching a filter.
+type AccessPlan struct {
+	// Constraints are the constraints of the filter on each field, sorted
+	// by field name
+	Constraints []Constraint
+
+	// Index is the kind of the index to probe, NoIndex for a full scan
+	Index IndexKind
+	// Probe is the constraint to look up in the index of its field, nil
+	// for a full scan
+	Probe *Constraint
+
+	// Residual is the filter to evaluate on the probed records, nil if all
+	// the probed records match
+	Residual *tsl.TSLNode
+}
+
+// String returns a short description of the plan.
+//
+//	probe sorted index on age: age >= 10 AND age < 20; filter: name LIKE '%x'
+func (p AccessPlan) String() string {
+	text := "scan"
+	if p.Probe != nil {
+		text = "probe " + p.Index.String() + " index on " + p.Probe.Field + ": " + p.Probe.String()
+	}
+	if p.Residual != nil {
+		text += "; filter: " + p.Residual.String()
+	}
+	return text
+}
+
+// Walk returns the access plan of a filter on a store with the given
+// indexes, keyed by field name.
+//
+//	tree, _ := tsl.ParseTSL("age >= 10 and age < 20 and name like '%x'")
+//	p, err := plan.Walk(tree, map[string]plan.IndexKind{"age": plan.SortedIndex})
+//	// probe sorted index on age: age >= 10 AND age < 20; filter: name LIKE '%x'
+//
+// Only the operands of the top level AND chain are used, comparing an
+// identifier with number or string literals: =, IN, <, <=, >, >=, BETWEEN
+// and LIKE patterns that start with a prefix. Boolean identifiers are
+// equalities with true. Strings that look like dates are not used, as
+// the semantics walker compares them as dates.
+//
+// Hash indexes are probed with equalities and IN lists, and sorted indexes
+// also with ranges and prefixes. The probe with the fewest values is
+// preferred, then ranges with two bounds or prefixes, then ranges with one
+// bound. The operands the probe replaces are removed from the residual
+// filter, other operands, including operands on the probed field, are kept.
+func Walk(n *tsl.TSLNode, indexes map[string]IndexKind) (AccessPlan, error) {
+	if n == nil {
+		return AccessPlan{}, nil
+	}
+
+	operands := tsl.Flatten(n, tsl.OpAnd)
+	e := newExtractor()
+	for _, conjunct := range operands {
+		if err := e.add(conjunct); err != nil {
+			return AccessPlan{}, err
+		}
+	}
+
+	p := AccessPlan{Residual: n}
+	fields := make([]string, 0, len(e.constraints))
+	for field := range e.constraints {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	bestCost := noProbe
+	var replaced []*tsl.TSLNode
+	for _, field := range fields {
+		c := *e.constraints[field]
+		p.Constraints = append(p.Constraints, c)
+
+		kind := indexes[field]
+		probe, nodes, cost := choose(c, kind)
+		if cost < bestCost {
+			bestCost, replaced = cost, nodes
+			p.Index, p.Probe = kind, probe
+		}
+	}
+
+	if p.Probe != nil {
+		p.Residual = residual(operands, replaced)
+	}
+	return p, nil
+}
+
+// Probe costs, lower is better
+const (
+	costRange    = 1 << 20
+	costHalfOpen = costRange + 1
+	noProbe      = costRange + 2
+)
+
+// choose returns the best probe of a constraint on an index, with the
+// filter operands it replaces and its cost
+func choose(c Constraint, kind IndexKind) (*Constraint, []*tsl.TSLNode, int) {
+	if kind == NoIndex {
+		return nil, nil, noProbe
+	}
+
+	if c.Values != nil {
+		return &Constraint{Field: c.Field, Values: c.Values}, c.valueNodes, len(c.Values)
+	}
+
+	if kind == SortedIndex && c.HasRange() {
+		probe := &Constraint{Field: c.Field, Lower: c.Lower, Upper: c.Upper, Prefix: c.Prefix}
+		cost := costRange
+		if c.Prefix == "" && (c.Lower == nil || c.Upper == nil) {
+			cost = costHalfOpen
+		}
+		return probe, c.rangeNodes, cost
+	}
+
+	return nil, nil, noProbe
+}
+
+// residual joins the AND operands of a filter that are not replaced
+func residual(operands, replaced []*tsl.TSLNode) *tsl.TSLNode {
+	skip := map[*tsl.TSLNode]bool{}
+	for _, node := range replaced {
+		skip[node] = true
+	}
+
+	var result *tsl.TSLNode
+	for _, conjunct := range operands {
+		if skip[conjunct] {
+			continue
+		}
+		if result == nil {
+			result = conjunct
+		} else {
+			result = tsl.NewBinaryExpr(tsl.OpAnd, result, conjunct)
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestPlanWalker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Plan walker")
+}
+
+var indexes = map[string]IndexKind{
+	"id":     HashIndex,
+	"author": HashIndex,
+	"pages":  SortedIndex,
+	"title":  SortedIndex,
+	"loaned": HashIndex,
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Plans the access path",
+		func(input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			p, err := Walk(tree, indexes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.String()).To(Equal(expected))
+		},
+		Entry("equality", "author = 'Joe'", "probe hash index on author: author = 'Joe'"),
+		Entry("flipped equality", "'Joe' = author", "probe hash index on author: author = 'Joe'"),
+		Entry("in list", "id in [3, 1, 3]", "probe hash index on id: id IN [3, 1]"),
+		Entry("merged values", "id in [1, 2, 3] and id in [2, 3, 4]", "probe hash index on id: id IN [2, 3]"),
+		Entry("no values", "id = 1 and id = 2", "probe hash index on id: id IN []"),
+		Entry("boolean identifier", "loaned and pages > 10", "probe hash index on loaned: loaned = TRUE; filter: pages > 10"),
+		Entry("range", "pages >= 10 and pages < 20 and rating > 3",
+			"probe sorted index on pages: pages >= 10 AND pages < 20; filter: rating > 3"),
+		Entry("tighter bounds", "pages > 10 and pages >= 10 and pages <= 20 and pages < 30",
+			"probe sorted index on pages: pages > 10 AND pages <= 20"),
+		Entry("between", "pages between -5 and 5", "probe sorted index on pages: pages >= -5 AND pages <= 5"),
+		Entry("range on hash index", "id > 5", "scan; filter: id > 5"),
+		Entry("prefix", "title like 'Go%'", "probe sorted index on title: title LIKE 'Go%'"),
+		Entry("escaped prefix", "title like 'a\\%b%'", "probe sorted index on title: title LIKE 'a\\\\%b%'"),
+		Entry("prefix and pattern", "title like 'Go%Book'",
+			"probe sorted index on title: title LIKE 'Go%'; filter: title LIKE 'Go%Book'"),
+		Entry("pattern with no wildcards", "title like 'Go'", "probe sorted index on title: title = 'Go'"),
+		Entry("pattern with no prefix", "title like '%Go'", "scan; filter: title LIKE '%Go'"),
+		Entry("prefix and bound", "title like 'Go%' and title > 'Go B'",
+			"probe sorted index on title: title > 'Go B' AND title LIKE 'Go%'"),
+		Entry("mixed types", "title > 'a' and title < 5", "probe sorted index on title: title > 'a'; filter: title < 5"),
+		Entry("values before ranges", "pages > 10 and author = 'Joe'",
+			"probe hash index on author: author = 'Joe'; filter: pages > 10"),
+		Entry("fewer values", "author in ['Joe', 'Jane'] and id = 1",
+			"probe hash index on id: id = 1; filter: author IN ['Joe', 'Jane']"),
+		Entry("two bounds before one", "pages > 10 and title >= 'a' and title < 'b'",
+			"probe sorted index on title: title >= 'a' AND title < 'b'; filter: pages > 10"),
+		Entry("values and range on one field", "pages = 5 and pages > 1",
+			"probe sorted index on pages: pages = 5; filter: pages > 1"),
+		Entry("dates", "title = '2020-01-01'", "scan; filter: title = 2020-01-01"),
+		Entry("or", "author = 'Joe' or id = 1", "scan; filter: author = 'Joe' OR id = 1"),
+		Entry("not indexed", "rating = 5", "scan; filter: rating = 5"),
+	)
+
+	It("Returns the constraints of all fields", func() {
+		tree, err := tsl.ParseTSL("rating > 3 and author = 'Joe' and rating <= 5")
+		Expect(err).ToNot(HaveOccurred())
+
+		p, err := Walk(tree, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.Probe).To(BeNil())
+		Expect(p.Residual).To(BeIdenticalTo(tree))
+		Expect(p.Constraints).To(HaveLen(2))
+		Expect(p.Constraints[0].String()).To(Equal("author = 'Joe'"))
+		Expect(p.Constraints[1].String()).To(Equal("rating > 3 AND rating <= 5"))
+	})
+
+	It("Fails on invalid LIKE patterns", func() {
+		tree := tsl.NewBinaryExpr(tsl.OpLike, tsl.NewIdentifier("title"), tsl.NewStringLiteral("a\\"))
+
+		_, err := Walk(tree, indexes)
+		Expect(err).To(MatchError(tsl.LikePatternError{Pattern: "a\\"}))
+	})
+})
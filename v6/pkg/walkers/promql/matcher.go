@@ -0,0 +1,149 @@
+package promql
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// labelName matches PromQL label names
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// matchTypes are the PromQL matchers of TSL operators
+var matchTypes = map[tsl.Operator]string{
+	tsl.OpEQ:  "=",
+	tsl.OpNE:  "!=",
+	tsl.OpREQ: "=~",
+	tsl.OpRNE: "!~",
+}
+
+// negatedMatchTypes are the negations of PromQL matchers
+var negatedMatchTypes = map[string]string{
+	"=":  "!=",
+	"!=": "=",
+	"=~": "!~",
+	"!~": "=~",
+}
+
+// matcher is one label matcher of a vector selector
+type matcher struct {
+	name      string
+	matchType string
+	value     string
+}
+
+// String returns the PromQL text of the matcher
+func (m matcher) String() string {
+	return m.name + m.matchType + strconv.Quote(m.value)
+}
+
+// matchesEmpty returns true if a series without the label matches
+func (m matcher) matchesEmpty() bool {
+	switch m.matchType {
+	case "=":
+		return m.value == ""
+	case "!=":
+		return m.value != ""
+	}
+
+	re, err := regexp.Compile("^(?:" + m.value + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString("") == (m.matchType == "=~")
+}
+
+// newMatcher converts one TSL expression into a label matcher
+func newMatcher(n *tsl.TSLNode) (matcher, error) {
+	op, ok := n.AsExprOp()
+	if !ok {
+		return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a label comparison"}
+	}
+
+	not := false
+	if n.Type() == tsl.KindUnaryExpr && op.Operator == tsl.OpNot {
+		not = true
+		if op, ok = op.Right.AsExprOp(); !ok {
+			return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a label comparison"}
+		}
+	}
+	if n.Type() == tsl.KindUnaryExpr && !not {
+		return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: op.Operator.String() + " is not supported in label matchers"}
+	}
+
+	if op.Operator == tsl.OpOr {
+		return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "label matchers must be joined using AND"}
+	}
+
+	// Literal on the left, identifier on the right
+	if op.Left.Type() != tsl.KindIdentifier && (op.Operator == tsl.OpEQ || op.Operator == tsl.OpNE) {
+		op.Left, op.Right = op.Right, op.Left
+	}
+
+	if op.Left.Type() != tsl.KindIdentifier {
+		return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a label name on the left side"}
+	}
+	name := op.Left.Value().(string)
+	if !labelName.MatchString(name) {
+		return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "invalid label name " + strconv.Quote(name)}
+	}
+
+	m := matcher{name: name}
+	switch op.Operator {
+	case tsl.OpEQ, tsl.OpNE:
+		v, err := labelValue(n, op.Right)
+		if err != nil {
+			return matcher{}, err
+		}
+		m.matchType, m.value = matchTypes[op.Operator], v
+
+	case tsl.OpREQ, tsl.OpRNE:
+		if op.Right.Type() != tsl.KindStringLiteral {
+			return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "regular expressions must be strings"}
+		}
+		pattern, _ := op.Right.AsString()
+		if _, err := regexp.Compile(pattern); err != nil {
+			return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "invalid regular expression"}
+		}
+		m.matchType, m.value = matchTypes[op.Operator], tsl.AnchoredRegexp(pattern)
+
+	case tsl.OpIn:
+		arr, ok := op.Right.AsArray()
+		if !ok || len(arr.Values) == 0 {
+			return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "expected a list of values"}
+		}
+		values := make([]string, len(arr.Values))
+		for i, item := range arr.Values {
+			v, err := labelValue(n, item)
+			if err != nil {
+				return matcher{}, err
+			}
+			values[i] = v
+		}
+		m.matchType, m.value = "=~", alternation(values)
+
+	default:
+		return matcher{}, tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "label matchers support =, !=, ~=, ~!, IN and NOT IN"}
+	}
+
+	if not {
+		m.matchType = negatedMatchTypes[m.matchType]
+	}
+	return m, nil
+}
+
+// labelValue returns the label value of a literal node, label values are
+// strings, numbers are formatted as PromQL formats them
+func labelValue(n *tsl.TSLNode, value *tsl.TSLNode) (string, error) {
+	switch value.Type() {
+	case tsl.KindStringLiteral, tsl.KindDateLiteral:
+		v, _ := value.AsString()
+		return v, nil
+	case tsl.KindNumericLiteral:
+		f, _ := value.AsFloat64()
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+
+	return "", tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "label values must be strings or numbers"}
+}
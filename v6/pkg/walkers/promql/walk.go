@@ -0,0 +1,84 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package promql helps to create Prometheus PromQL vector selectors using
+// the TSL package.
+package promql
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+// target names PromQL selectors in unsupported expression errors
+const target = "promql selector"
+
+// Walk travel the TSL tree to create a PromQL vector selector.
+//
+// Identifiers are label names, the metric name is the __name__ label.
+// Label matchers support =, != , ~= (=~), ~! (!~) and IN / NOT IN, that
+// are written as an alternation regular expression, and must be joined
+// using AND. TSL regular expressions match anywhere in the value, so
+// unanchored patterns are padded with ".*".
+//
+//	selector, err := promql.Walk(tree)
+//	// selector: {job="api", code=~"5.."}
+//	result, warnings, err := api.Query(ctx, "rate("+selector+"[5m])", time.Now())
+//
+// Expressions PromQL selectors can not express return a tsl.UnsupportedError
+// naming the offending expression.
+func Walk(n *tsl.TSLNode) (string, error) {
+	var selectors []matcher
+	for _, conjunct := range tsl.Flatten(n, tsl.OpAnd) {
+		m, err := newMatcher(conjunct)
+		if err != nil {
+			return "", err
+		}
+		selectors = append(selectors, m)
+	}
+
+	// PromQL rejects selectors that match every series
+	if !selectsSeries(selectors) {
+		return "", tsl.UnsupportedError{Target: target, Expression: n.String(), Reason: "a selector needs a label matcher that does not match an empty value"}
+	}
+
+	texts := make([]string, len(selectors))
+	for i, m := range selectors {
+		texts[i] = m.String()
+	}
+	return "{" + strings.Join(texts, ", ") + "}", nil
+}
+
+// selectsSeries returns true if one of the matchers does not match the
+// empty value, a selector without such a matcher matches every series
+func selectsSeries(matchers []matcher) bool {
+	for _, m := range matchers {
+		if !m.matchesEmpty() {
+			return true
+		}
+	}
+	return false
+}
+
+// alternation creates a pattern matching one of the values
+func alternation(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = regexp.QuoteMeta(v)
+	}
+	return strings.Join(quoted, "|")
+}
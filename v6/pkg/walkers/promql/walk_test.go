@@ -0,0 +1,96 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promql
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestWalk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PromQL walker")
+}
+
+var _ = Describe("Walk", func() {
+	DescribeTable("Generates the expected selector",
+		func(input string, expected string) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			selector, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selector).To(Equal(expected))
+		},
+
+		Entry("equal", "job = 'api'", `{job="api"}`),
+		Entry("literal on the left", "'api' = job", `{job="api"}`),
+		Entry("metric name", "__name__ = 'http_requests_total'", `{__name__="http_requests_total"}`),
+		Entry("number", "code = 500", `{code="500"}`),
+		Entry("and", "job = 'api' and code ~= '^5..$'", `{job="api", code=~"5.."}`),
+		Entry("not equal", "job = 'api' and env != 'dev'", `{job="api", env!="dev"}`),
+		Entry("regexp", "job ~= 'api'", `{job=~".*api.*"}`),
+		Entry("regexp alternation", "job ~= '^api|web$'", `{job=~"(api.*|.*web)"}`),
+		Entry("regexp unanchored alternation", "job ~= 'a|b'", `{job=~"(.*a.*|.*b.*)"}`),
+		Entry("not regexp", "job = 'api' and path ~! '^/health'", `{job="api", path!~"/health.*"}`),
+		Entry("in", "code in [500, 503] and job = 'api'", `{code=~"500|503", job="api"}`),
+		Entry("in quotes values", "path in ['/a.b', '/c']", `{path=~"/a\\.b|/c"}`),
+		Entry("not in", "job = 'api' and env not in ['dev', 'test']", `{job="api", env!~"dev|test"}`),
+		Entry("not of equal", "job = 'api' and not (env = 'dev')", `{job="api", env!="dev"}`),
+		Entry("not of regexp", "job = 'api' and not (path ~= '^/a')", `{job="api", path!~"/a.*"}`),
+		Entry("escapes values", `job = 'say "hi"\\'`, `{job="say \"hi\"\\"}`),
+		Entry("exists", "job != ''", `{job!=""}`),
+	)
+
+	DescribeTable("Rejects expressions PromQL selectors can not express",
+		func(input string, expected tsl.UnsupportedError) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = Walk(tree)
+			Expect(err).To(Equal(expected))
+		},
+
+		Entry("or", "job = 'api' or job = 'web'",
+			tsl.UnsupportedError{Target: target, Expression: "job = 'api' OR job = 'web'", Reason: "label matchers must be joined using AND"}),
+		Entry("greater than", "job = 'api' and code > 500",
+			tsl.UnsupportedError{Target: target, Expression: "code > 500", Reason: "label matchers support =, !=, ~=, ~!, IN and NOT IN"}),
+		Entry("like", "job like 'a%'",
+			tsl.UnsupportedError{Target: target, Expression: "job LIKE 'a%'", Reason: "label matchers support =, !=, ~=, ~!, IN and NOT IN"}),
+		Entry("label comparison", "job = instance",
+			tsl.UnsupportedError{Target: target, Expression: "job = instance", Reason: "label values must be strings or numbers"}),
+		Entry("arithmetic", "code + 1 = 501",
+			tsl.UnsupportedError{Target: target, Expression: "code + 1 = 501", Reason: "expected a label name on the left side"}),
+		Entry("invalid label name", "spec.job = 'api'",
+			tsl.UnsupportedError{Target: target, Expression: "spec.job = 'api'", Reason: `invalid label name "spec.job"`}),
+		Entry("boolean value", "up = true",
+			tsl.UnsupportedError{Target: target, Expression: "up = TRUE", Reason: "label values must be strings or numbers"}),
+		Entry("empty in", "job in []",
+			tsl.UnsupportedError{Target: target, Expression: "job IN []", Reason: "expected a list of values"}),
+		Entry("invalid regexp", "job ~= '('",
+			tsl.UnsupportedError{Target: target, Expression: "job ~= '('", Reason: "invalid regular expression"}),
+		Entry("any", "any (job = 'api')",
+			tsl.UnsupportedError{Target: target, Expression: "ANY (job = 'api')", Reason: "ANY is not supported in label matchers"}),
+		Entry("identifier", "job",
+			tsl.UnsupportedError{Target: target, Expression: "job", Reason: "expected a label comparison"}),
+		Entry("matches every series", "env != 'dev' and job ~= '.*'",
+			tsl.UnsupportedError{Target: target, Expression: "env != 'dev' AND job ~= '.*'", Reason: "a selector needs a label matcher that does not match an empty value"}),
+	)
+})
@@ -0,0 +1,22 @@
+package semantics
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Analyzer splits a text into the tokens compared by the MATCH operator
+type Analyzer = func(string) []string
+
+// MatchAnalyzer is the analyzer used to tokenize both the value and the
+// query of a MATCH operator. A record matches when every query token is
+// one of the value tokens. Replace it to add stemming or stop words.
+var MatchAnalyzer Analyzer = DefaultAnalyzer
+
+// DefaultAnalyzer lower cases the text and splits it on every character
+// that is not a letter or a digit.
+func DefaultAnalyzer(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -90,7 +90,7 @@ func isValueInArray(value interface{}, arr []interface{}) (bool, error) {
 			}
 		case time.Time:
 			for _, item := range arr {
-				if t, ok := item.(time.Time); ok && t.Equal(v) {
+				if t, ok := toDate(item); ok && t.Equal(v) {
 					return true, nil
 				}
 			}
@@ -111,7 +111,8 @@ func isValueInArray(value interface{}, arr []interface{}) (bool, error) {
 }
 
 // isValueInRange checks if a value is within a range (inclusive)
-// Supports both numeric values and time.Time comparisons
+// Supports both numeric values and time.Time comparisons, time bounds may
+// also be date strings (e.g. a DATE literal)
 func isValueInRange(value, min, max interface{}) (bool, error) {
 	switch v := value.(type) {
 	case float64:
@@ -141,8 +142,8 @@ func isValueInRange(value, min, max interface{}) (bool, error) {
 		}
 		return v >= minVal && v <= maxVal, nil
 	case time.Time:
-		minTime, okMin := min.(time.Time)
-		maxTime, okMax := max.(time.Time)
+		minTime, okMin := toDate(min)
+		maxTime, okMax := toDate(max)
 		if !okMin || !okMax {
 			return false, &tsl.TypeMismatchError{
 				Expected: "time values",
@@ -158,7 +159,8 @@ func isValueInRange(value, min, max interface{}) (bool, error) {
 }
 
 // evaluateLikePattern performs pattern matching with SQL LIKE semantics
-// Supports % for any sequence of characters and _ for single character
+// Supports % for any sequence of characters, _ for single character and
+// tsl.LikeEscape to match a literal %, _ or escape character
 func evaluateLikePattern(value interface{}, pattern interface{}) (bool, error) {
 	if value == nil || pattern == nil {
 		return false, nil
@@ -180,12 +182,13 @@ func evaluateLikePattern(value interface{}, pattern interface{}) (bool, error) {
 		}
 	}
 
-	// Escape regex metacharacters, then convert SQL LIKE wildcards to regex
-	patternStr = regexp.QuoteMeta(patternStr)
-	patternStr = strings.ReplaceAll(patternStr, "%", ".*")
-	patternStr = strings.ReplaceAll(patternStr, "_", ".")
+	// Convert SQL LIKE wildcards and escapes to regex
+	patternStr, err := tsl.LikeToRegexp(patternStr)
+	if err != nil {
+		return false, err
+	}
 
-	re, err := regexp.Compile("^" + patternStr + "$")
+	re, err := regexp.Compile(patternStr)
 	if err != nil {
 		return false, err
 	}
@@ -245,3 +248,44 @@ func evaluateRegexMatch(value interface{}, pattern interface{}) (bool, error) {
 	}
 	return re.MatchString(valueStr), nil
 }
+
+// evaluateTextMatch checks if every token of the query is a token of the
+// value, tokens are created by MatchAnalyzer. A query without tokens
+// matches nothing.
+func evaluateTextMatch(value interface{}, query interface{}) (bool, error) {
+	if value == nil || query == nil {
+		return false, nil
+	}
+
+	valueStr, okValue := value.(string)
+	queryStr, okQuery := query.(string)
+
+	if !okValue {
+		return false, &tsl.TypeMismatchError{
+			Expected: "string",
+			Got:      value,
+		}
+	}
+	if !okQuery {
+		return false, &tsl.TypeMismatchError{
+			Expected: "string",
+			Got:      query,
+		}
+	}
+
+	queryTokens := MatchAnalyzer(queryStr)
+	if len(queryTokens) == 0 {
+		return false, nil
+	}
+
+	valueTokens := map[string]bool{}
+	for _, token := range MatchAnalyzer(valueStr) {
+		valueTokens[token] = true
+	}
+	for _, token := range queryTokens {
+		if !valueTokens[token] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -156,6 +156,8 @@ func evaluateBinaryExpression(operator tsl.Operator, leftVal, rightVal interface
 		return evaluateLikePattern(leftVal, rightVal)
 	case tsl.OpILike:
 		return evaluateIlikePattern(leftVal, rightVal)
+	case tsl.OpMatch:
+		return evaluateTextMatch(leftVal, rightVal)
 	case tsl.OpIn:
 		// Try to extract the array values from the right side of the expression
 		rightArray, ok := rightVal.([]interface{})
@@ -47,6 +47,8 @@ var _ = Describe("Walk", func() {
 		"booleans":     []interface{}{true, false, true},
 		"dateStr":      "2020-01-01T00:00:00Z", // full ISO string
 		"shortDateStr": "2020-01-01",           // short date
+		"filename":     "my_file%1.txt",
+		"discount":     "10%",
 	}
 
 	// This is the evaluation function that we will use:
@@ -82,8 +84,26 @@ var _ = Describe("Walk", func() {
 		Entry("not equals string", "author != 'Jane'", true),
 		Entry("like with wildcard", "title like '%good%'", true),
 		Entry("ilike case insensitive", "title ilike '%GOOD%'", true),
+		Entry("like underscore wildcard", "title like 'A_good%'", true),
+		Entry("like escaped underscore", `title like 'A\_good%'`, false),
+		Entry("like escaped underscore match", `filename like 'my\_file%'`, true),
+		Entry("like escaped percent", `filename like '%\%1.txt'`, true),
+		Entry("like escaped percent no match", `filename like 'my\%'`, false),
+		Entry("like escape clause", "filename like 'my!_file!%1%' escape '!'", true),
+		Entry("not like escape clause", "filename not like 'my!_%' escape '!'", false),
+		Entry("ilike escaped underscore", `filename ilike 'MY\_FILE%'`, true),
+		Entry("equals escaped percent", `discount = '10\%'`, true),
+		Entry("equals escaped underscore", `filename = 'my\_file\%1.txt'`, true),
+		Entry("in escaped percent", `discount in ['5\%', '10\%']`, true),
 		Entry("regexp equals", "title ~= 'good.*'", true),
 		Entry("regexp not equals", "title ~! '.*bad.*'", true),
+		Entry("match all tokens", "title match 'Good book'", true),
+		Entry("match with punctuation", "filename @@ 'file, txt!'", true),
+		Entry("match missing token", "title match 'good movie'", false),
+		Entry("match partial token", "title match 'boo'", false),
+		Entry("match empty query", "title match ' ,'", false),
+		Entry("not match", "title not match 'movie'", true),
+		Entry("match array", "any (tags match 'FICTION')", true),
 
 		// Numeric operations
 		Entry("equals number", "spec.pages = 14", true),
@@ -115,6 +135,8 @@ var _ = Describe("Walk", func() {
 		Entry("equals date", "date = '2020-01-01T00:00:00Z'", true),
 		Entry("greater than date", "date > '2019-12-31T00:00:00Z'", true),
 		Entry("between dates", "date between '2019-12-31T00:00:00Z' and '2020-01-02T00:00:00Z'", true),
+		Entry("between date literals", "date between 2019-12-31 and 2020-01-01", true),
+		Entry("in date literals", "date in [2019-12-31, 2020-01-01]", true),
 		Entry("date before", "date < '2021-01-01T00:00:00Z'", true),
 		Entry("date after", "date > '2019-01-01T00:00:00Z'", true),
 
@@ -286,6 +308,7 @@ var _ = Describe("Walk error messages", func() {
 		Entry("division by zero", "count / 0", "division by zero"),
 		Entry("modulus by zero", "count % 0", "modulus by zero"),
 		Entry("invalid regex", "name ~= '[invalid'", "error parsing regexp"),
+		Entry("like trailing escape", `name like 'al\\'`, "LIKE pattern must not end with the escape character"),
 		Entry("boolean type mismatch", "count and true", "type mismatch: expected boolean"),
 		Entry("number type mismatch", "name + 1", "type mismatch: expected number"),
 	)
@@ -322,6 +345,7 @@ var _ = Describe("Walk nil value cases", func() {
 		// Like/ILike operators with nil
 		Entry("nil like", "nullable_field like '%test%'", false),
 		Entry("nil ilike", "nullable_field ilike '%TEST%'", false),
+		Entry("nil match", "nullable_field match 'test'", false),
 
 		// Equality with nil (already works, but verify)
 		Entry("nil equals string", "nullable_field = 'something'", false),
@@ -329,3 +353,27 @@ var _ = Describe("Walk nil value cases", func() {
 		Entry("nil is null", "nullable_field is null", true),
 	)
 })
+
+var _ = Describe("MatchAnalyzer", func() {
+	AfterEach(func() {
+		MatchAnalyzer = DefaultAnalyzer
+	})
+
+	It("Uses the configured analyzer", func() {
+		// A naive stemmer that drops a trailing "s"
+		MatchAnalyzer = func(text string) []string {
+			tokens := DefaultAnalyzer(text)
+			for i, token := range tokens {
+				tokens[i] = strings.TrimSuffix(token, "s")
+			}
+			return tokens
+		}
+
+		tree, err := tsl.ParseTSL("title MATCH 'good books'")
+		Expect(err).ToNot(HaveOccurred())
+
+		actual, err := Walk(tree, func(string) (interface{}, bool) { return "A good book", true })
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(BeTrue())
+	})
+})
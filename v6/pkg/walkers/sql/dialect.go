@@ -0,0 +1,69 @@
+// Copyright 2018 Yaacov Zamir <kobi.zamir@gmail.com>
+// and other contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import sq "github.com/Masterminds/squirrel"
+
+// Dialect selects how operators that databases spell differently are written.
+type Dialect int
+
+const (
+	// DialectDefault writes ILIKE and full-text search as PostgreSQL does,
+	// and regular expressions as MySQL does.
+	DialectDefault Dialect = iota
+	// DialectSQLite writes ILIKE as LOWER(x) LIKE LOWER(y), and rejects
+	// regular expressions and full-text search.
+	DialectSQLite
+	// DialectMySQL writes ILIKE as LOWER(x) LIKE LOWER(y), and rejects
+	// full-text search.
+	DialectMySQL
+	// DialectPostgres writes regular expressions using the ~ operator.
+	DialectPostgres
+)
+
+// String returns the database name of the dialect
+func (d Dialect) String() string {
+	switch d {
+	case DialectSQLite:
+		return "SQLite"
+	case DialectMySQL:
+		return "MySQL"
+	case DialectPostgres:
+		return "PostgreSQL"
+	default:
+		return "SQL"
+	}
+}
+
+// PlaceholderFormat returns the squirrel placeholder format of the dialect,
+// PostgreSQL uses numbered dollar placeholders and the other dialects use
+// question marks
+func (d Dialect) PlaceholderFormat() sq.PlaceholderFormat {
+	if d == DialectPostgres {
+		return sq.Dollar
+	}
+	return sq.Question
+}
+
+// hasILike returns true if the dialect has the ILIKE operator
+func (d Dialect) hasILike() bool {
+	return d == DialectDefault || d == DialectPostgres
+}
+
+// hasFullTextSearch returns true if the dialect has PostgreSQL full-text search
+func (d Dialect) hasFullTextSearch() bool {
+	return d == DialectDefault || d == DialectPostgres
+}
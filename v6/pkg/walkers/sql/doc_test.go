@@ -44,7 +44,7 @@ func Example() {
 	fmt.Printf("Args: %v\n", args)
 
 	// Output:
-	// SQL : SELECT name, city, state FROM users WHERE (name = ? AND city != ?)
+	// SQL : SELECT name, city, state FROM users WHERE (name = ? AND city <> ?)
 	// Args: [joe rome]
 }
 
@@ -71,7 +71,7 @@ func Example_complex() {
 	fmt.Printf("Args: %v\n", args)
 
 	// Output:
-	// SQL : SELECT name, department, salary FROM employees WHERE ((((salary * ?) > ? AND department IN (?,?)) AND hire_date BETWEEN ? AND ?) AND (manager IS NULL OR title LIKE ?))
+	// SQL : SELECT name, department, salary FROM employees WHERE ((salary * ?) > ? AND department IN (?,?) AND hire_date BETWEEN ? AND ? AND (manager IS NULL OR title LIKE ?))
 	// Args: [12 50000 IT HR 2020-01-01 00:00:00 2023-12-31 23:59:59 %Senior%]
 }
 
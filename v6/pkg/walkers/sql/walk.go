@@ -17,6 +17,7 @@
 package sql
 
 import (
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -34,37 +35,37 @@ import (
 //	  Where(filter).
 //	  ToSql()
 //
+// Comparisons between an identifier and a literal are emitted as native
+// squirrel predicates (sq.Eq, sq.NotEq, sq.Lt, sq.Like, ...), and nested
+// AND / OR operators are flattened into a single sq.And / sq.Or, so the
+// returned filter can be inspected and combined with hand written builders.
+// Expressions that have no squirrel equivalent (arithmetic, identifier to
+// identifier comparisons, regular expressions) fall back to sq.Expr.
+// MATCH is emitted as a PostgreSQL full-text search,
+// `to_tsvector(col) @@ plainto_tsquery(?)`.
+//
+// Walk uses DialectDefault, use WalkDialect to build queries for one database.
+//
 // Squirrel: https://github.com/Masterminds/squirrel
-func Walk(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
+func Walk(n *tsl.TSLNode) (sq.Sqlizer, error) {
+	return WalkDialect(n, DialectDefault)
+}
+
+// WalkDialect travel the TSL tree to create squirrel SQL select operators
+// for a database dialect, operators the dialect can not express return a
+// tsl.UnsupportedError.
+func WalkDialect(n *tsl.TSLNode, d Dialect) (s sq.Sqlizer, err error) {
 	switch n.Type() {
 	case tsl.KindIdentifier:
 		s = sq.Expr(n.Value().(string))
-	case tsl.KindNumericLiteral:
-		s = sq.Expr("?", n.Value().(float64))
-	case tsl.KindDateLiteral:
-		// Parse date string and format for SQL
-		dateStr := n.Value().(string)
-		if t, err := time.Parse("2006-01-02", dateStr); err == nil {
-			s = sq.Expr("?", t.Format("2006-01-02 15:04:05"))
-		} else {
-			s = sq.Expr("?", dateStr)
-		}
-	case tsl.KindTimestampLiteral:
-		// Format time value using SQL timestamp format
-		t := n.Value().(time.Time)
-		s = sq.Expr("?", t.Format("2006-01-02 15:04:05"))
-	case tsl.KindStringLiteral:
-		s = sq.Expr("?", n.Value().(string))
-	case tsl.KindBooleanLiteral:
-		if n.Value().(bool) {
-			s = sq.Expr("?", 1)
-		} else {
-			s = sq.Expr("?", 0)
-		}
+	case tsl.KindNumericLiteral, tsl.KindDateLiteral, tsl.KindTimestampLiteral,
+		tsl.KindStringLiteral, tsl.KindBooleanLiteral:
+		v, _ := literalValue(n)
+		s = sq.Expr("?", v)
 	case tsl.KindBinaryExpr:
-		return binaryStep(n)
+		return binaryStep(n, d)
 	case tsl.KindUnaryExpr:
-		return unaryStep(n)
+		return unaryStep(n, d)
 	case tsl.KindNullLiteral:
 		// NULL literal is handled as a special case of IS NULL operator
 		s = sq.Expr("")
@@ -75,8 +76,44 @@ func Walk(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
 	return
 }
 
+// literalValue returns the SQL argument for a literal node, times use
+// the SQL timestamp format and booleans are 1 or 0.
+func literalValue(n *tsl.TSLNode) (interface{}, bool) {
+	v, ok := tsl.LiteralValue(n)
+	if !ok {
+		return nil, false
+	}
+	return sqlValue(v), true
+}
+
+// literalArrayValues returns the SQL arguments of an array of literals.
+func literalArrayValues(n *tsl.TSLNode) ([]interface{}, bool) {
+	values, ok := tsl.LiteralArrayValues(n)
+	if !ok {
+		return nil, false
+	}
+	for i, v := range values {
+		values[i] = sqlValue(v)
+	}
+	return values, true
+}
+
+// sqlValue returns the SQL argument for a literal value
+func sqlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	}
+	return v
+}
+
 // Helper function to walk array nodes and return values
-func walkArrayValues(n *tsl.TSLNode) ([]sq.Sqlizer, error) {
+func walkArrayValues(n *tsl.TSLNode, d Dialect) ([]sq.Sqlizer, error) {
 	if n.Type() != tsl.KindArrayLiteral {
 		return nil, tsl.UnexpectedTypeError{Type: n.Type()}
 	}
@@ -86,7 +123,7 @@ func walkArrayValues(n *tsl.TSLNode) ([]sq.Sqlizer, error) {
 	var err error
 
 	for i, node := range array.Values {
-		values[i], err = Walk(node)
+		values[i], err = WalkDialect(node, d)
 		if err != nil {
 			return nil, err
 		}
@@ -95,11 +132,149 @@ func walkArrayValues(n *tsl.TSLNode) ([]sq.Sqlizer, error) {
 	return values, nil
 }
 
-func binaryStep(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
+// comparisonStep creates a native squirrel predicate for a comparison
+// between an identifier and a literal, it returns false if the
+// comparison can not be expressed as a squirrel predicate.
+func comparisonStep(op tsl.TSLExpressionOp, d Dialect) (sq.Sqlizer, bool) {
+	operator := op.Operator
+
+	col, okCol := tsl.FieldName(op.Left)
+	val, okVal := literalValue(op.Right)
+	if !okCol || !okVal {
+		// Try the swapped form: literal on the left, identifier on the right
+		flipped, ok := tsl.FlippedComparisons[operator]
+		if !ok {
+			return nil, false
+		}
+		col, okCol = tsl.FieldName(op.Right)
+		val, okVal = literalValue(op.Left)
+		if !okCol || !okVal {
+			return nil, false
+		}
+		operator = flipped
+	}
+
+	switch operator {
+	case tsl.OpEQ:
+		return sq.Eq{col: val}, true
+	case tsl.OpNE:
+		return sq.NotEq{col: val}, true
+	case tsl.OpLT:
+		return sq.Lt{col: val}, true
+	case tsl.OpLE:
+		return sq.LtOrEq{col: val}, true
+	case tsl.OpGT:
+		return sq.Gt{col: val}, true
+	case tsl.OpGE:
+		return sq.GtOrEq{col: val}, true
+	case tsl.OpLike, tsl.OpILike:
+		return likeStep(col, operator, false, val, d), true
+	}
+
+	return nil, false
+}
+
+// likeKeywords are the SQL keywords of the LIKE operators and their negation.
+var likeKeywords = map[tsl.Operator][2]string{
+	tsl.OpLike:  {"LIKE", "NOT LIKE"},
+	tsl.OpILike: {"ILIKE", "NOT ILIKE"}, // PostgreSQL specific
+}
+
+// likeStep creates a LIKE or ILIKE predicate. Patterns using tsl.LikeEscape
+// get an explicit ESCAPE clause, because databases disagree on the default
+// escape character (SQLite has none, MySQL and PostgreSQL use a backslash).
+func likeStep(col string, operator tsl.Operator, not bool, pattern interface{}, d Dialect) sq.Sqlizer {
+	if operator == tsl.OpILike && !d.hasILike() {
+		return lowerLikeStep(col, not, pattern)
+	}
+
+	if p, ok := pattern.(string); ok && strings.ContainsRune(p, tsl.LikeEscape) {
+		keyword := likeKeywords[operator][0]
+		if not {
+			keyword = likeKeywords[operator][1]
+		}
+		return sq.Expr(col+" "+keyword+" ? ESCAPE ?", p, string(tsl.LikeEscape))
+	}
+
+	switch {
+	case operator == tsl.OpLike && !not:
+		return sq.Like{col: pattern}
+	case operator == tsl.OpLike:
+		return sq.NotLike{col: pattern}
+	case !not:
+		return sq.ILike{col: pattern}
+	default:
+		return sq.NotILike{col: pattern}
+	}
+}
+
+// lowerLikeStep creates a case insensitive LIKE predicate for dialects
+// without ILIKE, by comparing the lower case column and pattern.
+func lowerLikeStep(col string, not bool, pattern interface{}) sq.Sqlizer {
+	keyword := "LIKE"
+	if not {
+		keyword = "NOT LIKE"
+	}
+
+	if p, ok := pattern.(string); ok && strings.ContainsRune(p, tsl.LikeEscape) {
+		return sq.Expr("LOWER("+col+") "+keyword+" LOWER(?) ESCAPE ?", p, string(tsl.LikeEscape))
+	}
+	return sq.Expr("LOWER("+col+") "+keyword+" LOWER(?)", pattern)
+}
+
+// walkDialect returns a walk function for the operands of AND / OR operators
+func walkDialect(d Dialect) func(n *tsl.TSLNode) (sq.Sqlizer, error) {
+	return func(n *tsl.TSLNode) (sq.Sqlizer, error) {
+		return WalkDialect(n, d)
+	}
+}
+
+func binaryStep(n *tsl.TSLNode, d Dialect) (s sq.Sqlizer, err error) {
 	var l sq.Sqlizer
 	op := n.Value().(tsl.TSLExpressionOp)
 
-	l, err = Walk(op.Left)
+	// Handle logical operators, flattening nested operators of the same kind
+	switch op.Operator {
+	case tsl.OpAnd:
+		parts, err := tsl.WalkChain(n, tsl.OpAnd, walkDialect(d))
+		if err != nil {
+			return nil, err
+		}
+		return sq.And(parts), nil
+	case tsl.OpOr:
+		parts, err := tsl.WalkChain(n, tsl.OpOr, walkDialect(d))
+		if err != nil {
+			return nil, err
+		}
+		return sq.Or(parts), nil
+	}
+
+	// Handle identifier to literal comparisons using squirrel predicates
+	if s, ok := comparisonStep(op, d); ok {
+		return s, nil
+	}
+
+	// Handle array and null operations on identifiers specially
+	if col, ok := tsl.FieldName(op.Left); ok {
+		switch op.Operator {
+		case tsl.OpIn:
+			if values, ok := literalArrayValues(op.Right); ok {
+				// An empty list is rendered by squirrel as a false expression
+				return sq.Eq{col: values}, nil
+			}
+		case tsl.OpBetween:
+			if values, ok := literalArrayValues(op.Right); ok {
+				if len(values) != 2 {
+					return nil, tsl.BetweenOperatorError{Message: "BETWEEN requires exactly two values"}
+				}
+				return sq.Expr(col+" BETWEEN ? AND ?", values...), nil
+			}
+		case tsl.OpIs:
+			return sq.Eq{col: nil}, nil
+		}
+	}
+
+	l, err = WalkDialect(op.Left, d)
 	if err != nil {
 		return
 	}
@@ -107,14 +282,18 @@ func binaryStep(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
 	// Handle array operations specially
 	switch op.Operator {
 	case tsl.OpIn:
-		values, err := walkArrayValues(op.Right)
+		values, err := walkArrayValues(op.Right, d)
 		if err != nil {
 			return nil, err
 		}
-		return sq.Expr("? IN ("+placeholders(len(values))+")", append([]interface{}{l}, sqlizersToInterface(values)...)...), nil
+		if len(values) == 0 {
+			// An empty OR is rendered by squirrel as a false expression
+			return sq.Or{}, nil
+		}
+		return sq.Expr("? IN ("+sq.Placeholders(len(values))+")", append([]interface{}{l}, sqlizersToInterface(values)...)...), nil
 
 	case tsl.OpBetween:
-		values, err := walkArrayValues(op.Right)
+		values, err := walkArrayValues(op.Right, d)
 		if err != nil {
 			return nil, err
 		}
@@ -125,7 +304,7 @@ func binaryStep(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
 	}
 
 	// For non-array operations, handle normally
-	r, err := Walk(op.Right)
+	r, err := WalkDialect(op.Right, d)
 	if err != nil {
 		return
 	}
@@ -147,7 +326,7 @@ func binaryStep(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
 	case tsl.OpEQ:
 		return sq.Expr("? = ?", l, r), nil
 	case tsl.OpNE:
-		return sq.Expr("? != ?", l, r), nil
+		return sq.Expr("? <> ?", l, r), nil
 	case tsl.OpLT:
 		return sq.Expr("? < ?", l, r), nil
 	case tsl.OpLE:
@@ -156,22 +335,22 @@ func binaryStep(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
 		return sq.Expr("? > ?", l, r), nil
 	case tsl.OpGE:
 		return sq.Expr("? >= ?", l, r), nil
-	case tsl.OpREQ:
-		return sq.Expr("? REGEXP ?", l, r), nil // MySQL specific
-	case tsl.OpRNE:
-		return sq.Expr("NOT (? REGEXP ?)", l, r), nil // MySQL specific
-
-	// Logical operators
-	case tsl.OpAnd:
-		return sq.And{l, r}, nil
-	case tsl.OpOr:
-		return sq.Or{l, r}, nil
+	case tsl.OpREQ, tsl.OpRNE:
+		return regexpStep(n, op.Operator, l, r, d)
+	case tsl.OpMatch:
+		if !d.hasFullTextSearch() {
+			return nil, tsl.UnsupportedError{Target: d.String(), Expression: n.String(), Reason: "full-text search is PostgreSQL specific"}
+		}
+		return sq.Expr("to_tsvector(?) @@ plainto_tsquery(?)", l, r), nil
 
 	// String operators
 	case tsl.OpLike:
-		return sq.Expr("? LIKE ?", l, r), nil
+		return sq.Expr("? LIKE ? ESCAPE ?", l, r, string(tsl.LikeEscape)), nil
 	case tsl.OpILike:
-		return sq.Expr("? ILIKE ?", l, r), nil // PostgreSQL specific
+		if !d.hasILike() {
+			return sq.Expr("LOWER(?) LIKE LOWER(?) ESCAPE ?", l, r, string(tsl.LikeEscape)), nil
+		}
+		return sq.Expr("? ILIKE ? ESCAPE ?", l, r, string(tsl.LikeEscape)), nil
 
 	// Null operator
 	case tsl.OpIs:
@@ -182,12 +361,88 @@ func binaryStep(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
 	}
 }
 
+// regexpStep creates a regular expression match, using the REGEXP operator
+// of MySQL or the ~ operator of PostgreSQL.
+func regexpStep(n *tsl.TSLNode, operator tsl.Operator, l, r sq.Sqlizer, d Dialect) (sq.Sqlizer, error) {
+	switch d {
+	case DialectSQLite:
+		return nil, tsl.UnsupportedError{Target: d.String(), Expression: n.String(), Reason: "SQLite has no REGEXP function by default"}
+	case DialectPostgres:
+		if operator == tsl.OpRNE {
+			return sq.Expr("? !~ ?", l, r), nil
+		}
+		return sq.Expr("? ~ ?", l, r), nil
+	}
+
+	if operator == tsl.OpRNE {
+		return sq.Expr("NOT (? REGEXP ?)", l, r), nil
+	}
+	return sq.Expr("? REGEXP ?", l, r), nil
+}
+
+// negatedStep creates the native negated form of LIKE, ILIKE, IN, BETWEEN
+// and IS NULL predicates on identifiers, it returns false if the
+// node has no such form.
+func negatedStep(n *tsl.TSLNode, d Dialect) (sq.Sqlizer, bool, error) {
+	op, ok := n.AsExprOp()
+	if !ok || n.Type() != tsl.KindBinaryExpr {
+		return nil, false, nil
+	}
+
+	col, ok := tsl.FieldName(op.Left)
+	if !ok {
+		return nil, false, nil
+	}
+
+	switch op.Operator {
+	case tsl.OpLike, tsl.OpILike:
+		val, ok := literalValue(op.Right)
+		if !ok {
+			return nil, false, nil
+		}
+		return likeStep(col, op.Operator, true, val, d), true, nil
+	case tsl.OpIn:
+		values, ok := literalArrayValues(op.Right)
+		if !ok {
+			return nil, false, nil
+		}
+		// An empty list is rendered by squirrel as a true expression
+		return sq.NotEq{col: values}, true, nil
+	case tsl.OpBetween:
+		values, ok := literalArrayValues(op.Right)
+		if !ok {
+			return nil, false, nil
+		}
+		if len(values) != 2 {
+			return nil, false, tsl.BetweenOperatorError{Message: "BETWEEN requires exactly two values"}
+		}
+		return sq.Expr(col+" NOT BETWEEN ? AND ?", values...), true, nil
+	case tsl.OpIs:
+		return sq.NotEq{col: nil}, true, nil
+	}
+
+	return nil, false, nil
+}
+
 // unaryStep handles minus and not operators first
-func unaryStep(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
+func unaryStep(n *tsl.TSLNode, d Dialect) (s sq.Sqlizer, err error) {
 	op := n.Value().(tsl.TSLExpressionOp)
 
+	// Negative numbers are passed as a single argument
+	if v, ok := literalValue(n); ok {
+		return sq.Expr("?", v), nil
+	}
+
+	// Use the native negated form of a predicate when there is one
+	if op.Operator == tsl.OpNot {
+		s, ok, err := negatedStep(op.Right, d)
+		if err != nil || ok {
+			return s, err
+		}
+	}
+
 	// Get the child node's SQL representation
-	right, err := Walk(op.Right)
+	right, err := WalkDialect(op.Right, d)
 	if err != nil {
 		return nil, err
 	}
@@ -203,21 +458,6 @@ func unaryStep(n *tsl.TSLNode) (s sq.Sqlizer, err error) {
 	}
 }
 
-// Helper to generate SQL placeholders
-func placeholders(n int) string {
-	if n <= 0 {
-		return ""
-	}
-	ph := make([]byte, 2*n-1)
-	for i := 0; i < len(ph); i += 2 {
-		ph[i] = '?'
-		if i+1 < len(ph) {
-			ph[i+1] = ','
-		}
-	}
-	return string(ph)
-}
-
 // Helper to convert []sq.Sqlizer to []interface{}
 func sqlizersToInterface(sqlizers []sq.Sqlizer) []interface{} {
 	result := make([]interface{}, len(sqlizers))
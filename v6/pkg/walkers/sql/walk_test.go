@@ -62,7 +62,7 @@ var _ = Describe("Walk", func() {
 		Entry(
 			"Search by name and city",
 			"name = 'joe' and city != 'rome'",
-			"SELECT name, city, state FROM users WHERE (name = ? AND city != ?)",
+			"SELECT name, city, state FROM users WHERE (name = ? AND city <> ?)",
 			"joe", "rome",
 		),
 
@@ -94,6 +94,20 @@ var _ = Describe("Walk", func() {
 			1000.0,
 		),
 
+		Entry(
+			"Full-text search",
+			"description MATCH 'fast red car'",
+			"SELECT name, city, state FROM users WHERE to_tsvector(description) @@ plainto_tsquery(?)",
+			"fast red car",
+		),
+
+		Entry(
+			"Full-text search negation",
+			"description NOT MATCH 'fast' and name = 'joe'",
+			"SELECT name, city, state FROM users WHERE (NOT (to_tsvector(description) @@ plainto_tsquery(?)) AND name = ?)",
+			"fast", "joe",
+		),
+
 		Entry(
 			"Complex arithmetic",
 			"(salary + bonus) * 0.3 > 20000",
@@ -135,6 +149,34 @@ var _ = Describe("Walk", func() {
 			"%smith%",
 		),
 
+		Entry(
+			"LIKE with escaped wildcard",
+			`file_name LIKE 'my\_file%'`,
+			"SELECT name, city, state FROM users WHERE file_name LIKE ? ESCAPE ?",
+			`my\_file%`, `\`,
+		),
+
+		Entry(
+			"LIKE with ESCAPE clause",
+			"file_name LIKE '100!%' ESCAPE '!'",
+			"SELECT name, city, state FROM users WHERE file_name LIKE ? ESCAPE ?",
+			`100\%`, `\`,
+		),
+
+		Entry(
+			"NOT LIKE with escaped wildcard",
+			`file_name NOT LIKE 'my\_file%'`,
+			"SELECT name, city, state FROM users WHERE file_name NOT LIKE ? ESCAPE ?",
+			`my\_file%`, `\`,
+		),
+
+		Entry(
+			"LIKE identifier pattern",
+			"name LIKE pattern",
+			"SELECT name, city, state FROM users WHERE name LIKE pattern ESCAPE ?",
+			`\`,
+		),
+
 		Entry(
 			"Regular expression",
 			"email ~= '.*@gmail.com'",
@@ -159,42 +201,170 @@ var _ = Describe("Walk", func() {
 		Entry(
 			"NOT LIKE operator",
 			"name NOT LIKE '%smith%'",
-			"SELECT name, city, state FROM users WHERE NOT (name LIKE ?)",
+			"SELECT name, city, state FROM users WHERE name NOT LIKE ?",
 			"%smith%",
 		),
 
 		Entry(
 			"NOT IN operator",
 			"city NOT IN ['rome', 'paris']",
-			"SELECT name, city, state FROM users WHERE NOT (city IN (?,?))",
+			"SELECT name, city, state FROM users WHERE city NOT IN (?,?)",
 			"rome", "paris",
 		),
 
 		Entry(
 			"NOT BETWEEN operator",
 			"age NOT BETWEEN 20 and 30",
-			"SELECT name, city, state FROM users WHERE NOT (age BETWEEN ? AND ?)",
+			"SELECT name, city, state FROM users WHERE age NOT BETWEEN ? AND ?",
 			20.0, 30.0,
 		),
 
 		Entry(
 			"IS NOT NULL",
 			"email IS NOT NULL",
-			"SELECT name, city, state FROM users WHERE NOT (email IS NULL)",
+			"SELECT name, city, state FROM users WHERE email IS NOT NULL",
 		),
 
 		Entry(
 			"NOT ILIKE operator",
 			"name NOT ILIKE '%smith%'",
-			"SELECT name, city, state FROM users WHERE NOT (name ILIKE ?)",
+			"SELECT name, city, state FROM users WHERE name NOT ILIKE ?",
 			"%smith%",
 		),
 
 		Entry(
 			"Unary minus",
 			"-salary > -50000",
-			"SELECT name, city, state FROM users WHERE -(salary) > -(?)",
-			50000.0,
+			"SELECT name, city, state FROM users WHERE -(salary) > ?",
+			-50000.0,
+		),
+		Entry(
+			"Flattened AND",
+			"a = 1 and b = 2 and c = 3",
+			"SELECT name, city, state FROM users WHERE (a = ? AND b = ? AND c = ?)",
+			1.0, 2.0, 3.0,
+		),
+
+		Entry(
+			"Flattened OR inside AND",
+			"a = 1 and (b = 2 or c = 3 or d = 4)",
+			"SELECT name, city, state FROM users WHERE (a = ? AND (b = ? OR c = ? OR d = ?))",
+			1.0, 2.0, 3.0, 4.0,
+		),
+
+		Entry(
+			"Literal on the left side",
+			"18 <= age",
+			"SELECT name, city, state FROM users WHERE age >= ?",
+			18.0,
+		),
+
+		Entry(
+			"Negative number",
+			"balance < -100",
+			"SELECT name, city, state FROM users WHERE balance < ?",
+			-100.0,
+		),
+
+		Entry(
+			"Identifier to identifier comparison",
+			"salary > bonus",
+			"SELECT name, city, state FROM users WHERE salary > bonus",
+		),
+
+		Entry(
+			"Empty IN list",
+			"city IN []",
+			"SELECT name, city, state FROM users WHERE (1=0)",
 		),
+
+		Entry(
+			"Empty NOT IN list",
+			"city NOT IN []",
+			"SELECT name, city, state FROM users WHERE (1=1)",
+		),
+
+		Entry(
+			"IN list with identifiers",
+			"city IN ['rome', home_city]",
+			"SELECT name, city, state FROM users WHERE city IN (?,home_city)",
+			"rome",
+		),
+	)
+})
+
+var _ = Describe("Walk predicates", func() {
+	DescribeTable("Generates native squirrel predicates",
+		func(input string, expected sq.Sqlizer) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			filter, err := Walk(tree)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filter).To(Equal(expected))
+		},
+
+		Entry("equality", "name = 'joe'", sq.Eq{"name": "joe"}),
+		Entry("inequality", "name != 'joe'", sq.NotEq{"name": "joe"}),
+		Entry("less than", "age < 20", sq.Lt{"age": 20.0}),
+		Entry("greater or equal", "age >= 20", sq.GtOrEq{"age": 20.0}),
+		Entry("like", "name like 'j%'", sq.Like{"name": "j%"}),
+		Entry("not like", "name not like 'j%'", sq.NotLike{"name": "j%"}),
+		Entry("like with escape", `name like 'j\_%'`, sq.Expr("name LIKE ? ESCAPE ?", `j\_%`, `\`)),
+		Entry("in", "age in [1, 2]", sq.Eq{"age": []interface{}{1.0, 2.0}}),
+		Entry("empty in", "age in []", sq.Eq{"age": []interface{}{}}),
+		Entry("not in", "age not in [1, 2]", sq.NotEq{"age": []interface{}{1.0, 2.0}}),
+		Entry("is null", "age is null", sq.Eq{"age": nil}),
+		Entry("is not null", "age is not null", sq.NotEq{"age": nil}),
+		Entry("flattened and", "a = 1 and b = 2 and c = 3",
+			sq.And{sq.Eq{"a": 1.0}, sq.Eq{"b": 2.0}, sq.Eq{"c": 3.0}}),
+		Entry("flattened or", "a = 1 or (b = 2 or c = 3)",
+			sq.Or{sq.Eq{"a": 1.0}, sq.Eq{"b": 2.0}, sq.Eq{"c": 3.0}}),
+	)
+})
+
+var _ = Describe("WalkDialect", func() {
+	DescribeTable("Generates dialect specific SQL",
+		func(d Dialect, input string, expectedSQL string, expectedArgs ...interface{}) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			filter, err := WalkDialect(tree, d)
+			Expect(err).ToNot(HaveOccurred())
+
+			actualSQL, actualArgs, err := filter.ToSql()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actualSQL).To(Equal(expectedSQL))
+			Expect(actualArgs).To(Equal(expectedArgs))
+		},
+
+		Entry("sqlite ilike", DialectSQLite, "name ilike 'j%'", "LOWER(name) LIKE LOWER(?)", "j%"),
+		Entry("sqlite not ilike", DialectSQLite, "name not ilike 'j%'", "LOWER(name) NOT LIKE LOWER(?)", "j%"),
+		Entry("sqlite ilike with escape", DialectSQLite, `name ilike 'j\_%'`, "LOWER(name) LIKE LOWER(?) ESCAPE ?", `j\_%`, `\`),
+		Entry("sqlite ilike expression", DialectSQLite, "name ilike last", `LOWER(name) LIKE LOWER(last) ESCAPE ?`, `\`),
+		Entry("mysql ilike", DialectMySQL, "name ilike 'j%'", "LOWER(name) LIKE LOWER(?)", "j%"),
+		Entry("mysql regexp", DialectMySQL, "name ~= '^j'", "name REGEXP ?", "^j"),
+		Entry("mysql not regexp", DialectMySQL, "name ~! '^j'", "NOT (name REGEXP ?)", "^j"),
+		Entry("postgres ilike", DialectPostgres, "name ilike 'j%'", "name ILIKE ?", "j%"),
+		Entry("postgres regexp", DialectPostgres, "name ~= '^j'", "name ~ ?", "^j"),
+		Entry("postgres not regexp", DialectPostgres, "name ~! '^j'", "name !~ ?", "^j"),
+		Entry("postgres match", DialectPostgres, "description @@ 'fox'", "to_tsvector(description) @@ plainto_tsquery(?)", "fox"),
+	)
+
+	DescribeTable("Rejects operators the dialect can not express",
+		func(d Dialect, input string, expected error) {
+			tree, err := tsl.ParseTSL(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = WalkDialect(tree, d)
+			Expect(err).To(Equal(expected))
+		},
+
+		Entry("sqlite regexp", DialectSQLite, "name ~= '^j'", tsl.UnsupportedError{
+			Target: "SQLite", Expression: "name ~= '^j'", Reason: "SQLite has no REGEXP function by default"}),
+		Entry("sqlite match", DialectSQLite, "description @@ 'fox'", tsl.UnsupportedError{
+			Target: "SQLite", Expression: "description MATCH 'fox'", Reason: "full-text search is PostgreSQL specific"}),
+		Entry("mysql match", DialectMySQL, "description @@ 'fox'", tsl.UnsupportedError{
+			Target: "MySQL", Expression: "description MATCH 'fox'", Reason: "full-text search is PostgreSQL specific"}),
 	)
 })
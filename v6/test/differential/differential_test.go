@@ -0,0 +1,175 @@
+package differential
+
+import (
+	"testing"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+)
+
+func TestDifferential(t *testing.T) {
+	seeds := []int64{1, 2, 3, 4, 5}
+	queries := 400
+	if testing.Short() {
+		queries = 50
+	}
+
+	known := map[string]int{}
+	for _, seed := range seeds {
+		g := NewGenerator(seed, Config{MaxDepth: 3, NullComparisons: true, MixedTypes: true})
+		h, err := NewHarness(g.Dataset(40))
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+
+		for i := 0; i < queries; i++ {
+			query := g.Query()
+			tree, err := tsl.ParseTSL(query)
+			if err != nil {
+				t.Fatalf("seed %d: generated query %q does not parse: %v", seed, query, err)
+			}
+
+			m, err := h.Compare(tree)
+			if err != nil {
+				t.Errorf("seed %d: query %q: %v", seed, query, err)
+				continue
+			}
+			if m == nil {
+				continue
+			}
+
+			m, err = h.Minimize(tree, m)
+			if err != nil {
+				t.Errorf("seed %d: query %q: %v", seed, query, err)
+				continue
+			}
+
+			d, err := h.Classify(m)
+			switch {
+			case err != nil:
+				t.Errorf("seed %d: query %q: %v", seed, m.Query, err)
+			case d == nil:
+				t.Errorf("seed %d: walkers disagree\n%s", seed, m)
+			default:
+				known[d.Name]++
+			}
+		}
+		h.Close()
+	}
+
+	for name, count := range known {
+		t.Logf("%d mismatches of known divergence %s", count, name)
+	}
+}
+
+// classify minimizes the mismatch of a query on a single row
+func classify(t *testing.T, row Row, query string) (*Mismatch, *Divergence) {
+	t.Helper()
+
+	h, err := NewHarness([]Row{row})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	tree, err := tsl.ParseTSL(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := h.Compare(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil {
+		t.Fatalf("expected a mismatch on %q", query)
+	}
+
+	m, err = h.Minimize(tree, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := h.Classify(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, d
+}
+
+func TestMinimize(t *testing.T) {
+	row := NewGenerator(1, Config{}).Dataset(1)[0]
+	row["num"] = 1.0
+	row["qty"] = int64(1)
+	row["rating"] = nil
+
+	// Semantics compares null with two valued logic, SQL with three valued logic
+	m, d := classify(t, row, "num > -100 AND (NOT (rating = 2) OR qty > 100)")
+	if expected := "NOT (rating = 2)"; m.Query != expected {
+		t.Errorf("expected minimized query %q, got %q", expected, m.Query)
+	}
+	if d == nil || d.Name != "null-unknown" {
+		t.Errorf("expected known divergence null-unknown, got %v", d)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	row := NewGenerator(1, Config{}).Dataset(1)[0]
+	row["num"] = 1.0
+	row["name"] = "a"
+	row["rating"] = 2.0
+	row["label"] = "b"
+
+	tests := []struct {
+		query      string
+		divergence string
+	}{
+		{"num = '1'", "type-coercion"},
+		{"name > 1 OR flag", "type-coercion"},
+		{"NOT (rating = 'x') AND label < 1", "type-coercion"},
+	}
+
+	for _, tt := range tests {
+		m, d := classify(t, row, tt.query)
+		if d == nil || d.Name != tt.divergence {
+			t.Errorf("expected known divergence %s for %q, got %v", tt.divergence, m.Query, d)
+		}
+	}
+}
+
+func TestClassifyOnlyTheMixedComparison(t *testing.T) {
+	row := NewGenerator(1, Config{}).Dataset(1)[0]
+	row["num"] = 1.0
+	row["name"] = "a"
+
+	h, err := NewHarness([]Row{row})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	// A mixed type comparison next to the clause the walkers disagree on
+	// does not explain the mismatch
+	tree, err := tsl.ParseTSL("num = '1' AND name = 'a'")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := h.Classify(&Mismatch{Tree: tree, Query: tree.String(), ID: 1, Row: row})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != nil {
+		t.Errorf("expected no known divergence, got %s", d.Name)
+	}
+}
+
+func TestGeneratorDeterministic(t *testing.T) {
+	a := NewGenerator(7, Config{MaxDepth: 3})
+	b := NewGenerator(7, Config{MaxDepth: 3})
+
+	for i := 0; i < 20; i++ {
+		if qa, qb := a.Query(), b.Query(); qa != qb {
+			t.Fatalf("expected same queries for same seed, got %q and %q", qa, qb)
+		}
+	}
+}
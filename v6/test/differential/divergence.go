@@ -0,0 +1,121 @@
+package differential
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	walker "github.com/yaacov/tree-search-language/v6/pkg/walkers/sql"
+)
+
+// Divergence is a known difference between the semantics walker and SQL,
+// mismatches it explains are reported but do not fail the tests
+type Divergence struct {
+	// Name identifies the divergence in test output
+	Name string
+	// Description explains why the walkers disagree
+	Description string
+	// matches returns true if the divergence explains a minimized mismatch
+	matches func(h *Harness, m *Mismatch) (bool, error)
+}
+
+// KnownDivergences are the differences between the walkers that the
+// differential tests accept, checked in order
+var KnownDivergences = []Divergence{
+	{
+		Name: "null-unknown",
+		Description: "SQL evaluates comparisons with NULL to UNKNOWN, which neither the filter nor its " +
+			"negation select, the semantics walker uses two-valued logic and fails on null arithmetic",
+		matches: func(h *Harness, m *Mismatch) (bool, error) {
+			return h.unknown(m.Tree, m.ID)
+		},
+	},
+	{
+		Name: "type-coercion",
+		Description: "SQLite converts strings to numbers by column affinity and orders numbers before " +
+			"strings, the semantics walker never finds a number equal to a string and fails on ordering them",
+		matches: func(h *Harness, m *Mismatch) (bool, error) {
+			return isMixedComparison(m.Tree), nil
+		},
+	},
+}
+
+// Classify returns the known divergence that explains a minimized
+// mismatch, or nil if the mismatch is a bug
+func (h *Harness) Classify(m *Mismatch) (*Divergence, error) {
+	for i := range KnownDivergences {
+		ok, err := KnownDivergences[i].matches(h, m)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &KnownDivergences[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// unknown returns true if the database evaluates the tree to NULL on a row
+func (h *Harness) unknown(tree *tsl.TSLNode, id int) (bool, error) {
+	filter, err := walker.WalkDialect(tree, walker.DialectSQLite)
+	if err != nil {
+		return false, err
+	}
+	query, args, err := filter.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	ids, err := h.ids(sq.And{sq.Eq{"id": id}, sq.Expr(fmt.Sprintf("(%s) IS NULL", query), args...)})
+	if err != nil {
+		return false, err
+	}
+	return ids[id], nil
+}
+
+// operandType returns TypeNumber or TypeString for the operands of the
+// generated comparisons, columns, literals and arithmetic
+func operandType(n *tsl.TSLNode) (ColumnType, bool) {
+	switch n.Type() {
+	case tsl.KindNumericLiteral, tsl.KindBinaryExpr, tsl.KindUnaryExpr:
+		return TypeNumber, true
+	case tsl.KindStringLiteral:
+		return TypeString, true
+	case tsl.KindIdentifier:
+		name, _ := n.AsString()
+		for _, c := range Columns {
+			if c.Name != name {
+				continue
+			}
+			switch c.Type {
+			case TypeNumber, TypeInteger:
+				return TypeNumber, true
+			case TypeString:
+				return TypeString, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// isMixedComparison returns true if the tree is a comparison of a string
+// with a number, or the negation of one, so that a minimized mismatch is
+// only explained by the comparison itself and not by an unrelated clause
+func isMixedComparison(tree *tsl.TSLNode) bool {
+	op, ok := tree.AsExprOp()
+	for ok && op.Operator == tsl.OpNot {
+		op, ok = op.Right.AsExprOp()
+	}
+	if !ok {
+		return false
+	}
+
+	switch op.Operator {
+	case tsl.OpEQ, tsl.OpNE, tsl.OpLT, tsl.OpLE, tsl.OpGT, tsl.OpGE:
+		left, okLeft := operandType(op.Left)
+		right, okRight := operandType(op.Right)
+		return okLeft && okRight && left != right
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+package differential
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config selects the expressions the generator may produce
+type Config struct {
+	// MaxDepth limits the nesting of AND, OR and NOT operators
+	MaxDepth int
+	// NullComparisons allows comparing nullable columns with values, see
+	// the null-unknown known divergence
+	NullComparisons bool
+	// MixedTypes allows comparing string columns with numbers and number
+	// columns with strings, see the type-coercion known divergence
+	MixedTypes bool
+}
+
+// Generator creates random datasets and TSL queries
+type Generator struct {
+	rand   *rand.Rand
+	config Config
+}
+
+// NewGenerator creates a new generator, the same seed and config always
+// produce the same datasets and queries
+func NewGenerator(seed int64, config Config) *Generator {
+	return &Generator{
+		rand:   rand.New(rand.NewSource(seed)),
+		config: config,
+	}
+}
+
+// Value domains are small so that generated literals often hit stored values
+var (
+	numbers  = []float64{-3, -2, -1, -0.5, 0, 0.5, 1, 1.5, 2, 3}
+	integers = []int64{-3, -2, -1, 0, 1, 2, 3}
+	strs     = []string{"", "a", "b", "A", "ab", "ba", "abc", "a_c", "a%c", "bca", `a\c`, "a!c", "1", "2.5"}
+	patterns = []string{"a", "b", "c", "A", "!", "%", "_", `\%`, `\_`, `\\`}
+	baseDate = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func (g *Generator) pick(n int) int {
+	return g.rand.Intn(n)
+}
+
+func (g *Generator) chance(p float64) bool {
+	return g.rand.Float64() < p
+}
+
+// date returns one of a few days, at midnight or noon
+func (g *Generator) date() time.Time {
+	return baseDate.AddDate(0, 0, g.pick(5)).Add(time.Duration(g.pick(2)*12) * time.Hour)
+}
+
+// Dataset creates n random rows
+func (g *Generator) Dataset(n int) []Row {
+	rows := make([]Row, n)
+	for i := range rows {
+		row := Row{}
+		for _, c := range Columns {
+			if c.Nullable && g.chance(0.3) {
+				row[c.Name] = nil
+				continue
+			}
+			switch c.Type {
+			case TypeNumber:
+				row[c.Name] = numbers[g.pick(len(numbers))]
+			case TypeInteger:
+				row[c.Name] = integers[g.pick(len(integers))]
+			case TypeString:
+				row[c.Name] = strs[g.pick(len(strs))]
+			case TypeBoolean:
+				row[c.Name] = g.chance(0.5)
+			case TypeDate:
+				row[c.Name] = g.date()
+			}
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// column returns the name of a random column of one of the given types
+func (g *Generator) column(types ...ColumnType) string {
+	candidates := []string{}
+	for _, c := range Columns {
+		if c.Nullable && !g.config.NullComparisons {
+			continue
+		}
+		for _, t := range types {
+			if c.Type == t {
+				candidates = append(candidates, c.Name)
+			}
+		}
+	}
+	return candidates[g.pick(len(candidates))]
+}
+
+func (g *Generator) numberLiteral() string {
+	return strconv.FormatFloat(numbers[g.pick(len(numbers))], 'g', -1, 64)
+}
+
+func (g *Generator) stringLiteral(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func (g *Generator) dateLiteral() string {
+	d := g.date()
+	if d.Hour() == 0 && g.chance(0.5) {
+		return d.Format("2006-01-02")
+	}
+	return d.Format(time.RFC3339)
+}
+
+// list returns an array literal of up to three items
+func (g *Generator) list(item func() string) string {
+	items := make([]string, g.pick(4))
+	for i := range items {
+		items[i] = item()
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// comparison returns a random comparison operator
+func (g *Generator) comparison() string {
+	return []string{"=", "!=", "<", "<=", ">", ">="}[g.pick(6)]
+}
+
+// numericTerm returns a numeric column or a simple arithmetic expression,
+// divisors are non zero literals so that no row divides by zero
+func (g *Generator) numericTerm() string {
+	col := g.column(TypeNumber, TypeInteger)
+	switch g.pick(7) {
+	case 0:
+		return fmt.Sprintf("%s + %s", col, g.numberLiteral())
+	case 1:
+		return fmt.Sprintf("%s - %s", col, g.column(TypeNumber, TypeInteger))
+	case 2:
+		return fmt.Sprintf("%s * %s", col, g.numberLiteral())
+	case 3:
+		return fmt.Sprintf("%s / %d", col, 1+g.pick(3))
+	case 4:
+		return fmt.Sprintf("%s %% %d", col, 1+g.pick(3))
+	case 5:
+		return "-" + col
+	default:
+		return col
+	}
+}
+
+func (g *Generator) numericPredicate() string {
+	switch g.pick(6) {
+	case 0:
+		return fmt.Sprintf("%s %s %s", g.numberLiteral(), g.comparison(), g.column(TypeNumber, TypeInteger))
+	case 1:
+		return fmt.Sprintf("%s BETWEEN %s AND %s", g.numericTerm(), g.numberLiteral(), g.numberLiteral())
+	case 2:
+		not := []string{"", "NOT "}[g.pick(2)]
+		return fmt.Sprintf("%s %sIN %s", g.column(TypeNumber, TypeInteger), not, g.list(g.numberLiteral))
+	case 3:
+		return fmt.Sprintf("%s %s %s", g.numericTerm(), g.comparison(), g.column(TypeNumber, TypeInteger))
+	case 4:
+		if g.config.MixedTypes {
+			return fmt.Sprintf("%s %s %s", g.column(TypeNumber, TypeInteger), g.comparison(), g.stringLiteral(strs[g.pick(len(strs))]))
+		}
+		fallthrough
+	default:
+		return fmt.Sprintf("%s %s %s", g.numericTerm(), g.comparison(), g.numberLiteral())
+	}
+}
+
+func (g *Generator) pattern() string {
+	var b strings.Builder
+	for i := 0; i <= g.pick(3); i++ {
+		b.WriteString(patterns[g.pick(len(patterns))])
+	}
+	return b.String()
+}
+
+func (g *Generator) stringPredicate() string {
+	col := g.column(TypeString)
+	lit := func() string { return g.stringLiteral(strs[g.pick(len(strs))]) }
+
+	switch g.pick(6) {
+	case 0:
+		not := []string{"", "NOT "}[g.pick(2)]
+		return fmt.Sprintf("%s %sLIKE %s", col, not, g.stringLiteral(g.pattern()))
+	case 4:
+		not := []string{"", "NOT "}[g.pick(2)]
+		return fmt.Sprintf("%s %sILIKE %s", col, not, g.stringLiteral(g.pattern()))
+	case 1:
+		// A pattern using a custom escape character, "!!" matches "!"
+		pattern := strings.NewReplacer("!", "!!", `\`, "!").Replace(g.pattern())
+		return fmt.Sprintf("%s LIKE %s ESCAPE '!'", col, g.stringLiteral(pattern))
+	case 2:
+		not := []string{"", "NOT "}[g.pick(2)]
+		return fmt.Sprintf("%s %sIN %s", col, not, g.list(lit))
+	case 3:
+		if g.config.MixedTypes {
+			return fmt.Sprintf("%s %s %s", col, g.comparison(), g.numberLiteral())
+		}
+		fallthrough
+	default:
+		return fmt.Sprintf("%s %s %s", col, g.comparison(), lit())
+	}
+}
+
+func (g *Generator) booleanPredicate() string {
+	col := g.column(TypeBoolean)
+	switch g.pick(3) {
+	case 0:
+		return col
+	case 1:
+		return "NOT " + col
+	default:
+		return fmt.Sprintf("%s %s %s", col, []string{"=", "!="}[g.pick(2)], []string{"TRUE", "FALSE"}[g.pick(2)])
+	}
+}
+
+func (g *Generator) datePredicate() string {
+	col := g.column(TypeDate)
+	switch g.pick(4) {
+	case 0:
+		return fmt.Sprintf("%s BETWEEN %s AND %s", col, g.dateLiteral(), g.dateLiteral())
+	case 1:
+		return fmt.Sprintf("%s IN %s", col, g.list(g.dateLiteral))
+	default:
+		return fmt.Sprintf("%s %s %s", col, g.comparison(), g.dateLiteral())
+	}
+}
+
+func (g *Generator) nullPredicate() string {
+	candidates := []string{}
+	for _, c := range Columns {
+		if c.Nullable {
+			candidates = append(candidates, c.Name)
+		}
+	}
+	not := []string{"", "NOT "}[g.pick(2)]
+	return fmt.Sprintf("%s IS %sNULL", candidates[g.pick(len(candidates))], not)
+}
+
+func (g *Generator) predicate() string {
+	switch g.pick(5) {
+	case 0:
+		return g.numericPredicate()
+	case 1:
+		return g.stringPredicate()
+	case 2:
+		return g.booleanPredicate()
+	case 3:
+		return g.datePredicate()
+	default:
+		return g.nullPredicate()
+	}
+}
+
+func (g *Generator) expr(depth int) string {
+	if depth >= g.config.MaxDepth || g.chance(0.3) {
+		return g.predicate()
+	}
+
+	switch g.pick(3) {
+	case 0:
+		return fmt.Sprintf("NOT (%s)", g.expr(depth+1))
+	case 1:
+		return fmt.Sprintf("(%s) AND (%s)", g.expr(depth+1), g.expr(depth+1))
+	default:
+		return fmt.Sprintf("(%s) OR (%s)", g.expr(depth+1), g.expr(depth+1))
+	}
+}
+
+// Query creates a random TSL phrase
+func (g *Generator) Query() string {
+	return g.expr(0)
+}
@@ -0,0 +1,278 @@
+package differential
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/yaacov/tree-search-language/v6/pkg/tsl"
+	"github.com/yaacov/tree-search-language/v6/pkg/walkers/semantics"
+	walker "github.com/yaacov/tree-search-language/v6/pkg/walkers/sql"
+
+	// The harness compares against SQLite, registered as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// Harness evaluates TSL trees over a dataset both in memory, using the
+// semantics walker, and in an SQLite database, using the sql walker with
+// the SQLite dialect
+type Harness struct {
+	db   *sql.DB
+	rows []Row
+}
+
+// NewHarness creates an in memory database holding the rows
+func NewHarness(rows []Row) (*Harness, error) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// Each connection to ":memory:" opens a new empty database
+	db.SetMaxOpenConns(1)
+
+	h := &Harness{db: db, rows: rows}
+	if err := h.load(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// load creates the test table and inserts the rows, row i gets id i + 1
+func (h *Harness) load() error {
+	// TSL LIKE is case sensitive
+	if _, err := h.db.Exec("pragma case_sensitive_like = on"); err != nil {
+		return err
+	}
+	if _, err := h.db.Exec(createTableSQL()); err != nil {
+		return err
+	}
+
+	names := make([]string, len(Columns))
+	for i, c := range Columns {
+		names[i] = c.Name
+	}
+	for i, row := range h.rows {
+		values := []interface{}{i + 1}
+		for _, c := range Columns {
+			values = append(values, sqlValue(row[c.Name]))
+		}
+		query, args, err := sq.Insert(tableName).
+			Columns(append([]string{"id"}, names...)...).
+			Values(values...).
+			ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := h.db.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the database
+func (h *Harness) Close() error {
+	return h.db.Close()
+}
+
+// Mismatch describes a row on which the two walkers disagree
+type Mismatch struct {
+	Tree      *tsl.TSLNode
+	Query     string
+	ID        int
+	Row       Row
+	Memory    bool
+	Database  bool
+	MemoryErr error
+}
+
+// String returns a reproducer for the mismatch
+func (m Mismatch) String() string {
+	memory := fmt.Sprintf("%v", m.Memory)
+	if m.MemoryErr != nil {
+		memory = "error: " + m.MemoryErr.Error()
+	}
+	return fmt.Sprintf("query: %s\nrow %d: %s\nsemantics: %s, sql: %v", m.Query, m.ID, m.Row, memory, m.Database)
+}
+
+// Error returns the mismatch as an error message
+func (m Mismatch) Error() string {
+	return m.String()
+}
+
+// evaluate runs the semantics walker over one row
+func evaluate(tree *tsl.TSLNode, row Row) (bool, error) {
+	eval := func(name string) (interface{}, bool) {
+		v, ok := row[name]
+		return v, ok
+	}
+
+	result, err := semantics.Walk(tree, eval)
+	if err != nil {
+		return false, err
+	}
+	b, ok := result.(bool)
+	if !ok {
+		return false, tsl.TypeMismatchError{Expected: "boolean", Got: fmt.Sprintf("%T", result)}
+	}
+	return b, nil
+}
+
+// selected returns the ids of the rows matched in the database, extra
+// predicates are added to the where clause
+func (h *Harness) selected(tree *tsl.TSLNode, extra ...sq.Sqlizer) (map[int]bool, error) {
+	filter, err := walker.WalkDialect(tree, walker.DialectSQLite)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.ids(append(sq.And{filter}, extra...))
+}
+
+// ids returns the ids of the rows matching a where clause
+func (h *Harness) ids(where sq.Sqlizer) (map[int]bool, error) {
+	query, args, err := sq.Select("id").
+		From(tableName).
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", query, err)
+	}
+	defer rows.Close()
+
+	ids := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// Compare evaluates the tree with both walkers, it returns the first row
+// the walkers disagree on, or nil if they agree on all rows
+func (h *Harness) Compare(tree *tsl.TSLNode) (*Mismatch, error) {
+	ids, err := h.selected(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, row := range h.rows {
+		memory, memoryErr := evaluate(tree, row)
+		database := ids[i+1]
+		if memoryErr != nil || memory != database {
+			return &Mismatch{
+				Tree:      tree,
+				Query:     tree.String(),
+				ID:        i + 1,
+				Row:       row,
+				Memory:    memory,
+				Database:  database,
+				MemoryErr: memoryErr,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// compareRow evaluates the tree with both walkers on a single row
+func (h *Harness) compareRow(tree *tsl.TSLNode, id int) (*Mismatch, error) {
+	row := h.rows[id-1]
+	ids, err := h.selected(tree, sq.Eq{"id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	memory, memoryErr := evaluate(tree, row)
+	if memoryErr == nil && memory == ids[id] {
+		return nil, nil
+	}
+	return &Mismatch{
+		Tree:      tree,
+		Query:     tree.String(),
+		ID:        id,
+		Row:       row,
+		Memory:    memory,
+		Database:  ids[id],
+		MemoryErr: memoryErr,
+	}, nil
+}
+
+// shrinkCandidates returns smaller trees to try in place of n, the
+// boolean operands of AND, OR and NOT
+func shrinkCandidates(n *tsl.TSLNode) []*tsl.TSLNode {
+	op, ok := n.AsExprOp()
+	if !ok {
+		return nil
+	}
+
+	switch op.Operator {
+	case tsl.OpAnd, tsl.OpOr:
+		return []*tsl.TSLNode{op.Left, op.Right}
+	case tsl.OpNot:
+		if r, ok := op.Right.AsExprOp(); ok && isJunction(r.Operator) {
+			return []*tsl.TSLNode{op.Right}
+		}
+	}
+	return nil
+}
+
+func isJunction(op tsl.Operator) bool {
+	return op == tsl.OpAnd || op == tsl.OpOr || op == tsl.OpNot
+}
+
+// replacements returns copies of the tree where one junction node is
+// replaced by one of its operands
+func replacements(n *tsl.TSLNode) []*tsl.TSLNode {
+	out := shrinkCandidates(n)
+
+	op, ok := n.AsExprOp()
+	if !ok || !isJunction(op.Operator) {
+		return out
+	}
+
+	if op.Left != nil {
+		for _, left := range replacements(op.Left) {
+			c := n.Clone()
+			c.SetLeft(left)
+			out = append(out, c)
+		}
+	}
+	if op.Right != nil {
+		for _, right := range replacements(op.Right) {
+			c := n.Clone()
+			c.SetRight(right)
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Minimize greedily shrinks the tree of a mismatch while the walkers
+// still disagree on the same row, and returns the smallest mismatch found
+func (h *Harness) Minimize(tree *tsl.TSLNode, m *Mismatch) (*Mismatch, error) {
+	id := m.ID
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for _, candidate := range replacements(tree) {
+			found, err := h.compareRow(candidate, id)
+			if err != nil {
+				return nil, err
+			}
+			if found != nil {
+				tree, m, shrunk = candidate, found, true
+				break
+			}
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,103 @@
+package differential
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ColumnType is the type of the values stored in a column
+type ColumnType int
+
+const (
+	// TypeNumber columns hold float64 values, stored as SQL REAL
+	TypeNumber ColumnType = iota
+	// TypeInteger columns hold int64 values, stored as SQL INTEGER
+	TypeInteger
+	// TypeString columns hold string values, stored as SQL TEXT
+	TypeString
+	// TypeBoolean columns hold bool values, stored as SQL 0 / 1
+	TypeBoolean
+	// TypeDate columns hold UTC time.Time values, stored as SQL timestamp text
+	TypeDate
+)
+
+// Column describes one column of the test table
+type Column struct {
+	Name     string
+	Type     ColumnType
+	Nullable bool
+}
+
+// Columns are the columns of the test table
+var Columns = []Column{
+	{Name: "num", Type: TypeNumber},
+	{Name: "qty", Type: TypeInteger},
+	{Name: "name", Type: TypeString},
+	{Name: "flag", Type: TypeBoolean},
+	{Name: "created", Type: TypeDate},
+	{Name: "rating", Type: TypeNumber, Nullable: true},
+	{Name: "label", Type: TypeString, Nullable: true},
+}
+
+// tableName is the name of the test table
+const tableName = "records"
+
+// sqlTimeFormat is the timestamp format used by the sql walker
+const sqlTimeFormat = "2006-01-02 15:04:05"
+
+// Row is one record of the dataset keyed by column name, values are
+// float64, int64, string, bool, time.Time or nil
+type Row map[string]interface{}
+
+// String returns the row values ordered by column
+func (r Row) String() string {
+	parts := make([]string, len(Columns))
+	for i, c := range Columns {
+		v := r[c.Name]
+		switch x := v.(type) {
+		case string:
+			v = fmt.Sprintf("%q", x)
+		case time.Time:
+			v = x.Format(time.RFC3339)
+		}
+		parts[i] = fmt.Sprintf("%s: %v", c.Name, v)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// sqlType returns the SQL column type
+func (c Column) sqlType() string {
+	switch c.Type {
+	case TypeNumber:
+		return "real"
+	case TypeInteger, TypeBoolean:
+		return "integer"
+	default:
+		return "text"
+	}
+}
+
+// createTableSQL returns the statement creating the test table
+func createTableSQL() string {
+	defs := []string{"id integer not null primary key"}
+	for _, c := range Columns {
+		defs = append(defs, c.Name+" "+c.sqlType())
+	}
+	return fmt.Sprintf("create table %s (%s)", tableName, strings.Join(defs, ", "))
+}
+
+// sqlValue converts a row value into the value stored in the database
+func sqlValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case bool:
+		if x {
+			return 1
+		}
+		return 0
+	case time.Time:
+		return x.UTC().Format(sqlTimeFormat)
+	default:
+		return v
+	}
+}